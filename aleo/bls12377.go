@@ -1,97 +1,115 @@
 package aleo
 
 import (
-	"encoding/binary"
+	"bufio"
 	"fmt"
 	"io"
-	"os"
 	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/consensys/gnark-crypto/ecc/bls12-377"
 	"github.com/consensys/gnark-crypto/ecc/bls12-377/fp"
 	blsKzg "github.com/consensys/gnark-crypto/ecc/bls12-377/kzg"
 	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/audit"
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/offheap"
+	"linea/aztec-srs-to-gnark/parallel"
+	"linea/aztec-srs-to-gnark/verify"
 )
 
-func readG1SetupFile(path string, srs *blsKzg.SRS) error {
-	file, err := os.Open(path)
-	if err != nil {
-		return fmt.Errorf("failed to open setup file: %w", err)
-	}
-	defer file.Close()
+const (
+	// readBufferSize is the size of the buffer used to read a single setup file.
+	readBufferSize = 4 << 20
+	// g1ReadBatch is the number of G1 points read from a setup file at once.
+	g1ReadBatch = 1 << 12
+	// g1PointSize is the size of an encoded G1 point.
+	g1PointSize = 2 * fp.Bytes
+	// pointsNumberSize is the size of the number of points heading a G1 setup file.
+	pointsNumberSize = 8
+	// g2PointSize is the size of the τG2 point of the G2 setup file.
+	g2PointSize = 2 * g1PointSize
+	// setupFileExt is the extension of the setup files, followed by a
+	// checksum in the names of the remote ones.
+	setupFileExt = ".usrs"
+)
 
-	var Nbuffer [8]byte
-	if _, err = io.ReadFull(file, Nbuffer[:]); err != nil {
-		return fmt.Errorf("failed to read number of points: %w", err)
+// Kinds of the setup files, see classify.
+const (
+	kindG1 = "g1"
+	kindG2 = "g2"
+)
+
+// readPointsNumber reads the number of points heading the G1 setup file and
+// checks it against the size of the file.
+func readPointsNumber(r io.Reader, size int64) (int, error) {
+	var header [pointsNumberSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, fmt.Errorf("failed to read number of points: %w", err)
 	}
-	pointsN := binary.LittleEndian.Uint64(Nbuffer[:])
 
-	if err = readG1Points(file, pointsN, srs); err != nil {
-		return fmt.Errorf("failed to read G1 points: %w", err)
+	return checkPointsNumber(header, size)
+}
+
+// filePointsNumber returns the number of G1 points a setup file of the size
+// holds, for the files skipped before their header is read.
+func filePointsNumber(size int64) int {
+	if size < pointsNumberSize {
+		return 0
 	}
 
-	return nil
+	return int((size - pointsNumberSize) / g1PointSize)
 }
 
-func readG1Points(r io.Reader, n uint64, srs *blsKzg.SRS) error {
-	for i := uint64(0); i < n; i++ {
-		x, err := extract48ByteFieldElement(r)
-		if err != nil {
-			return fmt.Errorf("failed to read x-coordinate: %w", err)
-		}
+// readG1Points reads the points in batches into a single reusable buffer; every
+// decoded batch is passed to the verification checks. The points start at
+// index offset of the SRS, their coordinates are in the byte order.
+func readG1Points(r io.Reader, order fp.ByteOrder, points []bls12377.G1Affine, offset int, checks *verify.Pipeline[bls12377.G1Affine]) error {
+	buf := make([]byte, g1ReadBatch*g1PointSize)
 
-		y, err := extract48ByteFieldElement(r)
-		if err != nil {
-			return fmt.Errorf("failed to read y-coordinate: %w", err)
-		}
+	for from := 0; from < len(points); from += g1ReadBatch {
+		batch := points[from:min(from+g1ReadBatch, len(points))]
+		data := buf[:len(batch)*g1PointSize]
 
-		point := bls12377.G1Affine{
-			X: x,
-			Y: y,
+		if _, err := io.ReadFull(r, data); err != nil {
+			return fmt.Errorf("failed to read points %d-%d: %w", from, from+len(batch)-1, err)
 		}
 
-		srs.Pk.G1 = append(srs.Pk.G1, point)
+		for i := range batch {
+			point := data[i*g1PointSize : (i+1)*g1PointSize]
 
-		if len(srs.Pk.G1) == 2 {
-			fmt.Printf("> a^1*G1: %s %s\n", srs.Pk.G1[1].X.String(), srs.Pk.G1[1].Y.String())
+			if err := decodeCoordinates(order, point, &batch[i].X, &batch[i].Y); err != nil {
+				return fmt.Errorf("invalid point %d: %w", from+i, err)
+			}
 		}
+
+		checks.Check(offset+from, batch)
 	}
 
 	return nil
 }
 
-func readG2SetupFile(path string, srs *blsKzg.SRS) error {
-	file, err := os.Open(path)
-	if err != nil {
-		return fmt.Errorf("failed to open setup file: %w", err)
+// readG2SetupFile reads τG2, x.c0, x.c1, y.c0 and y.c1 in the byte order
+// putting it on the curve.
+func readG2SetupFile(file io.Reader, srs *blsKzg.SRS) error {
+	var data [g2PointSize]byte
+	if _, err := io.ReadFull(file, data[:]); err != nil {
+		return fmt.Errorf("failed to read τG2: %w", err)
 	}
-	defer file.Close()
 
-	x1, err := extract48ByteFieldElement(file)
+	point, order, err := decodeG2Point(data[:])
 	if err != nil {
-		return fmt.Errorf("failed to read x-coordinate c0: %w", err)
+		return err
 	}
-
-	x2, err := extract48ByteFieldElement(file)
-	if err != nil {
-		return fmt.Errorf("failed to read x-coordinate c1: %w", err)
+	if order != fp.LittleEndian {
+		fmt.Printf("> τG2 has %s coordinates\n", order)
 	}
 
-	y1, err := extract48ByteFieldElement(file)
-	if err != nil {
-		return fmt.Errorf("failed to read y-coordinate c0: %w", err)
-	}
-
-	y2, err := extract48ByteFieldElement(file)
-	if err != nil {
-		return fmt.Errorf("failed to read y-coordinate c1: %w", err)
-	}
-
-	srs.Vk.G2[1] = bls12377.G2Affine{
-		X: bls12377.E2{A0: x1, A1: x2},
-		Y: bls12377.E2{A0: y1, A1: y2},
-	}
+	srs.Vk.G2[1] = point
 
 	fmt.Printf("> a^1*G2: %s %s\n", srs.Vk.G2[1].X.String(), srs.Vk.G2[1].Y.String())
 
@@ -99,44 +117,279 @@ func readG2SetupFile(path string, srs *blsKzg.SRS) error {
 }
 
 // TranslateBls12377SRS reads all the bls12377 setup files and constructs KZG SRS from them.
-func TranslateBls12377SRS(setupDir string) (kzg.SRS, int, error) {
-	files, err := os.ReadDir(setupDir)
+// The files are classified by their sizes and the points of the G1 setup files
+// are concatenated in the order of the files: by name for a directory, the one
+// of the manifest when it pins them. With opts.Degree only the files holding
+// the first 2^opts.Degree points are read, see setupFiles. The files are opened
+// in order; the points of up to opts.IOParallelism of them are read at the same
+// time. The other files are skipped, but the setup files of neither size and
+// the G1 setup files that can't be read are rejected with opts.Reject: under
+// the lenient validation the SRS ends before the first points missing, but the
+// G2 setup file can't be skipped.
+func TranslateBls12377SRS(files []input.File, opts config.Options) (kzg.SRS, int, error) {
+	files = slices.Clone(files)
+	for i := 0; i < len(files); i++ {
+		if kind, err := classify(files[i]); err != nil || kind != "" {
+			continue
+		}
+		file := files[i]
+		if strings.Contains(file.Name, setupFileExt) {
+			// A truncated G1 setup file, whose points would be taken by the next ones
+			err := fmt.Errorf("setup file %s of %d bytes holds neither τG2 nor G1 points", file.Name, file.Size)
+			if err = opts.Reject(config.Skip{File: file.Name}, err); err != nil {
+				return nil, 0, err
+			}
+			opts.Audit.Record(audit.Event{Kind: audit.KindSetupFile, File: file.Name, Decision: "ignored: malformed setup file"})
+		} else {
+			opts.Ignore(file.Name, "not a setup file")
+			opts.Audit.Record(audit.Event{Kind: audit.KindSetupFile, File: file.Name, Decision: "ignored: not a setup file"})
+		}
+		files = slices.Delete(files, i, i+1)
+		i--
+	}
+
+	files, err := setupFiles(files, opts.Degree, func(file input.File, reason string) {
+		fmt.Printf("Skipping %s: %s\n", file.Name, reason)
+		opts.Audit.Record(audit.Event{Kind: audit.KindSetupFile, File: file.Name, Decision: "ignored: " + reason})
+	})
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to read setup directory '%s': %w", setupDir, err)
+		return nil, 0, err
+	}
+
+	// The file sizes give the number of points, so the storage is allocated once.
+	maxPointsN := 1
+	for _, file := range files {
+		if kind, _ := classify(file); kind == kindG1 {
+			maxPointsN += int((file.Size - pointsNumberSize) / g1PointSize)
+		}
 	}
 
 	_, _, gen1Aff, gen2Aff := bls12377.Generators()
 
 	srs := new(blsKzg.SRS)
 
-	srs.Pk.G1 = make([]bls12377.G1Affine, 1)
+	srs.Pk.G1, err = offheap.Make[bls12377.G1Affine](maxPointsN, opts)
+	if err != nil {
+		return nil, 0, err
+	}
 	srs.Pk.G1[0] = gen1Aff
 	srs.Vk.G1 = gen1Aff
 	srs.Vk.G2[0] = gen2Aff
+	opts.Progress.SetTotal(len(srs.Pk.G1))
+	opts.Progress.Add(1)
+
+	var (
+		checker *verify.Bls12377Checker
+		checks  *verify.Pipeline[bls12377.G1Affine]
+	)
+	if opts.Verify {
+		if checker, err = verify.NewBls12377Checker(); err != nil {
+			return nil, 0, err
+		}
+		checks = verify.NewPipeline(checker.Batch, opts.Workers)
+		checks.Check(0, srs.Pk.G1[:1])
+	}
 
-	// Sort files by name.
-	slices.SortFunc(files, func(i os.DirEntry, j os.DirEntry) int {
-		return strings.Compare(strings.ToLower(i.Name()), strings.ToLower(j.Name()))
-	})
+	var numProcessed atomic.Int32
 
-	numProcessed := 0
-	for i, file := range files {
-		fileName := file.Name()
-		filePath := fmt.Sprintf("%s/%s", setupDir, fileName)
+	group := parallel.NewGroup(opts.IOParallelism)
 
-		fmt.Printf("Processing file %s\n", fileName)
+	// The G1 setup files placed, in the order of their points
+	type placement struct {
+		name               string
+		index, from, count int
+	}
+	var (
+		placements []placement
+		// The first point missing under the lenient validation, the SRS ends
+		// before it; -1 while none is.
+		missingMu   sync.Mutex
+		missingFrom = -1
+	)
+	miss := func(from int) {
+		missingMu.Lock()
+		defer missingMu.Unlock()
+		if missingFrom < 0 || from < missingFrom {
+			missingFrom = from
+		}
+	}
+	missing := func() int {
+		missingMu.Lock()
+		defer missingMu.Unlock()
+		return missingFrom
+	}
 
-		if strings.Contains(strings.ToLower(fileName), "g2") {
-			err = readG2SetupFile(filePath, srs)
-		} else {
-			err = readG1SetupFile(filePath, srs)
+	// The generator is the first point
+	offset := 1
+	for index, file := range files {
+		if group.Err() != nil {
+			break
 		}
+
+		kind, _ := classify(file)
+		if from := missing(); kind == kindG1 && from >= 0 {
+			pointsN := filePointsNumber(file.Size)
+			opts.Skipped.Add(config.Skip{File: file.Name, Index: audit.Index(index), Offset: audit.Index(offset), Points: pointsN, Reason: fmt.Sprintf("follows the G1 points missing from %d", from)})
+			offset += pointsN
+			continue
+		}
+
+		fmt.Printf("Processing file %s\n", file.Name)
+
+		f, err := file.Open()
 		if err != nil {
-			return nil, 0, fmt.Errorf("failed to read setup file: %w", err)
+			group.Wait()
+			return nil, 0, fmt.Errorf("failed to open setup file %s: %w", file.Name, err)
+		}
+
+		r := bufio.NewReaderSize(f, readBufferSize)
+
+		var (
+			read  func() error
+			event = audit.Event{Kind: audit.KindSetupFile, File: file.Name, Decision: "used"}
+		)
+		// reject applies the validation policy to the G1 setup file of
+		// pointsN points, closing it: the SRS ends before its points.
+		reject := func(pointsN int, err error) error {
+			f.Close()
+			skip := config.Skip{File: file.Name, Index: audit.Index(index), Offset: audit.Index(offset), Points: pointsN}
+			if rejectErr := opts.Reject(skip, err); rejectErr != nil {
+				group.Wait()
+				return rejectErr
+			}
+			opts.Audit.Record(audit.Event{Kind: audit.KindSetupFile, File: file.Name, Index: skip.Index, Decision: fmt.Sprintf("ignored: %v", err)})
+			miss(offset)
+			offset += pointsN
+			return nil
+		}
+
+		if kind == kindG2 {
+			read = func() error {
+				return readG2SetupFile(r, srs)
+			}
+			event.Decision = "used for τG2"
+		} else {
+			pointsN, err := readPointsNumber(r, file.Size)
+			if err != nil {
+				if err = reject(filePointsNumber(file.Size), fmt.Errorf("failed to read header of %s: %w", file.Name, err)); err != nil {
+					return nil, 0, err
+				}
+				continue
+			}
+
+			if offset+pointsN <= opts.Extend {
+				f.Close()
+				fmt.Printf("Skipping %s: its G1 points %d-%d are in the extended SRS\n", file.Name, offset, offset+pointsN-1)
+				opts.Audit.Record(audit.Event{Kind: audit.KindSetupFile, File: file.Name, Index: audit.Index(index), Offset: offset, Points: pointsN, Decision: "covered by the extended SRS"})
+				opts.Progress.Add(pointsN)
+				placements = append(placements, placement{file.Name, index, offset, pointsN})
+				offset += pointsN
+				continue
+			}
+
+			// The byte order of the coordinates is the one putting the first
+			// point on the curve.
+			order := fp.ByteOrder(fp.LittleEndian)
+			if pointsN > 0 {
+				first, err := r.Peek(g1PointSize)
+				if err == nil {
+					order, err = detectG1ByteOrder(first)
+				}
+				if err != nil {
+					if err = reject(pointsN, fmt.Errorf("failed to read setup file %s: %w", file.Name, err)); err != nil {
+						return nil, 0, err
+					}
+					continue
+				}
+				if order != fp.LittleEndian {
+					fmt.Printf("> %s has %s coordinates\n", file.Name, order)
+				}
+			}
+
+			points, pointsOffset := srs.Pk.G1[offset:offset+pointsN], offset
+			offset += pointsN
+			event.Offset, event.Points = pointsOffset, len(points)
+			placements = append(placements, placement{file.Name, index, pointsOffset, pointsN})
+
+			read = func() error {
+				if err := readG1Points(r, order, points, pointsOffset, checks); err != nil {
+					return fmt.Errorf("failed to read G1 points: %w", err)
+				}
+				return nil
+			}
 		}
 
-		fmt.Printf("Processed setup files %d/%d\n", i+1, len(files))
-		numProcessed++
+		group.Go(func() error {
+			defer f.Close()
+
+			if err := read(); err != nil {
+				err = fmt.Errorf("failed to read setup file %s: %w", file.Name, err)
+				// Without τG2 there is no verifying key to skip to.
+				if kind == kindG2 {
+					return err
+				}
+				skip := config.Skip{File: file.Name, Index: audit.Index(index), Offset: audit.Index(event.Offset), Points: event.Points}
+				decision := "dropped: " + err.Error()
+				if err = opts.Reject(skip, err); err != nil {
+					return err
+				}
+				event.Decision = decision
+				opts.Audit.Record(event)
+				miss(event.Offset)
+				return nil
+			}
+			opts.Audit.Record(event)
+			opts.Progress.Add(event.Points)
+
+			fmt.Printf("Processed setup files %d/%d\n", numProcessed.Add(1), len(files))
+
+			return nil
+		})
+	}
+
+	if err = group.Wait(); err != nil {
+		return nil, 0, err
+	}
+
+	// The points are consecutive powers of τ, the SRS ends before the first
+	// ones missing.
+	if missingFrom >= 0 {
+		for _, p := range placements {
+			if p.from > missingFrom {
+				opts.Skipped.Add(config.Skip{File: p.name, Index: audit.Index(p.index), Offset: audit.Index(p.from), Points: p.count, Reason: fmt.Sprintf("follows the G1 points missing from %d", missingFrom)})
+			}
+		}
+		if missingFrom == 1 {
+			return nil, 0, fmt.Errorf("no G1 points left in the setup files")
+		}
+		opts.Warn("G1 points from %d are missing: the SRS ends at G1 point %d", missingFrom, missingFrom-1)
+		offset = missingFrom
+	}
+	srs.Pk.G1 = srs.Pk.G1[:offset]
+
+	if len(srs.Pk.G1) > 1 && opts.Extend <= 1 {
+		fmt.Printf("> a^1*G1: %s %s\n", srs.Pk.G1[1].X.String(), srs.Pk.G1[1].Y.String())
+	}
+
+	if checker != nil {
+		err = checks.Wait()
+		switch {
+		case missingFrom >= 0:
+			// The points skipped were checked too, so the fused checks don't apply
+			err = verify.SRS(srs, opts)
+		case err == nil:
+			err = checker.Finish(srs)
+		}
+		// Under the lenient validation the SRS ends before a malformed point
+		if err = verify.Recover(srs, opts, err); err != nil {
+			return nil, 0, fmt.Errorf("%w: %w", verify.ErrFailed, err)
+		}
+		fmt.Println("SRS verified: all G1 points are in the subgroup and are consecutive powers of tau")
+	}
+
+	// The last file read may hold more points than requested
+	if opts.Degree > 0 {
+		srs.Pk.G1 = srs.Pk.G1[:min(len(srs.Pk.G1), 1<<opts.Degree)]
 	}
 
 	// Precompute the lines when the G2 points are set
@@ -146,17 +399,19 @@ func TranslateBls12377SRS(setupDir string) (kzg.SRS, int, error) {
 	return srs, len(srs.Pk.G1), nil
 }
 
-// Extracts a 396-bit integer (48 bytes) stored in little-endian order.
-func extract48ByteFieldElement(r io.Reader) (result fp.Element, err error) {
-	var buf [48]byte
-	if _, err = io.ReadFull(r, buf[:]); err != nil {
-		return result, fmt.Errorf("failed to read 48 bytes: %w", err)
-	}
-
-	result, err = fp.LittleEndian.Element(&buf)
-	if err != nil {
-		return result, fmt.Errorf("failed to convert bytes to field element: %w", err)
+// classify tells the setup files apart by their sizes, whatever their names:
+// the G2 setup file holds τG2 alone and a G1 setup file holds the number of its
+// points followed by them, which sizes never coincide. It returns "" for the
+// other files.
+func classify(file input.File) (string, error) {
+	switch {
+	case file.Size == input.UnknownSize:
+		return "", fmt.Errorf("size of setup file %s, which tells the G1 from the G2 setup files, is unknown: decompress it first if it is compressed", file.Name)
+	case file.Size == g2PointSize:
+		return kindG2, nil
+	case file.Size >= pointsNumberSize && (file.Size-pointsNumberSize)%g1PointSize == 0:
+		return kindG1, nil
 	}
 
-	return result, nil
+	return "", nil
 }
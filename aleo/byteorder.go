@@ -0,0 +1,65 @@
+package aleo
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fp"
+)
+
+// byteOrders are the byte orders of the coordinates of the setup files, in the
+// order they are tried: snarkVM writes little endian coordinates, some tools
+// re-serialize them big endian.
+var byteOrders = []fp.ByteOrder{fp.LittleEndian, fp.BigEndian}
+
+// detectG1ByteOrder returns the byte order the coordinates of the encoded G1
+// point are in, the one decoding them into a point on the curve.
+func detectG1ByteOrder(point []byte) (fp.ByteOrder, error) {
+	for _, order := range byteOrders {
+		var p bls12377.G1Affine
+		if err := decodeCoordinates(order, point, &p.X, &p.Y); err == nil && p.IsOnCurve() {
+			return order, nil
+		}
+	}
+
+	return nil, errors.New("the first G1 point isn't on the curve in any byte order")
+}
+
+// decodeG2Point decodes the τG2 point of the G2 setup file, in the byte order
+// putting it on the curve.
+func decodeG2Point(point []byte) (bls12377.G2Affine, fp.ByteOrder, error) {
+	for _, order := range byteOrders {
+		var p bls12377.G2Affine
+		if err := decodeCoordinates(order, point, &p.X.A0, &p.X.A1, &p.Y.A0, &p.Y.A1); err == nil && p.IsOnCurve() {
+			return p, order, nil
+		}
+	}
+
+	return bls12377.G2Affine{}, nil, errors.New("τG2 isn't on the curve in any byte order")
+}
+
+// decodeCoordinates decodes consecutive coordinates of fp.Bytes bytes.
+func decodeCoordinates(order fp.ByteOrder, data []byte, coordinates ...*fp.Element) error {
+	for i, c := range coordinates {
+		var err error
+		if *c, err = order.Element((*[fp.Bytes]byte)(data[i*fp.Bytes : (i+1)*fp.Bytes])); err != nil {
+			return fmt.Errorf("invalid coordinate %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// checkPointsNumber checks the number of points heading a G1 setup file against
+// the size of the file. It is little endian in the files of snarkVM, and big
+// endian in the re-serialized ones.
+func checkPointsNumber(header [pointsNumberSize]byte, size int64) (int, error) {
+	expected := uint64((size - pointsNumberSize) / g1PointSize)
+	if binary.LittleEndian.Uint64(header[:]) != expected && binary.BigEndian.Uint64(header[:]) != expected {
+		return 0, fmt.Errorf("the header announces %d points, the size of %d bytes holds %d", binary.LittleEndian.Uint64(header[:]), size, expected)
+	}
+
+	return int(expected), nil
+}
@@ -0,0 +1,86 @@
+package aleo
+
+import (
+	"context"
+	"fmt"
+
+	"linea/aztec-srs-to-gnark/digest"
+	"linea/aztec-srs-to-gnark/fetch"
+)
+
+const (
+	// ResourcesURL is the snarkVM directory holding the setup files shipped with
+	// it and the metadata of all the setup files.
+	ResourcesURL = "https://raw.githubusercontent.com/ProvableHQ/snarkVM/82f1dbbf255a3b34d3732f395597a30276227966/parameters/src/mainnet/resources/"
+	// RemoteURL is the storage of the setup files too large to be shipped with snarkVM.
+	RemoteURL = "https://parameters.provable.com/mainnet/"
+
+	// MinDegree is the degree of the powers shipped with snarkVM, the larger ones
+	// are split into a file per degree.
+	MinDegree = 15
+	// MaxDegree is the largest degree of the ceremony.
+	MaxDegree = 28
+)
+
+// metadata is the description snarkVM keeps next to every setup file.
+type metadata struct {
+	Checksum string `json:"checksum"`
+	Size     int64  `json:"size"`
+}
+
+// Downloads lists the setup files holding the powers up to 2^sel.Degree, all
+// of them when the degree isn't set, and g2^tau. The files are named the way
+// TranslateBls12377SRS expects them and are checked against the SHA256 digest
+// published in their snarkVM metadata.
+func Downloads(ctx context.Context, sel fetch.Selection) ([]fetch.Download, error) {
+	degree := sel.Degree
+	if degree == 0 {
+		degree = MaxDegree
+	}
+	if degree > MaxDegree {
+		return nil, fmt.Errorf("the ceremony has less than 2^%d points", degree)
+	}
+
+	// The file holding g2^tau comes first, the importer recognizes it by its size.
+	g2, err := setupFile(ctx, "beta-h", "g2-beta-h.usrs", false)
+	if err != nil {
+		return nil, err
+	}
+
+	downloads := []fetch.Download{g2}
+	for d := MinDegree; d <= max(degree, MinDegree); d++ {
+		name := fmt.Sprintf("powers-of-beta-%d", d)
+
+		file, err := setupFile(ctx, name, name+".usrs", d > MinDegree)
+		if err != nil {
+			return nil, err
+		}
+
+		downloads = append(downloads, file)
+	}
+
+	return downloads, nil
+}
+
+// setupFile describes the setup file from its metadata. The remote files are
+// stored under their name suffixed with the beginning of their checksum.
+func setupFile(ctx context.Context, name, fileName string, remote bool) (fetch.Download, error) {
+	var meta metadata
+	if err := fetch.JSON(ctx, ResourcesURL+name+".metadata", &meta); err != nil {
+		return fetch.Download{}, fmt.Errorf("failed to get metadata of %s: %w", name, err)
+	}
+
+	url := ResourcesURL + name + ".usrs"
+	if remote {
+		if len(meta.Checksum) < 7 {
+			return fetch.Download{}, fmt.Errorf("invalid checksum '%s' of %s", meta.Checksum, name)
+		}
+		url = RemoteURL + name + ".usrs." + meta.Checksum[:7]
+	}
+
+	return fetch.Download{
+		URL:    url,
+		Name:   fileName,
+		Verify: fetch.VerifyDigest(digest.SHA256, []string{meta.Checksum}, meta.Size),
+	}, nil
+}
@@ -0,0 +1,46 @@
+package aleo
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377"
+	blsKzg "github.com/consensys/gnark-crypto/ecc/bls12-377/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/input"
+)
+
+// ExtractTauG2 returns an SRS with only the verifying key, read from the G2
+// setup file, recognized by its size. The G1 setup files aren't opened.
+func ExtractTauG2(files []input.File) (kzg.SRS, error) {
+	for _, file := range files {
+		kind, err := classify(file)
+		if err != nil {
+			return nil, err
+		}
+		if kind != kindG2 {
+			continue
+		}
+
+		f, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open setup file %s: %w", file.Name, err)
+		}
+		defer f.Close()
+
+		_, _, gen1Aff, gen2Aff := bls12377.Generators()
+
+		srs := new(blsKzg.SRS)
+		srs.Vk.G1 = gen1Aff
+		srs.Vk.G2[0] = gen2Aff
+		if err = readG2SetupFile(f, srs); err != nil {
+			return nil, fmt.Errorf("failed to read setup file %s: %w", file.Name, err)
+		}
+		srs.Vk.Lines[0] = bls12377.PrecomputeLines(srs.Vk.G2[0])
+		srs.Vk.Lines[1] = bls12377.PrecomputeLines(srs.Vk.G2[1])
+
+		return srs, nil
+	}
+
+	return nil, fmt.Errorf("no G2 setup file found")
+}
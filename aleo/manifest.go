@@ -0,0 +1,60 @@
+package aleo
+
+import (
+	"fmt"
+
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/manifest"
+)
+
+// Describe describes the setup files classified by their sizes: the G1 setup
+// files from their headers, with the number of points they announce and their
+// position in the SRS, and the G2 setup file. The files the importer skips,
+// like the standalone setups of lower degrees, are ignored.
+func Describe(files []input.File, descs []manifest.File) error {
+	ignored := make(map[string]bool)
+	if _, err := setupFiles(files, 0, func(file input.File, _ string) { ignored[file.Name] = true }); err != nil {
+		return err
+	}
+
+	position := 0
+	for i, file := range files {
+		desc := &descs[i]
+
+		kind, _ := classify(file)
+		switch {
+		case ignored[file.Name]:
+			desc.Kind = manifest.KindIgnored
+			continue
+		case kind == kindG2:
+			desc.Kind = kindG2
+			desc.Sections = []manifest.Section{{Name: "tau_g2", Offset: 0, Size: g2PointSize, Points: 1}}
+			continue
+		}
+
+		f, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open setup file %s: %w", file.Name, err)
+		}
+		pointsN, err := readPointsNumber(f, file.Size)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read header of %s: %w", file.Name, err)
+		}
+		size := int64(pointsN) * g1PointSize
+
+		// The G1 setup files are concatenated in the order of the manifest.
+		index := position
+		position++
+
+		desc.Kind = kindG1
+		desc.Index = &index
+		desc.Points = pointsN
+		desc.Sections = []manifest.Section{
+			{Name: "header", Offset: 0, Size: pointsNumberSize},
+			{Name: "tau_g1", Offset: pointsNumberSize, Size: size, Points: pointsN},
+		}
+	}
+
+	return nil
+}
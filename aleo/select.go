@@ -0,0 +1,138 @@
+package aleo
+
+import (
+	"fmt"
+	"io"
+	"slices"
+
+	"linea/aztec-srs-to-gnark/input"
+)
+
+// setupFiles classifies the files and selects the ones the SRS is read from,
+// in their order: the G2 setup file and the G1 setup files holding the powers
+// up to 2^degree, all of them when degree is 0. The other files are passed to
+// skipped with the reason.
+//
+// A directory may hold the pieces of a setup split by degree, as snarkVM
+// ships it, which are stitched in order, only the first ones holding the
+// powers needed. It may also hold standalone setups of different degrees,
+// which all start with τ¹·G1: the smallest one holding the powers needed, or
+// the largest one, is selected.
+func setupFiles(files []input.File, degree int, skipped func(file input.File, reason string)) ([]input.File, error) {
+	var (
+		used, g1 []input.File
+		g2File   string
+		firsts   = make(map[string][]int)
+	)
+	for _, file := range files {
+		kind, err := classify(file)
+		switch {
+		case err != nil:
+			return nil, err
+		case kind == "":
+			skipped(file, "not a setup file")
+		case kind == kindG2 && g2File != "":
+			return nil, fmt.Errorf("setup files %s and %s both have the size of the G2 setup file", g2File, file.Name)
+		case kind == kindG2:
+			g2File = file.Name
+			used = append(used, file)
+		case file.Size == pointsNumberSize:
+			skipped(file, "holds no points")
+		default:
+			first, err := firstG1Point(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read setup file %s: %w", file.Name, err)
+			}
+			firsts[first] = append(firsts[first], len(g1))
+			g1 = append(g1, file)
+		}
+	}
+
+	pointsN := func(file input.File) int {
+		return int((file.Size - pointsNumberSize) / g1PointSize)
+	}
+	// The generator is the first point
+	needed := 1 << degree
+
+	var setups []int
+	for _, indices := range firsts {
+		if len(indices) < 2 {
+			continue
+		}
+		if setups != nil {
+			return nil, fmt.Errorf("the setup files hold several sets of standalone setups of different degrees")
+		}
+		setups = indices
+	}
+
+	if setups != nil {
+		// Standalone setups of different degrees
+		slices.SortStableFunc(setups, func(a, b int) int { return pointsN(g1[a]) - pointsN(g1[b]) })
+		selected := setups[len(setups)-1]
+		if degree > 0 {
+			i := slices.IndexFunc(setups, func(i int) bool { return 1+pointsN(g1[i]) >= needed })
+			if i < 0 {
+				return nil, fmt.Errorf("the largest setup, %s, holds %d points, less than 2^%d", g1[selected].Name, 1+pointsN(g1[selected]), degree)
+			}
+			selected = setups[i]
+		}
+
+		fmt.Printf("%d setup files hold standalone setups of different degrees, using %s\n", len(setups), g1[selected].Name)
+		for i, file := range g1 {
+			switch {
+			case i == selected:
+				used = append(used, file)
+			case slices.Contains(setups, i):
+				skipped(file, fmt.Sprintf("setup of another degree than %s", g1[selected].Name))
+			default:
+				skipped(file, fmt.Sprintf("not part of the setup of %s", g1[selected].Name))
+			}
+		}
+
+		return keepOrder(files, used), nil
+	}
+
+	// Pieces of a setup split by degree
+	total := 1
+	for _, file := range g1 {
+		if degree > 0 && total >= needed {
+			skipped(file, fmt.Sprintf("not needed for 2^%d points", degree))
+			continue
+		}
+		used = append(used, file)
+		total += pointsN(file)
+	}
+	if degree > 0 && total < needed {
+		return nil, fmt.Errorf("the setup files hold %d points, less than 2^%d", total, degree)
+	}
+
+	return keepOrder(files, used), nil
+}
+
+// firstG1Point returns the encoding of the first point of a G1 setup file.
+func firstG1Point(file input.File) (string, error) {
+	f, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var data [pointsNumberSize + g1PointSize]byte
+	if _, err = io.ReadFull(f, data[:]); err != nil {
+		return "", fmt.Errorf("failed to read the first point: %w", err)
+	}
+
+	return string(data[pointsNumberSize:]), nil
+}
+
+// keepOrder returns the selected files in the order of files.
+func keepOrder(files, selected []input.File) []input.File {
+	ordered := make([]input.File, 0, len(selected))
+	for _, file := range files {
+		if slices.ContainsFunc(selected, func(s input.File) bool { return s.Name == file.Name }) {
+			ordered = append(ordered, file)
+		}
+	}
+
+	return ordered
+}
@@ -0,0 +1,196 @@
+package attest
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	StatementType = "https://in-toto.io/Statement/v1"
+	PayloadType   = "application/vnd.in-toto+json"
+	// PredicateType identifies the conversion predicate.
+	PredicateType = "https://github.com/distributed-lab/gnark-mpc-kzg-srs/conversion/v1"
+)
+
+// Subject is an artifact the statement is about.
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// Statement is an in-toto statement. Attestations are statements wrapped in
+// DSSE envelopes, the structure used by Sigstore and SLSA provenance, so they
+// can be checked with the existing tooling.
+type Statement struct {
+	Type          string    `json:"_type"`
+	Subject       []Subject `json:"subject"`
+	PredicateType string    `json:"predicateType"`
+	Predicate     any       `json:"predicate"`
+}
+
+// Input is a setup file the SRS was converted from.
+type Input struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+	// Annotations are facts about the file, e.g. the signer of a transcript.
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Conversion is the predicate describing how an SRS was produced.
+type Conversion struct {
+	Operator   string    `json:"operator"`
+	Tool       string    `json:"tool"`
+	Protocol   string    `json:"protocol"`
+	Curve      string    `json:"curve"`
+	Format     string    `json:"format"`
+	Points     int       `json:"points,omitempty"`
+	Source     string    `json:"source"`
+	Inputs     []Input   `json:"inputs"`
+	Checks     []string  `json:"checks"`
+	StartedOn  time.Time `json:"startedOn"`
+	FinishedOn time.Time `json:"finishedOn"`
+}
+
+// Signature is a DSSE signature.
+type Signature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// Envelope is a DSSE envelope.
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+// pae is the DSSE pre-authentication encoding, which is what gets signed.
+func pae(payloadType string, payload []byte) []byte {
+	return fmt.Appendf(nil, "DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload)
+}
+
+// KeyID identifies a public key by the SHA256 of its PKIX encoding.
+func KeyID(pub ed25519.PublicKey) string {
+	der, _ := x509.MarshalPKIXPublicKey(pub) // never fails for ed25519 keys
+	sum := sha256.Sum256(der)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Sign wraps the statement into an envelope signed with the key.
+func Sign(statement Statement, key ed25519.PrivateKey) (*Envelope, error) {
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode statement: %w", err)
+	}
+
+	return &Envelope{
+		PayloadType: PayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures: []Signature{{
+			KeyID: KeyID(key.Public().(ed25519.PublicKey)),
+			Sig:   base64.StdEncoding.EncodeToString(ed25519.Sign(key, pae(PayloadType, payload))),
+		}},
+	}, nil
+}
+
+// Verify checks that the envelope is signed with the key and returns its statement.
+func (e *Envelope) Verify(pub ed25519.PublicKey) (*Statement, error) {
+	payload, err := base64.StdEncoding.DecodeString(e.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+
+	keyID := KeyID(pub)
+	for _, s := range e.Signatures {
+		sig, err := base64.StdEncoding.DecodeString(s.Sig)
+		if err != nil || (s.KeyID != "" && s.KeyID != keyID) {
+			continue
+		}
+
+		if ed25519.Verify(pub, pae(e.PayloadType, payload), sig) {
+			var statement Statement
+			if err = json.Unmarshal(payload, &statement); err != nil {
+				return nil, fmt.Errorf("invalid statement: %w", err)
+			}
+			return &statement, nil
+		}
+	}
+
+	return nil, errors.New("no valid signature for the key")
+}
+
+// WriteFile writes the envelope as a JSON document.
+func (e *Envelope) WriteFile(path string) error {
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode attestation: %w", err)
+	}
+
+	if err = os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write attestation: %w", err)
+	}
+
+	return nil
+}
+
+// LoadKey reads an ed25519 private key in a PKCS #8 PEM file, as generated by
+// `openssl genpkey -algorithm ed25519`.
+func LoadKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block in %s", path)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key: %w", err)
+	}
+
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key is a %T, not an ed25519 key", key)
+	}
+
+	return edKey, nil
+}
+
+// LoadPublicKey reads an ed25519 public key in a PKIX PEM file, as extracted
+// by `openssl pkey -pubout`.
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block in %s", path)
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	edKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key is a %T, not an ed25519 key", key)
+	}
+
+	return edKey, nil
+}
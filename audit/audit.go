@@ -0,0 +1,108 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Kinds of the events recorded.
+const (
+	KindConversion = "conversion"
+	KindSetupFile  = "setup_file"
+	KindTranscript = "transcript"
+	KindChunk      = "chunk"
+	KindDigest     = "digest"
+	KindOutput     = "output"
+)
+
+// Event is an entry of the audit log: a setup file, transcript or chunk
+// encountered during a conversion and what was done with it.
+type Event struct {
+	Seq  int       `json:"seq"`
+	Time time.Time `json:"time"`
+	Kind string    `json:"kind"`
+	File string    `json:"file,omitempty"`
+	// Index is the index of the transcript or of the chunk in the ceremony.
+	Index        *int   `json:"index,omitempty"`
+	Participant  string `json:"participant,omitempty"`
+	Contribution string `json:"contribution,omitempty"`
+	// Offset is the index in the SRS of the first point read from the file.
+	Offset int `json:"offset,omitempty"`
+	Points int `json:"points,omitempty"`
+	// Hash is the hash embedded in the file by the ceremony, in hex.
+	Hash string `json:"hash,omitempty"`
+	// HashAlgorithm is the algorithm of Hash, as named in the profiles.
+	HashAlgorithm string `json:"hash_algorithm,omitempty"`
+	// SHA256 is the hash of the whole file, in hex.
+	SHA256 string `json:"sha256,omitempty"`
+	// Decision is what was done with the file, e.g. when several files are
+	// candidates for the same part of the SRS.
+	Decision string `json:"decision,omitempty"`
+}
+
+// Log is an append-only log of the events of a conversion. The zero value is
+// ready to use, and the methods of a nil log do nothing.
+type Log struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+// Index returns a pointer to i, for Event.Index.
+func Index(i int) *int {
+	return &i
+}
+
+// Record appends the event to the log, numbering and timestamping it.
+func (l *Log) Record(e Event) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e.Seq = len(l.events)
+	e.Time = time.Now().UTC()
+	l.events = append(l.events, e)
+}
+
+// Events returns a copy of the events recorded.
+func (l *Log) Events() []Event {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]Event(nil), l.events...)
+}
+
+// Append appends the events to the file as JSON lines, creating it if needed,
+// so that the logs of successive conversions to the same output accumulate.
+func (l *Log) Append(path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	var buf []byte
+	for _, e := range l.Events() {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to encode audit event: %w", err)
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+
+	// A single write keeps the lines of a conversion together.
+	if _, err = f.Write(buf); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	return f.Close()
+}
@@ -1,26 +1,53 @@
 package aztec
 
 import (
+	"bufio"
 	"encoding/binary"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
-	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
 
 	"github.com/consensys/gnark-crypto/ecc/bn254"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
 	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
 	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/audit"
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/offheap"
+	"linea/aztec-srs-to-gnark/parallel"
+	"linea/aztec-srs-to-gnark/revision"
+	"linea/aztec-srs-to-gnark/verify"
+)
+
+const (
+	// readBufferSize is the size of the buffer used to read a single transcript.
+	readBufferSize = 4 << 20
+	// g1ReadBatch is the number of G1 points read from a transcript at once.
+	g1ReadBatch = 1 << 12
+	// g1PointSize is the size of an encoded G1 point.
+	g1PointSize = 2 * fp.Bytes
+	// maxG1PointsN bounds the number of G1 points a header may announce: the
+	// SRS is allocated from it before the points are read, and BN254 has no
+	// FFT domain larger than 2^28 to use more with.
+	maxG1PointsN = 1 << 28
 )
 
-// transcriptMetadata Each value is big-endian encoded 4 bytes.
+// transcriptMetadata Each value is big-endian encoded 4 bytes. The totals
+// describe the ceremony, they are the same in all its transcripts.
 type transcriptMetadata struct {
-	// From 0 to 19 - 20 transcripts per participant
+	// From 0 to TotalTranscriptsN-1, 0 to 19 in Ignition
 	TranscriptN int32
-	// Should be always 20
+	// 20 transcripts per participant in Ignition
 	TotalTranscriptsN int32
-	// Should always be 100,000,000
+	// 100,800,000 in Ignition
 	TotalG1PointsN int32
-	// Should always be 1
+	// 1 in Ignition
 	TotalG2PointsN int32
 	// Number of G1 points in this transcript
 	G1PointsN int32
@@ -36,64 +63,140 @@ func readMetadata(r io.Reader) (transcriptMetadata, error) {
 	return metadata, err
 }
 
-// readTranscriptFile The file is structured as follows:
-// - A 24-byte header containing metadata
-// - 5,040,000 G1 points
-// - 2 G2 points (first transcript only)
-//   - The first G2 point is z*Gen, where z is the toxic waste from the previous participant
-//   - The second G2 point is x*Gen where x is the trusted setup toxic waste
-// - A 64-byte BLAKE2B hash of the rest of the file's data
-func readTranscriptFile(path string, srs *bnKzg.SRS) error {
-	file, err := os.Open(path)
+// errNotTranscript is returned for the files of a setup directory that aren't
+// transcripts, e.g. a README or a checksums file, which the importer skips.
+var errNotTranscript = errors.New("not a transcript")
+
+// errTruncated is returned for the transcripts whose size isn't the one their
+// header announces.
+var errTruncated = errors.New("truncated or corrupted")
+
+// readTranscriptHeader reads the metadata of a transcript, wrapping
+// errNotTranscript when it isn't consistent: transcripts have no magic number,
+// their header is all there is to recognize them. The size of the file is then
+// checked against the one the header announces, so a truncated or corrupted
+// transcript is reported before any point is parsed.
+func (c Ceremony) readTranscriptHeader(file input.File, r io.Reader) (transcriptMetadata, error) {
+	metadata, err := readMetadata(r)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return metadata, fmt.Errorf("%w: shorter than a transcript header", errNotTranscript)
+	}
 	if err != nil {
-		return err
+		return metadata, fmt.Errorf("failed to read metadata: %w", err)
 	}
-	defer file.Close()
 
-	metadata, err := readMetadata(file)
-	if err != nil {
-		return fmt.Errorf("failed to read metadata: %w", err)
+	m := metadata
+	if m.TotalTranscriptsN <= 0 || m.TranscriptN < 0 || m.TranscriptN >= m.TotalTranscriptsN ||
+		m.TotalG1PointsN > maxG1PointsN || m.G1PointsN < 0 || (m.G2PointsN != 0 && m.G2PointsN != 2) ||
+		m.StartFrom < 0 || int64(m.StartFrom)+int64(m.G1PointsN) > int64(m.TotalG1PointsN) {
+		return metadata, fmt.Errorf("%w: inconsistent header", errNotTranscript)
+	}
+
+	expected := c.transcriptSize(metadata)
+	if file.Size != input.UnknownSize {
+		if err = c.Format.CheckSize(formatName, file.Size, expected); err != nil {
+			return metadata, fmt.Errorf("transcript %d: %w", metadata.TranscriptN, err)
+		}
+	}
+	if file.Size != input.UnknownSize && file.Size != expected {
+		return metadata, fmt.Errorf("the header of transcript %d announces %d bytes (%d G1 and %d G2 points), the file has %d: %w",
+			metadata.TranscriptN, expected, metadata.G1PointsN, metadata.G2PointsN, file.Size, errTruncated)
+	}
+
+	if err = input.Skip(r, c.Format.HeaderExtension); err != nil {
+		return metadata, fmt.Errorf("failed to skip the header extension of transcript %d: %w", metadata.TranscriptN, errTruncated)
+	}
+
+	return metadata, nil
+}
+
+// formatName is the name of the transcript format in the errors.
+const formatName = "aztec transcript"
+
+// headerSize returns the size of the header of the transcripts, extension
+// included.
+func (c Ceremony) headerSize() int64 {
+	return int64(binary.Size(transcriptMetadata{})) + c.Format.HeaderExtension
+}
+
+// maxSRSSize bounds the number of points of the SRS read from the files, the
+// generator included: the total the ceremony announces, or less when the
+// sizes of the files are known and can't hold as many, or when only the first
+// transcripts are read. The header is read from the file, when the sizes
+// aren't known the files are assumed to be transcripts of the ceremony, so a
+// corrupted header can't have the SRS take up to maxG1PointsN points.
+func (c Ceremony) maxSRSSize(files []input.File, metadata transcriptMetadata, transcripts int) int {
+	n := int64(metadata.TotalG1PointsN)
+	if transcripts > 0 {
+		// The transcripts are of the same size but the last one
+		n = min(n, int64(transcripts)*int64(max(c.TranscriptG1PointsN, int(metadata.G1PointsN))))
 	}
 
-	if err = readG1Points(file, int(metadata.G1PointsN), srs); err != nil {
+	var inFiles int64
+	for _, file := range files {
+		if file.Size == input.UnknownSize {
+			return int(min(n, int64(len(files))*int64(c.TranscriptG1PointsN))) + 1
+		}
+		inFiles += max(file.Size-c.headerSize()-int64(c.Checksum.Size()), 0) / g1PointSize
+	}
+
+	return int(min(n, inFiles)) + 1
+}
+
+// transcriptSize returns the size of the transcript the metadata describes.
+func (c Ceremony) transcriptSize(metadata transcriptMetadata) int64 {
+	return c.headerSize() + int64(metadata.G1PointsN)*g1PointSize + int64(metadata.G2PointsN)*g2PointSize + int64(c.Checksum.Size())
+}
+
+// readTranscriptPoints reads the points following the header of a transcript.
+// The file is structured as follows:
+// - A 28-byte header containing metadata
+// - 5,040,000 G1 points
+// - 2 G2 points (first transcript only)
+//   - The first G2 point is z*Gen, where z is the toxic waste from the previous participant
+//   - The second G2 point is x*Gen where x is the trusted setup toxic waste
+// - A 64-byte BLAKE2B hash of the rest of the file's data
+//
+// The G1 points are written into points, which must be exactly G1PointsN long
+// and start at index offset of the SRS.
+func readTranscriptPoints(r io.Reader, metadata transcriptMetadata, points []bn254.G1Affine, offset int, checks *verify.Pipeline[bn254.G1Affine], srs *bnKzg.SRS) error {
+	if err := readG1Points(r, points, offset, checks); err != nil {
 		return fmt.Errorf("failed to read G1 points: %w", err)
 	}
 
 	if metadata.G2PointsN != 0 {
-		if err = readG2Points(file, srs); err != nil {
+		if err := readG2Points(r, srs); err != nil {
 			return fmt.Errorf("failed to read G2 points: %w", err)
 		}
 	}
 
-	// Checksum is skipped here
+	// Checksum is skipped here, it is only read for the audit log
 
 	return nil
 }
 
 // readG1Points G1 are described as a uint64_t[4] array. The first entry is the least
 // significant word of the field element. Each 'word' is written in big-endian form.
-func readG1Points(r io.Reader, n int, srs *bnKzg.SRS) error {
-	for i := 0; i < n; i++ {
-		x, err := extract32ByteFieldElement(r)
-		if err != nil {
-			return fmt.Errorf("failed to read x-coordinate: %w", err)
-		}
+// The points are read in batches into a single reusable buffer; every decoded
+// batch is passed to the verification checks.
+func readG1Points(r io.Reader, points []bn254.G1Affine, offset int, checks *verify.Pipeline[bn254.G1Affine]) error {
+	buf := make([]byte, g1ReadBatch*g1PointSize)
 
-		y, err := extract32ByteFieldElement(r)
-		if err != nil {
-			return fmt.Errorf("failed to read y-coordinate: %w", err)
-		}
+	for from := 0; from < len(points); from += g1ReadBatch {
+		batch := points[from:min(from+g1ReadBatch, len(points))]
+		data := buf[:len(batch)*g1PointSize]
 
-		point := bn254.G1Affine{
-			X: x,
-			Y: y,
+		if _, err := io.ReadFull(r, data); err != nil {
+			return fmt.Errorf("failed to read points %d-%d: %w", from, from+len(batch)-1, err)
 		}
 
-		srs.Pk.G1 = append(srs.Pk.G1, point)
-
-		if len(srs.Pk.G1) == 2 {
-			fmt.Printf("> a^1*G1: %s %s\n", srs.Pk.G1[1].X.String(), srs.Pk.G1[1].Y.String())
+		for i := range batch {
+			point := data[i*g1PointSize : (i+1)*g1PointSize]
+			decode32ByteFieldElement(&batch[i].X, point[:fp.Bytes])
+			decode32ByteFieldElement(&batch[i].Y, point[fp.Bytes:])
 		}
+
+		checks.Check(offset+from, batch)
 	}
 
 	return nil
@@ -145,48 +248,347 @@ func extract32ByteFieldElement(r io.Reader) (result fp.Element, err error) {
 		return result, err
 	}
 
+	decode32ByteFieldElement(&result, buf[:])
+
+	return result, nil
+}
+
+// decode32ByteFieldElement decodes the 32 bytes of buf into result without allocating.
+func decode32ByteFieldElement(result *fp.Element, buf []byte) {
 	// Reverse the order of 8-byte chunks
 	var reordered [32]byte
 	for i := 0; i < 4; i++ {
 		copy(reordered[i*8:(i+1)*8], buf[(3-i)*8:(4-i)*8])
 	}
 
-	(&result).SetBytes(reordered[:])
+	result.SetBytes(reordered[:])
+}
 
-	return result, nil
+// TranslateBn254SRS reads all the bn254 transcripts of the Ignition ceremony
+// and constructs KZG SRS from them, see Ceremony.Translate.
+func TranslateBn254SRS(files []input.File, opts config.Options) (kzg.SRS, int, error) {
+	return Ignition.Translate(files, opts)
 }
 
-// TranslateBn254SRS reads all the bn254 transcripts and constructs KZG SRS from them.
-func TranslateBn254SRS(setupDir string) (kzg.SRS, int, error) {
-	files, err := os.ReadDir(setupDir)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to read setup directory '%s': %w", setupDir, err)
+// Translate reads all the bn254 transcripts of the ceremony and constructs KZG SRS from them.
+// The transcripts are opened in order; the points of up to opts.IOParallelism
+// of them are read at the same time. The files that aren't transcripts of the
+// ceremony and the transcripts that can't be read are rejected with
+// opts.Reject: under the lenient validation the SRS ends before the first
+// points missing, but the transcript holding the G2 points can't be skipped.
+// With opts.Transcripts only the first transcripts are read, into a smaller SRS.
+// The transcripts with a signature file are checked against the checksum
+// ending them, and the signer of the checksum is reported, see
+// Ceremony.Signer.
+func (c Ceremony) Translate(files []input.File, opts config.Options) (kzg.SRS, int, error) {
+	if err := c.Validate(); err != nil {
+		return nil, 0, err
 	}
 
+	files, signatures := splitSignatures(files)
+
 	_, _, gen1Aff, gen2Aff := bn254.Generators()
 
 	srs := new(bnKzg.SRS)
 
-	srs.Pk.G1 = make([]bn254.G1Affine, 1)
-	srs.Pk.G1[0] = gen1Aff
 	srs.Vk.G1 = gen1Aff
 	srs.Vk.G2[0] = gen2Aff
 
-	numProcessed := 0
-	for i, file := range files {
-		fmt.Printf("Processing file %s\n", file.Name())
+	var (
+		checker *verify.Bn254Checker
+		checks  *verify.Pipeline[bn254.G1Affine]
+	)
+	if opts.Verify {
+		var err error
+		if checker, err = verify.NewBn254Checker(); err != nil {
+			return nil, 0, err
+		}
+		checks = verify.NewPipeline(checker.Batch, opts.Workers)
+	}
+
+	var numProcessed atomic.Int32
+
+	group := parallel.NewGroup(opts.IOParallelism)
+
+	// The G1 points [from, to) of the SRS every transcript is read into
+	type placement struct {
+		from, to int
+		name     string
+	}
+	var placements []placement
+	// The header of the first transcript, the others must be of the same ceremony
+	var ceremony transcriptMetadata
+	// The transcripts skipped after their points were placed, by name
+	var (
+		failedMu sync.Mutex
+		failed   = make(map[string]bool)
+	)
+
+	for _, file := range files {
+		if group.Err() != nil {
+			break
+		}
+
+		fmt.Printf("Processing file %s\n", file.Name)
 
-		err = readTranscriptFile(fmt.Sprintf("%s/%s", setupDir, file.Name()), srs)
+		f, err := file.Open()
 		if err != nil {
-			return nil, 0, fmt.Errorf("failed to read setup file: %w", err)
+			group.Wait()
+			return nil, 0, fmt.Errorf("failed to open setup file %s: %w", file.Name, err)
 		}
 
-		fmt.Printf("Processed setup files %d/%d\n", i+1, len(files))
-		numProcessed++
+		// The content of a signed transcript is hashed while it is read, for
+		// the checksum the signature is of.
+		var (
+			src    io.Reader = f
+			hashed *tailHash
+		)
+		signature, signed := signatures[file.Name]
+		if signed {
+			hashed = newTailHash(c.Checksum.New(), c.Checksum.Size())
+			src = io.TeeReader(f, hashed)
+		}
+		r := bufio.NewReaderSize(src, readBufferSize)
+
+		// reject applies the validation policy to the file, closing it.
+		reject := func(skip config.Skip, err error) error {
+			f.Close()
+			if rejectErr := opts.Reject(skip, err); rejectErr != nil {
+				group.Wait()
+				return rejectErr
+			}
+			opts.Audit.Record(audit.Event{Kind: audit.KindSetupFile, File: file.Name, Index: skip.Index, Decision: fmt.Sprintf("ignored: %v", err)})
+			return nil
+		}
+
+		metadata, err := c.readTranscriptHeader(file, r)
+		if errors.Is(err, errNotTranscript) {
+			f.Close()
+			opts.Ignore(file.Name, err.Error())
+			opts.Audit.Record(audit.Event{Kind: audit.KindSetupFile, File: file.Name, Decision: fmt.Sprintf("ignored: %v", err)})
+			continue
+		}
+		if opts.Transcripts > 0 && (err == nil || errors.Is(err, errTruncated) || errors.Is(err, revision.ErrUnsupported)) && int(metadata.TranscriptN) >= opts.Transcripts {
+			f.Close()
+			fmt.Printf("Skipping %s: transcript %d isn't one of the first %d\n", file.Name, metadata.TranscriptN, opts.Transcripts)
+			opts.Audit.Record(audit.Event{Kind: audit.KindTranscript, File: file.Name, Index: audit.Index(int(metadata.TranscriptN)),
+				Decision: fmt.Sprintf("ignored: not one of the first %d transcripts", opts.Transcripts)})
+			continue
+		}
+
+		if errors.Is(err, errTruncated) && metadata.G2PointsN == 0 {
+			skip := config.Skip{File: file.Name, Index: audit.Index(int(metadata.TranscriptN)), Offset: audit.Index(int(metadata.StartFrom) + 1), Points: int(metadata.G1PointsN)}
+			if err = reject(skip, fmt.Errorf("failed to read setup file %s: %w", file.Name, err)); err != nil {
+				return nil, 0, err
+			}
+			continue
+		}
+		if err != nil {
+			f.Close()
+			group.Wait()
+			return nil, 0, fmt.Errorf("failed to read setup file %s: %w", file.Name, err)
+		}
+
+		if srs.Pk.G1 == nil {
+			// Every transcript announces the total number of points in the
+			// ceremony, a trimmed mirror of it may hold far less.
+			ceremony = metadata
+			if opts.Transcripts > int(metadata.TotalTranscriptsN) {
+				f.Close()
+				group.Wait()
+				return nil, 0, fmt.Errorf("the first %d transcripts are requested, the ceremony has %d", opts.Transcripts, metadata.TotalTranscriptsN)
+			}
+			srs.Pk.G1, err = offheap.Make[bn254.G1Affine](c.maxSRSSize(files, metadata, opts.Transcripts), opts)
+			if err != nil {
+				f.Close()
+				return nil, 0, err
+			}
+			srs.Pk.G1[0] = gen1Aff
+			checks.Check(0, srs.Pk.G1[:1])
+			opts.Progress.SetTotal(len(srs.Pk.G1))
+			opts.Progress.Add(1)
+		} else if metadata.TotalTranscriptsN != ceremony.TotalTranscriptsN || metadata.TotalG1PointsN != ceremony.TotalG1PointsN {
+			skip := config.Skip{File: file.Name, Index: audit.Index(int(metadata.TranscriptN))}
+			err = fmt.Errorf("setup file %s is a transcript of a ceremony of %d transcripts and %d G1 points, the previous ones of one of %d and %d",
+				file.Name, metadata.TotalTranscriptsN, metadata.TotalG1PointsN, ceremony.TotalTranscriptsN, ceremony.TotalG1PointsN)
+			if err = reject(skip, err); err != nil {
+				return nil, 0, err
+			}
+			continue
+		}
+
+		// The generator is the first point, the transcript holds the powers
+		// from tau^(StartFrom+1) whatever the order the files are read in.
+		offset, n := int(metadata.StartFrom)+1, int(metadata.G1PointsN)
+		skip := config.Skip{File: file.Name, Index: audit.Index(int(metadata.TranscriptN)), Offset: audit.Index(offset), Points: n}
+		if n < 0 || offset < 1 || offset+n > len(srs.Pk.G1) {
+			if err = reject(skip, fmt.Errorf("setup file %s exceeds the announced total of %d G1 points", file.Name, len(srs.Pk.G1)-1)); err != nil {
+				return nil, 0, err
+			}
+			continue
+		}
+		if c.Signer != "" && !signed {
+			err = fmt.Errorf("transcript %s: %w %s, it must be signed by %s", file.Name, errUnsigned, signatureName(file.Name), c.Signer)
+			if err = reject(skip, err); err != nil {
+				return nil, 0, err
+			}
+			continue
+		}
+
+		if i := slices.IndexFunc(placements, func(p placement) bool { return offset < p.to && p.from < offset+n }); i >= 0 {
+			// The points are the ones of the other transcript, none is missing
+			err = fmt.Errorf("setup file %s holds G1 points %d-%d, which overlap the ones of %s", file.Name, offset, offset+n-1, placements[i].name)
+			if err = reject(config.Skip{File: skip.File, Index: skip.Index}, err); err != nil {
+				return nil, 0, err
+			}
+			continue
+		}
+		placements = append(placements, placement{offset, offset + n, file.Name})
+		if offset+n <= opts.Extend {
+			f.Close()
+			fmt.Printf("Skipping %s: its G1 points %d-%d are in the extended SRS\n", file.Name, offset, offset+n-1)
+			opts.Audit.Record(audit.Event{Kind: audit.KindTranscript, File: file.Name, Index: skip.Index, Offset: offset, Points: n, Decision: "covered by the extended SRS"})
+			opts.Progress.Add(n)
+			continue
+		}
+
+		points, pointsOffset := srs.Pk.G1[offset:offset+n], offset
+
+		group.Go(func() error {
+			defer f.Close()
+
+			// drop applies the validation policy to the transcript whose
+			// points were placed.
+			drop := func(err error) error {
+				// Without the G2 points there is no verifying key to skip to.
+				if metadata.G2PointsN != 0 {
+					return err
+				}
+				decision := "dropped: " + err.Error()
+				if err = opts.Reject(skip, err); err != nil {
+					return err
+				}
+				opts.Audit.Record(audit.Event{Kind: audit.KindTranscript, File: file.Name, Index: skip.Index, Offset: pointsOffset, Decision: decision})
+
+				failedMu.Lock()
+				failed[file.Name] = true
+				failedMu.Unlock()
+				return nil
+			}
+
+			if err := readTranscriptPoints(r, metadata, points, pointsOffset, checks, srs); err != nil {
+				return drop(fmt.Errorf("failed to read setup file %s: %w", file.Name, err))
+			}
+
+			var (
+				checksum []byte
+				signer   string
+			)
+			if opts.Audit != nil || signed {
+				checksum = make([]byte, c.Checksum.Size())
+				if _, err := io.ReadFull(r, checksum); err != nil {
+					return fmt.Errorf("failed to read checksum of setup file %s: %w", file.Name, err)
+				}
+			}
+			if signed {
+				var err error
+				if signer, err = c.verifySignature(signature, checksum, hashed); err != nil {
+					return drop(fmt.Errorf("failed to verify the signature of setup file %s: %w", file.Name, err))
+				}
+				fmt.Printf("Transcript %d (%s) is signed by %s\n", metadata.TranscriptN, file.Name, signer)
+			}
+
+			if opts.Audit != nil {
+				opts.Audit.Record(audit.Event{
+					Kind:          audit.KindTranscript,
+					File:          file.Name,
+					Index:         audit.Index(int(metadata.TranscriptN)),
+					Participant:   signer,
+					Offset:        pointsOffset,
+					Points:        len(points),
+					Hash:          hex.EncodeToString(checksum),
+					HashAlgorithm: string(c.Checksum),
+					Decision:      "used",
+				})
+			}
+
+			opts.Progress.Add(len(points))
+			fmt.Printf("Processed setup files %d/%d\n", numProcessed.Add(1), len(files))
+
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, 0, err
+	}
+
+	if srs.Pk.G1 == nil {
+		return nil, 0, fmt.Errorf("no transcripts found")
 	}
 
-	if numProcessed != 20 {
-		fmt.Printf("WARNING: expected 20 setup files, but got %d\n", numProcessed)
+	// The transcripts must hold consecutive powers from tau^1, the SRS ends
+	// with the last one, or before the first points missing under the lenient
+	// validation.
+	placements = slices.DeleteFunc(placements, func(p placement) bool { return failed[p.name] })
+	slices.SortFunc(placements, func(a, b placement) int { return a.from - b.from })
+	// The points skipped were checked too, so the fused checks don't apply
+	reverify := len(failed) != 0
+	end := 1
+	for i, p := range placements {
+		if p.from != end {
+			err := fmt.Errorf("G1 points %d-%d are missing, the transcript holding them isn't in the setup files", end, p.from-1)
+			if !opts.Lenient() {
+				return nil, 0, err
+			}
+			opts.Skipped.Add(config.Skip{Offset: audit.Index(end), Points: p.from - end, Reason: err.Error()})
+			for _, after := range placements[i:] {
+				opts.Skipped.Add(config.Skip{File: after.name, Offset: audit.Index(after.from), Points: after.to - after.from, Reason: err.Error()})
+			}
+			opts.Warn("%v: the SRS ends at G1 point %d", err, end-1)
+			placements = placements[:i]
+			reverify = true
+			break
+		}
+		end = p.to
+	}
+	if end == 1 {
+		return nil, 0, fmt.Errorf("no G1 points left in the setup files")
+	}
+	srs.Pk.G1 = srs.Pk.G1[:end]
+
+	switch {
+	case opts.Transcripts > 0 && len(placements) < opts.Transcripts:
+		err := fmt.Errorf("the first %d transcripts are requested, but got %d", opts.Transcripts, len(placements))
+		if !opts.Lenient() {
+			return nil, 0, err
+		}
+		opts.Warn("%v: the SRS holds the first %d of the %d G1 points of the ceremony", err, end-1, ceremony.TotalG1PointsN)
+	case opts.Transcripts > 0:
+		fmt.Printf("The SRS holds the first %d of the %d G1 points of the ceremony, from its first %d transcripts\n",
+			end-1, ceremony.TotalG1PointsN, opts.Transcripts)
+	case len(placements) != int(ceremony.TotalTranscriptsN):
+		opts.Warn("the ceremony has %d transcripts, but got %d: the SRS holds the first %d of its %d G1 points",
+			ceremony.TotalTranscriptsN, len(placements), end-1, ceremony.TotalG1PointsN)
+	}
+
+	if len(srs.Pk.G1) > 1 && opts.Extend <= 1 {
+		fmt.Printf("> a^1*G1: %s %s\n", srs.Pk.G1[1].X.String(), srs.Pk.G1[1].Y.String())
+	}
+
+	if checker != nil {
+		err := checks.Wait()
+		switch {
+		case reverify:
+			err = verify.SRS(srs, opts)
+		case err == nil:
+			err = checker.Finish(srs)
+		}
+		// Under the lenient validation the SRS ends before a malformed point
+		if err = verify.Recover(srs, opts, err); err != nil {
+			return nil, 0, fmt.Errorf("%w: %w", verify.ErrFailed, err)
+		}
+		fmt.Println("SRS verified: all G1 points are in the subgroup and are consecutive powers of tau")
 	}
 
 	// Precompute the lines when the G2 points are set
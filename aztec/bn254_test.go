@@ -0,0 +1,74 @@
+package aztec_test
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"linea/aztec-srs-to-gnark/aztec"
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/testsetup"
+)
+
+// g1PointSize is the size of a G1 point in a transcript.
+const g1PointSize = 64
+
+// FuzzReadTranscript converts a single transcript, read from a file and from
+// a stream of unknown size, under both validation policies. The conversion
+// mustn't panic, and mustn't return more points than the file holds whatever
+// its header announces.
+func FuzzReadTranscript(f *testing.F) {
+	dir := f.TempDir()
+	if _, err := testsetup.Aztec(dir, 2, 4, testsetup.DefaultSecrets()); err != nil {
+		f.Fatal(err)
+	}
+	for _, name := range []string{"transcript00.dat", "transcript01.dat"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+		f.Add(data[:28])
+		f.Add(data[:len(data)-1])
+
+		// A header announcing the largest ceremony
+		huge := append([]byte(nil), data...)
+		binary.BigEndian.PutUint32(huge[8:], 1<<28)
+		f.Add(huge)
+	}
+
+	// The ceremony of the transcripts of the seeds
+	ceremony := aztec.Ignition
+	ceremony.TranscriptsN, ceremony.TranscriptG1PointsN = 2, 4
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "transcript00.dat")
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		files, err := input.Dir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stream := input.New("transcript00.dat", input.UnknownSize, func() (io.ReadCloser, error) {
+			return os.Open(path)
+		})
+
+		for _, validation := range config.Validations {
+			for _, files := range [][]input.File{files, {stream}} {
+				opts := config.Tune(config.Options{IOParallelism: 1, Validation: validation}, "")
+				opts.Skipped = new(config.Skips)
+
+				_, n, err := ceremony.Translate(files, opts)
+				if err == nil && n-1 > len(data)/g1PointSize {
+					t.Fatalf("%d points read from a transcript of %d bytes", n, len(data))
+				}
+			}
+		}
+	})
+}
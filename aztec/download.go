@@ -0,0 +1,135 @@
+package aztec
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"linea/aztec-srs-to-gnark/digest"
+	"linea/aztec-srs-to-gnark/fetch"
+	"linea/aztec-srs-to-gnark/revision"
+)
+
+// Ceremony describes the published transcripts of an Aztec ceremony. The
+// importer reads the rest of its constants from the headers of the transcripts.
+type Ceremony struct {
+	// TranscriptURL is the location of the transcripts, formatted with their number.
+	TranscriptURL string `json:"transcript_url"`
+	// TranscriptsN is the number of transcripts.
+	TranscriptsN int `json:"transcripts"`
+	// TranscriptG1PointsN is the number of G1 points in every transcript.
+	TranscriptG1PointsN int `json:"transcript_g1_points"`
+	// Checksum is the hash algorithm of the checksum ending every transcript.
+	Checksum digest.Algorithm `json:"checksum"`
+	// Format is the revision of the transcript format, the one of Ignition
+	// when unset.
+	Format revision.Format `json:"format"`
+	// Signer is the Ethereum address of the participant every transcript must
+	// be signed by, see SignatureExt. When it is unset the signatures found
+	// are verified and their signers reported, but none is required.
+	Signer string `json:"signer"`
+}
+
+// Ignition is the Aztec Ignition ceremony.
+var Ignition = Ceremony{
+	TranscriptURL:       "https://aztec-ignition.s3.eu-west-2.amazonaws.com/MAIN+IGNITION/sealed/transcript%02d.dat",
+	TranscriptsN:        20,
+	TranscriptG1PointsN: 5_040_000,
+	Checksum:            digest.BLAKE2b512,
+}
+
+// Validate checks that the constants of the ceremony are consistent.
+func (c Ceremony) Validate() error {
+	if c.TranscriptsN < 1 || c.TranscriptG1PointsN < 1 {
+		return fmt.Errorf("a ceremony of %d transcripts of %d points has no points", c.TranscriptsN, c.TranscriptG1PointsN)
+	}
+	if err := c.Format.Validate(formatName, revision.Latest); err != nil {
+		return err
+	}
+	if c.Signer != "" {
+		if err := ValidateAddress(c.Signer); err != nil {
+			return err
+		}
+	}
+
+	return c.Checksum.Validate()
+}
+
+// Downloads lists the transcripts of the Ignition ceremony, see Ceremony.Downloads.
+func Downloads(ctx context.Context, sel fetch.Selection) ([]fetch.Download, error) {
+	return Ignition.Downloads(ctx, sel)
+}
+
+// Downloads lists the transcripts of the ceremony, only the first ones holding
+// 2^sel.Degree points when the degree is set, or the first sel.Transcripts.
+func (c Ceremony) Downloads(_ context.Context, sel fetch.Selection) ([]fetch.Download, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	n := c.TranscriptsN
+	if sel.Degree > 0 {
+		// The generator isn't part of the transcripts.
+		n = ((1 << sel.Degree) - 1 + c.TranscriptG1PointsN - 1) / c.TranscriptG1PointsN
+		if n > c.TranscriptsN {
+			return nil, fmt.Errorf("the ceremony has less than 2^%d points", sel.Degree)
+		}
+	}
+	if sel.Transcripts > 0 {
+		if sel.Transcripts > c.TranscriptsN {
+			return nil, fmt.Errorf("the ceremony has %d transcripts, less than %d", c.TranscriptsN, sel.Transcripts)
+		}
+		n = min(n, sel.Transcripts)
+	}
+
+	downloads := make([]fetch.Download, n)
+	for i := range downloads {
+		downloads[i] = fetch.Download{
+			URL:    fmt.Sprintf(c.TranscriptURL, i),
+			Verify: c.VerifyTranscript,
+		}
+	}
+
+	return downloads, nil
+}
+
+// VerifyTranscript checks the BLAKE2B hash closing a transcript of the
+// Ignition ceremony, see Ceremony.VerifyTranscript.
+func VerifyTranscript(path string) error {
+	return Ignition.VerifyTranscript(path)
+}
+
+// VerifyTranscript checks the checksum closing the transcript against the rest
+// of its data.
+func (c Ceremony) VerifyTranscript(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open transcript: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to get transcript info: %w", err)
+	}
+
+	checksumSize := int64(c.Checksum.Size())
+	if info.Size() < checksumSize {
+		return fmt.Errorf("transcript is too short: %d bytes", info.Size())
+	}
+
+	h := c.Checksum.New()
+	if _, err = io.CopyBuffer(h, io.LimitReader(file, info.Size()-checksumSize), make([]byte, readBufferSize)); err != nil {
+		return fmt.Errorf("failed to hash transcript: %w", err)
+	}
+
+	checksum := make([]byte, checksumSize)
+	if _, err = io.ReadFull(file, checksum); err != nil {
+		return fmt.Errorf("failed to read transcript checksum: %w", err)
+	}
+
+	return digest.Check(c.Checksum, "transcript "+filepath.Base(path), hex.EncodeToString(h.Sum(nil)), hex.EncodeToString(checksum))
+}
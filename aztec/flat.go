@@ -0,0 +1,189 @@
+package aztec
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/audit"
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/offheap"
+	"linea/aztec-srs-to-gnark/verify"
+)
+
+const (
+	// FlatG1File holds the G1 points of the flat CRS, starting with the generator
+	FlatG1File = "g1.dat"
+	// FlatG2File holds τG2 of the flat CRS
+	FlatG2File = "g2.dat"
+	// Size of a G1 point of the flat CRS: x and y as 32-byte big endian integers
+	flatG1PointSize = 64
+	// Size of τG2 in the flat CRS: x.c0, x.c1, y.c0 and y.c1 likewise
+	flatG2PointSize = 128
+)
+
+// TranslateFlatCRS reads the flat CRS of the Ignition ceremony, the g1.dat and
+// g2.dat files Barretenberg downloads, and constructs a KZG SRS from them. It
+// is a source of the points independent of the transcripts. The other files
+// and the G1 points that can't be read are rejected with opts.Reject: under the
+// lenient validation the SRS ends before the first point that can't be read.
+func TranslateFlatCRS(files []input.File, opts config.Options) (kzg.SRS, int, error) {
+	var g1File, g2File *input.File
+	for i := range files {
+		switch files[i].Name {
+		case FlatG1File:
+			g1File = &files[i]
+		case FlatG2File:
+			g2File = &files[i]
+		default:
+			opts.Ignore(files[i].Name, "not a file of the flat CRS")
+			opts.Audit.Record(audit.Event{Kind: audit.KindSetupFile, File: files[i].Name, Decision: "ignored: not a file of the flat CRS"})
+		}
+	}
+	if g1File == nil || g2File == nil {
+		return nil, 0, fmt.Errorf("the flat CRS is made of the %s and %s files", FlatG1File, FlatG2File)
+	}
+	// The points of a file whose size is unknown, e.g. compressed, are read
+	// until its end
+	unknownSize := g1File.Size == input.UnknownSize
+	if !unknownSize && g1File.Size < flatG1PointSize {
+		return nil, 0, fmt.Errorf("size of %s isn't the one of G1 points of %d bytes", FlatG1File, flatG1PointSize)
+	}
+	if !unknownSize && g1File.Size%flatG1PointSize != 0 {
+		// The last point is truncated
+		n := int(g1File.Size / flatG1PointSize)
+		err := fmt.Errorf("size of %s isn't the one of G1 points of %d bytes", FlatG1File, flatG1PointSize)
+		if err = opts.Reject(config.Skip{File: g1File.Name, Offset: &n, Points: 1}, err); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	srs := new(bnKzg.SRS)
+	_, _, _, srs.Vk.G2[0] = bn254.Generators()
+
+	tauG2, err := readFlatG2Point(*g2File)
+	if err != nil {
+		return nil, 0, err
+	}
+	srs.Vk.G2[1] = tauG2
+	opts.Audit.Record(audit.Event{Kind: audit.KindSetupFile, File: g2File.Name, Decision: "used for τG2"})
+
+	n := 0
+	if !unknownSize {
+		n = int(g1File.Size / flatG1PointSize)
+	}
+	if srs.Pk.G1, err = offheap.Make[bn254.G1Affine](n, opts); err != nil {
+		return nil, 0, err
+	}
+	opts.Progress.SetTotal(n)
+	points, err := readFlatG1Points(*g1File, srs.Pk.G1, unknownSize)
+	if err != nil {
+		// The points before the first one that can't be read are kept
+		read := len(points)
+		if read < 2 {
+			return nil, 0, err
+		}
+		if err = opts.Reject(config.Skip{File: g1File.Name, Offset: &read, Points: max(n-read, 1)}, err); err != nil {
+			return nil, 0, err
+		}
+	}
+	srs.Pk.G1 = points
+	opts.Progress.Add(len(srs.Pk.G1))
+	opts.Audit.Record(audit.Event{Kind: audit.KindSetupFile, File: g1File.Name, Points: len(srs.Pk.G1), Decision: "used"})
+	srs.Vk.G1 = srs.Pk.G1[0]
+
+	if _, _, g1Gen, _ := bn254.Generators(); !srs.Vk.G1.Equal(&g1Gen) {
+		return nil, 0, fmt.Errorf("the first point of %s isn't the generator", FlatG1File)
+	}
+
+	if opts.Verify {
+		if err = verify.Recover(srs, opts, verify.SRS(srs, opts)); err != nil {
+			return nil, 0, fmt.Errorf("%w: %w", verify.ErrFailed, err)
+		}
+		fmt.Println("SRS verified: all G1 points are in the subgroup and are consecutive powers of tau")
+	}
+
+	srs.Vk.Lines[0] = bn254.PrecomputeLines(srs.Vk.G2[0])
+	srs.Vk.Lines[1] = bn254.PrecomputeLines(srs.Vk.G2[1])
+
+	return srs, len(srs.Pk.G1), nil
+}
+
+// readFlatG1Points reads the G1 points of the flat CRS into points and checks
+// that they are on the curve, returning the points read before an error. With
+// grow, the points are appended to points until the end of the file.
+func readFlatG1Points(file input.File, points []bn254.G1Affine, grow bool) ([]bn254.G1Affine, error) {
+	f, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", file.Name, err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReaderSize(f, 1<<20)
+	var buf [flatG1PointSize]byte
+	for i := 0; grow || i < len(points); i++ {
+		if _, err = io.ReadFull(r, buf[:]); err != nil {
+			if grow && err == io.EOF {
+				break
+			}
+			return points[:i], fmt.Errorf("failed to read G1 point %d: %w", i, err)
+		}
+		if grow {
+			points = append(points, bn254.G1Affine{})
+		}
+		if err = decodeFlatCoordinates(buf[:], &points[i].X, &points[i].Y); err != nil {
+			return points[:i], fmt.Errorf("invalid G1 point %d: %w", i, err)
+		}
+		if !points[i].IsOnCurve() {
+			return points[:i], fmt.Errorf("G1 point %d is not on the curve", i)
+		}
+	}
+
+	return points, nil
+}
+
+// readFlatG2Point reads τG2 of the flat CRS and checks that it is on the curve.
+func readFlatG2Point(file input.File) (bn254.G2Affine, error) {
+	if file.Size != input.UnknownSize && file.Size != flatG2PointSize {
+		return bn254.G2Affine{}, fmt.Errorf("size of %s isn't the one of a G2 point of %d bytes", file.Name, flatG2PointSize)
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return bn254.G2Affine{}, fmt.Errorf("failed to open %s: %w", file.Name, err)
+	}
+	defer f.Close()
+
+	var buf [flatG2PointSize]byte
+	if _, err = io.ReadFull(f, buf[:]); err != nil {
+		return bn254.G2Affine{}, fmt.Errorf("failed to read τG2: %w", err)
+	}
+
+	var p bn254.G2Affine
+	if err = decodeFlatCoordinates(buf[:], &p.X.A0, &p.X.A1, &p.Y.A0, &p.Y.A1); err != nil {
+		return bn254.G2Affine{}, fmt.Errorf("invalid τG2: %w", err)
+	}
+	if !p.IsOnCurve() {
+		return bn254.G2Affine{}, fmt.Errorf("τG2 is not on the curve")
+	}
+
+	return p, nil
+}
+
+// decodeFlatCoordinates decodes consecutive 32-byte big endian coordinates.
+func decodeFlatCoordinates(data []byte, coordinates ...*fp.Element) error {
+	for i, c := range coordinates {
+		var err error
+		if *c, err = fp.BigEndian.Element((*[fp.Bytes]byte)(data[i*fp.Bytes : (i+1)*fp.Bytes])); err != nil {
+			return fmt.Errorf("coordinate %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
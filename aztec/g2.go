@@ -0,0 +1,75 @@
+package aztec
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/input"
+)
+
+// ExtractTauG2 returns an SRS with only the verifying key, read from the
+// transcripts of the Ignition ceremony, see Ceremony.ExtractTauG2.
+func ExtractTauG2(files []input.File) (kzg.SRS, error) {
+	return Ignition.ExtractTauG2(files)
+}
+
+// ExtractTauG2 returns an SRS with only the verifying key, read from the G2
+// points of the first transcript announcing some. The G1 points are skipped
+// over without being parsed, and so are the files that aren't transcripts.
+func (c Ceremony) ExtractTauG2(files []input.File) (kzg.SRS, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		srs, ok, err := c.extractTauG2(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read setup file %s: %w", file.Name, err)
+		}
+		if ok {
+			return srs, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no transcript with G2 points found")
+}
+
+func (c Ceremony) extractTauG2(file input.File) (*bnKzg.SRS, bool, error) {
+	f, err := file.Open()
+	if err != nil {
+		return nil, false, err
+	}
+	defer f.Close()
+
+	metadata, err := c.readTranscriptHeader(file, f)
+	if errors.Is(err, errNotTranscript) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if metadata.G2PointsN == 0 {
+		return nil, false, nil
+	}
+
+	if err = input.Skip(f, int64(metadata.G1PointsN)*g1PointSize); err != nil {
+		return nil, false, fmt.Errorf("failed to skip G1 points: %w", err)
+	}
+
+	_, _, gen1Aff, gen2Aff := bn254.Generators()
+
+	srs := new(bnKzg.SRS)
+	srs.Vk.G1 = gen1Aff
+	srs.Vk.G2[0] = gen2Aff
+	if err = readG2Points(f, srs); err != nil {
+		return nil, false, fmt.Errorf("failed to read G2 points: %w", err)
+	}
+	srs.Vk.Lines[0] = bn254.PrecomputeLines(srs.Vk.G2[0])
+	srs.Vk.Lines[1] = bn254.PrecomputeLines(srs.Vk.G2[1])
+
+	return srs, true, nil
+}
@@ -0,0 +1,70 @@
+package aztec
+
+import (
+	"errors"
+	"fmt"
+
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/manifest"
+)
+
+// g2PointSize is the size of an encoded G2 point.
+const g2PointSize = 2 * g1PointSize
+
+// Describe describes the transcripts of the Ignition ceremony, see
+// Ceremony.Describe.
+func Describe(files []input.File, descs []manifest.File) error {
+	return Ignition.Describe(files, descs)
+}
+
+// Describe describes the transcripts from their headers: their numbers, their
+// G1 and G2 points and their checksums. The other files are ignored.
+func (c Ceremony) Describe(files []input.File, descs []manifest.File) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	for i, file := range files {
+		if err := c.describeTranscript(file, &descs[i]); err != nil {
+			return fmt.Errorf("failed to describe %s: %w", file.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (c Ceremony) describeTranscript(file input.File, desc *manifest.File) error {
+	f, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open setup file: %w", err)
+	}
+	defer f.Close()
+
+	metadata, err := c.readTranscriptHeader(file, f)
+	if errors.Is(err, errNotTranscript) {
+		desc.Kind = manifest.KindIgnored
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	headerSize := c.headerSize()
+	g1Size := int64(metadata.G1PointsN) * g1PointSize
+	g2Size := int64(metadata.G2PointsN) * g2PointSize
+
+	desc.Kind = "transcript"
+	index := int(metadata.TranscriptN)
+	desc.Index = &index
+	desc.Points = int(metadata.G1PointsN)
+	desc.Sections = []manifest.Section{
+		{Name: "header", Offset: 0, Size: headerSize},
+		{Name: "g1", Offset: headerSize, Size: g1Size, Points: int(metadata.G1PointsN)},
+	}
+	if metadata.G2PointsN != 0 {
+		desc.Sections = append(desc.Sections, manifest.Section{Name: "g2", Offset: headerSize + g1Size, Size: g2Size, Points: int(metadata.G2PointsN)})
+	}
+	desc.Sections = append(desc.Sections, manifest.Section{Name: "checksum", Offset: headerSize + g1Size + g2Size, Size: int64(c.Checksum.Size())})
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package aztec
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+	"path/filepath"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc/secp256k1/ecdsa"
+	"golang.org/x/crypto/sha3"
+
+	"linea/aztec-srs-to-gnark/input"
+)
+
+// SignatureExt is the extension of the signature files of the transcripts,
+// the signature of transcript00.dat being transcript00.sig next to it.
+const SignatureExt = ".sig"
+
+// signatureSize is the size of an Ethereum signature: r, s and the recovery id v.
+const signatureSize = 65
+
+// signatureName returns the name of the signature file of the transcript.
+func signatureName(transcript string) string {
+	return strings.TrimSuffix(transcript, filepath.Ext(transcript)) + SignatureExt
+}
+
+// splitSignatures separates the signature files from the other files, which
+// are returned in the same order, and returns them by the name of their
+// transcript.
+func splitSignatures(files []input.File) ([]input.File, map[string]input.File) {
+	var others []input.File
+	signatures := make(map[string]input.File)
+	for _, file := range files {
+		if filepath.Ext(file.Name) == SignatureExt {
+			signatures[file.Name] = file
+			continue
+		}
+		others = append(others, file)
+	}
+
+	byTranscript := make(map[string]input.File, len(signatures))
+	for _, file := range others {
+		if signature, ok := signatures[signatureName(file.Name)]; ok {
+			byTranscript[file.Name] = signature
+		}
+	}
+
+	return others, byTranscript
+}
+
+// ValidateAddress checks that s is a hex encoded Ethereum address.
+func ValidateAddress(s string) error {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil || len(b) != 20 {
+		return fmt.Errorf("invalid Ethereum address %q", s)
+	}
+
+	return nil
+}
+
+// readSignature reads the hex encoded signature of the signature file.
+func readSignature(file input.File) ([]byte, error) {
+	f, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open signature file %s: %w", file.Name, err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, 1<<10))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signature file %s: %w", file.Name, err)
+	}
+
+	signature, err := hex.DecodeString(strings.TrimPrefix(string(bytes.TrimSpace(data)), "0x"))
+	if err != nil || len(signature) != signatureSize {
+		return nil, fmt.Errorf("signature file %s doesn't hold a hex encoded signature of %d bytes", file.Name, signatureSize)
+	}
+
+	return signature, nil
+}
+
+// recoverSigner returns the Ethereum address, hex encoded, of the signer of
+// the message, signed as an Ethereum signed message (EIP-191 personal_sign).
+func recoverSigner(message, signature []byte) (string, error) {
+	h := sha3.NewLegacyKeccak256()
+	fmt.Fprintf(h, "\x19Ethereum Signed Message:\n%d", len(message))
+	h.Write(message)
+	digest := h.Sum(nil)
+
+	r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:64])
+	v := uint(signature[64])
+	if v >= 27 {
+		v -= 27
+	}
+	if v > 1 {
+		return "", fmt.Errorf("invalid recovery id %d", signature[64])
+	}
+
+	var pub ecdsa.PublicKey
+	if err := pub.RecoverFrom(digest, v, r, s); err != nil {
+		return "", fmt.Errorf("failed to recover the signer: %w", err)
+	}
+
+	encoded := pub.A.RawBytes()
+	h.Reset()
+	h.Write(encoded[:])
+
+	return "0x" + hex.EncodeToString(h.Sum(nil)[12:]), nil
+}
+
+// verifySignature checks that the checksum ending the transcript is the hash of
+// the bytes before it, and returns the signer of the checksum. It must be the
+// signer of the ceremony, when it is set.
+func (c Ceremony) verifySignature(file input.File, checksum []byte, hashed *tailHash) (string, error) {
+	if got := hashed.Sum(); !bytes.Equal(got, checksum) {
+		return "", fmt.Errorf("the %s checksum ending the transcript isn't the one of its content", c.Checksum)
+	}
+
+	signature, err := readSignature(file)
+	if err != nil {
+		return "", err
+	}
+	signer, err := recoverSigner(checksum, signature)
+	if err != nil {
+		return "", err
+	}
+	if c.Signer != "" && !strings.EqualFold(signer, c.Signer) {
+		return "", fmt.Errorf("signed by %s, not by the signer of the ceremony %s", signer, c.Signer)
+	}
+
+	return signer, nil
+}
+
+// tailHash hashes what is written to it but its last bytes, the checksum
+// ending a transcript.
+type tailHash struct {
+	hash hash.Hash
+	tail []byte
+	size int
+}
+
+func newTailHash(h hash.Hash, size int) *tailHash {
+	return &tailHash{hash: h, tail: make([]byte, 0, 2*size), size: size}
+}
+
+func (t *tailHash) Write(p []byte) (int, error) {
+	n := len(p)
+	if len(p) > t.size {
+		// Only the last bytes can be the checksum
+		t.hash.Write(t.tail)
+		t.hash.Write(p[:len(p)-t.size])
+		t.tail, p = t.tail[:0], p[len(p)-t.size:]
+	}
+	t.tail = append(t.tail, p...)
+	if extra := len(t.tail) - t.size; extra > 0 {
+		t.hash.Write(t.tail[:extra])
+		t.tail = t.tail[:copy(t.tail, t.tail[extra:])]
+	}
+
+	return n, nil
+}
+
+// Sum returns the hash of the bytes written but the last ones.
+func (t *tailHash) Sum() []byte {
+	return t.hash.Sum(nil)
+}
+
+// errUnsigned is returned for the transcripts without a signature file when
+// the ceremony has a signer.
+var errUnsigned = errors.New("no signature file")
@@ -0,0 +1,230 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/lock"
+	"linea/aztec-srs-to-gnark/metrics"
+)
+
+// Version is part of every key, it changes when the conversion output changes.
+const Version = "1"
+
+var lookups = metrics.Default.NewCounter("srs_cache_lookups_total",
+	"Number of conversion cache lookups by result.", "result")
+
+// Cache keeps the outputs of conversions, keyed by the hashes of their setup
+// files and their parameters. The hashes of the setup files are memoized by
+// path, size and modification time, so only the first conversion of a file
+// has to read it.
+type Cache struct {
+	dir string
+}
+
+// digest is the memoized hash of a setup file.
+type digest struct {
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"mod_time"`
+	SHA256  string `json:"sha256"`
+}
+
+// Open opens the cache stored in dir, creating it if needed.
+func Open(dir string) (*Cache, error) {
+	for _, sub := range []string{"digests", "outputs"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create cache directory: %w", err)
+		}
+	}
+
+	return &Cache{dir: dir}, nil
+}
+
+// Key returns the key of the conversion of the files with the parameters. The
+// files must be stored locally.
+func (c *Cache) Key(files []input.File, params ...string) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "version %s\n", Version)
+	for _, param := range params {
+		fmt.Fprintf(h, "param %q\n", param)
+	}
+
+	for _, file := range files {
+		if file.Path == "" {
+			return "", fmt.Errorf("setup file %s isn't stored locally", file.Name)
+		}
+
+		sum, err := c.fileDigest(file.Path)
+		if err != nil {
+			return "", err
+		}
+
+		fmt.Fprintf(h, "file %q %s\n", file.Name, sum)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// fileDigest returns the SHA256 of the file, hashing it only if it changed
+// since it was last hashed.
+func (c *Cache) fileDigest(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to get file info of %s: %w", path, err)
+	}
+
+	// The same file is memoized once, whichever link or relative path it is
+	// reached through.
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	id := sha256.Sum256([]byte(abs))
+	memoPath := filepath.Join(c.dir, "digests", hex.EncodeToString(id[:])+".json")
+
+	var memo digest
+	if data, err := os.ReadFile(memoPath); err == nil && json.Unmarshal(data, &memo) == nil &&
+		memo.Size == info.Size() && memo.ModTime == info.ModTime().UnixNano() {
+		return memo.SHA256, nil
+	}
+
+	fmt.Printf("Hashing %s\n", path)
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	if _, err = io.Copy(h, file); err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+
+	memo = digest{Size: info.Size(), ModTime: info.ModTime().UnixNano(), SHA256: hex.EncodeToString(h.Sum(nil))}
+	if data, err := json.Marshal(memo); err == nil {
+		if err = os.WriteFile(memoPath, data, 0o644); err != nil {
+			fmt.Printf("WARNING: failed to memoize the hash of %s: %v\n", path, err)
+		}
+	}
+
+	return memo.SHA256, nil
+}
+
+// Restore links the outputs cached under key into dir and returns their
+// names, or false when the key isn't cached.
+func (c *Cache) Restore(key, dir string) ([]string, bool, error) {
+	entries, err := os.ReadDir(filepath.Join(c.dir, "outputs", key))
+	if errors.Is(err, os.ErrNotExist) || (err == nil && len(entries) == 0) {
+		lookups.Inc("miss")
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+
+	lookups.Inc("hit")
+
+	// The age of an entry is the time since it was last used, see Prune.
+	now := time.Now()
+	if err = os.Chtimes(filepath.Join(c.dir, "outputs", key), now, now); err != nil {
+		fmt.Printf("WARNING: failed to record the use of cache entry %s: %v\n", key, err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if err = link(filepath.Join(c.dir, "outputs", key, entry.Name()), filepath.Join(dir, entry.Name())); err != nil {
+			return nil, false, fmt.Errorf("failed to restore %s from cache: %w", entry.Name(), err)
+		}
+		names = append(names, entry.Name())
+	}
+
+	return names, true, nil
+}
+
+// Lock locks the entry of key, which a single conversion stores at a time.
+func (c *Cache) Lock(key string) (*lock.File, error) {
+	return lock.Acquire(filepath.Join(c.dir, "outputs", key+".lock"))
+}
+
+// Store caches the output files under key.
+func (c *Cache) Store(key string, paths ...string) error {
+	// The entry is built aside and renamed, so it's never seen incomplete.
+	tmp, err := os.MkdirTemp(filepath.Join(c.dir, "outputs"), "."+key+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to create cache entry: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	for _, path := range paths {
+		if err = link(path, filepath.Join(tmp, filepath.Base(path))); err != nil {
+			return fmt.Errorf("failed to cache %s: %w", path, err)
+		}
+	}
+
+	entry := filepath.Join(c.dir, "outputs", key)
+	os.RemoveAll(entry)
+
+	if err = os.Rename(tmp, entry); err != nil {
+		return fmt.Errorf("failed to store cache entry: %w", err)
+	}
+
+	return nil
+}
+
+// link hard links src to dst, copying it when linking isn't possible, e.g.
+// across file systems. An existing dst is replaced.
+func link(src, dst string) error {
+	if same, err := sameFile(src, dst); err == nil && same {
+		return nil
+	}
+
+	os.Remove(dst)
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+func sameFile(a, b string) (bool, error) {
+	infoA, err := os.Stat(a)
+	if err != nil {
+		return false, err
+	}
+
+	infoB, err := os.Stat(b)
+	if err != nil {
+		return false, err
+	}
+
+	return os.SameFile(infoA, infoB), nil
+}
@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Entry is a cached conversion.
+type Entry struct {
+	Key string
+	// Files are the names of the cached outputs.
+	Files []string
+	// Size is the total size of the outputs, which only frees disk space once
+	// the outputs restored from the entry are removed too, being hard links.
+	Size int64
+	// LastUsed is when the entry was stored or last restored.
+	LastUsed time.Time
+	// Incomplete is set for the entries left over by an interrupted Store.
+	Incomplete bool
+
+	path string
+}
+
+// Entries returns the entries of the cache, the least recently used first.
+func (c *Cache) Entries() ([]Entry, error) {
+	dir := filepath.Join(c.dir, "outputs")
+	dirs, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	entries := make([]Entry, 0, len(dirs))
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get info of cache entry %s: %w", d.Name(), err)
+		}
+
+		e := Entry{Key: d.Name(), LastUsed: info.ModTime(), path: filepath.Join(dir, d.Name())}
+		// Store builds the entries in ".<key>-<random>" directories.
+		if strings.HasPrefix(e.Key, ".") {
+			e.Key, _, _ = strings.Cut(e.Key[1:], "-")
+			e.Incomplete = true
+		}
+
+		files, err := os.ReadDir(e.path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cache entry %s: %w", d.Name(), err)
+		}
+		for _, f := range files {
+			info, err := f.Info()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get info of %s: %w", f.Name(), err)
+			}
+			e.Files = append(e.Files, f.Name())
+			e.Size += info.Size()
+		}
+
+		entries = append(entries, e)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastUsed.Before(entries[j].LastUsed)
+	})
+
+	return entries, nil
+}
+
+// Remove removes the entry from the cache.
+func (c *Cache) Remove(e Entry) error {
+	if err := os.RemoveAll(e.path); err != nil {
+		return fmt.Errorf("failed to remove cache entry %s: %w", e.Key, err)
+	}
+
+	return nil
+}
+
+// Prune selects the entries to remove, given the least recently used first:
+// the ones unused for longer than maxAge, then the least recently used ones
+// until the rest takes at most maxSize bytes. Zero maxAge and negative maxSize
+// disable the limits.
+func Prune(entries []Entry, now time.Time, maxAge time.Duration, maxSize int64) []Entry {
+	var total int64
+	for _, e := range entries {
+		total += e.Size
+	}
+
+	var pruned []Entry
+	for _, e := range entries {
+		expired := maxAge > 0 && now.Sub(e.LastUsed) > maxAge
+		if !expired && (maxSize < 0 || total <= maxSize) {
+			continue
+		}
+
+		pruned = append(pruned, e)
+		total -= e.Size
+	}
+
+	return pruned
+}
@@ -1,24 +1,32 @@
 package celo
 
 import (
+	"bufio"
+	"cmp"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
-	"os"
-	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 
 	bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761"
 	"github.com/consensys/gnark-crypto/ecc/bw6-761/fp"
 	bwKzg "github.com/consensys/gnark-crypto/ecc/bw6-761/kzg"
 	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/audit"
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/offheap"
+	"linea/aztec-srs-to-gnark/parallel"
+	"linea/aztec-srs-to-gnark/phase1"
+	"linea/aztec-srs-to-gnark/verify"
 )
 
 const (
-	// Hash size at the beginning of each file
-	HashSize = 64
 	// BW6-761 field element size in bytes
 	PointCoordinateSize = 96
 	// Size of a G1 point (x, y coordinates)
@@ -26,73 +34,217 @@ const (
 	// Size of a G2 point (x, y coordinates) - same size as G1 for BW6-761
 	G2PointSize = bw6761.SizeOfG2AffineUncompressed
 	// Total number of chunks (256) that divide the full SRS for manageable processing
-	// Each chunk typically contains 2^20 points, for a total of ~2^28 points across all chunks
+	// Each chunk contains 2^20 points in the Plumo ceremony, see Plumo
 	TotalChunks = 256
 	// Halfway point - chunks 128-255 only have G1 points and 1 beta_G2
-	// Chunks 0-127 contain G1, G2, alpha_G1, beta_G1, beta_G2
+	// Chunks 0-127 contain G1, G2, alpha_G1, beta_G1, beta_G2, in the Plumo ceremony
 	ChunkHalfwayPoint = 128
-	// Regex to extract the chunk number from filenames
+	// Regex to extract the round, the chunk number, the contribution id and the
+	// contributor from filenames
 	// Expected format: [round].[chunk_number].[contribution_id].[contributor_address]
-	ChunkNumberRegexp = `\d+\.(\d+)\..*`
+	ChunkFileRegexp = `^(\d+)\.(\d+)\.([^.]*)(?:\.(.*))?$`
+	// Size of the buffer used to read a single chunk file
+	readBufferSize = 4 << 20
+	// Number of G1 points read from a chunk file at once
+	g1ReadBatch = 1 << 12
 )
 
-var fileRegexp = regexp.MustCompile(ChunkNumberRegexp)
+var fileRegexp = regexp.MustCompile(ChunkFileRegexp)
 
-// TranslateBw6761SRS reads the Celo BW6-761 setup files and constructs a KZG SRS
-func TranslateBw6761SRS(setupDir string) (kzg.SRS, int, error) {
-	files, err := os.ReadDir(setupDir)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to read setup directory '%s': %w", setupDir, err)
+// TranslateBw6761SRS reads the setup files of the Plumo ceremony, see Ceremony.Translate.
+func TranslateBw6761SRS(files []input.File, opts config.Options) (kzg.SRS, int, error) {
+	return Plumo.Translate(files, opts)
+}
+
+// Translate reads the Celo BW6-761 setup files of the ceremony and constructs a KZG SRS
+// The size of every chunk file must be the one of the points of its chunk.
+// Up to opts.IOParallelism chunk files are read at the same time.
+// With opts.Phase1 the Groth16 phase 1 points are also read and a *phase1.SRS
+// is returned, with the mpcsetup Phase1 of gnark of the powers of τ of the
+// full chunks.
+// The files that aren't chunk files and the chunks that are missing or can't be
+// read are rejected with opts.Reject: under the lenient validation the SRS
+// ends before the points of the first chunk rejected.
+func (c Ceremony) Translate(files []input.File, opts config.Options) (kzg.SRS, int, error) {
+	if err := c.Validate(); err != nil {
+		return nil, 0, err
 	}
 
 	_, _, gen1Aff, gen2Aff := bw6761.Generators()
 
 	// Initialize SRS
 	srs := new(bwKzg.SRS)
-	srs.Pk.G1 = make([]bw6761.G1Affine, 0)
 	srs.Vk.G1 = gen1Aff
 	srs.Vk.G2[0] = gen2Aff
 
-	// Create a map to store chunks
-	chunkFiles := make(map[int]string)
-
-	// Extract chunk numbers from filenames
-	for _, file := range files {
-		matches := fileRegexp.FindStringSubmatch(file.Name())
-		if len(matches) <= 1 {
-			continue
+	var unexpected []input.File
+	chunkFiles, err := latestChunks(files, opts.Contributor, func(file input.File, chunkNum int, reason string) {
+		if chunkNum < 0 {
+			unexpected = append(unexpected, file)
+			opts.Audit.Record(audit.Event{Kind: audit.KindSetupFile, File: file.Name, Decision: "ignored: " + reason})
+		} else {
+			opts.Audit.Record(chunkEvent(file, chunkNum, "ignored: "+reason))
 		}
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, file := range unexpected {
+		opts.Ignore(file.Name, "not a chunk file")
+	}
+
+	fmt.Printf("Found %d chunk files\n", len(chunkFiles))
 
-		chunkNum, err := strconv.Atoi(matches[1])
+	// Compute where the points of each chunk go, up to the first chunk
+	// rejected under the lenient validation
+	chunksN := c.ChunksN
+	offsets := make([]int, c.ChunksN+1)
+	layouts := make([]chunkLayout, c.ChunksN)
+	for chunkNum := 0; chunkNum < c.ChunksN; chunkNum++ {
+		file, ok := chunkFiles[chunkNum]
+		if !ok && opts.Contributor != "" {
+			return nil, 0, fmt.Errorf("missing contribution of %s to chunk %d", opts.Contributor, chunkNum)
+		}
+		if !ok {
+			err = fmt.Errorf("missing chunk file for chunk %d", chunkNum)
+		} else if file.Size == input.UnknownSize {
+			return nil, 0, fmt.Errorf("size of chunk file %s, which tells a challenge from a response file, is unknown: decompress it first if it is compressed", file.Name)
+		} else if layouts[chunkNum], err = c.chunkLayout(chunkNum, file.Size); err != nil {
+			err = fmt.Errorf("chunk file %s: %w", file.Name, err)
+		}
 		if err != nil {
-			return nil, 0, fmt.Errorf("failed to parse chunk number from filename %s: %w", file.Name(), err)
+			if err = opts.Reject(config.Skip{File: chunkFiles[chunkNum].Name, Index: audit.Index(chunkNum), Offset: audit.Index(offsets[chunkNum]), Points: c.chunkPoints(chunkNum)}, err); err != nil {
+				return nil, 0, err
+			}
+			chunksN = chunkNum
+			c.skipChunks(chunkFiles, chunkNum+1, c.ChunksN, nil, opts)
+			break
 		}
+		offsets[chunkNum+1] = offsets[chunkNum] + layouts[chunkNum].points
+	}
+	if chunksN == 0 {
+		return nil, 0, fmt.Errorf("no G1 points left in the setup files")
+	}
+	if opts.Phase1 && chunksN < c.FullChunksN {
+		return nil, 0, fmt.Errorf("the Groth16 phase 1 points of chunk %d are missing", chunksN)
+	}
 
-		// If we have multiple files for the same chunk,
-		// we'll use the one that appears last alphabetically
-		// (which should be the latest contribution)
-		if existingFile, ok := chunkFiles[chunkNum]; !ok || strings.Compare(existingFile, file.Name()) < 0 {
-			chunkFiles[chunkNum] = file.Name()
+	srs.Pk.G1, err = offheap.Make[bw6761.G1Affine](offsets[chunksN], opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	opts.Progress.SetTotal(len(srs.Pk.G1))
+
+	// The powers of τ in G2 and the α and β powers are only in the full
+	// chunks, the first ones
+	var points *phase1.Points[bw6761.G1Affine, bw6761.G2Affine]
+	if opts.Phase1 {
+		points = new(phase1.Points[bw6761.G1Affine, bw6761.G2Affine])
+		if points.TauG2, err = offheap.Make[bw6761.G2Affine](offsets[c.FullChunksN], opts); err != nil {
+			return nil, 0, err
+		}
+		if points.AlphaTauG1, err = offheap.Make[bw6761.G1Affine](offsets[c.FullChunksN], opts); err != nil {
+			return nil, 0, err
+		}
+		if points.BetaTauG1, err = offheap.Make[bw6761.G1Affine](offsets[c.FullChunksN], opts); err != nil {
+			return nil, 0, err
 		}
 	}
 
-	fmt.Printf("Found %d chunk files\n", len(chunkFiles))
+	var (
+		checker *verify.Bw6761Checker
+		checks  *verify.Pipeline[bw6761.G1Affine]
+	)
+	if opts.Verify {
+		if checker, err = verify.NewBw6761Checker(); err != nil {
+			return nil, 0, err
+		}
+		checks = verify.NewPipeline(checker.Batch, opts.Workers)
+	}
 
-	// Process chunks in order
-	for chunkNum := 0; chunkNum < TotalChunks; chunkNum++ {
-		fileName, ok := chunkFiles[chunkNum]
-		if !ok {
-			return nil, 0, fmt.Errorf("missing chunk file for chunk %d", chunkNum)
+	// Chunks that fail to be processed are rejected, the SRS ends before the
+	// first one under the lenient validation
+	var (
+		failedMu sync.Mutex
+		failed   = make(map[int]bool)
+	)
+
+	err = parallel.Run(chunksN, opts.IOParallelism, func(chunkNum int) error {
+		file := chunkFiles[chunkNum]
+		if offsets[chunkNum+1] <= opts.Extend {
+			fmt.Printf("Skipping chunk %d: its G1 points %d-%d are in the extended SRS\n", chunkNum, offsets[chunkNum], offsets[chunkNum+1]-1)
+			event := chunkEvent(file, chunkNum, "covered by the extended SRS")
+			event.Offset, event.Points = offsets[chunkNum], offsets[chunkNum+1]-offsets[chunkNum]
+			opts.Audit.Record(event)
+			opts.Progress.Add(event.Points)
+			return nil
 		}
+		fmt.Printf("Processing chunk %d from %s file %s\n", chunkNum, layouts[chunkNum], file.Name)
 
-		filePath := filepath.Join(setupDir, fileName)
-		fmt.Printf("Processing chunk %d from file %s\n", chunkNum, fileName)
+		hash, err := processChunk(file, chunkNum, layouts[chunkNum], srs.Pk.G1[offsets[chunkNum]:offsets[chunkNum+1]], offsets[chunkNum], checks, srs, points)
+		event := chunkEvent(file, chunkNum, "used")
+		event.Offset, event.Points, event.Hash = offsets[chunkNum], offsets[chunkNum+1]-offsets[chunkNum], hex.EncodeToString(hash)
+		event.HashAlgorithm = string(c.Hash)
+		if err != nil {
+			err = fmt.Errorf("failed to process chunk %d: %w", chunkNum, err)
+			event.Decision = fmt.Sprintf("dropped: %v", err)
+		}
+		opts.Audit.Record(event)
 
-		err := processChunk(filePath, chunkNum, srs)
 		if err != nil {
-			fmt.Printf("failed to process chunk %d: %v\n", chunkNum, err)
+			if err = opts.Reject(config.Skip{File: file.Name, Index: event.Index, Offset: audit.Index(event.Offset), Points: event.Points}, err); err != nil {
+				return err
+			}
+
+			failedMu.Lock()
+			failed[chunkNum] = true
+			failedMu.Unlock()
+			return nil
 		}
+		opts.Progress.Add(event.Points)
+
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
 
+	if checker != nil {
+		err = checks.Wait()
+	}
+
+	if points != nil {
+		for chunkNum := range failed {
+			if layouts[chunkNum].full {
+				return nil, 0, fmt.Errorf("failed to read the Groth16 phase 1 points of chunk %d", chunkNum)
+			}
+		}
+	}
+
+	// The points are consecutive powers of τ, the SRS ends before the first
+	// chunk failed.
+	if first := firstFailed(failed); first >= 0 {
+		if first == 0 {
+			return nil, 0, fmt.Errorf("no G1 points left in the setup files")
+		}
+		c.skipChunks(chunkFiles, first+1, chunksN, failed, opts)
+		opts.Warn("chunk %d failed: the SRS ends at G1 point %d", first, offsets[first]-1)
+		srs.Pk.G1 = srs.Pk.G1[:offsets[first]]
+	}
+
+	if checker != nil {
+		switch {
+		case len(failed) != 0:
+			// The points skipped were checked too, so the fused checks don't apply
+			err = verify.SRS(srs, opts)
+		case err == nil:
+			err = checker.Finish(srs)
+		}
+		// Under the lenient validation the SRS ends before a malformed point
+		if err = verify.Recover(srs, opts, err); err != nil {
+			return nil, 0, fmt.Errorf("%w: %w", verify.ErrFailed, err)
+		}
+		fmt.Println("SRS verified: all G1 points are in the subgroup and are consecutive powers of tau")
 	}
 
 	// Precompute the lines when the G2 points are set
@@ -101,148 +253,321 @@ func TranslateBw6761SRS(setupDir string) (kzg.SRS, int, error) {
 		srs.Vk.Lines[1] = bw6761.PrecomputeLines(srs.Vk.G2[1])
 	}
 
+	if points != nil {
+		points.TauG1 = srs.Pk.G1
+		p, err := phase1.Bw6761(*points)
+		if err != nil {
+			return nil, 0, err
+		}
+		fmt.Printf("Read the Groth16 phase 1 of %d powers of τ in G2\n", len(points.TauG2))
+		return &phase1.SRS{SRS: srs, Phase1: p}, len(srs.Pk.G1), nil
+	}
+
 	return srs, len(srs.Pk.G1), nil
 }
 
-func processChunk(filePath string, chunkNum int, srs *bwKzg.SRS) error {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to open file: %w", err)
+// chunkName is what the name of a chunk file tells about it:
+// [round].[chunk_number].[contribution_id].[contributor_address]
+type chunkName struct {
+	round, chunk int
+	contribution string
+	contributor  string
+}
+
+// parseChunkName parses the name of a chunk file, reporting whether it is one.
+func parseChunkName(name string) (chunkName, bool, error) {
+	matches := fileRegexp.FindStringSubmatch(name)
+	if matches == nil {
+		return chunkName{}, false, nil
 	}
-	defer file.Close()
 
-	fileInfo, err := file.Stat()
+	round, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return chunkName{}, false, fmt.Errorf("failed to parse round from filename %s: %w", name, err)
+	}
+	chunk, err := strconv.Atoi(matches[2])
 	if err != nil {
-		return fmt.Errorf("failed to get file info: %w", err)
+		return chunkName{}, false, fmt.Errorf("failed to parse chunk number from filename %s: %w", name, err)
+	}
+
+	return chunkName{round: round, chunk: chunk, contribution: matches[3], contributor: matches[4]}, true, nil
+}
+
+// compareContributions orders the contributions to a chunk by round and then
+// contribution id, numerically when the ids are numbers: contribution 10
+// comes after contribution 9.
+func compareContributions(a, b chunkName) int {
+	if a.round != b.round {
+		return cmp.Compare(a.round, b.round)
 	}
-	fileSize := fileInfo.Size()
 
-	// Skip the hash at the beginning of the file
-	if _, err := file.Seek(int64(HashSize), io.SeekStart); err != nil {
-		return fmt.Errorf("failed to seek past hash: %w", err)
+	idA, errA := strconv.Atoi(a.contribution)
+	idB, errB := strconv.Atoi(b.contribution)
+	if errA == nil && errB == nil {
+		return cmp.Compare(idA, idB)
 	}
 
-	// Calculate chunk size
-	chunkSize := calculateChunkSize(chunkNum, fileSize)
-	buffer := make([]byte, G1PointSize)
-	pointsToRead := chunkSize
-	pointsProcessed := 0
-	pointsAdded := 0
+	return strings.Compare(a.contribution, b.contribution)
+}
+
+// latestChunks maps the chunk numbers to the chunk files. When a chunk has
+// several files, the latest contribution is used, see compareContributions,
+// or the contribution of contributor when set. The files that aren't used are
+// passed to skipped with the reason, and chunk number -1 if they aren't chunk
+// files.
+func latestChunks(files []input.File, contributor string, skipped func(file input.File, chunkNum int, reason string)) (map[int]input.File, error) {
+	chunkFiles := make(map[int]input.File)
+	names := make(map[int]chunkName)
 
-	// Process G1 points
-	for i := 0; i < pointsToRead; i++ {
-		_, err = io.ReadFull(file, buffer)
+	for _, file := range files {
+		name, ok, err := parseChunkName(file.Name)
 		if err != nil {
-			return fmt.Errorf("error reading file at point %d: %w", i, err)
+			return nil, err
+		}
+		if !ok {
+			skipped(file, -1, "not a chunk file")
+			continue
 		}
 
-		pointsProcessed++
+		chunkNum := name.chunk
+		if contributor != "" && !strings.EqualFold(name.contributor, contributor) {
+			skipped(file, chunkNum, "contribution of another participant than "+contributor)
+			continue
+		}
 
-		x, err := extractBw6FieldElement(buffer[:PointCoordinateSize])
-		if err != nil {
-			return fmt.Errorf("failed to extract x coordinate: %w", err)
+		existing, ok := chunkFiles[chunkNum]
+		if !ok {
+			chunkFiles[chunkNum], names[chunkNum] = file, name
+			continue
 		}
 
-		y, err := extractBw6FieldElement(buffer[PointCoordinateSize:])
-		if err != nil {
-			return fmt.Errorf("failed to extract y coordinate: %w", err)
+		order := compareContributions(names[chunkNum], name)
+		if order == 0 {
+			return nil, fmt.Errorf("chunk files %s and %s are the same contribution to chunk %d", existing.Name, file.Name, chunkNum)
 		}
 
-		point := bw6761.G1Affine{X: x, Y: y}
+		superseded, latest := file, existing
+		if order < 0 {
+			superseded, latest = existing, file
+			chunkFiles[chunkNum], names[chunkNum] = file, name
+		}
+		skipped(superseded, chunkNum, "superseded by "+latest.Name)
+	}
+
+	return chunkFiles, nil
+}
 
-		if point.IsInfinity() || !point.IsOnCurve() {
-			return fmt.Errorf("point at index %d is not on curve or infinity", i)
+// chunkEvent returns the audit event of a chunk file, with the contribution
+// and the participant found in its name.
+func chunkEvent(file input.File, chunkNum int, decision string) audit.Event {
+	event := audit.Event{Kind: audit.KindChunk, File: file.Name, Index: audit.Index(chunkNum), Decision: decision}
+
+	if name, ok, _ := parseChunkName(file.Name); ok {
+		event.Contribution, event.Participant = name.contribution, name.contributor
+	}
+
+	return event
+}
+
+// firstFailed returns the lowest chunk number of failed, -1 when it is empty.
+func firstFailed(failed map[int]bool) int {
+	first := -1
+	for chunkNum := range failed {
+		if first < 0 || chunkNum < first {
+			first = chunkNum
 		}
+	}
+
+	return first
+}
 
-		srs.Pk.G1 = append(srs.Pk.G1, point)
-		pointsAdded++
+// skipChunks records the chunks from the chunk number from up to to as
+// skipped, their points following the ones of a chunk rejected under the
+// lenient validation. The failed chunks are already recorded.
+func (c Ceremony) skipChunks(chunkFiles map[int]input.File, from, to int, failed map[int]bool, opts config.Options) {
+	for chunkNum := from; chunkNum < to; chunkNum++ {
+		if file, ok := chunkFiles[chunkNum]; ok && !failed[chunkNum] {
+			opts.Skipped.Add(config.Skip{File: file.Name, Index: audit.Index(chunkNum), Offset: audit.Index(chunkNum * c.ChunkG1PointsN), Points: c.chunkPoints(chunkNum), Reason: fmt.Sprintf("follows the rejected chunk %d", from-1)})
+		}
 	}
+}
+
+// processChunk reads the G1 points of the chunk file of the layout into points,
+// which must be exactly layout.points long and start at index offset of the SRS.
+// Chunk 0 also provides the τG2 point. When groth16 is set, the powers of τ in
+// G2 and the α and β powers of the chunks holding them are read at the same
+// offset, and chunk 0 also provides the βG2 point. The hash at the beginning
+// of the file is returned.
+func processChunk(chunkFile input.File, chunkNum int, layout chunkLayout, points []bw6761.G1Affine, offset int, checks *verify.Pipeline[bw6761.G1Affine], srs *bwKzg.SRS, groth16 *phase1.Points[bw6761.G1Affine, bw6761.G2Affine]) ([]byte, error) {
+	f, err := chunkFile.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	file := bufio.NewReaderSize(f, readBufferSize)
+
+	// The hash at the beginning of the file is only recorded
+	hash := make([]byte, layout.hashSize)
+	if _, err := io.ReadFull(file, hash); err != nil {
+		return nil, fmt.Errorf("failed to read hash: %w", err)
+	}
+	if err = input.Skip(file, layout.extension); err != nil {
+		return hash, fmt.Errorf("failed to skip header extension: %w", err)
+	}
+
+	// Process G1 points, reading them in batches into a single reusable buffer
+	buffer := make([]byte, g1ReadBatch*G1PointSize)
+	if err = readG1Points(file, layout, buffer, points, offset, checks); err != nil {
+		return hash, err
+	}
+
+	// File structure for the full chunks:
+	// [hash]
+	// [tau_g1 points]
+	// [tau_g2 points]
+	// [alpha_g1 points]
+	// [beta_g1 points]
+	// [beta_g2 point]
+	//
+	// At this point, we've already read all the tau_g1 points,
+	// so the file pointer should already be positioned at the beginning
+	// of the G2 points section.
+	g2Read := 0
 
 	// If this is chunk 0, also process the G2 points
 	if chunkNum == 0 {
-		// File structure for chunk 0:
-		// [hash]
-		// [tau_g1 points]
-		// [tau_g2 points]
-		// [alpha_g1 points]
-		// [beta_g1 points]
-		// [beta_g2 point]
-
-		// At this point, we've already read all the tau_g1 points,
-		// so the file pointer should already be positioned at the beginning
-		// of the G2 points section.
-
 		// Read the generator (first G2 point)
-		g2GeneratorBuffer := make([]byte, G2PointSize)
-		if _, err := io.ReadFull(file, g2GeneratorBuffer); err != nil {
-			return fmt.Errorf("failed to read G2 generator: %w", err)
+		g2Generator, err := readG2Point(file, layout, "G2 generator")
+		if err != nil {
+			return hash, err
 		}
 
-		g2GenX, err := extractBw6FieldElement(g2GeneratorBuffer[:PointCoordinateSize])
-		if err != nil {
-			return fmt.Errorf("failed to parse G2 generator X coordinate: %w", err)
+		// Verify this matches the expected G2 generator
+		_, _, _, expectedGen2 := bw6761.Generators()
+		if !g2Generator.Equal(&expectedGen2) {
+			return hash, errors.New("G2 generator in file doesn't match expected generator")
 		}
 
-		g2GenY, err := extractBw6FieldElement(g2GeneratorBuffer[PointCoordinateSize:])
+		// Read tau*G2 (second G2 point - tau^1 * G2)
+		tauG2, err := readG2Point(file, layout, "τG2")
 		if err != nil {
-			return fmt.Errorf("failed to parse G2 generator Y coordinate: %w", err)
+			return hash, err
 		}
 
-		g2Generator := bw6761.G2Affine{X: g2GenX, Y: g2GenY}
-		if !g2Generator.IsOnCurve() {
-			return fmt.Errorf("G2 generator point is not on curve")
+		// Store the tau*G2 point in the SRS verification key
+		srs.Vk.G2[1] = tauG2
+		fmt.Printf("Added τG2 from chunk 0\n")
+
+		if groth16 != nil {
+			groth16.TauG2[0], groth16.TauG2[1] = g2Generator, tauG2
 		}
+		g2Read = 2
+	}
 
-		// Verify this matches the expected G2 generator
-		_, _, _, expectedGen2 := bw6761.Generators()
-		if !g2Generator.Equal(&expectedGen2) {
-			return errors.New("G2 generator in file doesn't match expected generator")
+	if groth16 != nil && layout.full {
+		for i := offset + g2Read; i < offset+len(points); i++ {
+			if groth16.TauG2[i], err = readG2Point(file, layout, fmt.Sprintf("τ^%d·G2", i)); err != nil {
+				return hash, err
+			}
 		}
 
-		// Read tau*G2 (second G2 point - tau^1 * G2)
-		tauG2Buffer := make([]byte, G2PointSize)
-		if _, err := io.ReadFull(file, tauG2Buffer); err != nil {
-			return fmt.Errorf("failed to read τG2: %w", err)
+		if err = readG1Points(file, layout, buffer, groth16.AlphaTauG1[offset:offset+len(points)], offset, nil); err != nil {
+			return hash, fmt.Errorf("failed to read α powers: %w", err)
+		}
+		if err = readG1Points(file, layout, buffer, groth16.BetaTauG1[offset:offset+len(points)], offset, nil); err != nil {
+			return hash, fmt.Errorf("failed to read β powers: %w", err)
 		}
 
-		tauG2x, err := extractBw6FieldElement(tauG2Buffer[:PointCoordinateSize])
+		// Every chunk ends with the same βG2 point
+		betaG2, err := readG2Point(file, layout, "βG2")
 		if err != nil {
-			return fmt.Errorf("failed to parse τG2 X coordinate: %w", err)
+			return hash, err
+		}
+		if chunkNum == 0 {
+			groth16.BetaG2 = betaG2
 		}
+	}
 
-		tauG2y, err := extractBw6FieldElement(tauG2Buffer[PointCoordinateSize:])
-		if err != nil {
-			return fmt.Errorf("failed to parse τG2 Y coordinate: %w", err)
+	fmt.Printf("Chunk %d: Processed %d points\n", chunkNum, len(points))
+
+	return hash, nil
+}
+
+// readG1Points reads len(points) G1 points of the layout in batches of
+// g1ReadBatch through buffer, passing each batch to checks at its index in the
+// SRS.
+func readG1Points(file *bufio.Reader, layout chunkLayout, buffer []byte, points []bw6761.G1Affine, offset int, checks *verify.Pipeline[bw6761.G1Affine]) error {
+	var err error
+	pointSize := int(layout.pointSize())
+	for from := 0; from < len(points); from += g1ReadBatch {
+		batch := points[from:min(from+g1ReadBatch, len(points))]
+		data := buffer[:len(batch)*pointSize]
+
+		if _, err = io.ReadFull(file, data); err != nil {
+			return fmt.Errorf("error reading file at point %d: %w", from, err)
 		}
 
-		tauG2 := bw6761.G2Affine{X: tauG2x, Y: tauG2y}
-		if !tauG2.IsOnCurve() {
-			return fmt.Errorf("tau*G2 point is not on curve")
+		for i := range batch {
+			point := data[i*pointSize : (i+1)*pointSize]
+
+			if layout.response {
+				if err = decodeCompressedG1(point, &batch[i]); err != nil {
+					return fmt.Errorf("failed to decompress point %d: %w", from+i, err)
+				}
+				continue
+			}
+
+			if batch[i].X, err = fp.LittleEndian.Element((*[PointCoordinateSize]byte)(point[:PointCoordinateSize])); err != nil {
+				return fmt.Errorf("failed to extract x coordinate of point %d: %w", from+i, err)
+			}
+			if batch[i].Y, err = fp.LittleEndian.Element((*[PointCoordinateSize]byte)(point[PointCoordinateSize:])); err != nil {
+				return fmt.Errorf("failed to extract y coordinate of point %d: %w", from+i, err)
+			}
+
+			if batch[i].IsInfinity() || !batch[i].IsOnCurve() {
+				return fmt.Errorf("point at index %d is not on curve or infinity", from+i)
+			}
 		}
 
-		// Store the tau*G2 point in the SRS verification key
-		srs.Vk.G2[1] = tauG2
-		fmt.Printf("Added τG2 from chunk 0\n")
+		checks.Check(offset+from, batch)
 	}
 
-	fmt.Printf("Chunk %d: Processed %d points, added %d valid points\n",
-		chunkNum, pointsProcessed, pointsAdded)
-
 	return nil
 }
 
-func calculateChunkSize(chunkNum int, fileSize int64) int {
-	// All chunks < ChunkHalfwayPoint have tau_g1, tau_g2, alpha_g1, beta_g1
-	if chunkNum < ChunkHalfwayPoint {
-		// Estimate based on file size - hash
-		availableBytes := fileSize - int64(HashSize)
-		// tau_g1 takes 1/4
-		return int(availableBytes / (4 * int64(G1PointSize)))
-	}
-	// Chunks >= ChunkHalfwayPoint have tau_g1 + beta_g2
-	// So subtract 1 beta_g2 point from the total count
-	totalPoints := int((fileSize - int64(HashSize)) / int64(G1PointSize))
-	return totalPoints - 1 // Subtract beta_g2
+// readG2Point reads the G2 point named name of the layout and checks that it
+// is on the curve.
+func readG2Point(file io.Reader, layout chunkLayout, name string) (bw6761.G2Affine, error) {
+	buffer := make([]byte, layout.pointSize())
+	if _, err := io.ReadFull(file, buffer); err != nil {
+		return bw6761.G2Affine{}, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	if layout.response {
+		var point bw6761.G2Affine
+		if err := decodeCompressedG2(buffer, &point); err != nil {
+			return bw6761.G2Affine{}, fmt.Errorf("failed to decompress %s: %w", name, err)
+		}
+		return point, nil
+	}
+
+	x, err := extractBw6FieldElement(buffer[:PointCoordinateSize])
+	if err != nil {
+		return bw6761.G2Affine{}, fmt.Errorf("failed to parse %s X coordinate: %w", name, err)
+	}
+
+	y, err := extractBw6FieldElement(buffer[PointCoordinateSize:])
+	if err != nil {
+		return bw6761.G2Affine{}, fmt.Errorf("failed to parse %s Y coordinate: %w", name, err)
+	}
+
+	point := bw6761.G2Affine{X: x, Y: y}
+	if !point.IsOnCurve() {
+		return bw6761.G2Affine{}, fmt.Errorf("%s point is not on curve", name)
+	}
+
+	return point, nil
 }
 
 func extractBw6FieldElement(data []byte) (fp.Element, error) {
@@ -0,0 +1,80 @@
+package celo
+
+import (
+	"errors"
+	"fmt"
+
+	bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761"
+	bwKzg "github.com/consensys/gnark-crypto/ecc/bw6-761/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/input"
+)
+
+// ExtractTauG2 reads τG2 from the setup files of the Plumo ceremony, see
+// Ceremony.ExtractTauG2.
+func ExtractTauG2(files []input.File) (kzg.SRS, error) {
+	return Plumo.ExtractTauG2(files)
+}
+
+// ExtractTauG2 returns an SRS with only the verifying key, read from the
+// τG2 point of chunk 0 of the ceremony. The G1 points of the chunk are skipped
+// over without being parsed.
+func (c Ceremony) ExtractTauG2(files []input.File) (kzg.SRS, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	chunkFiles, err := latestChunks(files, "", func(input.File, int, string) {})
+	if err != nil {
+		return nil, err
+	}
+
+	file, ok := chunkFiles[0]
+	if !ok {
+		return nil, errors.New("missing chunk file for chunk 0")
+	}
+	if file.Size == input.UnknownSize {
+		return nil, fmt.Errorf("size of chunk file %s, which tells a challenge from a response file, is unknown: decompress it first if it is compressed", file.Name)
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open chunk file %s: %w", file.Name, err)
+	}
+	defer f.Close()
+
+	layout, err := c.chunkLayout(0, file.Size)
+	if err != nil {
+		return nil, fmt.Errorf("chunk file %s: %w", file.Name, err)
+	}
+
+	// [hash] [tau_g1 points] [tau_g2 points], the first of which is the generator
+	if err = input.Skip(f, layout.hashSize+layout.extension+int64(layout.points)*layout.pointSize()); err != nil {
+		return nil, fmt.Errorf("failed to skip the G1 points of %s: %w", file.Name, err)
+	}
+
+	_, _, gen1Aff, gen2Aff := bw6761.Generators()
+
+	g2Generator, err := readG2Point(f, layout, "G2 generator")
+	if err != nil {
+		return nil, err
+	}
+	if !g2Generator.Equal(&gen2Aff) {
+		return nil, errors.New("G2 generator in file doesn't match expected generator")
+	}
+
+	tauG2, err := readG2Point(f, layout, "τG2")
+	if err != nil {
+		return nil, err
+	}
+
+	srs := new(bwKzg.SRS)
+	srs.Vk.G1 = gen1Aff
+	srs.Vk.G2[0] = gen2Aff
+	srs.Vk.G2[1] = tauG2
+	srs.Vk.Lines[0] = bw6761.PrecomputeLines(srs.Vk.G2[0])
+	srs.Vk.Lines[1] = bw6761.PrecomputeLines(srs.Vk.G2[1])
+
+	return srs, nil
+}
@@ -0,0 +1,240 @@
+package celo
+
+import (
+	"fmt"
+
+	bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761"
+	"github.com/consensys/gnark-crypto/ecc/bw6-761/fp"
+
+	"linea/aztec-srs-to-gnark/digest"
+	"linea/aztec-srs-to-gnark/revision"
+)
+
+const (
+	// Size of a compressed G1 or G2 point: the x coordinate with the flags
+	// in the most significant bits of its last byte
+	CompressedPointSize = PointCoordinateSize
+	// Size of the public key ending a response file: τ, α and β in G1 twice
+	// and in G2 once, uncompressed
+	PublicKeySize = 6*G1PointSize + 3*G2PointSize
+
+	// Flags of a compressed point: the y coordinate is the lexicographically
+	// largest of the two, and the point at infinity
+	flagLargestY = 1 << 7
+	flagInfinity = 1 << 6
+)
+
+// chunkLayout is the layout of a chunk file. The ceremony published a
+// challenge and a response file for every contribution to a chunk:
+//
+//	challenge: [hash of the previous response] [points, uncompressed]
+//	response:  [hash of the challenge] [points, compressed] [public key]
+type chunkLayout struct {
+	// response is set for the response files
+	response bool
+	// points is the number of powers of tau in G1 of the chunk
+	points int
+	// full is set for the chunks also holding the powers of tau in G2 and the
+	// α and β powers in G1
+	full bool
+	// hashSize is the size of the hash starting the file
+	hashSize int64
+	// extension is the size of the header extension following the hash, see
+	// revision.Format
+	extension int64
+}
+
+func (l chunkLayout) String() string {
+	if l.response {
+		return "response"
+	}
+	return "challenge"
+}
+
+// pointSize is the size of a G1 or G2 point of the file.
+func (l chunkLayout) pointSize() int64 {
+	if l.response {
+		return CompressedPointSize
+	}
+	return G1PointSize
+}
+
+// size returns the size of the file of the chunk: tau_g1, tau_g2, alpha_g1 and
+// beta_g1 for the full chunks and tau_g1 alone for the others, then beta_g2.
+func (l chunkLayout) size() int64 {
+	sections := int64(1)
+	if l.full {
+		sections = 4
+	}
+
+	size := l.hashSize + l.extension + (sections*int64(l.points)+1)*l.pointSize()
+	if l.response {
+		size += PublicKeySize
+	}
+
+	return size
+}
+
+// Ceremony describes the chunks of a Celo ceremony, which the sizes of the
+// chunk files are checked against. Variants of the ceremony run with the same
+// software differ by these constants.
+type Ceremony struct {
+	// ChunkG1PointsN is the number of powers of τ in G1 of every chunk but the last one.
+	ChunkG1PointsN int `json:"chunk_g1_points"`
+	// G1PointsN is the number of powers of τ in G1 of the ceremony, the last
+	// chunk holding the rest.
+	G1PointsN int `json:"g1_points"`
+	// ChunksN is the number of chunks.
+	ChunksN int `json:"chunks"`
+	// FullChunksN is the number of the first chunks also holding the powers
+	// of τ in G2 and the α and β powers in G1.
+	FullChunksN int `json:"full_chunks"`
+	// Hash is the hash algorithm of the hash starting every chunk file, of
+	// the previous response in a challenge and of the challenge in a response.
+	Hash digest.Algorithm `json:"hash"`
+	// Format is the revision of the chunk file format, the one of Plumo when
+	// unset. Its header extension follows the hash.
+	Format revision.Format `json:"format"`
+}
+
+// Plumo is the Plumo ceremony of Celo: 2^28 - 1 powers of τ in G1, in 256
+// chunks of 2^20.
+var Plumo = Ceremony{
+	ChunkG1PointsN: 1 << 20,
+	G1PointsN:      1<<28 - 1,
+	ChunksN:        TotalChunks,
+	FullChunksN:    ChunkHalfwayPoint,
+	Hash:           digest.BLAKE2b512,
+}
+
+// Validate checks that the constants of the ceremony are consistent: every
+// chunk holds points, and chunk 0 at least the 2 G2 points.
+func (c Ceremony) Validate() error {
+	switch {
+	case c.ChunksN < 1 || c.FullChunksN < 1 || c.FullChunksN > c.ChunksN:
+		return fmt.Errorf("a ceremony of %d chunks can't have %d full chunks", c.ChunksN, c.FullChunksN)
+	case c.ChunkG1PointsN < 2:
+		return fmt.Errorf("the chunks must hold at least 2 points, not %d", c.ChunkG1PointsN)
+	case c.G1PointsN <= (c.ChunksN-1)*c.ChunkG1PointsN || c.G1PointsN > c.ChunksN*c.ChunkG1PointsN:
+		return fmt.Errorf("%d points don't fill %d chunks of %d points, the last one holding the rest", c.G1PointsN, c.ChunksN, c.ChunkG1PointsN)
+	}
+	if err := c.Format.Validate(formatName, revision.Latest); err != nil {
+		return err
+	}
+
+	return c.Hash.Validate()
+}
+
+// formatName is the name of the chunk file format in the errors.
+const formatName = "celo chunk"
+
+// chunkPoints returns the number of powers of τ in G1 of the chunk.
+func (c Ceremony) chunkPoints(chunkNum int) int {
+	return min(c.ChunkG1PointsN, c.G1PointsN-chunkNum*c.ChunkG1PointsN)
+}
+
+// chunkLayout tells a challenge file from a response file of the chunk by its
+// size, which must be the one of either layout holding the points of the chunk.
+func (c Ceremony) chunkLayout(chunkNum int, fileSize int64) (chunkLayout, error) {
+	points := c.chunkPoints(chunkNum)
+	if chunkNum >= c.ChunksN || points < 1 {
+		return chunkLayout{}, fmt.Errorf("the ceremony has %d points in %d chunks, none left for chunk %d", c.G1PointsN, c.ChunksN, chunkNum)
+	}
+
+	challenge := chunkLayout{points: points, full: chunkNum < c.FullChunksN, hashSize: int64(c.Hash.Size()), extension: c.Format.HeaderExtension}
+	response := challenge
+	response.response = true
+	switch fileSize {
+	case challenge.size():
+		if fileSize == response.size() {
+			return chunkLayout{}, fmt.Errorf("size of %d bytes is the one of both a challenge and a response file of chunk %d", fileSize, chunkNum)
+		}
+		return challenge, nil
+	case response.size():
+		return response, nil
+	}
+
+	// A revision of the format with a longer header
+	for _, layout := range []chunkLayout{challenge, response} {
+		if err := c.Format.CheckSize(formatName, fileSize, layout.size()); err != nil {
+			return chunkLayout{}, fmt.Errorf("%s file of chunk %d: %w", layout, chunkNum, err)
+		}
+	}
+
+	return chunkLayout{}, fmt.Errorf("size of %d bytes is neither the one of the challenge (%d bytes) nor of the response file (%d bytes) of chunk %d, which holds %d points",
+		fileSize, challenge.size(), response.size(), chunkNum, points)
+}
+
+// decodeCompressed decodes the x coordinate of a compressed point, returning
+// the flags of its last byte.
+func decodeCompressed(data []byte) (x fp.Element, flags byte, err error) {
+	var buffer [PointCoordinateSize]byte
+	copy(buffer[:], data)
+	flags = buffer[PointCoordinateSize-1] & (flagLargestY | flagInfinity)
+	buffer[PointCoordinateSize-1] &^= flagLargestY | flagInfinity
+
+	x, err = fp.LittleEndian.Element(&buffer)
+	return x, flags, err
+}
+
+// decompressY computes the y coordinate of the point of the curve
+// y² = x³ + b with the x coordinate, the one the flags select.
+func decompressY(x, b *fp.Element, flags byte) (fp.Element, error) {
+	var y fp.Element
+	y.Square(x).Mul(&y, x).Add(&y, b)
+	if y.Sqrt(&y) == nil {
+		return y, fmt.Errorf("x coordinate isn't the one of a point of the curve")
+	}
+
+	if y.LexicographicallyLargest() != (flags&flagLargestY != 0) {
+		y.Neg(&y)
+	}
+
+	return y, nil
+}
+
+// Coefficients b of the curves of G1, y² = x³ - 1, and G2, y² = x³ + 4
+var g1B, g2B = func() (fp.Element, fp.Element) {
+	var g1, g2 fp.Element
+	g1.SetOne().Neg(&g1)
+	g2.SetUint64(4)
+	return g1, g2
+}()
+
+// decodeCompressedG1 decodes a compressed G1 point of a response file.
+func decodeCompressedG1(data []byte, p *bw6761.G1Affine) error {
+	x, flags, err := decodeCompressed(data)
+	if err != nil {
+		return err
+	}
+	if flags&flagInfinity != 0 {
+		return fmt.Errorf("point at infinity")
+	}
+
+	y, err := decompressY(&x, &g1B, flags)
+	if err != nil {
+		return err
+	}
+	p.X, p.Y = x, y
+
+	return nil
+}
+
+// decodeCompressedG2 decodes a compressed G2 point of a response file.
+func decodeCompressedG2(data []byte, p *bw6761.G2Affine) error {
+	x, flags, err := decodeCompressed(data)
+	if err != nil {
+		return err
+	}
+	if flags&flagInfinity != 0 {
+		return fmt.Errorf("point at infinity")
+	}
+
+	y, err := decompressY(&x, &g2B, flags)
+	if err != nil {
+		return err
+	}
+	p.X, p.Y = x, y
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package celo
+
+import (
+	"fmt"
+
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/manifest"
+)
+
+// Describe describes the chunk files of the Plumo ceremony, see Ceremony.Describe.
+func Describe(files []input.File, descs []manifest.File) error {
+	return Plumo.Describe(files, descs)
+}
+
+// Describe describes the chunk files of the ceremony from their names and
+// sizes: their chunk numbers and the sections of their points. The files which
+// aren't chunk files or are superseded by a later contribution to their chunk
+// are ignored.
+func (c Ceremony) Describe(files []input.File, descs []manifest.File) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	chunkFiles, err := latestChunks(files, "", func(input.File, int, string) {})
+	if err != nil {
+		return err
+	}
+	chunkNums := make(map[string]int, len(chunkFiles))
+	for chunkNum, file := range chunkFiles {
+		chunkNums[file.Name] = chunkNum
+	}
+
+	for i, file := range files {
+		desc := &descs[i]
+		chunkNum, ok := chunkNums[file.Name]
+		if !ok {
+			desc.Kind = manifest.KindIgnored
+			continue
+		}
+
+		if file.Size == input.UnknownSize {
+			return fmt.Errorf("size of chunk file %s, which tells a challenge from a response file, is unknown: decompress it first if it is compressed", file.Name)
+		}
+
+		layout, err := c.chunkLayout(chunkNum, file.Size)
+		if err != nil {
+			return fmt.Errorf("chunk file %s: %w", file.Name, err)
+		}
+		n := layout.points
+		size := int64(n) * layout.pointSize()
+
+		desc.Kind = "chunk"
+		desc.Index = &chunkNum
+		desc.Points = n
+		desc.Sections = []manifest.Section{{Name: "hash", Offset: 0, Size: layout.hashSize}}
+		if layout.extension != 0 {
+			desc.Sections = append(desc.Sections, manifest.Section{Name: "header_extension", Offset: layout.hashSize, Size: layout.extension})
+		}
+		offset := layout.hashSize + layout.extension
+		desc.Sections = append(desc.Sections, manifest.Section{Name: "tau_g1", Offset: offset, Size: size, Points: n})
+		offset += size
+		if layout.full {
+			for _, name := range []string{"tau_g2", "alpha_g1", "beta_g1"} {
+				desc.Sections = append(desc.Sections, manifest.Section{Name: name, Offset: offset, Size: size, Points: n})
+				offset += size
+			}
+		}
+		desc.Sections = append(desc.Sections, manifest.Section{Name: "beta_g2", Offset: offset, Size: layout.pointSize(), Points: 1})
+		if layout.response {
+			desc.Sections = append(desc.Sections, manifest.Section{Name: "public_key", Offset: offset + layout.pointSize(), Size: PublicKeySize})
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/srsio"
+	"linea/aztec-srs-to-gnark/verify"
+)
+
+// checkPrefix checks that the shorter of two SRS files is a prefix of the
+// other, e.g. that a truncated or re-published SRS derives from the same
+// ceremony as a trusted original.
+func checkPrefix(args []string) error {
+	var opts config.Options
+
+	flags := flag.NewFlagSet("check-prefix", flag.ExitOnError)
+	batchSize := flags.Int("batch-size", srsio.DefaultCompareBatch, "number of points compared at once")
+	flags.BoolVar(&opts.Verify, "verify", false, "also verify that the points of the longer SRS are consecutive powers of tau, i.e. that it is a valid extension of the shorter one")
+	flags.IntVar(&opts.Workers, "workers", 0, "number of CPU workers verifying the points (0 - auto)")
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s check-prefix [flags] <SRS file> <SRS file>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() < 2 {
+		flags.Usage()
+		return errUsage
+	}
+
+	short, err := srsio.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer short.Close()
+
+	long, err := srsio.Open(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+	defer long.Close()
+
+	shortName, longName := flags.Arg(0), flags.Arg(1)
+	if short.NbPoints > long.NbPoints {
+		short, long = long, short
+		shortName, longName = longName, shortName
+	}
+
+	if err = srsio.IsPrefix(short, long, *batchSize); err != nil {
+		return fmt.Errorf("%s is not a prefix of %s: %w", shortName, longName, err)
+	}
+
+	if short.NbPoints == long.NbPoints {
+		fmt.Printf("%s and %s hold the same %d points and verifying key\n", shortName, longName, short.NbPoints)
+	} else {
+		fmt.Printf("%s is a prefix of %s: its %d points are the first of the %d points\n", shortName, longName, short.NbPoints, long.NbPoints)
+	}
+
+	if !opts.Verify {
+		return nil
+	}
+
+	opts.IOParallelism = 1
+	opts = config.Tune(opts, "")
+
+	srs, err := long.Range(0, long.NbPoints)
+	if err != nil {
+		return err
+	}
+
+	if err = verify.SRS(srs, opts); err != nil {
+		return fmt.Errorf("%s: %w", longName, err)
+	}
+
+	fmt.Printf("%s is made of consecutive powers of tau\n", longName)
+
+	return nil
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"linea/aztec-srs-to-gnark/cache"
+	"linea/aztec-srs-to-gnark/offheap"
+)
+
+// clean lists the entries of a conversion cache and the spill files left over
+// by interrupted conversions, and prunes them by age and size.
+func clean(args []string) error {
+	flags := flag.NewFlagSet("clean", flag.ExitOnError)
+	cacheDir := flags.String("cache-dir", "", "directory of the conversion cache, as given to convert")
+	spillDir := flags.String("spill-dir", "", "directory of the spill files, as given to convert")
+	olderThan := flags.Duration("older-than", 0, "remove the cache entries unused and the spill files unchanged for longer than this, e.g. 720h (0 - no age limit)")
+	maxSize := flags.String("max-size", "", "remove the least recently used cache entries until the cache takes at most this many bytes, with an optional K, M, G or T suffix")
+	dryRun := flags.Bool("dry-run", false, "only print what would be removed")
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s clean [flags]\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if *cacheDir == "" && *spillDir == "" {
+		flags.Usage()
+		return errUsage
+	}
+
+	limit := int64(-1)
+	if *maxSize != "" {
+		var err error
+		if limit, err = parseSize(*maxSize); err != nil {
+			return err
+		}
+	}
+	if *olderThan == 0 && limit < 0 {
+		fmt.Println("No -older-than or -max-size given, listing only")
+	}
+
+	// The removals that fail are reported once all the others are done.
+	failures := 0
+	remove := func(kind, name string, size int64, fn func() error) bool {
+		if *dryRun {
+			fmt.Printf("Would remove %s %s (%s)\n", kind, name, formatBytes(size))
+			return true
+		}
+		if err := fn(); err != nil {
+			fmt.Printf("ERROR: %v\n", err)
+			failures++
+			return false
+		}
+		fmt.Printf("Removed %s %s (%s)\n", kind, name, formatBytes(size))
+		return true
+	}
+
+	now := time.Now()
+
+	if *cacheDir != "" {
+		if _, err := os.Stat(*cacheDir); err != nil {
+			return err
+		}
+
+		c, err := cache.Open(*cacheDir)
+		if err != nil {
+			return err
+		}
+
+		entries, err := c.Entries()
+		if err != nil {
+			return err
+		}
+
+		var total int64
+		for _, e := range entries {
+			total += e.Size
+			state := ""
+			if e.Incomplete {
+				state = ", incomplete"
+			}
+			fmt.Printf("%s  %10s  last used %s ago%s  %s\n", e.Key, formatBytes(e.Size), now.Sub(e.LastUsed).Round(time.Minute), state, strings.Join(e.Files, ", "))
+		}
+		fmt.Printf("Cache %s: %d entries, %s\n", *cacheDir, len(entries), formatBytes(total))
+
+		var freed int64
+		for _, e := range cache.Prune(entries, now, *olderThan, limit) {
+			if remove("cache entry", e.Key, e.Size, func() error { return c.Remove(e) }) {
+				freed += e.Size
+			}
+		}
+		if freed != 0 && *dryRun {
+			fmt.Printf("Cache would be pruned by %s\n", formatBytes(freed))
+		} else if freed != 0 {
+			fmt.Printf("Cache pruned by %s\n", formatBytes(freed))
+		}
+	}
+
+	if *spillDir != "" {
+		paths, err := filepath.Glob(filepath.Join(*spillDir, offheap.SpillFilePattern))
+		if err != nil {
+			return err
+		}
+
+		for _, path := range paths {
+			info, err := os.Stat(path)
+			if err != nil {
+				fmt.Printf("ERROR: %v\n", err)
+				continue
+			}
+
+			age := now.Sub(info.ModTime())
+			fmt.Printf("%s  %10s  modified %s ago\n", path, formatBytes(info.Size()), age.Round(time.Minute))
+
+			// The spill files of running conversions are in use, only the
+			// stale ones are removed.
+			if *olderThan > 0 && age > *olderThan {
+				remove("spill file", path, info.Size(), func() error { return os.Remove(path) })
+			}
+		}
+		fmt.Printf("Spill directory %s: %d spill files\n", *spillDir, len(paths))
+	}
+
+	if failures != 0 {
+		return fmt.Errorf("%d removals failed", failures)
+	}
+
+	return nil
+}
+
+// parseSize parses a number of bytes with an optional K, M, G or T suffix for
+// KiB, MiB, GiB and TiB, e.g. "500G" or "1.5T".
+func parseSize(s string) (int64, error) {
+	number := strings.TrimSpace(s)
+	unit := 1.0
+	if number != "" {
+		switch strings.ToUpper(number[len(number)-1:]) {
+		case "K":
+			unit = 1 << 10
+		case "M":
+			unit = 1 << 20
+		case "G":
+			unit = 1 << 30
+		case "T":
+			unit = 1 << 40
+		}
+		if unit != 1 {
+			number = number[:len(number)-1]
+		}
+	}
+
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid size '%s', expected bytes with an optional K, M, G or T suffix", s)
+	}
+
+	return int64(value * unit), nil
+}
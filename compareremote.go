@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+
+	"linea/aztec-srs-to-gnark/attest"
+	"linea/aztec-srs-to-gnark/fetch"
+	"linea/aztec-srs-to-gnark/registry"
+	"linea/aztec-srs-to-gnark/srsio"
+)
+
+// compareRemote tells whether an SRS file matches an officially published
+// conversion, listed in a signed registry, in the one installed by registry
+// update or in the one embedded in the tool.
+func compareRemote(args []string) error {
+	flags := flag.NewFlagSet("compare-remote", flag.ExitOnError)
+	registryLocation := flags.String("registry", "", "URL or path of a signed registry of known-good SRS (default: the registry installed by registry update, or the embedded one)")
+	registryKey := flags.String("registry-key", "", "ed25519 public key (PKIX PEM) the registry is signed with, required with -registry")
+	batchSize := flags.Int("batch-size", srsio.DefaultCompareBatch, "number of points decoded at once to compute the SRS digest")
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s compare-remote [flags] <SRS file>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	args = parseInterspersed(flags, args)
+
+	if len(args) < 1 || (*registryLocation != "" && *registryKey == "") {
+		flags.Usage()
+		return errUsage
+	}
+
+	var (
+		reg *registry.Registry
+		err error
+	)
+	if *registryLocation == "" {
+		reg, err = currentRegistry()
+	} else {
+		reg, err = loadRegistry(*registryLocation, *registryKey)
+	}
+	if err != nil {
+		return err
+	}
+
+	r, err := srsio.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	digest, err := srsio.Digest(r, *batchSize)
+	if err != nil {
+		return err
+	}
+
+	curve := string(curveNames[r.Curve])
+	fmt.Printf("%s: %s, %d points, canonical digest %x\n", args[0], curve, r.NbPoints, digest)
+
+	matches := reg.Lookup(curve, hex.EncodeToString(digest))
+	if len(matches) == 0 {
+		return fmt.Errorf("no match: the SRS isn't one of the %d published conversions of the registry (version %d)", len(reg.Entries), reg.Version)
+	}
+
+	for _, e := range matches {
+		fmt.Printf("MATCH: %s, the %s conversion of %d points", e.Name, e.Protocol, e.Points)
+		if e.URL != "" {
+			fmt.Printf(" published at %s", e.URL)
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
+
+// currentRegistry returns the registry installed by registry update when it
+// is newer than the embedded one, the embedded one otherwise.
+func currentRegistry() (*registry.Registry, error) {
+	dir, err := registry.DefaultDir()
+	if err != nil {
+		return registry.Embedded()
+	}
+
+	reg, _, err := registry.Current(dir)
+	return reg, err
+}
+
+// loadRegistry fetches a registry and checks it is signed with the key.
+func loadRegistry(location, keyPath string) (*registry.Registry, error) {
+	pub, err := attest.LoadPublicKey(keyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := fetch.Read(context.Background(), location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry: %w", err)
+	}
+
+	return registry.Open(data, pub)
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"errors"
+	"sync"
+)
+
+// Releaser frees memory held by a conversion, such as an off-heap mapping.
+type Releaser interface {
+	Release() error
+}
+
+// Mappings collects the off-heap memory allocated for the SRS of a conversion,
+// from the goroutines of an importer, so that releasing it leaves the memory of
+// the other conversions untouched. The methods of a nil Mappings do nothing.
+type Mappings struct {
+	mu       sync.Mutex
+	mappings []Releaser
+}
+
+// Add records a mapping to release with the others.
+func (m *Mappings) Add(r Releaser) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.mappings = append(m.mappings, r)
+}
+
+// Release releases the mappings recorded, which may not be used afterwards.
+func (m *Mappings) Release() error {
+	if m == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var errs []error
+	for _, r := range m.mappings {
+		if err := r.Release(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	m.mappings = nil
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package config
+
+// AvailableMemory is not implemented on this platform.
+func AvailableMemory() uint64 {
+	return 0
+}
@@ -0,0 +1,60 @@
+package config
+
+import "linea/aztec-srs-to-gnark/audit"
+
+// Options tune how the setup files are converted.
+// Zero values are replaced by auto-tuned ones, see Tune.
+type Options struct {
+	// Workers is the number of goroutines used for CPU bound work.
+	Workers int
+	// IOParallelism is the maximum number of setup files read at the same time.
+	IOParallelism int
+	// BatchSize is the number of points a worker processes at once.
+	BatchSize int
+	// OffHeap keeps the G1 points in memory mapped outside the Go heap.
+	OffHeap bool
+	// SpillDir, when set, backs the off-heap memory with files in this directory.
+	SpillDir string
+	// Mappings records the off-heap memory of the conversion, for the caller
+	// to release once the SRS isn't used anymore. It must be set with OffHeap.
+	Mappings *Mappings
+	// Verify checks the points while they are parsed: subgroup membership and
+	// that they are consecutive powers of tau.
+	Verify bool
+	// Degree, when set, limits the SRS to its first 2^Degree points, reading
+	// only the setup files holding them. Only the aleo setup, the ethereum
+	// one, whose smallest sub-ceremony holding them is read, and the halo2,
+	// ppot, ptau, zcash and zksync ones, whose G1 powers after them aren't
+	// read, support it.
+	Degree int
+	// Transcripts, when set, limits the SRS to the points of the first
+	// Transcripts transcripts of the ceremony, reading only them. Only the
+	// aztec setup supports it.
+	Transcripts int
+	// Extend, when set, is the number of the first G1 points of an existing
+	// SRS the conversion extends: the setup files only holding some of them
+	// aren't read, their points and the verifying key being left for the
+	// caller to fill in. Only the aztec, aleo and celo setups support it.
+	Extend int
+	// Contributor, when set, selects the contributions of this participant to
+	// the chunks instead of the latest ones. Only the celo setup supports it.
+	Contributor string
+	// Align, when set, pads a memdump output so its G1 points start at a
+	// multiple of Align bytes in the file, see srsio.Write.
+	Align int
+	// Phase1 also reads the Groth16 phase 1 points (powers of τ in G2, α and β
+	// powers, βG2) of the setups providing them, see phase1.SRS.
+	Phase1 bool
+	// Audit, when set, records the setup files encountered and what was done
+	// with them.
+	Audit *audit.Log
+	// Validation is the policy for the malformed points and short reads,
+	// strict when unset, see Reject.
+	Validation Validation
+	// Skipped, when set, records the parts of the setup skipped under the
+	// lenient validation.
+	Skipped *Skips
+	// Progress, when set, tracks the G1 points processed and the warnings of
+	// the conversion.
+	Progress *Progress
+}
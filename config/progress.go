@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Stages of a conversion reported by Progress.
+const (
+	StageSetup   = "setup"
+	StageReading = "reading"
+	StageWriting = "writing"
+	StageDone    = "done"
+	StageFailed  = "failed"
+)
+
+// Progress tracks the stage of a conversion, the G1 points processed and the
+// warnings, for the status file of long jobs. The methods of a nil Progress do
+// nothing, it is safe for use by the goroutines of an importer.
+type Progress struct {
+	mu         sync.Mutex
+	stage      string
+	stageStart time.Time
+	total      int
+	processed  int
+	warnings   []string
+}
+
+// ProgressState is a snapshot of a Progress.
+type ProgressState struct {
+	Stage      string
+	StageStart time.Time
+	// Total is the number of G1 points the importer reads, 0 while unknown.
+	Total     int
+	Processed int
+	Warnings  []string
+}
+
+// Stage starts a stage of the conversion.
+func (p *Progress) Stage(stage string) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.stage, p.stageStart = stage, time.Now()
+}
+
+// SetTotal sets the number of G1 points the importer reads.
+func (p *Progress) SetTotal(total int) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.total = total
+}
+
+// Add records n more G1 points processed.
+func (p *Progress) Add(n int) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.processed += n
+}
+
+// Warn records a warning.
+func (p *Progress) Warn(warning string) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.warnings = append(p.warnings, warning)
+}
+
+// State returns a snapshot of the progress.
+func (p *Progress) State() ProgressState {
+	if p == nil {
+		return ProgressState{}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return ProgressState{
+		Stage:      p.stage,
+		StageStart: p.stageStart,
+		Total:      p.total,
+		Processed:  p.processed,
+		Warnings:   append([]string(nil), p.warnings...),
+	}
+}
+
+// Warn prints a warning, recording it in the progress of the conversion.
+func (o Options) Warn(format string, args ...any) {
+	warning := fmt.Sprintf(format, args...)
+	o.Progress.Warn(warning)
+	fmt.Printf("WARNING: %s\n", warning)
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"linea/aztec-srs-to-gnark/input"
+)
+
+const (
+	// DefaultBatchSize is used when the available memory is unknown.
+	DefaultBatchSize = 1 << 16
+	minBatchSize     = 1 << 12
+	maxBatchSize     = 1 << 20
+	// maxPointSize is the size of the largest uncompressed G1 point (BW6-761).
+	maxPointSize = 192
+	// memoryShare is the fraction (1/memoryShare) of the available memory
+	// the in-flight batches may take.
+	memoryShare = 100
+
+	// ioSampleSize is the number of bytes read to estimate the disk throughput.
+	ioSampleSize = 64 << 20
+	// fastDiskThroughput is the throughput (bytes/s) from which the drive is
+	// considered fast enough (SSD/NVMe) to benefit from parallel reads.
+	fastDiskThroughput = 1 << 30
+	// maxIOParallelism caps the number of files read at the same time.
+	maxIOParallelism = 8
+)
+
+// Tune replaces the zero-valued options with values picked from the CPU count,
+// the available memory and a short read throughput sample of the setup directory.
+func Tune(opts Options, setupDir string) Options {
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.NumCPU()
+	}
+
+	if opts.IOParallelism <= 0 {
+		opts.IOParallelism = 1
+
+		throughput, err := SampleReadThroughput(setupDir)
+		if err != nil {
+			fmt.Printf("WARNING: failed to sample read throughput: %v\n", err)
+		} else if throughput >= fastDiskThroughput {
+			opts.IOParallelism = min(opts.Workers, maxIOParallelism)
+		}
+	}
+
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+
+		// Every worker holds up to two batches of marshalled points.
+		if memory := AvailableMemory(); memory > 0 {
+			batchSize := memory / memoryShare / uint64(2*opts.Workers*maxPointSize)
+			opts.BatchSize = int(min(max(batchSize, minBatchSize), maxBatchSize))
+		}
+	}
+
+	return opts
+}
+
+// SampleReadThroughput reads the beginning of the largest file in the directory,
+// or of the archive of the setup files, and returns the observed throughput in
+// bytes per second.
+func SampleReadThroughput(dir string) (float64, error) {
+	// An archive of the setup files, or a single setup file, is sampled itself
+	if info, err := os.Stat(dir); err == nil && info.Mode().IsRegular() {
+		return sampleFile(dir)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		largest string
+		size    int64
+	)
+	for _, entry := range entries {
+		info, err := input.Info(dir, entry)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		if info.Size() > size {
+			largest, size = entry.Name(), info.Size()
+		}
+	}
+
+	// Nothing to sample yet, e.g. the files are still to be downloaded.
+	if largest == "" {
+		return 0, nil
+	}
+
+	return sampleFile(filepath.Join(dir, largest))
+}
+
+// sampleFile reads the beginning of the file and returns the observed
+// throughput in bytes per second.
+func sampleFile(path string) (float64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	start := time.Now()
+	n, err := io.CopyN(io.Discard, file, ioSampleSize)
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+
+	elapsed := time.Since(start).Seconds()
+	if elapsed == 0 {
+		return fastDiskThroughput, nil
+	}
+
+	return float64(n) / elapsed, nil
+}
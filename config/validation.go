@@ -0,0 +1,114 @@
+package config
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Validation is the policy of the importers for the parts of a setup they
+// can't use: malformed points and short reads. The files which aren't setup
+// files are skipped under both, see Ignore.
+type Validation string
+
+const (
+	// ValidationStrict aborts the conversion on the first part of the setup
+	// that can't be used.
+	ValidationStrict Validation = "strict"
+	// ValidationLenient skips the parts of the setup that can't be used,
+	// recording them, and converts the rest. The SRS ends before the first
+	// points skipped, since its points must be consecutive powers of τ.
+	ValidationLenient Validation = "lenient"
+)
+
+// Validations are the supported validation policies.
+var Validations = []Validation{ValidationStrict, ValidationLenient}
+
+// ParseValidation returns the validation policy of the name.
+func ParseValidation(name string) (Validation, error) {
+	for _, v := range Validations {
+		if string(v) == name {
+			return v, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown validation policy %q, use one of %v", name, Validations)
+}
+
+// Skip is a part of a setup skipped under the lenient validation.
+type Skip struct {
+	// File is the name of the file skipped, unset for a missing one.
+	File string `json:"file,omitempty"`
+	// Index is the index of the transcript, setup file or chunk in the
+	// ceremony, unset when the file isn't one.
+	Index *int `json:"index,omitempty"`
+	// Offset is the index in the SRS of the first point skipped, unset when
+	// the file holds no points of the SRS.
+	Offset *int `json:"offset,omitempty"`
+	// Points is the number of points of the SRS missing for the skip from
+	// Offset, the range [Offset, Offset+Points) of the ceremony. 0 when unknown.
+	Points int    `json:"points,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// Skips collects the parts of a setup skipped under the lenient validation,
+// from the goroutines of an importer. The methods of a nil Skips do nothing.
+type Skips struct {
+	mu    sync.Mutex
+	skips []Skip
+}
+
+// Add records a skipped part of the setup.
+func (s *Skips) Add(skip Skip) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.skips = append(s.skips, skip)
+}
+
+// List returns a copy of the skipped parts recorded.
+func (s *Skips) List() []Skip {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Skip(nil), s.skips...)
+}
+
+// Lenient tells whether the parts of the setup that can't be used are skipped
+// instead of aborting the conversion.
+func (o Options) Lenient() bool {
+	return o.Validation == ValidationLenient
+}
+
+// Reject applies the validation policy to a part of the setup that can't be
+// used for err. Under the strict validation it returns err, under the lenient
+// one it records the skip and returns nil.
+func (o Options) Reject(skip Skip, err error) error {
+	if !o.Lenient() {
+		return err
+	}
+
+	skip.Reason = err.Error()
+	o.Skipped.Add(skip)
+	if skip.File == "" {
+		o.Warn("skipping: %v", err)
+	} else {
+		o.Warn("skipping %s: %v", skip.File, err)
+	}
+
+	return nil
+}
+
+// Ignore skips a file of the setup directory which isn't a setup file, e.g. a
+// README or a checksums file, for the reason. It holds no points, so it is
+// skipped under both validation policies, with a notice.
+func (o Options) Ignore(file, reason string) {
+	fmt.Printf("Skipping %s: %s\n", file, reason)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/mpc"
+	"linea/aztec-srs-to-gnark/srsio"
+)
+
+// contribute applies a fresh secret to an SRS file, as a participant of an
+// MPC ceremony, and writes the new SRS together with the proof of the contribution.
+func contribute(args []string) error {
+	var opts config.Options
+
+	flags := flag.NewFlagSet("contribute", flag.ExitOnError)
+	format := flags.String("format", "", fmt.Sprintf("output format, one of %v (default: the format of the input)", srsio.Formats))
+	proofPath := flags.String("proof", "", "file to write the contribution proof to (default: <output SRS file>.proof.json)")
+	beaconValue := flags.String("beacon", "", "hex encoded random beacon value (e.g. a block hash) to derive the secret from, for the final contribution of a ceremony")
+	beaconExp := flags.Int("beacon-exp", 10, "base 2 logarithm of the number of SHA256 iterations applied to the beacon")
+	flags.IntVar(&opts.Workers, "workers", 0, "number of CPU workers (0 - auto)")
+	flags.IntVar(&opts.BatchSize, "batch-size", 0, "number of points processed by a worker at once (0 - auto)")
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s contribute [flags] <input SRS file> <output SRS file>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() < 2 {
+		flags.Usage()
+		return errUsage
+	}
+	in, out := flags.Arg(0), flags.Arg(1)
+	if *proofPath == "" {
+		*proofPath = out + ".proof.json"
+	}
+
+	var beacon mpc.Beacon
+	if *beaconValue != "" {
+		if err := beacon.Value.UnmarshalText([]byte(*beaconValue)); err != nil {
+			return fmt.Errorf("invalid beacon value: %w", err)
+		}
+		beacon.IterationsExp = *beaconExp
+	}
+
+	r, err := srsio.Open(in)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	outputFormat := r.Format
+	if *format != "" {
+		if outputFormat, err = srsio.ParseFormat(*format); err != nil {
+			return err
+		}
+	}
+
+	// Only the CPU settings matter, no setup directory is read.
+	opts.IOParallelism = 1
+	opts = config.Tune(opts, "")
+
+	srs, err := r.Range(0, r.NbPoints)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Contributing to %d %s points of %s\n", r.NbPoints, curveNames[r.Curve], in)
+
+	var proof mpc.Proof
+	if *beaconValue != "" {
+		fmt.Printf("Applying beacon %s hashed 2^%d times\n", *beaconValue, *beaconExp)
+		proof, err = mpc.ContributeBeacon(srs, beacon, opts)
+	} else {
+		proof, err = mpc.Contribute(srs, opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err = writeOutput(out, srs, outputFormat, opts); err != nil {
+		return err
+	}
+
+	if err = mpc.WriteProof(*proofPath, proof); err != nil {
+		return err
+	}
+
+	fmt.Printf("Contribution proof written to %s\n", *proofPath)
+
+	return nil
+}
@@ -0,0 +1,1074 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"linea/aztec-srs-to-gnark/attest"
+	"linea/aztec-srs-to-gnark/audit"
+	"linea/aztec-srs-to-gnark/cache"
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/encrypt"
+	"linea/aztec-srs-to-gnark/fetch"
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/journal"
+	"linea/aztec-srs-to-gnark/lock"
+	"linea/aztec-srs-to-gnark/manifest"
+	"linea/aztec-srs-to-gnark/metrics"
+	"linea/aztec-srs-to-gnark/offheap"
+	"linea/aztec-srs-to-gnark/phase1"
+	"linea/aztec-srs-to-gnark/srsio"
+	"linea/aztec-srs-to-gnark/torrent"
+	"linea/aztec-srs-to-gnark/verify"
+)
+
+var (
+	conversions = metrics.Default.NewCounter("srs_conversions_total",
+		"Number of conversions by result.", "protocol", "curve", "result")
+	conversionsInProgress = metrics.Default.NewGauge("srs_conversions_in_progress",
+		"Number of conversions running.", "protocol", "curve")
+	conversionDuration = metrics.Default.NewHistogram("srs_conversion_duration_seconds",
+		"Duration of the conversions.", metrics.DefaultBuckets, "protocol", "curve")
+	convertedPoints = metrics.Default.NewCounter("srs_converted_points_total",
+		"Number of G1 points converted.", "protocol", "curve")
+	verifications = metrics.Default.NewCounter("srs_verifications_total",
+		"Number of SRS verifications by result.", "protocol", "curve", "result")
+	writtenBytes = metrics.Default.NewCounter("srs_written_bytes_total",
+		"Number of bytes of SRS files written.", "format")
+	writeThroughput = metrics.Default.NewGauge("srs_write_throughput_bytes_per_second",
+		"Throughput of the last SRS file written.", "format")
+)
+
+// loadCheckSamples is the number of G1 points sampled by -check-load, besides
+// the first and the last ones.
+const loadCheckSamples = 1024
+
+// validationUsage is the usage of the -validation flag of the commands
+// converting setup files.
+const validationUsage = "policy for the malformed points, short reads and malformed setup files: strict aborts, lenient skips them and ends the SRS before the first points skipped"
+
+// convert translates the setup files of a ceremony into a gnark SRS file.
+func convert(args []string) error {
+	var opts config.Options
+
+	flags := flag.NewFlagSet("convert", flag.ExitOnError)
+
+	format := flags.String("format", string(srsio.FormatMemDump), fmt.Sprintf("output format, one of %v", srsio.Formats))
+	flags.IntVar(&opts.Align, "align", 0, "pad a memdump output so its G1 points start at a multiple of this number of bytes, e.g. 4096 for the pages, to map it in memory with srsio.MapDump (0 - the layout of WriteDump)")
+	flags.IntVar(&opts.Workers, "workers", 0, "number of CPU workers (0 - auto)")
+	flags.IntVar(&opts.IOParallelism, "io-parallelism", 0, "number of setup files read at the same time (0 - auto)")
+	flags.IntVar(&opts.BatchSize, "batch-size", 0, "number of points processed by a worker at once (0 - auto)")
+	flags.BoolVar(&opts.OffHeap, "offheap", offheap.Supported, "keep the G1 points outside the Go heap")
+	flags.StringVar(&opts.SpillDir, "spill-dir", "", "back the off-heap G1 points with files in this directory")
+	cacheDir := flags.String("cache-dir", "", "directory caching the outputs by the hashes of the setup files and the options")
+	metricsAddr := flags.String("metrics-addr", "", "address to expose the Prometheus metrics on during the conversion")
+	metricsFile := flags.String("metrics-file", "", "file to write the Prometheus metrics to once the conversion ends, e.g. for the node exporter textfile collector")
+	makeTorrent := flags.Bool("make-torrent", false, "write a .torrent file of the output next to it and print its magnet link")
+	torrentTrackers := flags.String("torrent-trackers", "", "comma separated announce URLs of the generated torrent")
+	torrentWebSeeds := flags.String("torrent-webseeds", "", "comma separated URLs the output will be published at, as web seeds of the generated torrent (a URL ending with / is a directory)")
+	attestKey := flags.String("attest-key", "", "ed25519 private key (PKCS #8 PEM) to sign an attestation of the conversion with, written to <output>.attestation.json")
+	operator := flags.String("operator", defaultOperator(), "identity of the operator recorded in the attestation")
+	auditLog := flags.Bool("audit-log", false, "append an audit log of the setup files, transcripts and chunks encountered, with their hashes and the decisions taken, to <output>.audit.jsonl")
+	writeJournal := flags.Bool("journal", false, "append a hash-chained journal of the setup files, transcripts and chunks the points were read from, with their hashes and the running hash of the output points, to <output>.journal.jsonl")
+	webhook := flags.String("notify-url", "", "URL to post the JSON run report to once the conversion ends")
+	doneFile := flags.String("done-file", "", "file to write the JSON run report to once the conversion ends")
+	statusFile := flags.String("status-file", "", "file to write the JSON status of the conversion to during it, its stage, G1 points processed, ETA and warnings, e.g. next to the output for the orchestrators and dashboards polling it")
+	statusInterval := flags.Duration("status-interval", 5*time.Second, "interval between the updates of the status file")
+	flags.BoolVar(&opts.Verify, "verify", false, "verify the points while parsing: subgroup membership and consecutive powers of tau")
+	encryptTo := flags.String("encrypt-to", "", "comma separated age public keys (age1...), or files of age public keys or of GPG public keys, to encrypt the output and its checksums and skip info to, written to <file>.age or <file>.gpg")
+	checkLoad := flags.Bool("check-load", false, "load the output back with the decoders of gnark-crypto (ReadDump for a memdump) and compare the verifying key and a sample of the G1 points with the converted SRS")
+	flags.IntVar(&opts.Degree, "degree", 0, "log2 of the number of points of the SRS, only the setup files holding them are read, aleo, ethereum, halo2, ppot, ptau, zcash and zksync only (0 - all the points)")
+	flags.IntVar(&opts.Transcripts, "transcripts", 0, "number of the first transcripts read into a smaller SRS, aztec only (0 - all the transcripts)")
+	extendFile := flags.String("extend", "", "existing SRS file of the setup to extend with the G1 points it doesn't hold, only the transcripts, setup files or chunks holding them being read, aztec, aleo and celo only; the output is in its format")
+	flags.StringVar(&opts.Contributor, "contributor", "", "address of the participant whose contributions to the chunks are used instead of the latest ones, celo only")
+	phase1File := flags.String("phase1", "", "file to also write the Groth16 phase 1 of the setup to, as a gnark mpcsetup Phase1, celo, ppot and zcash only")
+	torrentSource := flags.String("torrent", "", "path, URL or magnet link of a torrent with the setup files to download into the setup directory from its peers and web seeds")
+	urlsFile := flags.String("urls", "", "file listing the URLs of the setup files to download into the setup directory, one file per line with the URLs of its mirrors separated by spaces")
+	streamURLs := flags.Bool("stream", false, "stream the setup files of -urls from the first mirror of each without storing them, no setup directory is given")
+	manifestFile := flags.String("manifest", "", "manifest written by the manifest command pinning the setup files to convert, checked by size and SHA256")
+	profile := flags.String("profile", "", profileUsage)
+	validation := flags.String("validation", string(config.ValidationStrict), validationUsage)
+	var fetchOpts fetch.Options
+	flags.IntVar(&fetchOpts.Parallelism, "download-parallelism", 4, "number of setup files downloaded at the same time")
+	flags.IntVar(&fetchOpts.Retries, "download-retries", 5, "number of times a failed download is resumed")
+	flags.IntVar(&fetchOpts.Quorum, "mirror-quorum", 2, "number of mirrors that must serve identical data for a setup file with several mirrors")
+	flags.DurationVar(&fetchOpts.Backoff, "download-backoff", time.Second, "delay before the first download retry, doubled on every next one")
+	var network fetch.Network
+	flags.StringVar(&network.Proxy, "proxy", "", "URL of the HTTP(S) proxy for the downloads (default from HTTP_PROXY, HTTPS_PROXY and NO_PROXY)")
+	flags.StringVar(&network.CACert, "ca-cert", "", "PEM file with additional certificate authorities to trust for the downloads")
+	bwlimit := flags.String("bwlimit", "", "limit of the total download rate in bytes per second, with an optional K, M or G suffix (e.g. 20M)")
+	flags.BoolVar(&network.CloudCredentials, "cloud-credentials", false, "authorize the requests to S3, Cloud Storage and Azure Blob Storage with the credentials of the environment variables of their tools")
+	uploadTo := flags.String("upload", "", "s3:// or gs:// location, or URL of a directory, e.g. of an Azure container, to upload the output and the files written next to it to once the conversion succeeds")
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s convert [flags] <protocol> <curve> <setup files directory, archive, setup files or - for a setup file read from the standard input>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if err := applyProfile(*profile); err != nil {
+		return err
+	}
+
+	args = flags.Args()
+	if *streamURLs {
+		if *urlsFile == "" {
+			return errors.New("-stream streams the setup files listed by -urls, which is missing")
+		}
+		if len(args) > 2 {
+			return errors.New("the streamed setup files aren't stored, no setup directory is given with -stream")
+		}
+		// The URL list stands for the setup directory in the reports
+		args = append(args, *urlsFile)
+	}
+	if len(args) < 3 {
+		flags.Usage()
+		return errUsage
+	}
+
+	outputFormat, err := srsio.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+
+	if opts.Align != 0 {
+		if outputFormat != srsio.FormatMemDump {
+			return fmt.Errorf("only a %s output can be aligned", srsio.FormatMemDump)
+		}
+		if err = srsio.ValidateAlignment(opts.Align); err != nil {
+			return err
+		}
+	}
+
+	if opts.Validation, err = config.ParseValidation(*validation); err != nil {
+		return err
+	}
+	opts.Skipped = new(config.Skips)
+
+	if *statusFile != "" {
+		if *statusInterval <= 0 {
+			return fmt.Errorf("invalid status interval %s", *statusInterval)
+		}
+		opts.Progress = new(config.Progress)
+	}
+
+	if *bwlimit != "" {
+		rate, err := fetch.ParseRate(*bwlimit)
+		if err != nil {
+			return err
+		}
+		network.BandwidthLimit = rate
+	}
+
+	if err = fetch.Setup(network); err != nil {
+		return err
+	}
+	if *uploadTo != "" {
+		if _, err = uploadLocation(*uploadTo, ""); err != nil {
+			return err
+		}
+	}
+
+	translateFunc, ok := supportedSetups[ProtocolName(args[0])][CurveName(args[1])]
+	if !ok {
+		fmt.Println("ERROR: Unsupported protocol or curve, use one of:")
+
+		for protocol := range supportedSetups {
+			for curve := range supportedSetups[protocol] {
+				fmt.Printf("\t%s %s\n", protocol, curve)
+			}
+		}
+
+		return errUsage
+	}
+
+	if *phase1File != "" {
+		if protocol := ProtocolName(args[0]); protocol != CeloProtocol && protocol != PPoTProtocol && protocol != ZcashProtocol {
+			return errors.New("the Groth16 phase 1 points are only available in the celo, ppot and zcash setups")
+		}
+		if opts.Degree != 0 {
+			return errors.New("the Groth16 phase 1 needs all the powers of τ, they can't be selected by degree")
+		}
+		opts.Phase1 = true
+	}
+
+	if protocol := ProtocolName(args[0]); opts.Degree != 0 && protocol != AleoProtocol && protocol != EthereumProtocol && protocol != PPoTProtocol && protocol != PtauProtocol && protocol != ZcashProtocol && protocol != ZksyncProtocol && protocol != Halo2Protocol {
+		return errors.New("selecting the setup files by degree is only available in the aleo, ethereum, halo2, ppot, ptau, zcash and zksync setups")
+	}
+	if opts.Transcripts != 0 && ProtocolName(args[0]) != AztecProtocol {
+		return errors.New("selecting the first transcripts is only available in the aztec setup")
+	}
+	if opts.Transcripts < 0 {
+		return fmt.Errorf("invalid number of transcripts %d", opts.Transcripts)
+	}
+	if opts.Contributor != "" && ProtocolName(args[0]) != CeloProtocol {
+		return errors.New("selecting the contributions of a participant is only available in the celo setup")
+	}
+	if opts.Degree < 0 || opts.Degree > 62 {
+		return fmt.Errorf("invalid degree %d", opts.Degree)
+	}
+
+	// The setup files holding only points of the extended SRS aren't read
+	var extended *srsio.Reader
+	if *extendFile != "" {
+		if extended, err = openExtended(*extendFile, ProtocolName(args[0]), CurveName(args[1])); err != nil {
+			return err
+		}
+		defer extended.Close()
+
+		formatSet := false
+		flags.Visit(func(f *flag.Flag) { formatSet = formatSet || f.Name == "format" })
+		if formatSet && outputFormat != extended.Format {
+			return fmt.Errorf("%s is a %s file, its extension is written in the same format", *extendFile, extended.Format)
+		}
+		if opts.Phase1 {
+			return errors.New("the Groth16 phase 1 points can't be read while extending an SRS")
+		}
+		outputFormat = extended.Format
+		opts.Extend = extended.NbPoints
+		fmt.Printf("Extending the %d G1 points of %s\n", extended.NbPoints, *extendFile)
+	}
+
+	var recipients *encrypt.Recipients
+	if *encryptTo != "" {
+		if recipients, err = encrypt.ParseRecipients(splitList(*encryptTo)); err != nil {
+			return err
+		}
+		if *checkLoad {
+			return errors.New("an encrypted output can't be loaded back, convert without -check-load or check it once decrypted")
+		}
+	}
+
+	var signingKey ed25519.PrivateKey
+	if *attestKey != "" {
+		if signingKey, err = attest.LoadKey(*attestKey); err != nil {
+			return err
+		}
+	}
+
+	// The journal, the attestation and the run report take the inputs and
+	// the signers of the transcripts from the events of the audit log.
+	reported := *webhook != "" || *doneFile != ""
+	if *auditLog || *writeJournal || signingKey != nil || reported {
+		opts.Audit = new(audit.Log)
+	}
+
+	// Concurrent conversions of the setup into the working directory would
+	// write the same outputs.
+	outputLock, err := lock.Acquire(outputLockName(args[0], args[1], outputFormat))
+	if err != nil {
+		return err
+	}
+	defer releaseLock(outputLock)
+
+	var files []input.File
+	if *torrentSource != "" {
+		m, err := torrent.Load(context.Background(), *torrentSource)
+		if err != nil {
+			return err
+		}
+
+		// The conversion starts on the first files while the rest is
+		// downloading, the client stops when the conversion returns.
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		files, err = torrent.Files(ctx, m, args[2])
+		if err != nil {
+			return err
+		}
+	} else if *urlsFile != "" && *streamURLs {
+		sources, err := readURLs(*urlsFile)
+		if err != nil {
+			return err
+		}
+
+		// A stream can't be compared between mirrors, it's read from the first one.
+		urls := make([]string, len(sources))
+		for i, mirrors := range sources {
+			urls[i] = mirrors[0]
+		}
+
+		files, err = fetch.Stream(context.Background(), urls, fetchOpts)
+		if err != nil {
+			return err
+		}
+
+		// The files are read from the network, one stream per download slot.
+		if opts.IOParallelism <= 0 {
+			opts.IOParallelism = fetchOpts.Parallelism
+		}
+	} else if *urlsFile != "" {
+		sources, err := readURLs(*urlsFile)
+		if err != nil {
+			return err
+		}
+
+		// The conversion starts on the first files while the rest is downloading.
+		files, err = fetch.Files(context.Background(), sources, args[2], fetchOpts)
+		if err != nil {
+			return err
+		}
+	} else if args[2] == "-" {
+		// A single setup file piped into the standard input, read as a stream.
+		files = []input.File{input.Stdin()}
+		if opts.IOParallelism <= 0 {
+			opts.IOParallelism = 1
+		}
+	} else if fetch.IsObjectLocation(args[2]) {
+		// The objects under an s3:// or gs:// prefix are streamed without a local copy.
+		urls, err := fetch.ListObjects(context.Background(), args[2])
+		if err != nil {
+			return err
+		}
+
+		files, err = fetch.Stream(context.Background(), urls, fetchOpts)
+		if err != nil {
+			return err
+		}
+
+		if opts.IOParallelism <= 0 {
+			opts.IOParallelism = fetchOpts.Parallelism
+		}
+	} else if len(args) > 3 {
+		// The setup files given one by one, e.g. the g1.dat and g2.dat of the
+		// flat CRS.
+		files, err = input.Paths(args[2:])
+		if err != nil {
+			return err
+		}
+	} else {
+		files, err = input.Dir(args[2])
+		if err != nil {
+			return err
+		}
+	}
+
+	var pinned *manifest.Manifest
+	if *manifestFile != "" {
+		if pinned, err = manifest.Read(*manifestFile); err != nil {
+			return err
+		}
+		if pinned.Protocol != args[0] || pinned.Curve != args[1] {
+			return fmt.Errorf("the manifest describes a %s %s setup", pinned.Protocol, pinned.Curve)
+		}
+		if files, err = pinned.Pin(files); err != nil {
+			return err
+		}
+	}
+
+	if err = validateSetup(ProtocolName(args[0]), CurveName(args[1]), files, opts.Validation); err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		printSetupLayout(ProtocolName(args[0]), files)
+		return exitStatus(1)
+	}
+
+	// The setup files are hashed for the attestation, the audit log, the
+	// journal and the manifest while they are converted.
+	var digests *input.Digests
+	if signingKey != nil || *auditLog || *writeJournal || pinned != nil {
+		digests = input.NewDigests()
+		files = digests.Wrap(files)
+	}
+
+	opts = config.Tune(opts, args[2])
+	fmt.Printf("Using %d workers, %d parallel file reads, batches of %d points\n",
+		opts.Workers, opts.IOParallelism, opts.BatchSize)
+
+	if *metricsAddr != "" {
+		go func() {
+			if err := http.ListenAndServe(*metricsAddr, metrics.Default.Handler()); err != nil {
+				fmt.Printf("WARNING: failed to expose metrics: %v\n", err)
+			}
+		}()
+	}
+
+	start := time.Now()
+	result := "failure"
+	report := runReport{
+		Command:    "convert",
+		Protocol:   args[0],
+		Curve:      args[1],
+		Setup:      args[2],
+		Format:     string(outputFormat),
+		Verified:   opts.Verify,
+		Validation: string(opts.Validation),
+		Start:      start,
+	}
+	conversion := attest.Conversion{
+		Operator:  *operator,
+		Tool:      "gnark-mpc-kzg-srs",
+		Protocol:  args[0],
+		Curve:     args[1],
+		Format:    string(outputFormat),
+		Source:    args[2],
+		Checks:    conversionChecks(args[0], opts.Verify, *torrentSource != "", *urlsFile != "" && !*streamURLs, fetchOpts.Quorum),
+		StartedOn: start.UTC(),
+	}
+	opts.Audit.Record(audit.Event{
+		Kind:     audit.KindConversion,
+		File:     args[2],
+		Decision: fmt.Sprintf("convert the %s %s setup to %s", args[0], args[1], outputFormat),
+	})
+	// fail records the error ending the conversion in the report.
+	fail := func(err error) error {
+		report.Error = err.Error()
+		return err
+	}
+	var status *statusWriter
+	if *statusFile != "" {
+		opts.Progress.Stage(config.StageSetup)
+		status = startStatus(*statusFile, *statusInterval, runStatus{
+			Command:  "convert",
+			Protocol: args[0],
+			Curve:    args[1],
+			Setup:    args[2],
+			Start:    start,
+		}, opts)
+	}
+	conversionsInProgress.Set(1, args[0], args[1])
+	defer func() {
+		conversionsInProgress.Set(0, args[0], args[1])
+		if status != nil {
+			status.finish(report.Output, report.Error)
+		}
+		conversions.Inc(args[0], args[1], result)
+		conversionDuration.Observe(time.Since(start).Seconds(), args[0], args[1])
+
+		if *webhook != "" || *doneFile != "" {
+			report.Result = result
+			report.notify(*webhook, *doneFile)
+		}
+
+		if *metricsFile != "" {
+			if err := writeMetrics(*metricsFile); err != nil {
+				fmt.Printf("WARNING: %v\n", err)
+			}
+		}
+	}()
+
+	var (
+		outputs  *cache.Cache
+		cacheKey string
+	)
+	if *cacheDir != "" && opts.Phase1 {
+		fmt.Println("WARNING: not using the cache: the Groth16 phase 1 points aren't cached")
+	} else if *cacheDir != "" && recipients != nil {
+		fmt.Println("WARNING: not using the cache: the outputs are encrypted")
+	} else if *cacheDir != "" && extended != nil {
+		fmt.Println("WARNING: not using the cache: the conversion extends an existing SRS")
+	} else if *cacheDir != "" && *writeJournal {
+		fmt.Println("WARNING: not using the cache: the journal records the setup files read")
+	} else if *cacheDir != "" {
+		// Only setup files stored locally can be hashed before the conversion.
+		outputs, cacheKey, err = cacheLookup(*cacheDir, files, args[0], args[1], string(outputFormat), opts)
+		if err != nil {
+			fmt.Printf("WARNING: not using the cache: %v\n", err)
+		}
+	}
+	if outputs != nil {
+		// The entry is restored or stored by a single conversion at a time.
+		entryLock, err := outputs.Lock(cacheKey)
+		if err != nil {
+			return fail(err)
+		}
+		defer releaseLock(entryLock)
+
+		if names, ok, err := outputs.Restore(cacheKey, "."); err != nil {
+			return fail(err)
+		} else if ok {
+			fmt.Printf("\nSRS restored from cache: %s\n", strings.Join(names, ", "))
+			report.FromCache = true
+			report.Output = strings.Join(names, ", ")
+
+			var restored string
+			for _, name := range names {
+				if !strings.HasSuffix(name, ".checksums") && !strings.HasSuffix(name, ".skipped.json") {
+					restored = name
+				}
+			}
+
+			if *makeTorrent {
+				if report.Torrent, report.Magnet, err = writeTorrent(restored, *torrentTrackers, *torrentWebSeeds); err != nil {
+					return fail(err)
+				}
+			}
+
+			if digests != nil {
+				// Nothing was converted, the setup files and the output are hashed now.
+				var sums srsio.Checksums
+				err = digests.Complete(files)
+				if err == nil && pinned != nil {
+					err = pinned.Check(digests.Sums())
+				}
+				if err == nil {
+					sums, err = srsio.FileChecksums(restored)
+				}
+				if err == nil && signingKey != nil {
+					if r, err := srsio.Open(restored); err == nil {
+						conversion.Points = r.NbPoints
+						r.Close()
+					}
+					conversion.Checks = append(conversion.Checks, "output restored from the cache of a previous conversion with the same inputs and options")
+					err = writeAttestation(signingKey, conversion, restored, sums, digests.Sums(), nil)
+				}
+				if err == nil && *auditLog {
+					err = writeAuditLog(opts.Audit, restored, sums, digests.Sums(), "restored from the cache")
+				}
+				if err != nil {
+					return fail(err)
+				}
+			}
+
+			if *uploadTo != "" {
+				uploads := names
+				if report.Torrent != "" {
+					uploads = append(uploads, report.Torrent)
+				}
+				if signingKey != nil {
+					uploads = append(uploads, restored+".attestation.json")
+				}
+				if report.Uploaded, err = uploadOutputs(*uploadTo, fetchOpts, uploads); err != nil {
+					return fail(err)
+				}
+			}
+
+			result = "success"
+			return nil
+		}
+	}
+
+	// The points of an extension are verified once the ones of the extended
+	// SRS are copied in, see extendSRS.
+	importOpts := opts
+	importOpts.Verify = opts.Verify && extended == nil
+	importOpts.Mappings = new(config.Mappings)
+	opts.Progress.Stage(config.StageReading)
+	srs, pointsNum, err := translateFunc(files, importOpts)
+	if report.Skipped = opts.Skipped.List(); len(report.Skipped) != 0 {
+		printSkipped(report.Skipped)
+	}
+	report.Signers = transcriptSigners(opts.Audit.Events())
+	if importOpts.Verify {
+		if err == nil {
+			verifications.Inc(args[0], args[1], "success")
+		} else if errors.Is(err, verify.ErrFailed) {
+			verifications.Inc(args[0], args[1], "failure")
+		}
+	}
+	defer func() {
+		if err := importOpts.Mappings.Release(); err != nil {
+			fmt.Printf("WARNING: failed to release off-heap memory: %v\n", err)
+		}
+	}()
+	if err != nil {
+		return fail(err)
+	}
+
+	if pinned != nil {
+		if err = pinned.Check(digests.Sums()); err != nil {
+			return fail(err)
+		}
+		fmt.Printf("Setup files match the manifest %s\n", *manifestFile)
+	}
+
+	var groth16 io.WriterTo
+	if ext, ok := srs.(*phase1.SRS); ok {
+		srs, groth16 = ext.SRS, ext.Phase1
+	}
+
+	if extended != nil {
+		if pointsNum, err = extendWith(srs, extended, opts); err != nil {
+			if opts.Verify && errors.Is(err, verify.ErrFailed) {
+				verifications.Inc(args[0], args[1], "failure")
+			}
+			return fail(err)
+		}
+		if opts.Verify {
+			verifications.Inc(args[0], args[1], "success")
+		}
+		report.Skipped = opts.Skipped.List()
+	}
+
+	// The running hashes of the inputs are computed before the output is
+	// written, so that the points copied from an extended SRS are checked
+	// against its journal first.
+	var (
+		record *journal.Journal
+		chain  *journal.Chain
+	)
+	if *writeJournal {
+		run := journal.Entry{Kind: journal.KindRun, Protocol: args[0], Curve: args[1], File: args[2]}
+		inputs := journal.Inputs(opts.Audit.Events(), digests.Sums(), pointsNum)
+		if record, chain, err = recordInputs(srs, run, inputs, *extendFile, opts.Extend); err != nil {
+			return fail(err)
+		}
+	}
+
+	resultFileName := fmt.Sprintf("kzg_srs_canonical_%d_%s_%s.%s", pointsNum-1, args[1], args[0], outputFormat)
+	// An encrypted output is written to the file with the extension of its
+	// scheme, the checksums being the ones of the decrypted SRS.
+	outputFileName := resultFileName
+	if recipients != nil {
+		outputFileName += recipients.Scheme.Ext()
+	}
+
+	// The outputs may be hard links to cached outputs, which must not be overwritten.
+	os.Remove(resultFileName)
+	os.Remove(resultFileName + ".checksums")
+	os.Remove(resultFileName + ".skipped.json")
+
+	f, err := os.Create(outputFileName)
+	if err != nil {
+		return fail(fmt.Errorf("failed to create output SRS file: %w", err))
+	}
+	defer f.Close()
+
+	var (
+		out       io.Writer = f
+		encrypted io.WriteCloser
+	)
+	if recipients != nil {
+		if encrypted, err = recipients.Encrypt(f, resultFileName); err != nil {
+			return fail(fmt.Errorf("failed to encrypt SRS: %w", err))
+		}
+		out = encrypted
+	}
+	hw := srsio.NewHashingWriter(out)
+
+	opts.Progress.Stage(config.StageWriting)
+	writeStart := time.Now()
+	err = srsio.Write(hw, srs, outputFormat, opts)
+	if err == nil && encrypted != nil {
+		err = encrypted.Close()
+	}
+	if err != nil {
+		return fail(fmt.Errorf("failed to write SRS to file: %w", err))
+	}
+
+	sums := hw.Checksums()
+
+	convertedPoints.Add(float64(pointsNum), args[0], args[1])
+	writtenBytes.Add(float64(sums.Size), string(outputFormat))
+	writeThroughput.Set(float64(sums.Size)/time.Since(writeStart).Seconds(), string(outputFormat))
+
+	manifestFileName, err := srsio.WriteManifest(resultFileName, sums)
+	if err == nil && recipients != nil {
+		manifestFileName, err = recipients.EncryptFile(manifestFileName)
+	}
+	if err != nil {
+		return fail(err)
+	}
+
+	if *checkLoad {
+		if err = srsio.CheckLoad(resultFileName, srs, outputFormat, loadCheckSamples); err != nil {
+			return fail(fmt.Errorf("the output doesn't load back: %w", err))
+		}
+		report.LoadChecked = true
+		conversion.Checks = append(conversion.Checks, "the output loads back with the decoders of gnark-crypto, with the same verifying key and sampled G1 points")
+	}
+
+	report.Output = outputFileName
+	report.Manifest = manifestFileName
+	report.NbPoints = pointsNum
+	report.Size = sums.Size
+	report.SHA256 = sums.SHA256
+	report.BLAKE2b = sums.BLAKE2b
+
+	fmt.Printf("\nSRS successfully created: %s\n", outputFileName)
+	if recipients != nil {
+		fmt.Printf("Encrypted with %s, the checksums are the ones of the decrypted %s\n", recipients.Scheme, resultFileName)
+	}
+	fmt.Printf("> SHA256:  %s\n", sums.SHA256)
+	fmt.Printf("> BLAKE2b: %s\n", sums.BLAKE2b)
+	fmt.Printf("Checksums written to %s\n", manifestFileName)
+
+	// The parts of the setup skipped are recorded next to the output, which
+	// lacks their points.
+	cached := []string{resultFileName, manifestFileName}
+	if len(report.Skipped) != 0 {
+		info := srsio.NewSkipInfo(pointsNum, report.Skipped)
+		report.SkipInfo, err = srsio.WriteSkipInfo(resultFileName, info)
+		if err == nil && recipients != nil {
+			report.SkipInfo, err = recipients.EncryptFile(report.SkipInfo)
+		}
+		if err != nil {
+			return fail(err)
+		}
+		report.Omitted = info.Omitted
+		cached = append(cached, report.SkipInfo)
+		fmt.Printf("Skipped parts of the setup written to %s\n", report.SkipInfo)
+		if !info.Prefix {
+			fmt.Printf("WARNING: the SRS lacks G1 points before its last one, it isn't a prefix of the powers of τ of the ceremony\n")
+		}
+	}
+
+	// The files uploaded once the conversion succeeds
+	uploads := []string{outputFileName, manifestFileName}
+	if report.SkipInfo != "" {
+		uploads = append(uploads, report.SkipInfo)
+	}
+
+	if groth16 != nil {
+		phase1FileName := *phase1File
+		err = writePhase1(phase1FileName, groth16)
+		if err == nil && recipients != nil {
+			phase1FileName, err = recipients.EncryptFile(phase1FileName)
+		}
+		if err != nil {
+			return fail(err)
+		}
+		fmt.Printf("Groth16 phase 1 points written to %s\n", phase1FileName)
+		uploads = append(uploads, phase1FileName)
+	}
+
+	if outputs != nil {
+		if err = f.Close(); err == nil {
+			err = outputs.Store(cacheKey, cached...)
+		}
+		if err != nil {
+			fmt.Printf("WARNING: failed to cache the SRS: %v\n", err)
+		}
+	}
+
+	if *makeTorrent {
+		if report.Torrent, report.Magnet, err = writeTorrent(outputFileName, *torrentTrackers, *torrentWebSeeds); err != nil {
+			return fail(err)
+		}
+		uploads = append(uploads, report.Torrent)
+	}
+
+	if signingKey != nil {
+		conversion.Points = pointsNum
+		if err = writeAttestation(signingKey, conversion, resultFileName, sums, digests.Sums(), report.Signers); err != nil {
+			return fail(err)
+		}
+		uploads = append(uploads, resultFileName+".attestation.json")
+	}
+
+	if *auditLog {
+		if err = writeAuditLog(opts.Audit, resultFileName, sums, digests.Sums(), "written"); err != nil {
+			return fail(err)
+		}
+	}
+
+	if record != nil {
+		if err = appendJournal(record, chain, srs, resultFileName, sums); err != nil {
+			return fail(err)
+		}
+	}
+
+	if *uploadTo != "" {
+		if report.Uploaded, err = uploadOutputs(*uploadTo, fetchOpts, uploads); err != nil {
+			return fail(err)
+		}
+	}
+
+	result = "success"
+
+	return nil
+}
+
+// writeAuditLog records the hashes of the setup files and of the output in the
+// audit log, and appends it to the log next to the output.
+func writeAuditLog(log *audit.Log, output string, sums srsio.Checksums, inputs map[string]string, decision string) error {
+	names := make([]string, 0, len(inputs))
+	for name := range inputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		log.Record(audit.Event{Kind: audit.KindDigest, File: name, SHA256: inputs[name]})
+	}
+	log.Record(audit.Event{Kind: audit.KindOutput, File: filepath.Base(output), SHA256: sums.SHA256, Decision: decision})
+
+	path := output + ".audit.jsonl"
+	if err := log.Append(path); err != nil {
+		return err
+	}
+	fmt.Printf("Audit log appended to %s\n", path)
+
+	return nil
+}
+
+// writeAttestation signs the attestation of the conversion of the setup files
+// into the output and writes it next to the output. The inputs signed by
+// their participants are annotated with their signers.
+func writeAttestation(key ed25519.PrivateKey, conversion attest.Conversion, output string, sums srsio.Checksums, inputs, signers map[string]string) error {
+	conversion.FinishedOn = time.Now().UTC()
+	if len(signers) != 0 {
+		conversion.Checks = append(conversion.Checks, fmt.Sprintf("the checksums of %d transcripts match their content and are signed by their participants, as Ethereum signed messages", len(signers)))
+	}
+
+	names := make([]string, 0, len(inputs))
+	for name := range inputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		in := attest.Input{Name: name, Digest: map[string]string{"sha256": inputs[name]}}
+		if signer, ok := signers[name]; ok {
+			in.Annotations = map[string]string{"signer": signer}
+		}
+		conversion.Inputs = append(conversion.Inputs, in)
+	}
+
+	envelope, err := attest.Sign(attest.Statement{
+		Type: attest.StatementType,
+		Subject: []attest.Subject{{
+			Name:   filepath.Base(output),
+			Digest: map[string]string{"sha256": sums.SHA256, "blake2b": sums.BLAKE2b},
+		}},
+		PredicateType: attest.PredicateType,
+		Predicate:     conversion,
+	}, key)
+	if err != nil {
+		return err
+	}
+
+	attestationFileName := output + ".attestation.json"
+	if err = envelope.WriteFile(attestationFileName); err != nil {
+		return err
+	}
+
+	fmt.Printf("Attestation signed by key %s written to %s\n", envelope.Signatures[0].KeyID, attestationFileName)
+
+	return nil
+}
+
+// conversionChecks describes the checks performed by the conversion.
+func conversionChecks(protocol string, verified, torrent, urls bool, quorum int) []string {
+	checks := []string{fmt.Sprintf("setup files parsed by the %s importer", protocol)}
+
+	if torrent {
+		checks = append(checks, "downloaded pieces checked against the SHA1 hashes of the torrent")
+	}
+	if urls {
+		checks = append(checks, fmt.Sprintf("chunks of the setup files with several mirrors served identically by %d mirrors", quorum))
+	}
+	if verified {
+		checks = append(checks,
+			"every G1 point is on the curve and in the prime order subgroup",
+			"the G1 points are consecutive powers of the tau of tau*G2, checked with a random linear combination and a pairing")
+	}
+
+	return checks
+}
+
+// defaultOperator identifies the operator by the user and host names.
+func defaultOperator() string {
+	operator := "unknown"
+	if u, err := user.Current(); err == nil {
+		operator = u.Username
+	}
+	if host, err := os.Hostname(); err == nil {
+		operator += "@" + host
+	}
+
+	return operator
+}
+
+// uploadOutputs uploads the files written by the conversion to the
+// destination under their base names, and returns their locations.
+func uploadOutputs(destination string, opts fetch.Options, paths []string) ([]string, error) {
+	locations := make([]string, 0, len(paths))
+	for _, path := range paths {
+		location, err := uploadLocation(destination, filepath.Base(path))
+		if err != nil {
+			return nil, err
+		}
+		if err = fetch.Upload(context.Background(), path, location, opts); err != nil {
+			return nil, err
+		}
+		fmt.Printf("Uploaded %s to %s\n", path, location)
+		locations = append(locations, location)
+	}
+
+	return locations, nil
+}
+
+// uploadLocation returns the location of the file of the name under the
+// destination, an s3:// or gs:// prefix or the URL of a directory.
+func uploadLocation(destination, name string) (string, error) {
+	if fetch.IsObjectLocation(destination) {
+		return strings.TrimSuffix(destination, "/") + "/" + name, nil
+	}
+
+	u, err := url.Parse(destination)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return "", fmt.Errorf("invalid upload destination '%s', expected an s3:// or gs:// location or an HTTP(S) URL", destination)
+	}
+
+	return u.JoinPath(name).String(), nil
+}
+
+// writeTorrent writes the .torrent file sharing the output next to it, and
+// returns its path and magnet link.
+func writeTorrent(output, trackers, webSeeds string) (string, string, error) {
+	m, err := torrent.Create(output, splitList(trackers), splitList(webSeeds))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create torrent: %w", err)
+	}
+
+	data, err := m.Marshal()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode torrent: %w", err)
+	}
+
+	torrentFileName := output + ".torrent"
+	if err = os.WriteFile(torrentFileName, data, 0o644); err != nil {
+		return "", "", fmt.Errorf("failed to write torrent file: %w", err)
+	}
+
+	magnet := m.MagnetLink("")
+	fmt.Printf("Torrent written to %s\n> Magnet: %s\n", torrentFileName, magnet)
+
+	return torrentFileName, magnet, nil
+}
+
+// splitList splits a comma separated list, ignoring empty elements.
+func splitList(s string) []string {
+	var list []string
+	for _, element := range strings.Split(s, ",") {
+		if element = strings.TrimSpace(element); element != "" {
+			list = append(list, element)
+		}
+	}
+
+	return list
+}
+
+// cacheLookup opens the cache and computes the key of the conversion.
+func cacheLookup(dir string, files []input.File, protocol, curve, format string, opts config.Options) (*cache.Cache, string, error) {
+	outputs, err := cache.Open(dir)
+	if err != nil {
+		return nil, "", err
+	}
+
+	params := []string{"protocol=" + protocol, "curve=" + curve, "format=" + format, fmt.Sprintf("verify=%t", opts.Verify)}
+	// The keys of the conversions of all the points, or of the latest
+	// contributions, are the same as before these could be chosen.
+	if opts.Degree != 0 {
+		params = append(params, fmt.Sprintf("degree=%d", opts.Degree))
+	}
+	if opts.Transcripts != 0 {
+		params = append(params, fmt.Sprintf("transcripts=%d", opts.Transcripts))
+	}
+	if opts.Contributor != "" {
+		params = append(params, "contributor="+strings.ToLower(opts.Contributor))
+	}
+	if opts.Lenient() {
+		params = append(params, "validation="+string(opts.Validation))
+	}
+	if opts.Align != 0 {
+		params = append(params, fmt.Sprintf("align=%d", opts.Align))
+	}
+	key, err := outputs.Key(files, params...)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to compute cache key: %w", err)
+	}
+
+	return outputs, key, nil
+}
+
+// outputLockName returns the name of the lock file of the outputs of the
+// conversions of the setup into the working directory, whatever their number
+// of points.
+func outputLockName(protocol, curve string, format srsio.Format) string {
+	return fmt.Sprintf(".kzg_srs_canonical_%s_%s.%s.lock", curve, protocol, format)
+}
+
+// releaseLock releases the lock, warning when it fails.
+func releaseLock(l *lock.File) {
+	if err := l.Release(); err != nil {
+		fmt.Printf("WARNING: %v\n", err)
+	}
+}
+
+// writePhase1 writes the mpcsetup Phase1 of the Groth16 phase 1 into the file.
+func writePhase1(path string, phase1 io.WriterTo) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create Groth16 phase 1 file: %w", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	if _, err = phase1.WriteTo(w); err != nil {
+		return fmt.Errorf("failed to write Groth16 phase 1 file: %w", err)
+	}
+	if err = w.Flush(); err != nil {
+		return fmt.Errorf("failed to write Groth16 phase 1 file: %w", err)
+	}
+
+	return f.Close()
+}
+
+// writeMetrics writes the metrics into the file, atomically so that a
+// collector never reads a partial file.
+func writeMetrics(path string) error {
+	tmp := path + ".tmp"
+
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to create metrics file: %w", err)
+	}
+
+	if _, err = metrics.Default.WriteTo(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write metrics file: %w", err)
+	}
+
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("failed to write metrics file: %w", err)
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// readURLs reads the URL list file: one setup file per line, given by the
+// URLs of its mirrors separated by spaces. The mirrors may be s3:// or gs://
+// objects. Empty lines and lines starting with # are ignored.
+func readURLs(path string) ([][]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open URL list: %w", err)
+	}
+	defer file.Close()
+
+	var sources [][]string
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		mirrors := strings.Fields(line)
+		for i := range mirrors {
+			mirrors[i] = fetch.ObjectURL(mirrors[i])
+		}
+
+		sources = append(sources, mirrors)
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read URL list: %w", err)
+	}
+
+	return sources, nil
+}
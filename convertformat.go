@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/lagrange"
+	"linea/aztec-srs-to-gnark/srsio"
+)
+
+// convertFormat writes an existing SRS file in another serialization, without
+// the setup files of its ceremony.
+func convertFormat(args []string) error {
+	var opts config.Options
+
+	flags := flag.NewFlagSet("convert-format", flag.ExitOnError)
+	out := flags.String("o", "", "output SRS file, or prefix of the shards (required)")
+	format := flags.String("format", "", fmt.Sprintf("output format, one of %v (default: the format of the input)", srsio.Formats))
+	shards := flags.Int("shards", 0, "split the SRS into this number of shards written to <output>.<index>, with their slice info")
+	toLagrange := flags.Bool("lagrange", false, "write the SRS in Lagrange basis, on the domain of the largest power of 2 not above the number of points")
+	flags.IntVar(&opts.Workers, "workers", 0, "number of CPU workers (0 - auto)")
+	flags.IntVar(&opts.BatchSize, "batch-size", 0, "number of points processed by a worker at once (0 - auto)")
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s convert-format [flags] <input SRS file>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	args = parseInterspersed(flags, args)
+
+	if len(args) < 1 || *out == "" {
+		flags.Usage()
+		return errUsage
+	}
+
+	if *toLagrange && *shards > 0 {
+		return errors.New("an SRS in Lagrange basis can't be sharded, its points aren't consecutive powers of tau")
+	}
+
+	r, err := srsio.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	outputFormat := r.Format
+	if *format != "" {
+		if outputFormat, err = srsio.ParseFormat(*format); err != nil {
+			return err
+		}
+	}
+
+	opts.IOParallelism = 1
+	opts = config.Tune(opts, "")
+
+	switch {
+	case *toLagrange:
+		srs, err := r.Range(0, r.NbPoints)
+		if err != nil {
+			return err
+		}
+
+		size := lagrange.MaxDomain(r.NbPoints)
+		fmt.Printf("Converting the first %d points of %s to Lagrange basis\n", size, args[0])
+
+		if srs, err = lagrange.ToLagrange(srs, size); err != nil {
+			return err
+		}
+
+		if _, err = writeOutput(*out, srs, outputFormat, opts); err != nil {
+			return err
+		}
+	case *shards > 0:
+		if *shards > r.NbPoints {
+			return fmt.Errorf("%d points can't be split into %d shards", r.NbPoints, *shards)
+		}
+
+		// The first shards get the remainder, one point each.
+		from := 0
+		for i := range *shards {
+			to := from + r.NbPoints / *shards
+			if i < r.NbPoints%*shards {
+				to++
+			}
+
+			path := fmt.Sprintf("%s.%d", *out, i)
+			if err = writeRange(r, path, from, to, outputFormat, opts); err != nil {
+				return err
+			}
+
+			if _, err = srsio.WriteSliceInfo(path, srsio.SliceInfo{
+				Source:       filepath.Base(args[0]),
+				SourcePoints: r.NbPoints,
+				From:         from,
+				To:           to,
+			}); err != nil {
+				return err
+			}
+
+			from = to
+		}
+
+		fmt.Printf("\n%s split into %d shards, merge them back with the merge command\n", args[0], *shards)
+	default:
+		if err = writeRange(r, *out, 0, r.NbPoints, outputFormat, opts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
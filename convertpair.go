@@ -0,0 +1,244 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc"
+
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/offheap"
+	"linea/aztec-srs-to-gnark/phase1"
+	"linea/aztec-srs-to-gnark/srsio"
+)
+
+// pairSide is a setup converted by convert-pair, as recorded in the pair file.
+type pairSide struct {
+	Protocol ProtocolName `json:"protocol"`
+	Curve    CurveName    `json:"curve"`
+	Output   string       `json:"output"`
+	NbPoints int          `json:"points"`
+	SHA256   string       `json:"sha256"`
+	BLAKE2b  string       `json:"blake2b"`
+	SkipInfo string       `json:"skip_info,omitempty"`
+	// setup is the directory of the setup files.
+	setup string
+	curve ecc.ID
+}
+
+// pairInfo describes the SRS files of a dual-curve run, see convertPair.
+type pairInfo struct {
+	// Inner is the SRS of the curve of the proofs verified in the circuits
+	// of the Outer one.
+	Inner pairSide `json:"inner"`
+	Outer pairSide `json:"outer"`
+	// TwoChain tells whether the scalar field of the outer curve is the base
+	// field of the inner one, the inner proofs being verified with native
+	// arithmetic, otherwise with emulated arithmetic.
+	TwoChain bool   `json:"two_chain"`
+	Format   string `json:"format"`
+	Verified bool   `json:"verified"`
+}
+
+// convertPair converts the setups of the two curves of a recursion stack, e.g.
+// the aleo BLS12-377 and the celo BW6-761 ones, in one run, checks that the
+// curves make a 2-chain, and writes the two SRS files with a pair file
+// describing them, since gnark recursion needs both.
+func convertPair(args []string) error {
+	var opts config.Options
+
+	flags := flag.NewFlagSet("convert-pair", flag.ExitOnError)
+	format := flags.String("format", string(srsio.FormatMemDump), fmt.Sprintf("output format of both SRS files, one of %v", srsio.Formats))
+	outDir := flags.String("o", ".", "directory the SRS files and the pair file are written to")
+	emulated := flags.Bool("emulated", false, "accept curves which aren't a 2-chain, e.g. bn254 and bw6761, whose inner proofs are verified with emulated arithmetic")
+	flags.IntVar(&opts.Workers, "workers", 0, "number of CPU workers (0 - auto)")
+	flags.BoolVar(&opts.OffHeap, "offheap", offheap.Supported, "keep the G1 points outside the Go heap")
+	flags.BoolVar(&opts.Verify, "verify", false, "verify the points of both setups while parsing: subgroup membership and consecutive powers of tau")
+	profile := flags.String("profile", "", profileUsage)
+	validation := flags.String("validation", string(config.ValidationStrict), validationUsage)
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s convert-pair [flags] <protocol>:<setup files directory> <protocol>:<setup files directory>\n", os.Args[0])
+		fmt.Println("One of the setups is on bw6761, the outer curve, the other one on the inner curve of the recursion stack.")
+		flags.PrintDefaults()
+	}
+	args = parseInterspersed(flags, args)
+	if err := applyProfile(*profile); err != nil {
+		return err
+	}
+
+	if len(args) < 2 {
+		flags.Usage()
+		return errUsage
+	}
+
+	outputFormat, err := srsio.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+	if opts.Validation, err = config.ParseValidation(*validation); err != nil {
+		return err
+	}
+
+	// The metadata are checked before the long conversions
+	info := pairInfo{Format: string(outputFormat), Verified: opts.Verify}
+	var sides []pairSide
+	for _, source := range args[:2] {
+		side, err := parsePairSource(source)
+		if err != nil {
+			return err
+		}
+		sides = append(sides, side)
+	}
+	if info.Inner, info.Outer, info.TwoChain, err = pairCurves(sides[0], sides[1]); err != nil {
+		return err
+	}
+	if !info.TwoChain && !*emulated {
+		return fmt.Errorf("%s and %s aren't a 2-chain: the scalar field of %s isn't the base field of %s, set -emulated to verify the %s proofs with emulated arithmetic",
+			info.Inner.Curve, info.Outer.Curve, info.Outer.Curve, info.Inner.Curve, info.Inner.Curve)
+	}
+	if info.TwoChain {
+		fmt.Printf("%s and %s are a 2-chain: the scalar field of %s is the base field of %s\n", info.Inner.Curve, info.Outer.Curve, info.Outer.Curve, info.Inner.Curve)
+	} else {
+		fmt.Printf("WARNING: %s and %s aren't a 2-chain, the %s proofs are verified with emulated arithmetic\n", info.Inner.Curve, info.Outer.Curve, info.Inner.Curve)
+	}
+
+	if err = os.MkdirAll(*outDir, 0o755); err != nil {
+		return err
+	}
+
+	for _, side := range []*pairSide{&info.Inner, &info.Outer} {
+		fmt.Printf("\nConverting the %s %s setup of %s\n", side.Protocol, side.Curve, side.setup)
+		if err = convertPairSide(side, *outDir, outputFormat, opts); err != nil {
+			return fmt.Errorf("%s:%s: %w", side.Protocol, side.setup, err)
+		}
+		fmt.Printf("SRS of %d points written to %s\n", side.NbPoints, filepath.Join(*outDir, side.Output))
+	}
+
+	pairFileName := filepath.Join(*outDir, fmt.Sprintf("kzg_srs_pair_%s_%s.json", info.Inner.Curve, info.Outer.Curve))
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err == nil {
+		err = os.WriteFile(pairFileName, append(data, '\n'), 0o644)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write pair file: %w", err)
+	}
+
+	fmt.Printf("\nSRS pair successfully created: %s\n", pairFileName)
+	fmt.Printf("> inner: %s (%d points, max degree %d)\n", info.Inner.Output, info.Inner.NbPoints, info.Inner.NbPoints-1)
+	fmt.Printf("> outer: %s (%d points, max degree %d)\n", info.Outer.Output, info.Outer.NbPoints, info.Outer.NbPoints-1)
+
+	return nil
+}
+
+// parsePairSource returns the setup of a <protocol>:<directory> source, on the
+// curve of the protocol.
+func parsePairSource(source string) (pairSide, error) {
+	protocol, dir, ok := strings.Cut(source, ":")
+	curves, known := supportedSetups[ProtocolName(protocol)]
+	if !ok || !known {
+		return pairSide{}, fmt.Errorf("%s isn't a <protocol>:<setup files directory> source of a supported protocol", source)
+	}
+	if len(curves) != 1 {
+		return pairSide{}, fmt.Errorf("the %s setup is available on several curves", protocol)
+	}
+
+	side := pairSide{Protocol: ProtocolName(protocol), setup: dir}
+	for curve := range curves {
+		side.Curve = curve
+	}
+	for id, name := range curveNames {
+		if name == side.Curve {
+			side.curve = id
+		}
+	}
+
+	return side, nil
+}
+
+// pairCurves tells the inner setup from the outer one, on bw6761, and whether
+// their curves are a 2-chain.
+func pairCurves(a, b pairSide) (inner, outer pairSide, twoChain bool, err error) {
+	switch {
+	case a.Curve == b.Curve:
+		return pairSide{}, pairSide{}, false, fmt.Errorf("both setups are on %s", a.Curve)
+	case b.Curve == BW6761Curve:
+		inner, outer = a, b
+	case a.Curve == BW6761Curve:
+		inner, outer = b, a
+	default:
+		return pairSide{}, pairSide{}, false, fmt.Errorf("none of the setups is on %s, the outer curve", BW6761Curve)
+	}
+
+	return inner, outer, outer.curve.ScalarField().Cmp(inner.curve.BaseField()) == 0, nil
+}
+
+// convertPairSide converts the setup of the side into an SRS file of the
+// format in dir, recording it in the side.
+func convertPairSide(side *pairSide, dir string, format srsio.Format, opts config.Options) error {
+	files, err := input.Dir(side.setup)
+	if err != nil {
+		return err
+	}
+	if err = validateSetup(side.Protocol, side.Curve, files, opts.Validation); err != nil {
+		return err
+	}
+
+	opts = config.Tune(opts, side.setup)
+	opts.Skipped = new(config.Skips)
+	opts.Mappings = new(config.Mappings)
+	srs, pointsNum, err := supportedSetups[side.Protocol][side.Curve](files, opts)
+	defer func() {
+		if err := opts.Mappings.Release(); err != nil {
+			fmt.Printf("WARNING: failed to release off-heap memory: %v\n", err)
+		}
+	}()
+	skipped := opts.Skipped.List()
+	if len(skipped) != 0 {
+		printSkipped(skipped)
+	}
+	if err != nil {
+		return err
+	}
+	if ext, ok := srs.(*phase1.SRS); ok {
+		srs = ext.SRS
+	}
+
+	// The pair file names the SRS files next to it
+	side.Output = fmt.Sprintf("kzg_srs_canonical_%d_%s_%s.%s", pointsNum-1, side.Curve, side.Protocol, format)
+	path := filepath.Join(dir, side.Output)
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create output SRS file: %w", err)
+	}
+	defer f.Close()
+
+	hw := srsio.NewHashingWriter(f)
+	if err = srsio.Write(hw, srs, format, opts); err != nil {
+		return fmt.Errorf("failed to write SRS to file: %w", err)
+	}
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("failed to write SRS to file: %w", err)
+	}
+
+	sums := hw.Checksums()
+	if _, err = srsio.WriteManifest(path, sums); err != nil {
+		return err
+	}
+	side.NbPoints, side.SHA256, side.BLAKE2b = pointsNum, sums.SHA256, sums.BLAKE2b
+
+	if len(skipped) != 0 {
+		infoPath, err := srsio.WriteSkipInfo(path, srsio.NewSkipInfo(pointsNum, skipped))
+		if err != nil {
+			return err
+		}
+		side.SkipInfo = filepath.Base(infoPath)
+	}
+
+	return nil
+}
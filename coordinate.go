@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/coordinator"
+)
+
+// coordinate runs the coordinator of an MPC ceremony over an SRS.
+func coordinate(args []string) error {
+	var opts config.Options
+
+	flags := flag.NewFlagSet("coordinate", flag.ExitOnError)
+	addr := flags.String("addr", ":8080", "address to listen on")
+	timeout := flags.Duration("timeout", time.Hour, "time given to the participant at the head of the queue to upload a contribution")
+	flags.IntVar(&opts.Workers, "workers", 0, "number of CPU workers verifying the contributions (0 - auto)")
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s coordinate [flags] <ceremony directory> [initial SRS file]\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() < 1 {
+		flags.Usage()
+		return errUsage
+	}
+
+	opts.IOParallelism = 1
+	opts = config.Tune(opts, "")
+
+	c, err := coordinator.New(flags.Arg(0), flags.Arg(1), *timeout, opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Coordinating the ceremony of %s on %s\n", flags.Arg(0), *addr)
+
+	return http.ListenAndServe(*addr, c.Handler())
+}
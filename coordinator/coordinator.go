@@ -0,0 +1,451 @@
+package coordinator
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/mpc"
+	"linea/aztec-srs-to-gnark/srsio"
+)
+
+const (
+	transcriptFileName = "transcript.json"
+	// maxProofSize bounds the size of an uploaded proof.
+	maxProofSize = 1 << 20
+)
+
+// Entry is a state of the ceremony: the initial SRS or an accepted contribution.
+type Entry struct {
+	Index       int        `json:"index"`
+	Participant string     `json:"participant"`
+	File        string     `json:"file"`
+	SHA256      string     `json:"sha256"`
+	Proof       *mpc.Proof `json:"proof,omitempty"`
+	Time        time.Time  `json:"time"`
+}
+
+// Transcript is the sequence of the states of the ceremony.
+type Transcript struct {
+	Curve    string  `json:"curve"`
+	NbPoints int     `json:"points"`
+	Entries  []Entry `json:"entries"`
+}
+
+// participant is waiting in the queue or contributing.
+type participant struct {
+	name  string
+	token string
+	// since is when the participant reached the head of the queue.
+	since time.Time
+}
+
+// Coordinator sequences the participants of a ceremony, verifies their
+// contributions against the current state and publishes the transcript.
+// The states and the transcript are kept in a directory, so a restarted
+// coordinator continues the ceremony.
+type Coordinator struct {
+	dir     string
+	timeout time.Duration
+	opts    config.Options
+
+	mu         sync.Mutex
+	transcript Transcript
+	queue      []*participant
+	verifying  bool
+}
+
+// New continues the ceremony kept in dir, or starts it from the initial SRS
+// file when dir holds no transcript yet. A participant at the head of the
+// queue is dropped when no valid contribution is uploaded within timeout.
+func New(dir, initial string, timeout time.Duration, opts config.Options) (*Coordinator, error) {
+	c := &Coordinator{dir: dir, timeout: timeout, opts: opts}
+
+	data, err := os.ReadFile(filepath.Join(dir, transcriptFileName))
+	if err == nil {
+		if err = json.Unmarshal(data, &c.transcript); err != nil {
+			return nil, fmt.Errorf("failed to decode transcript: %w", err)
+		}
+		if len(c.transcript.Entries) == 0 {
+			return nil, errors.New("transcript has no entries")
+		}
+		return c, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+
+	if initial == "" {
+		return nil, fmt.Errorf("no transcript in %s, the initial SRS file is required", dir)
+	}
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create ceremony directory: %w", err)
+	}
+
+	r, err := srsio.Open(initial)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	name := stateFileName(0)
+	sums, err := copyFile(initial, filepath.Join(dir, name))
+	if err != nil {
+		return nil, err
+	}
+
+	c.transcript = Transcript{
+		Curve:    r.Curve.String(),
+		NbPoints: r.NbPoints,
+		Entries:  []Entry{{Participant: "initial", File: name, SHA256: sums.SHA256, Time: time.Now().UTC()}},
+	}
+	if err = c.save(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Handler returns the HTTP handler of the coordinator:
+//   - GET /transcript serves the transcript as JSON;
+//   - GET /srs serves the current SRS, to contribute on top of;
+//   - GET /contributions/{index} and GET /contributions/{index}/proof serve
+//     the SRS and the proof of an entry of the transcript;
+//   - GET /queue lists the participants waiting;
+//   - POST /queue with {"participant": name} joins the queue and returns the
+//     token authorizing the upload of the participant's contribution;
+//   - POST /contributions with the token as bearer and a multipart form with
+//     the "srs" file and its "proof" uploads the contribution of the
+//     participant at the head of the queue.
+func (c *Coordinator) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /transcript", c.getTranscript)
+	mux.HandleFunc("GET /srs", func(w http.ResponseWriter, r *http.Request) {
+		c.mu.Lock()
+		entry := c.transcript.Entries[len(c.transcript.Entries)-1]
+		c.mu.Unlock()
+
+		c.serveFile(w, r, entry.File)
+	})
+	mux.HandleFunc("GET /contributions/{index}", func(w http.ResponseWriter, r *http.Request) {
+		if entry, ok := c.entry(w, r); ok {
+			c.serveFile(w, r, entry.File)
+		}
+	})
+	mux.HandleFunc("GET /contributions/{index}/proof", func(w http.ResponseWriter, r *http.Request) {
+		if entry, ok := c.entry(w, r); ok && entry.Proof != nil {
+			writeJSON(w, http.StatusOK, entry.Proof)
+		} else if ok {
+			http.Error(w, "the initial SRS has no proof", http.StatusNotFound)
+		}
+	})
+	mux.HandleFunc("GET /queue", c.getQueue)
+	mux.HandleFunc("POST /queue", c.join)
+	mux.HandleFunc("POST /contributions", c.upload)
+
+	return mux
+}
+
+func (c *Coordinator) getTranscript(w http.ResponseWriter, _ *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, c.transcript)
+}
+
+func (c *Coordinator) entry(w http.ResponseWriter, r *http.Request) (Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	index, err := strconv.Atoi(r.PathValue("index"))
+	if err != nil || index < 0 || index >= len(c.transcript.Entries) {
+		http.Error(w, "unknown contribution", http.StatusNotFound)
+		return Entry{}, false
+	}
+
+	return c.transcript.Entries[index], true
+}
+
+func (c *Coordinator) serveFile(w http.ResponseWriter, r *http.Request, name string) {
+	f, err := os.Open(filepath.Join(c.dir, name))
+	if err != nil {
+		http.Error(w, "failed to open SRS file", http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		http.Error(w, "failed to open SRS file", http.StatusInternalServerError)
+		return
+	}
+
+	// The states never change once written.
+	w.Header().Set("ETag", `"`+name+`"`)
+	http.ServeContent(w, r, name, info.ModTime(), f)
+}
+
+func (c *Coordinator) getQueue(w http.ResponseWriter, _ *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.expire()
+
+	names := make([]string, len(c.queue))
+	for i, p := range c.queue {
+		names[i] = p.name
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"participants": names})
+}
+
+func (c *Coordinator) join(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Participant string `json:"participant"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxProofSize)).Decode(&req); err != nil || strings.TrimSpace(req.Participant) == "" {
+		http.Error(w, "expected {\"participant\": name}", http.StatusBadRequest)
+		return
+	}
+
+	var token [16]byte
+	if _, err := rand.Read(token[:]); err != nil {
+		http.Error(w, "failed to generate token", http.StatusInternalServerError)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.expire()
+
+	p := &participant{name: strings.TrimSpace(req.Participant), token: hex.EncodeToString(token[:])}
+	if len(c.queue) == 0 {
+		p.since = time.Now()
+	}
+	c.queue = append(c.queue, p)
+
+	writeJSON(w, http.StatusCreated, map[string]any{"token": p.token, "position": len(c.queue) - 1})
+}
+
+// expire drops the participant at the head of the queue once its time is up.
+// The caller must hold the lock.
+func (c *Coordinator) expire() {
+	for len(c.queue) > 0 && !c.verifying && time.Since(c.queue[0].since) > c.timeout {
+		fmt.Printf("Participant %s timed out\n", c.queue[0].name)
+		c.advance()
+	}
+}
+
+// advance removes the head of the queue and starts the time of the next participant.
+func (c *Coordinator) advance() {
+	c.queue = c.queue[1:]
+	if len(c.queue) > 0 {
+		c.queue[0].since = time.Now()
+	}
+}
+
+func (c *Coordinator) upload(w http.ResponseWriter, r *http.Request) {
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	c.mu.Lock()
+	c.expire()
+	if len(c.queue) == 0 || subtle.ConstantTimeCompare([]byte(token), []byte(c.queue[0].token)) != 1 {
+		c.mu.Unlock()
+		http.Error(w, "not the turn of this participant", http.StatusForbidden)
+		return
+	}
+	if c.verifying {
+		c.mu.Unlock()
+		http.Error(w, "a contribution is already being verified", http.StatusConflict)
+		return
+	}
+	c.verifying = true
+	head := c.queue[0]
+	prev := c.transcript.Entries[len(c.transcript.Entries)-1]
+	c.mu.Unlock()
+
+	entry, err := c.accept(r, head.name, prev)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.verifying = false
+
+	if err != nil {
+		fmt.Printf("Contribution of %s rejected: %v\n", head.name, err)
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	c.transcript.Entries = append(c.transcript.Entries, entry)
+	if err = c.save(); err != nil {
+		c.transcript.Entries = c.transcript.Entries[:len(c.transcript.Entries)-1]
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	c.advance()
+
+	fmt.Printf("Contribution %d of %s accepted\n", entry.Index, entry.Participant)
+	writeJSON(w, http.StatusCreated, entry)
+}
+
+// accept reads the uploaded contribution and verifies it on top of the
+// previous state, storing it as the next state when valid.
+func (c *Coordinator) accept(r *http.Request, name string, prev Entry) (Entry, error) {
+	prevReader, err := srsio.Open(filepath.Join(c.dir, prev.File))
+	if err != nil {
+		return Entry{}, err
+	}
+	defer prevReader.Close()
+
+	// No format takes more than 4 times the space of another one.
+	r.Body = http.MaxBytesReader(nil, r.Body, 4*prevReader.Size+maxProofSize)
+
+	parts, err := r.MultipartReader()
+	if err != nil {
+		return Entry{}, fmt.Errorf("expected a multipart form: %w", err)
+	}
+
+	index := prev.Index + 1
+	tmpPath := filepath.Join(c.dir, stateFileName(index)+".tmp")
+	defer os.Remove(tmpPath)
+
+	var (
+		proof    *mpc.Proof
+		uploaded bool
+		sums     srsio.Checksums
+	)
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Entry{}, fmt.Errorf("failed to read upload: %w", err)
+		}
+
+		switch part.FormName() {
+		case "proof":
+			proof = new(mpc.Proof)
+			if err = json.NewDecoder(io.LimitReader(part, maxProofSize)).Decode(proof); err != nil {
+				return Entry{}, fmt.Errorf("invalid proof: %w", err)
+			}
+		case "srs":
+			if sums, err = writePart(part, tmpPath); err != nil {
+				return Entry{}, err
+			}
+			uploaded = true
+		}
+	}
+	if proof == nil || !uploaded {
+		return Entry{}, errors.New("the form must have an srs file and a proof")
+	}
+
+	nextReader, err := srsio.Open(tmpPath)
+	if err != nil {
+		return Entry{}, err
+	}
+	defer nextReader.Close()
+
+	if nextReader.Curve != prevReader.Curve || nextReader.NbPoints != prevReader.NbPoints {
+		return Entry{}, fmt.Errorf("expected %d %s points, got %d %s points",
+			prevReader.NbPoints, prevReader.Curve, nextReader.NbPoints, nextReader.Curve)
+	}
+
+	prevSRS, err := prevReader.Range(0, min(2, prevReader.NbPoints))
+	if err != nil {
+		return Entry{}, err
+	}
+	nextSRS, err := nextReader.Range(0, nextReader.NbPoints)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	if err = mpc.VerifyContribution(prevSRS, nextSRS, *proof, c.opts); err != nil {
+		return Entry{}, err
+	}
+
+	file := stateFileName(index)
+	if err = mpc.WriteProof(filepath.Join(c.dir, file+".proof.json"), *proof); err != nil {
+		return Entry{}, err
+	}
+	if err = os.Rename(tmpPath, filepath.Join(c.dir, file)); err != nil {
+		return Entry{}, fmt.Errorf("failed to store contribution: %w", err)
+	}
+
+	return Entry{Index: index, Participant: name, File: file, SHA256: sums.SHA256, Proof: proof, Time: time.Now().UTC()}, nil
+}
+
+// save writes the transcript atomically. The caller must hold the lock.
+func (c *Coordinator) save() error {
+	data, err := json.MarshalIndent(c.transcript, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode transcript: %w", err)
+	}
+
+	path := filepath.Join(c.dir, transcriptFileName)
+	if err = os.WriteFile(path+".tmp", append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write transcript: %w", err)
+	}
+
+	return os.Rename(path+".tmp", path)
+}
+
+func stateFileName(index int) string {
+	return fmt.Sprintf("%04d.srs", index)
+}
+
+func writePart(part *multipart.Part, path string) (srsio.Checksums, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return srsio.Checksums{}, fmt.Errorf("failed to store upload: %w", err)
+	}
+	defer f.Close()
+
+	hw := srsio.NewHashingWriter(f)
+	if _, err = io.Copy(hw, part); err != nil {
+		return srsio.Checksums{}, fmt.Errorf("failed to receive SRS file: %w", err)
+	}
+
+	return hw.Checksums(), f.Close()
+}
+
+func copyFile(src, dst string) (srsio.Checksums, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return srsio.Checksums{}, fmt.Errorf("failed to open %s: %w", src, err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return srsio.Checksums{}, fmt.Errorf("failed to create %s: %w", dst, err)
+	}
+	defer out.Close()
+
+	hw := srsio.NewHashingWriter(out)
+	if _, err = io.Copy(hw, in); err != nil {
+		return srsio.Checksums{}, fmt.Errorf("failed to copy %s: %w", src, err)
+	}
+
+	return hw.Checksums(), out.Close()
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
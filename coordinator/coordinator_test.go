@@ -0,0 +1,266 @@
+package coordinator_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/coordinator"
+	"linea/aztec-srs-to-gnark/mpc"
+	"linea/aztec-srs-to-gnark/srsio"
+)
+
+// ceremony is a coordinator served over HTTP.
+type ceremony struct {
+	t      *testing.T
+	server *httptest.Server
+}
+
+func start(t *testing.T, dir, initial string, timeout time.Duration) *ceremony {
+	t.Helper()
+
+	c, err := coordinator.New(dir, initial, timeout, config.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(c.Handler())
+	t.Cleanup(server.Close)
+
+	return &ceremony{t: t, server: server}
+}
+
+// do sends the request and decodes the JSON response into v, when set.
+func (c *ceremony) do(req *http.Request, status int, v any) {
+	c.t.Helper()
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if resp.StatusCode != status {
+		c.t.Fatalf("%s %s: status %d, not %d: %s", req.Method, req.URL.Path, resp.StatusCode, status, body)
+	}
+	if v != nil {
+		if err = json.Unmarshal(body, v); err != nil {
+			c.t.Fatal(err)
+		}
+	}
+}
+
+func (c *ceremony) get(path string, status int, v any) {
+	c.t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, c.server.URL+path, nil)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	c.do(req, status, v)
+}
+
+// join adds the participant to the queue and returns its token.
+func (c *ceremony) join(name string) string {
+	c.t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, c.server.URL+"/queue", strings.NewReader(fmt.Sprintf(`{"participant": %q}`, name)))
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	var joined struct {
+		Token string `json:"token"`
+	}
+	c.do(req, http.StatusCreated, &joined)
+
+	return joined.Token
+}
+
+// contribute downloads the current SRS and applies a contribution to it. The
+// contribution is made on top of the SRS of another τ when wrongPrev is set.
+func (c *ceremony) contribute(wrongPrev bool) ([]byte, mpc.Proof) {
+	c.t.Helper()
+
+	resp, err := http.Get(c.server.URL + "/srs")
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	path := filepath.Join(c.t.TempDir(), "current.srs")
+	f, err := os.Create(path)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if _, err = io.Copy(f, resp.Body); err != nil {
+		c.t.Fatal(err)
+	}
+	f.Close()
+
+	r, err := srsio.Open(path)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	defer r.Close()
+	srs, err := r.Range(0, r.NbPoints)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if wrongPrev {
+		srs = newSRS(c.t, 7)
+	}
+
+	proof, err := mpc.Contribute(srs, config.Options{})
+	if err != nil {
+		c.t.Fatal(err)
+	}
+
+	return encode(c.t, srs), proof
+}
+
+// upload uploads the contribution with the token of a participant.
+func (c *ceremony) upload(token string, srs []byte, proof mpc.Proof, status int) {
+	c.t.Helper()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	w, err := form.CreateFormFile("srs", "next.srs")
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	w.Write(srs)
+	w, err = form.CreateFormFile("proof", "next.srs.proof.json")
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if err = json.NewEncoder(w).Encode(proof); err != nil {
+		c.t.Fatal(err)
+	}
+	form.Close()
+
+	req, err := http.NewRequest(http.MethodPost, c.server.URL+"/contributions", &body)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	c.do(req, status, nil)
+}
+
+func newSRS(t *testing.T, tau int64) *bnKzg.SRS {
+	t.Helper()
+
+	srs, err := bnKzg.NewSRS(8, big.NewInt(tau))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return srs
+}
+
+func encode(t *testing.T, srs kzg.SRS) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := srsio.Write(&buf, srs, srsio.FormatCanonical, config.Options{}); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// TestCeremony runs a ceremony of two participants, the second one uploading
+// an invalid contribution before a valid one, and continues it after a restart.
+func TestCeremony(t *testing.T) {
+	initial := filepath.Join(t.TempDir(), "initial.srs")
+	if err := os.WriteFile(initial, encode(t, newSRS(t, 42)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(t.TempDir(), "ceremony")
+	c := start(t, dir, initial, time.Hour)
+
+	alice := c.join("alice")
+	bob := c.join("bob")
+	var queue struct {
+		Participants []string `json:"participants"`
+	}
+	c.get("/queue", http.StatusOK, &queue)
+	if strings.Join(queue.Participants, ",") != "alice,bob" {
+		t.Fatalf("queue is %v, not alice then bob", queue.Participants)
+	}
+
+	srs, proof := c.contribute(false)
+	// Only the head of the queue uploads
+	c.upload(bob, srs, proof, http.StatusForbidden)
+	c.upload(alice, srs, proof, http.StatusCreated)
+
+	// A contribution on top of another SRS, or of a previous state, is rejected
+	wrong, wrongProof := c.contribute(true)
+	c.upload(bob, wrong, wrongProof, http.StatusUnprocessableEntity)
+	c.upload(bob, srs, proof, http.StatusUnprocessableEntity)
+	srs, proof = c.contribute(false)
+	c.upload(bob, srs, proof, http.StatusCreated)
+
+	var transcript coordinator.Transcript
+	c.get("/transcript", http.StatusOK, &transcript)
+	if len(transcript.Entries) != 3 || transcript.Entries[1].Participant != "alice" || transcript.Entries[2].Participant != "bob" {
+		t.Fatalf("unexpected transcript %+v", transcript)
+	}
+	var stored mpc.Proof
+	c.get("/contributions/2/proof", http.StatusOK, &stored)
+	if !bytes.Equal(stored.TauG1, proof.TauG1) {
+		t.Fatal("the stored proof isn't the one of bob")
+	}
+	c.get("/contributions/0/proof", http.StatusNotFound, nil)
+	c.get("/contributions/3", http.StatusNotFound, nil)
+
+	// A restarted coordinator continues from the last contribution
+	c = start(t, dir, "", time.Hour)
+	carol := c.join("carol")
+	srs, proof = c.contribute(false)
+	c.upload(carol, srs, proof, http.StatusCreated)
+	c.get("/transcript", http.StatusOK, &transcript)
+	if len(transcript.Entries) != 4 {
+		t.Fatalf("%d entries after the restart, not 4", len(transcript.Entries))
+	}
+}
+
+// TestTimeout checks that a participant is dropped from the queue when no
+// contribution is uploaded in time.
+func TestTimeout(t *testing.T) {
+	initial := filepath.Join(t.TempDir(), "initial.srs")
+	if err := os.WriteFile(initial, encode(t, newSRS(t, 42)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := start(t, t.TempDir(), initial, 50*time.Millisecond)
+
+	alice := c.join("alice")
+	srs, proof := c.contribute(false)
+	time.Sleep(100 * time.Millisecond)
+
+	var queue struct {
+		Participants []string `json:"participants"`
+	}
+	c.get("/queue", http.StatusOK, &queue)
+	if len(queue.Participants) != 0 {
+		t.Fatalf("queue is %v after the timeout", queue.Participants)
+	}
+	c.upload(alice, srs, proof, http.StatusForbidden)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/srsio"
+)
+
+// crossCheck converts the SRS of a curve from two independent sources, e.g. the
+// transcripts of a ceremony and a flat CRS derived from them, and checks that
+// they agree point by point, so a corrupted mirror of either is detected.
+func crossCheck(args []string) error {
+	var opts config.Options
+
+	flags := flag.NewFlagSet("cross-check", flag.ExitOnError)
+	workDir := flags.String("work-dir", "", "directory the SRS of the sources are converted into, then removed (default: the temporary directory)")
+	batchSize := flags.Int("batch-size", srsio.DefaultCompareBatch, "number of points compared at once")
+	flags.IntVar(&opts.Workers, "workers", 0, "number of CPU workers (0 - auto)")
+	flags.BoolVar(&opts.Verify, "verify", false, "also verify the points of each source while converting them")
+	profile := flags.String("profile", "", profileUsage)
+	validation := flags.String("validation", string(config.ValidationStrict), validationUsage)
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s cross-check [flags] <curve> <source> <source>\n", os.Args[0])
+		fmt.Println("A source is either <protocol>:<setup files directory>, converted like convert does, or an SRS file.")
+		flags.PrintDefaults()
+	}
+	args = parseInterspersed(flags, args)
+	if err := applyProfile(*profile); err != nil {
+		return err
+	}
+
+	if len(args) < 3 {
+		flags.Usage()
+		return errUsage
+	}
+
+	var err error
+	if opts.Validation, err = config.ParseValidation(*validation); err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp(*workDir, "cross-check-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	curve := CurveName(args[0])
+	readers := make([]*srsio.Reader, 2)
+	for i, source := range args[1:3] {
+		path, err := crossCheckSource(curve, source, filepath.Join(dir, fmt.Sprintf("source%d.srs", i)), opts)
+		if err == nil {
+			readers[i], err = srsio.Open(path)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+		defer readers[i].Close()
+
+		if name := curveNames[readers[i].Curve]; name != curve {
+			return fmt.Errorf("%s is a %s SRS", source, name)
+		}
+		fmt.Printf("%s: %d points\n", source, readers[i].NbPoints)
+	}
+
+	d, err := srsio.Compare(readers[0], readers[1], *batchSize)
+	if err != nil {
+		return err
+	}
+
+	if d.SameVk {
+		fmt.Println("Verifying keys: identical")
+	} else {
+		fmt.Println("Verifying keys: differ")
+	}
+	if d.Mismatches == 0 {
+		fmt.Printf("G1 points: the %d common points are identical\n", d.Common)
+	} else {
+		fmt.Printf("G1 points: %d of the %d common points differ, the first one at index %d\n", d.Mismatches, d.Common, d.FirstMismatch)
+	}
+
+	if !d.SameVk || d.Mismatches != 0 {
+		return errors.New("the sources disagree, one of them is corrupted")
+	}
+	fmt.Printf("The sources agree on the first %d points\n", d.Common)
+
+	return nil
+}
+
+// crossCheckSource returns the SRS file of the source, converting the setup
+// files of a <protocol>:<directory> source into a canonical SRS file at path.
+func crossCheckSource(curve CurveName, source, path string, opts config.Options) (string, error) {
+	protocol, dir, ok := strings.Cut(source, ":")
+	curves, known := supportedSetups[ProtocolName(protocol)]
+	if !ok || !known {
+		// An SRS file, whose name may contain a colon, e.g. a Windows drive
+		return source, nil
+	}
+	translateFunc, ok := curves[curve]
+	if !ok {
+		return "", fmt.Errorf("the %s setup isn't available on %s", protocol, curve)
+	}
+
+	files, err := input.Dir(dir)
+	if err != nil {
+		return "", err
+	}
+	if err = validateSetup(ProtocolName(protocol), curve, files, opts.Validation); err != nil {
+		return "", err
+	}
+
+	opts = config.Tune(opts, dir)
+	opts.Mappings = new(config.Mappings)
+	srs, _, err := translateFunc(files, opts)
+	defer func() {
+		if err := opts.Mappings.Release(); err != nil {
+			fmt.Printf("WARNING: failed to release off-heap memory: %v\n", err)
+		}
+	}()
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create SRS file: %w", err)
+	}
+	defer f.Close()
+
+	if err = srsio.Write(f, srs, srsio.FormatCanonical, opts); err != nil {
+		return "", fmt.Errorf("failed to write SRS file: %w", err)
+	}
+
+	return path, f.Close()
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"linea/aztec-srs-to-gnark/srsio"
+)
+
+// diff compares two SRS files, e.g. to reconcile the outputs of independent
+// conversions of the same ceremony. Like diff(1), it exits with status 1 when
+// the SRS differ and 2 when they can't be compared.
+func diff(args []string) error {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	batchSize := flags.Int("batch-size", srsio.DefaultCompareBatch, "number of points compared at once")
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s diff [flags] <SRS file> <SRS file>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() < 2 {
+		flags.Usage()
+		return errUsage
+	}
+
+	nameA, nameB := flags.Arg(0), flags.Arg(1)
+
+	trouble := func(err error) error {
+		fmt.Printf("ERROR: %v\n", err)
+		return exitStatus(2)
+	}
+
+	a, err := srsio.Open(nameA)
+	if err != nil {
+		return trouble(err)
+	}
+	defer a.Close()
+
+	b, err := srsio.Open(nameB)
+	if err != nil {
+		return trouble(err)
+	}
+	defer b.Close()
+
+	for _, f := range []struct {
+		name string
+		r    *srsio.Reader
+	}{{nameA, a}, {nameB, b}} {
+		fmt.Printf("%s: %s, %s, %d points (degree %d)\n", f.name, f.r.Format, f.r.Curve, f.r.NbPoints, f.r.NbPoints-1)
+	}
+
+	d, err := srsio.Compare(a, b, *batchSize)
+	if err != nil {
+		return trouble(err)
+	}
+
+	if !d.SameCurve {
+		fmt.Printf("The SRS are on different curves: %s and %s\n", a.Curve, b.Curve)
+		return exitStatus(1)
+	}
+
+	if d.SameVk {
+		fmt.Println("Verifying keys: identical")
+	} else {
+		fmt.Println("Verifying keys: differ")
+	}
+
+	if d.Mismatches == 0 {
+		fmt.Printf("G1 points: the %d common points are identical\n", d.Common)
+	} else {
+		fmt.Printf("G1 points: %d of the %d common points differ, the first one at index %d\n", d.Mismatches, d.Common, d.FirstMismatch)
+	}
+
+	switch {
+	case a.NbPoints > b.NbPoints:
+		fmt.Printf("%s has %d more points\n", nameA, a.NbPoints-b.NbPoints)
+	case b.NbPoints > a.NbPoints:
+		fmt.Printf("%s has %d more points\n", nameB, b.NbPoints-a.NbPoints)
+	}
+
+	if !d.Identical(a, b) {
+		fmt.Println("The SRS differ")
+		return exitStatus(1)
+	}
+	fmt.Println("The SRS are identical")
+
+	return nil
+}
@@ -0,0 +1,142 @@
+// Package digest provides the hash algorithms the ceremonies check their
+// setup files, or sections of them, with. A ceremony profile names the
+// algorithm, so a new format with other hash choices needs no new code, and
+// the mismatches are reported the same way whatever the algorithm.
+package digest
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// Algorithm is the name of a hash algorithm, as written in the profiles.
+type Algorithm string
+
+// Algorithms of the supported ceremonies.
+const (
+	// BLAKE2b512 is the hash of the Aztec transcripts and of the Plumo and
+	// Perpetual Powers of Tau chunk files.
+	BLAKE2b512 Algorithm = "blake2b-512"
+	BLAKE2b256 Algorithm = "blake2b-256"
+	// SHA256 is the hash of the files published with their checksums, e.g.
+	// the Aleo setup files.
+	SHA256 Algorithm = "sha256"
+	SHA512 Algorithm = "sha512"
+)
+
+// algorithms are the constructors of the supported algorithms.
+var algorithms = map[Algorithm]func() hash.Hash{
+	BLAKE2b512: func() hash.Hash {
+		h, _ := blake2b.New512(nil) // only fails for keys longer than 64 bytes
+		return h
+	},
+	BLAKE2b256: func() hash.Hash {
+		h, _ := blake2b.New256(nil) // only fails for keys longer than 64 bytes
+		return h
+	},
+	SHA256: sha256.New,
+	SHA512: sha512.New,
+}
+
+// Algorithms returns the supported algorithms, sorted by name.
+func Algorithms() []Algorithm {
+	names := make([]Algorithm, 0, len(algorithms))
+	for name := range algorithms {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// Validate checks that the algorithm is a supported one.
+func (a Algorithm) Validate() error {
+	if _, ok := algorithms[a]; !ok {
+		return fmt.Errorf("unsupported hash algorithm %q, use one of %v", a, Algorithms())
+	}
+
+	return nil
+}
+
+// New returns a new hash of the algorithm, which must be a supported one.
+func (a Algorithm) New() hash.Hash {
+	newHash, ok := algorithms[a]
+	if !ok {
+		panic(fmt.Sprintf("unsupported hash algorithm %q", a))
+	}
+
+	return newHash()
+}
+
+// Size returns the size of the digests of the algorithm, which must be a
+// supported one.
+func (a Algorithm) Size() int {
+	return a.New().Size()
+}
+
+// Reader hashes r to its end, returning the hex encoded digest and the number
+// of bytes read.
+func (a Algorithm) Reader(r io.Reader) (string, int64, error) {
+	h := a.New()
+	n, err := io.Copy(h, r)
+	if err != nil {
+		return "", n, err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+// File hashes the file at path, returning the hex encoded digest and the size
+// of the file.
+func (a Algorithm) File(path string) (string, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+
+	sum, n, err := a.Reader(file)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to hash file: %w", err)
+	}
+
+	return sum, n, nil
+}
+
+// MismatchError reports a digest which is none of the expected ones.
+type MismatchError struct {
+	Algorithm Algorithm
+	// Subject is what was hashed, e.g. a file or a section of it.
+	Subject string
+	// Expected are the hex encoded digests accepted.
+	Expected []string
+	// Got is the hex encoded digest computed.
+	Got string
+}
+
+func (e *MismatchError) Error() string {
+	if len(e.Expected) == 1 {
+		return fmt.Sprintf("%s mismatch of %s: expected %s, got %s", e.Algorithm, e.Subject, e.Expected[0], e.Got)
+	}
+
+	return fmt.Sprintf("%s mismatch of %s: %s isn't one of the %d expected digests", e.Algorithm, e.Subject, e.Got, len(e.Expected))
+}
+
+// Check returns a *MismatchError unless the hex encoded digest sum of subject
+// is one of the expected ones, compared regardless of case.
+func Check(a Algorithm, subject, sum string, expected ...string) error {
+	if slices.ContainsFunc(expected, func(e string) bool { return strings.EqualFold(sum, e) }) {
+		return nil
+	}
+
+	return &MismatchError{Algorithm: a, Subject: subject, Expected: expected, Got: sum}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"linea/aztec-srs-to-gnark/fetch"
+)
+
+// download fetches the published setup files of a ceremony and verifies
+// them, leaving a directory ready to be converted.
+func download(args []string) error {
+	flags := flag.NewFlagSet("download", flag.ExitOnError)
+	dest := flags.String("dest", ".", "directory to download the setup files into")
+	var sel fetch.Selection
+	flags.IntVar(&sel.Degree, "degree", 0, "download only the files holding the first 2^degree points (0 - all)")
+	flags.IntVar(&sel.Transcripts, "transcripts", 0, "download only the first transcripts (aztec, 0 - all)")
+	flags.IntVar(&sel.Contribution, "contribution", 0, "number of the contribution to download (ppot)")
+	flags.BoolVar(&sel.Response, "response", false, "download the response of the contribution instead of its challenge (ppot)")
+	flags.StringVar(&sel.Attestation, "attestation", "", "URL or path of the attestation with the checksums of the contribution (ppot)")
+	var opts fetch.Options
+	flags.IntVar(&opts.Parallelism, "parallelism", 4, "number of setup files downloaded at the same time")
+	flags.IntVar(&opts.Retries, "retries", 5, "number of times a failed download is resumed")
+	flags.DurationVar(&opts.Backoff, "backoff", time.Second, "delay before the first retry, doubled on every next one")
+	var network fetch.Network
+	flags.StringVar(&network.Proxy, "proxy", "", "URL of the HTTP(S) proxy (default from HTTP_PROXY, HTTPS_PROXY and NO_PROXY)")
+	flags.StringVar(&network.CACert, "ca-cert", "", "PEM file with additional certificate authorities to trust")
+	bwlimit := flags.String("bwlimit", "", "limit of the total download rate in bytes per second, with an optional K, M or G suffix (e.g. 20M)")
+	flags.BoolVar(&network.CloudCredentials, "cloud-credentials", false, "authorize the requests to S3, Cloud Storage and Azure Blob Storage with the credentials of the environment variables of their tools")
+	profile := flags.String("profile", "", profileUsage)
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s download [flags] <protocol> <curve>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if err := applyProfile(*profile); err != nil {
+		return err
+	}
+
+	if flags.NArg() < 2 {
+		flags.Usage()
+		return errUsage
+	}
+
+	listFunc, ok := supportedDownloads[ProtocolName(flags.Arg(0))][CurveName(flags.Arg(1))]
+	if !ok {
+		fmt.Println("ERROR: Unsupported protocol or curve, use one of:")
+
+		for protocol := range supportedDownloads {
+			for curve := range supportedDownloads[protocol] {
+				fmt.Printf("\t%s %s\n", protocol, curve)
+			}
+		}
+
+		return errUsage
+	}
+
+	if *bwlimit != "" {
+		rate, err := fetch.ParseRate(*bwlimit)
+		if err != nil {
+			return err
+		}
+		network.BandwidthLimit = rate
+	}
+
+	if err := fetch.Setup(network); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	downloads, err := listFunc(ctx, sel)
+	if err != nil {
+		return err
+	}
+
+	if err = fetch.All(ctx, downloads, *dest, opts); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nSetup files downloaded to %s\n", *dest)
+
+	return nil
+}
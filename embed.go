@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/srsio"
+)
+
+// curveIDs maps the curves to the names of their gnark-crypto ecc.ID.
+var curveIDs = map[CurveName]string{
+	BN254Curve:    "BN254",
+	BLS12377Curve: "BLS12_377",
+	BLS12381Curve: "BLS12_381",
+	BW6761Curve:   "BW6_761",
+}
+
+// embedTemplate is the Go file loading the embedded SRS.
+var embedTemplate = template.Must(template.New("embed").Parse(`// Code generated by gnark_mpc_kzg_srs embed; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"bytes"
+	_ "embed"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/kzg"
+)
+
+// {{.Var}} is the {{.Format}} encoding of the first {{.Points}} points of the
+// {{.Curve}} SRS {{.Source}}, of canonical digest
+// {{.Digest}}.
+//
+//go:embed {{.DataFile}}
+var {{.Var}} []byte
+
+// {{.Func}} returns the {{.Curve}} KZG SRS of degree {{.Degree}} embedded from
+// {{.DataFile}}.
+func {{.Func}}() (kzg.SRS, error) {
+	srs := kzg.NewSRS(ecc.{{.CurveID}})
+{{- if eq .Format "memdump"}}
+	if err := srs.ReadDump(bytes.NewReader({{.Var}})); err != nil {
+		return nil, err
+	}
+{{- else}}
+	if _, err := srs.ReadFrom(bytes.NewReader({{.Var}})); err != nil {
+		return nil, err
+	}
+{{- end}}
+
+	return srs, nil
+}
+`))
+
+// embed writes a truncation of an SRS next to a Go file embedding it, to be
+// used as a ceremony derived test fixture by Go projects.
+func embed(args []string) error {
+	flags := flag.NewFlagSet("embed", flag.ExitOnError)
+	degree := flags.Int("degree", 64, "degree of the embedded SRS, which holds degree+1 points")
+	out := flags.String("o", "srs_test_data.go", "Go file to write, the SRS is written next to it with the .srs extension")
+	pkg := flags.String("package", "", "package of the Go file (default: the name of its directory)")
+	funcName := flags.String("func", "loadTestSRS", "name of the generated loader function")
+	outputFormat := flags.String("format", string(srsio.FormatCanonical), fmt.Sprintf("format of the embedded SRS, one of %v", srsio.Formats))
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s embed [flags] <SRS file>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	args = parseInterspersed(flags, args)
+
+	if len(args) < 1 {
+		flags.Usage()
+		return errUsage
+	}
+
+	srsFormat, err := srsio.ParseFormat(*outputFormat)
+	if err != nil {
+		return err
+	}
+
+	goFile, err := filepath.Abs(*out)
+	if err != nil {
+		return err
+	}
+	if *pkg == "" {
+		*pkg = filepath.Base(filepath.Dir(goFile))
+	}
+	if !token.IsIdentifier(*pkg) || !token.IsIdentifier(*funcName) {
+		return fmt.Errorf("%q and %q must be Go identifiers, set -package and -func", *pkg, *funcName)
+	}
+
+	r, err := srsio.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	if *degree < 1 || *degree >= r.NbPoints {
+		return fmt.Errorf("the degree must be between 1 and %d", r.NbPoints-1)
+	}
+
+	srs, err := r.Range(0, *degree+1)
+	if err != nil {
+		return err
+	}
+
+	var data bytes.Buffer
+	if err = srsio.Write(&data, srs, srsFormat, config.Options{Workers: 1}); err != nil {
+		return err
+	}
+
+	dataFile := strings.TrimSuffix(goFile, ".go") + ".srs"
+	if err = os.WriteFile(dataFile, data.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write embedded SRS: %w", err)
+	}
+
+	embedded, err := srsio.Open(dataFile)
+	if err != nil {
+		return err
+	}
+	digest, err := srsio.Digest(embedded, 0)
+	embedded.Close()
+	if err != nil {
+		return err
+	}
+
+	curve := curveNames[r.Curve]
+	var src bytes.Buffer
+	if err = embedTemplate.Execute(&src, map[string]any{
+		"Package":  *pkg,
+		"Var":      strings.ToLower((*funcName)[:1]) + (*funcName)[1:] + "Data",
+		"Func":     *funcName,
+		"Format":   string(srsFormat),
+		"Points":   *degree + 1,
+		"Degree":   *degree,
+		"Curve":    curve,
+		"CurveID":  curveIDs[curve],
+		"Source":   filepath.Base(args[0]),
+		"Digest":   hex.EncodeToString(digest),
+		"DataFile": filepath.Base(dataFile),
+	}); err != nil {
+		return err
+	}
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format the Go file: %w", err)
+	}
+
+	if err = os.WriteFile(goFile, formatted, 0o644); err != nil {
+		return fmt.Errorf("failed to write Go file: %w", err)
+	}
+
+	fmt.Printf("SRS of degree %d written to %s, loaded by %s in %s\n", *degree, dataFile, *funcName, goFile)
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package encrypt
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"filippo.io/age"
+)
+
+// The files are encrypted in the age v1 format (age-encryption.org/v1) with
+// X25519 recipients, the one of the age and rage tools.
+const (
+	ageIntro = "age-encryption.org/v1\n"
+	// ageRecipientHRP is the human readable part of the bech32 encoding of
+	// the X25519 public keys.
+	ageRecipientHRP = "age"
+)
+
+// parseAgeRecipients parses the X25519 public keys of data, one per line,
+// skipping the empty lines and the comments.
+func parseAgeRecipients(data []byte) ([]age.Recipient, error) {
+	return age.ParseRecipients(bytes.NewReader(data))
+}
+
+// parseAgeIdentities parses the X25519 secret keys of data, one per line,
+// skipping the empty lines and the comments.
+func parseAgeIdentities(data []byte) ([]age.Identity, error) {
+	return age.ParseIdentities(bytes.NewReader(data))
+}
+
+// encryptAge returns a writer encrypting what is written to w for the X25519
+// recipients. Closing it writes the last chunk, it doesn't close w.
+func encryptAge(w io.Writer, recipients []age.Recipient) (io.WriteCloser, error) {
+	return age.Encrypt(w, recipients...)
+}
+
+// decryptAge returns the payload of the age file r, decrypted with the first
+// of the X25519 identities its file key is wrapped for. The chunks of the
+// payload are authenticated while they are read, an error is returned for
+// a corrupted or truncated one.
+func decryptAge(r io.Reader, identities []age.Identity) (io.Reader, error) {
+	payload, err := age.Decrypt(r, identities...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt age file: %w", err)
+	}
+
+	return payload, nil
+}
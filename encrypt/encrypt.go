@@ -0,0 +1,255 @@
+// Package encrypt encrypts the output SRS files and their metadata to age or
+// GPG recipients, for the teams storing them in shared object storage under
+// key management policies, and decrypts them when they are loaded.
+package encrypt
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+)
+
+// Scheme is the format of the encrypted files.
+type Scheme string
+
+const (
+	// SchemeAge is the age v1 format with X25519 recipients.
+	SchemeAge Scheme = "age"
+	// SchemeGPG is an OpenPGP message, as gpg writes it.
+	SchemeGPG Scheme = "gpg"
+)
+
+// Ext returns the extension of the files encrypted with the scheme.
+func (s Scheme) Ext() string {
+	return "." + string(s)
+}
+
+// IdentityEnv is the environment variable listing the files of the identities
+// the encrypted SRS files are decrypted with: age identity files or GPG secret
+// keys, separated like the paths of PATH.
+const IdentityEnv = "SRS_IDENTITY_FILE"
+
+// ErrNoIdentity is returned when an encrypted file is loaded without
+// identities to decrypt it with.
+var ErrNoIdentity = errors.New("encrypted file: set " + IdentityEnv + " to the file of an identity to decrypt it with")
+
+// Recipients are the keys the outputs are encrypted to, all of a scheme.
+type Recipients struct {
+	Scheme Scheme
+	age    []age.Recipient
+	gpg    openpgp.EntityList
+}
+
+// ParseRecipients parses the recipients: age public keys (age1...), or files
+// of age public keys, one per line, or of GPG public keys, armored or binary.
+func ParseRecipients(specs []string) (*Recipients, error) {
+	r := new(Recipients)
+	for _, spec := range specs {
+		if strings.HasPrefix(spec, ageRecipientHRP+"1") {
+			if err := r.addAge(spec); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		data, err := os.ReadFile(spec)
+		if err != nil {
+			return nil, fmt.Errorf("recipient %s is neither an age public key nor a readable file: %w", spec, err)
+		}
+		if keys, err := readKeyRing(data); err == nil && len(keys) > 0 {
+			if err = r.setScheme(SchemeGPG); err != nil {
+				return nil, err
+			}
+			// The keys without an encryption subkey are rejected before the
+			// conversion
+			if _, err = encryptGPG(io.Discard, keys, ""); err != nil {
+				return nil, fmt.Errorf("GPG keys of %s: %w", spec, err)
+			}
+			r.gpg = append(r.gpg, keys...)
+			continue
+		}
+
+		keys, err := parseAgeRecipients(data)
+		if err != nil {
+			return nil, fmt.Errorf("recipient file %s holds neither GPG keys nor age public keys: %w", spec, err)
+		}
+		if err = r.setScheme(SchemeAge); err != nil {
+			return nil, err
+		}
+		r.age = append(r.age, keys...)
+	}
+	if r.Scheme == "" {
+		return nil, errors.New("no recipients")
+	}
+
+	return r, nil
+}
+
+// setScheme sets the scheme of the recipients, they can't mix age and GPG keys.
+func (r *Recipients) setScheme(scheme Scheme) error {
+	if r.Scheme != "" && r.Scheme != scheme {
+		return errors.New("the recipients mix age and GPG keys")
+	}
+	r.Scheme = scheme
+
+	return nil
+}
+
+// addAge adds the age public key.
+func (r *Recipients) addAge(s string) error {
+	key, err := age.ParseX25519Recipient(s)
+	if err != nil {
+		return fmt.Errorf("invalid age recipient %s: %w", s, err)
+	}
+	if err = r.setScheme(SchemeAge); err != nil {
+		return err
+	}
+	r.age = append(r.age, key)
+
+	return nil
+}
+
+// Encrypt returns a writer encrypting what is written to w, a file of the
+// name, for the recipients. Closing it writes the end of the encrypted file,
+// it doesn't close w.
+func (r *Recipients) Encrypt(w io.Writer, name string) (io.WriteCloser, error) {
+	if r.Scheme == SchemeAge {
+		return encryptAge(w, r.age)
+	}
+
+	return encryptGPG(w, r.gpg, name)
+}
+
+// EncryptFile encrypts the file at path into path + the extension of the
+// scheme and removes it, returning the path of the encrypted file.
+func (r *Recipients) EncryptFile(path string) (string, error) {
+	encrypted := path + r.Scheme.Ext()
+
+	in, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt %s: %w", path, err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(encrypted)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt %s: %w", path, err)
+	}
+	defer out.Close()
+
+	w, err := r.Encrypt(out, filepath.Base(path))
+	if err == nil {
+		_, err = io.Copy(w, in)
+	}
+	if err == nil {
+		err = w.Close()
+	}
+	if err == nil {
+		err = out.Close()
+	}
+	if err != nil {
+		os.Remove(encrypted)
+		return "", fmt.Errorf("failed to encrypt %s: %w", path, err)
+	}
+
+	in.Close()
+	if err = os.Remove(path); err != nil {
+		return "", fmt.Errorf("failed to remove %s once encrypted: %w", path, err)
+	}
+
+	return encrypted, nil
+}
+
+// Identities are the keys decrypting the files: age X25519 secret keys or GPG
+// secret keys.
+type Identities struct {
+	age []age.Identity
+	gpg openpgp.EntityList
+}
+
+// LoadIdentities reads the identity files: age identity files, with a secret
+// key (AGE-SECRET-KEY-1...) per line, or GPG secret keys stored without a
+// passphrase, armored or binary.
+func LoadIdentities(paths ...string) (*Identities, error) {
+	ids := new(Identities)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read identity file: %w", err)
+		}
+
+		if keys, err := readKeyRing(data); err == nil && len(keys) > 0 {
+			ids.gpg = append(ids.gpg, keys...)
+			continue
+		}
+
+		keys, err := parseAgeIdentities(data)
+		if err != nil {
+			return nil, fmt.Errorf("identity file %s holds neither GPG keys nor age identities: %w", path, err)
+		}
+		ids.age = append(ids.age, keys...)
+	}
+
+	return ids, nil
+}
+
+// IdentitiesFromEnv loads the identity files listed by IdentityEnv, it returns
+// ErrNoIdentity when it is unset.
+func IdentitiesFromEnv() (*Identities, error) {
+	paths := filepath.SplitList(os.Getenv(IdentityEnv))
+	if len(paths) == 0 {
+		return nil, ErrNoIdentity
+	}
+
+	return LoadIdentities(paths...)
+}
+
+// Detect returns the scheme of the encrypted file of the name starting with
+// header, "" when it isn't encrypted. The binary OpenPGP messages are only
+// told from the SRS files by their .gpg extension, their first byte could be
+// the one of a point.
+func Detect(name string, header []byte) Scheme {
+	switch {
+	case bytes.HasPrefix(header, []byte(ageIntro)):
+		return SchemeAge
+	case bytes.HasPrefix(header, []byte(pgpArmorMessage)):
+		return SchemeGPG
+	case strings.HasSuffix(name, SchemeGPG.Ext()) && isPGPPacket(header):
+		return SchemeGPG
+	}
+
+	return ""
+}
+
+// Decrypt returns the content of the encrypted file r of the scheme. An error
+// of the integrity checks may only be returned when reading its end.
+func (ids *Identities) Decrypt(r io.Reader, scheme Scheme) (io.Reader, error) {
+	switch scheme {
+	case SchemeAge:
+		if len(ids.age) == 0 {
+			return nil, errors.New("no age identity to decrypt the file with")
+		}
+		return decryptAge(r, ids.age)
+	case SchemeGPG:
+		br := bufio.NewReader(r)
+		if header, _ := br.Peek(len(pgpArmorMessage)); string(header) == pgpArmorMessage {
+			block, err := armor.Decode(br)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode armored GPG message: %w", err)
+			}
+			return decryptGPG(block.Body, ids.gpg)
+		}
+		return decryptGPG(br, ids.gpg)
+	}
+
+	return nil, fmt.Errorf("unsupported encryption %q", scheme)
+}
@@ -0,0 +1,154 @@
+package encrypt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+// roundTrip encrypts a payload of more than a chunk of age for the
+// recipients, detects the scheme of the encrypted file and decrypts it with
+// the identities.
+func roundTrip(t *testing.T, recipients []string, identities string) []byte {
+	t.Helper()
+
+	r, err := ParseRecipients(recipients)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids, err := LoadIdentities(identities)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload := make([]byte, 100<<10)
+	if _, err = rand.Read(payload); err != nil {
+		t.Fatal(err)
+	}
+
+	var encrypted bytes.Buffer
+	w, err := r.Encrypt(&encrypted, "srs.memdump")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.Write(payload); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	name := "srs.memdump" + r.Scheme.Ext()
+	if scheme := Detect(name, encrypted.Bytes()); scheme != r.Scheme {
+		t.Fatalf("%s file detected as %q", r.Scheme, scheme)
+	}
+	if scheme := Detect("srs.memdump", payload); scheme != "" {
+		t.Fatalf("plaintext detected as %q", scheme)
+	}
+
+	plaintext, err := ids.Decrypt(bytes.NewReader(encrypted.Bytes()), r.Scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decrypted, err := io.ReadAll(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decrypted, payload) {
+		t.Fatal("the decrypted payload differs")
+	}
+
+	return encrypted.Bytes()
+}
+
+func TestAgeRoundTrip(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	identityFile := filepath.Join(dir, "identity.txt")
+	if err = os.WriteFile(identityFile, []byte("# created: test\n"+identity.String()+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	recipientsFile := filepath.Join(dir, "recipients.txt")
+	if err = os.WriteFile(recipientsFile, []byte("# the other one\n"+other.Recipient().String()+"\n\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	encrypted := roundTrip(t, []string{identity.Recipient().String(), recipientsFile}, identityFile)
+
+	ids, err := LoadIdentities(identityFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := bytes.Clone(encrypted)
+	tampered[len(tampered)-1] ^= 1
+	plaintext, err := ids.Decrypt(bytes.NewReader(tampered), SchemeAge)
+	if err == nil {
+		_, err = io.ReadAll(plaintext)
+	}
+	if err == nil {
+		t.Fatal("a tampered age file was decrypted")
+	}
+}
+
+func TestGPGRoundTrip(t *testing.T) {
+	entity, err := openpgp.NewEntity("srs", "test", "srs@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	var public, secret bytes.Buffer
+	if err = entity.Serialize(&public); err != nil {
+		t.Fatal(err)
+	}
+	if err = entity.SerializePrivate(&secret, nil); err != nil {
+		t.Fatal(err)
+	}
+	publicFile, secretFile := filepath.Join(dir, "public.gpg"), filepath.Join(dir, "secret.gpg")
+	if err = os.WriteFile(publicFile, public.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(secretFile, secret.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	roundTrip(t, []string{publicFile}, secretFile)
+}
+
+func TestRecipientsMixingSchemes(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entity, err := openpgp.NewEntity("srs", "test", "srs@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var public bytes.Buffer
+	if err = entity.Serialize(&public); err != nil {
+		t.Fatal(err)
+	}
+	publicFile := filepath.Join(t.TempDir(), "public.gpg")
+	if err = os.WriteFile(publicFile, public.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = ParseRecipients([]string{identity.Recipient().String(), publicFile}); err == nil {
+		t.Fatal("age and GPG recipients mixed")
+	}
+}
@@ -0,0 +1,74 @@
+package encrypt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+)
+
+// pgpArmorMessage starts the armored OpenPGP messages.
+const pgpArmorMessage = "-----BEGIN PGP MESSAGE-----"
+
+// readKeyRing reads the armored or binary OpenPGP keys of data.
+func readKeyRing(data []byte) (openpgp.EntityList, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("-----BEGIN PGP")) {
+		return openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+	}
+
+	return openpgp.ReadKeyRing(bytes.NewReader(data))
+}
+
+// isPGPPacket tells whether data starts with the packet of a public key
+// encrypted session key, the first one of an OpenPGP message encrypted to
+// recipients.
+func isPGPPacket(data []byte) bool {
+	if len(data) == 0 || data[0]&0x80 == 0 {
+		return false
+	}
+
+	tag := data[0] & 0x3f
+	if data[0]&0x40 == 0 {
+		// Old format packet
+		tag = (data[0] >> 2) & 0x0f
+	}
+
+	return tag == 1
+}
+
+// encryptGPG returns a writer encrypting what is written to w for the OpenPGP
+// recipients, as a binary message. Closing it ends the message, it doesn't
+// close w.
+func encryptGPG(w io.Writer, recipients openpgp.EntityList, name string) (io.WriteCloser, error) {
+	hints := &openpgp.FileHints{IsBinary: true, FileName: name}
+	config := &packet.Config{DefaultCipher: packet.CipherAES256}
+
+	return openpgp.Encrypt(w, recipients, nil, hints, config)
+}
+
+// decryptGPG returns the content of the OpenPGP message r, decrypted with the
+// secret keys. The integrity of the message is checked once it is read to its
+// end, an error is returned then.
+func decryptGPG(r io.Reader, keys openpgp.EntityList) (io.Reader, error) {
+	if len(keys.DecryptionKeys()) == 0 {
+		return nil, errors.New("no GPG secret keys to decrypt with")
+	}
+
+	// Only the keys stored without a passphrase can be used
+	prompt := func(keys []openpgp.Key, symmetric bool) ([]byte, error) {
+		return nil, errors.New("the GPG secret key is protected by a passphrase, export it without one or decrypt the file with gpg")
+	}
+
+	md, err := openpgp.ReadMessage(r, keys, prompt, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt GPG message: %w", err)
+	}
+	if !md.IsEncrypted {
+		return nil, errors.New("not an encrypted GPG message")
+	}
+
+	return md.UnverifiedBody, nil
+}
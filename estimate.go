@@ -0,0 +1,239 @@
+package main
+
+import (
+	"crypto/rand"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	blsKzg "github.com/consensys/gnark-crypto/ecc/bls12-377/kzg"
+	bls381Kzg "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	bwKzg "github.com/consensys/gnark-crypto/ecc/bw6-761/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/manifest"
+	"linea/aztec-srs-to-gnark/srsio"
+	"linea/aztec-srs-to-gnark/verify"
+)
+
+const (
+	// calibrationPoints is the number of points of the SRS the write and
+	// verification times are measured on.
+	calibrationPoints = 1 << 12
+	// setupReadBuffer is the size of the buffer of a setup file being read.
+	setupReadBuffer = 4 << 20
+)
+
+// estimate predicts the outcome and the cost of a conversion from the headers
+// of the setup files alone: the degree of the SRS, the size of the output in
+// every format, the peak memory and the approximate duration.
+func estimate(args []string) error {
+	var opts config.Options
+
+	flags := flag.NewFlagSet("estimate", flag.ExitOnError)
+	format := flags.String("format", string(srsio.FormatMemDump), fmt.Sprintf("output format the duration is estimated for, one of %v", srsio.Formats))
+	flags.IntVar(&opts.Workers, "workers", 0, "number of CPU workers (0 - auto)")
+	flags.IntVar(&opts.IOParallelism, "io-parallelism", 0, "number of setup files read at the same time (0 - auto)")
+	flags.IntVar(&opts.BatchSize, "batch-size", 0, "number of points processed by a worker at once (0 - auto)")
+	flags.BoolVar(&opts.Verify, "verify", false, "estimate a conversion verifying the points")
+	flags.IntVar(&opts.Transcripts, "transcripts", 0, "estimate a conversion of the first transcripts only, aztec only (0 - all the transcripts)")
+	profile := flags.String("profile", "", profileUsage)
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s estimate [flags] <protocol> <curve> <setup files directory>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	args = parseInterspersed(flags, args)
+	if err := applyProfile(*profile); err != nil {
+		return err
+	}
+
+	if len(args) < 3 {
+		flags.Usage()
+		return errUsage
+	}
+
+	outputFormat, err := srsio.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+
+	if opts.Transcripts != 0 && ProtocolName(args[0]) != AztecProtocol {
+		return errors.New("selecting the first transcripts is only available in the aztec setup")
+	}
+
+	describe, ok := supportedManifests[ProtocolName(args[0])][CurveName(args[1])]
+	if !ok {
+		fmt.Println("ERROR: Unsupported protocol or curve, use one of:")
+
+		for protocol := range supportedManifests {
+			for curve := range supportedManifests[protocol] {
+				fmt.Printf("\t%s %s\n", protocol, curve)
+			}
+		}
+
+		return errUsage
+	}
+
+	var curve ecc.ID
+	for id, name := range curveNames {
+		if name == CurveName(args[1]) {
+			curve = id
+		}
+	}
+
+	files, err := input.Dir(args[2])
+	if err != nil {
+		return err
+	}
+
+	descs, err := manifest.Detect(files, describe)
+	if err != nil {
+		return err
+	}
+
+	var (
+		used, ignored int
+		inputSize     int64
+		points        int
+	)
+	for _, desc := range descs {
+		// The importer skips the transcripts after the first opts.Transcripts
+		if desc.Kind == manifest.KindIgnored || (opts.Transcripts > 0 && desc.Index != nil && *desc.Index >= opts.Transcripts) {
+			ignored++
+			continue
+		}
+		used++
+		if desc.Size != input.UnknownSize {
+			inputSize += desc.Size
+		}
+		points += desc.Points
+	}
+	// The aztec and aleo setup files hold the powers from tau^1, the importers
+	// prepend the generator.
+	if ProtocolName(args[0]) != CeloProtocol {
+		points++
+	}
+	if used == 0 || points < 2 {
+		return errors.New("no setup files with points found")
+	}
+
+	opts = config.Tune(opts, args[2])
+
+	fmt.Printf("Setup files: %d used, %d ignored, %s\n", used, ignored, formatBytes(inputSize))
+	fmt.Printf("Output degree: %d (%d points)\n", points-1, points)
+
+	fmt.Println("Output size:")
+	for _, f := range srsio.Formats {
+		size, err := srsio.Size(curve, f, points)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("> %-10s %s\n", f, formatBytes(size))
+	}
+
+	// All the points are held in memory, in their memdump layout, while
+	// batches of them are read and marshalled.
+	dumpSize, _ := srsio.Size(curve, srsio.FormatMemDump, points)
+	emptyDumpSize, _ := srsio.Size(curve, srsio.FormatMemDump, 0)
+	pointsMemory := dumpSize - emptyDumpSize
+	rawSize, _ := srsio.Size(curve, srsio.FormatCanonical, 1)
+	emptyRawSize, _ := srsio.Size(curve, srsio.FormatCanonical, 0)
+	pointSize := rawSize - emptyRawSize
+	buffersMemory := int64(opts.IOParallelism)*setupReadBuffer + int64(2*opts.Workers*opts.BatchSize)*pointSize
+
+	fmt.Printf("Peak memory: %s (G1 points %s, buffers %s)\n", formatBytes(pointsMemory+buffersMemory), formatBytes(pointsMemory), formatBytes(buffersMemory))
+	if memory := config.AvailableMemory(); memory > 0 && uint64(pointsMemory+buffersMemory) > memory {
+		fmt.Printf("WARNING: only %s of memory is available, use -spill-dir to page the points out to disk\n", formatBytes(int64(memory)))
+	}
+
+	write, check, err := calibrate(curve, outputFormat, opts)
+	if err != nil {
+		return err
+	}
+	write = write * time.Duration(points) / calibrationPoints
+	check = check * time.Duration(points) / calibrationPoints
+
+	var read time.Duration
+	if throughput, err := config.SampleReadThroughput(args[2]); err != nil {
+		fmt.Printf("WARNING: failed to sample read throughput: %v\n", err)
+	} else if throughput > 0 {
+		read = time.Duration(float64(inputSize) / throughput * float64(time.Second))
+	}
+
+	// The points are verified while the setup files are read.
+	parse := max(read, check)
+	fmt.Printf("Approximate duration with %d workers: %v (reading %v", opts.Workers, (parse + write).Round(time.Millisecond), read.Round(time.Millisecond))
+	if opts.Verify {
+		fmt.Printf(", verifying %v", check.Round(time.Millisecond))
+	}
+	fmt.Printf(", writing %s %v)\n", outputFormat, write.Round(time.Millisecond))
+
+	return nil
+}
+
+// calibrate measures how long writing an SRS of calibrationPoints points in
+// the format takes, and with opts.Verify how long verifying it takes.
+func calibrate(curve ecc.ID, format srsio.Format, opts config.Options) (write, check time.Duration, err error) {
+	tau, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to sample tau: %w", err)
+	}
+
+	var srs kzg.SRS
+	switch curve {
+	case ecc.BN254:
+		srs, err = bnKzg.NewSRS(calibrationPoints, tau)
+	case ecc.BLS12_377:
+		srs, err = blsKzg.NewSRS(calibrationPoints, tau)
+	case ecc.BLS12_381:
+		srs, err = bls381Kzg.NewSRS(calibrationPoints, tau)
+	case ecc.BW6_761:
+		srs, err = bwKzg.NewSRS(calibrationPoints, tau)
+	default:
+		return 0, 0, fmt.Errorf("unsupported curve %s", curve)
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to generate calibration SRS: %w", err)
+	}
+
+	start := time.Now()
+	if err = srsio.Write(io.Discard, srs, format, opts); err != nil {
+		return 0, 0, fmt.Errorf("failed to write calibration SRS: %w", err)
+	}
+	write = time.Since(start)
+
+	if opts.Verify {
+		start = time.Now()
+		if err = verify.SRS(srs, opts); err != nil {
+			return 0, 0, fmt.Errorf("failed to verify calibration SRS: %w", err)
+		}
+		check = time.Since(start)
+	}
+
+	return write, check, nil
+}
+
+// formatBytes formats a size in bytes with a binary unit.
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,319 @@
+// Package ethereum reads the transcript of the Ethereum KZG ceremony, the
+// powers of τ of EIP-4844 on bls12-381.
+package ethereum
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381"
+	bls381Kzg "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/audit"
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/offheap"
+	"linea/aztec-srs-to-gnark/parallel"
+	"linea/aztec-srs-to-gnark/verify"
+)
+
+const (
+	// readBufferSize is the size of the buffer used to read the transcript.
+	readBufferSize = 4 << 20
+	// g1DecodeBatch is the number of G1 points decoded at once.
+	g1DecodeBatch = 1 << 10
+)
+
+// transcript is a sub-ceremony of the transcript file: its powers of τ, the
+// G1 and G2 points compressed as in ZCash, hex encoded. The witness of the
+// contributions isn't read.
+type transcript struct {
+	NumG1Powers int `json:"numG1Powers"`
+	NumG2Powers int `json:"numG2Powers"`
+	PowersOfTau struct {
+		G1Powers []string `json:"G1Powers"`
+		G2Powers []string `json:"G2Powers"`
+	} `json:"powersOfTau"`
+}
+
+// transcriptFile is what the importer reads of the transcript file.
+type transcriptFile struct {
+	Transcripts    []transcript `json:"transcripts"`
+	ParticipantIDs []string     `json:"participantIds"`
+}
+
+// check checks the numbers of powers the sub-ceremony announces against the
+// ones it holds.
+func (t transcript) check() error {
+	switch {
+	case len(t.PowersOfTau.G1Powers) != t.NumG1Powers:
+		return fmt.Errorf("it announces %d G1 powers but holds %d", t.NumG1Powers, len(t.PowersOfTau.G1Powers))
+	case len(t.PowersOfTau.G2Powers) != t.NumG2Powers:
+		return fmt.Errorf("it announces %d G2 powers but holds %d", t.NumG2Powers, len(t.PowersOfTau.G2Powers))
+	case t.NumG1Powers < 2 || t.NumG2Powers < 2:
+		return fmt.Errorf("it holds %d G1 and %d G2 powers, less than 2", t.NumG1Powers, t.NumG2Powers)
+	}
+
+	return nil
+}
+
+// readTranscriptFile decodes the transcript file.
+func readTranscriptFile(file input.File) (transcriptFile, error) {
+	var content transcriptFile
+
+	f, err := file.Open()
+	if err != nil {
+		return content, fmt.Errorf("failed to open transcript %s: %w", file.Name, err)
+	}
+	defer f.Close()
+
+	if err = json.NewDecoder(bufio.NewReaderSize(f, readBufferSize)).Decode(&content); err != nil {
+		return content, fmt.Errorf("failed to decode transcript %s: %w", file.Name, err)
+	}
+	if len(content.Transcripts) == 0 {
+		return content, fmt.Errorf("transcript %s holds no sub-ceremony", file.Name)
+	}
+	for i, t := range content.Transcripts {
+		if err = t.check(); err != nil {
+			return content, fmt.Errorf("sub-ceremony %d of transcript %s: %w", i, file.Name, err)
+		}
+	}
+
+	return content, nil
+}
+
+// findTranscript returns the transcript file among the files, the only one,
+// e.g. read from the standard input, or the only JSON file, and the other
+// files.
+func findTranscript(files []input.File) (input.File, []input.File, error) {
+	if len(files) == 1 {
+		return files[0], nil, nil
+	}
+
+	var found, others []input.File
+	for _, file := range files {
+		if strings.EqualFold(filepath.Ext(file.Name), ".json") {
+			found = append(found, file)
+		} else {
+			others = append(others, file)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return input.File{}, nil, errors.New("no transcript found: the transcript of the ceremony is a JSON file")
+	case 1:
+		return found[0], others, nil
+	}
+
+	return input.File{}, nil, fmt.Errorf("several transcripts found: %s and %s", found[0].Name, found[1].Name)
+}
+
+// selectTranscript returns the index of the sub-ceremony the SRS is read
+// from: the largest one, or with degree the smallest one holding 2^degree
+// powers.
+func selectTranscript(transcripts []transcript, degree int) (int, error) {
+	order := make([]int, len(transcripts))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return transcripts[a].NumG1Powers - transcripts[b].NumG1Powers })
+
+	largest := order[len(order)-1]
+	if degree == 0 {
+		return largest, nil
+	}
+	i := slices.IndexFunc(order, func(i int) bool { return transcripts[i].NumG1Powers >= 1<<degree })
+	if i < 0 {
+		return 0, fmt.Errorf("the largest sub-ceremony holds %d points, less than 2^%d", transcripts[largest].NumG1Powers, degree)
+	}
+
+	return order[i], nil
+}
+
+// decodePoint decodes the hex encoded point into p with the decoder options.
+func decodePoint(encoded string, p any, options ...func(*bls12381.Decoder)) error {
+	b, err := hex.DecodeString(strings.TrimPrefix(encoded, "0x"))
+	if err != nil {
+		return fmt.Errorf("invalid hex encoding: %w", err)
+	}
+
+	dec := bls12381.NewDecoder(bytes.NewReader(b), options...)
+	if err = dec.Decode(p); err != nil {
+		return err
+	}
+	if dec.BytesRead() != int64(len(b)) {
+		return fmt.Errorf("%d trailing bytes", int64(len(b))-dec.BytesRead())
+	}
+
+	return nil
+}
+
+// readG2Powers decodes the generator and τG2, the first G2 powers of the
+// sub-ceremony, into the verifying key.
+func readG2Powers(t transcript, srs *bls381Kzg.SRS) error {
+	for i := range srs.Vk.G2 {
+		if err := decodePoint(t.PowersOfTau.G2Powers[i], &srs.Vk.G2[i]); err != nil {
+			return fmt.Errorf("invalid G2 power %d: %w", i, err)
+		}
+	}
+	srs.Vk.Lines[0] = bls12381.PrecomputeLines(srs.Vk.G2[0])
+	srs.Vk.Lines[1] = bls12381.PrecomputeLines(srs.Vk.G2[1])
+
+	fmt.Printf("> a^1*G2: %s %s\n", srs.Vk.G2[1].X.String(), srs.Vk.G2[1].Y.String())
+
+	return nil
+}
+
+// TranslateBls12381SRS reads the transcript of the Ethereum KZG ceremony and
+// constructs the KZG SRS of one of its sub-ceremonies, whose τ are
+// independent: the largest one, or with opts.Degree the smallest one holding
+// 2^opts.Degree points, truncated to them. The G1 points are decoded on
+// opts.Workers goroutines without the subgroup checks, which opts.Verify runs
+// with the check of the powers. Under the lenient validation the SRS ends
+// before the first malformed G1 point.
+func TranslateBls12381SRS(files []input.File, opts config.Options) (kzg.SRS, int, error) {
+	file, others, err := findTranscript(files)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, other := range others {
+		opts.Ignore(other.Name, "not a transcript")
+		opts.Audit.Record(audit.Event{Kind: audit.KindSetupFile, File: other.Name, Decision: "ignored: not a transcript"})
+	}
+
+	fmt.Printf("Processing file %s\n", file.Name)
+	content, err := readTranscriptFile(file)
+	if err != nil {
+		return nil, 0, err
+	}
+	selected, err := selectTranscript(content.Transcripts, opts.Degree)
+	if err != nil {
+		return nil, 0, err
+	}
+	t := content.Transcripts[selected]
+	fmt.Printf("> %d sub-ceremonies and %d participants, using sub-ceremony %d of %d G1 and %d G2 powers\n", len(content.Transcripts), len(content.ParticipantIDs), selected, t.NumG1Powers, t.NumG2Powers)
+
+	g1Powers := t.PowersOfTau.G1Powers
+	if opts.Degree > 0 {
+		g1Powers = g1Powers[:1<<opts.Degree]
+	}
+
+	srs := new(bls381Kzg.SRS)
+	if err = readG2Powers(t, srs); err != nil {
+		return nil, 0, fmt.Errorf("failed to read transcript %s: %w", file.Name, err)
+	}
+
+	if srs.Pk.G1, err = offheap.Make[bls12381.G1Affine](len(g1Powers), opts); err != nil {
+		return nil, 0, err
+	}
+	opts.Progress.SetTotal(len(srs.Pk.G1))
+
+	var checker *verify.Bls12381Checker
+	if opts.Verify {
+		if checker, err = verify.NewBls12381Checker(); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	// The first malformed point of every batch, so that the first one of the
+	// SRS is known; -1 while none is. The batches before a failing check are
+	// all decoded.
+	nBatches := (len(g1Powers) + g1DecodeBatch - 1) / g1DecodeBatch
+	malformedFrom := make([]int, nBatches)
+	err = parallel.Run(nBatches, opts.Workers, func(b int) error {
+		from := b * g1DecodeBatch
+		batch := srs.Pk.G1[from:min(from+g1DecodeBatch, len(srs.Pk.G1))]
+		malformedFrom[b] = -1
+		for i := range batch {
+			if err := decodePoint(g1Powers[from+i], &batch[i], bls12381.NoSubgroupChecks()); err != nil {
+				malformedFrom[b] = from + i
+				return nil
+			}
+		}
+		opts.Progress.Add(len(batch))
+		if checker != nil {
+			return checker.Batch(from, batch)
+		}
+		return nil
+	})
+
+	malformed := -1
+	for _, from := range malformedFrom {
+		if from >= 0 {
+			malformed = from
+			break
+		}
+	}
+	if malformed >= 0 {
+		failure := fmt.Errorf("malformed G1 point %d in transcript %s", malformed, file.Name)
+		if rejectErr := opts.Reject(config.Skip{File: file.Name, Offset: audit.Index(malformed), Points: len(srs.Pk.G1) - malformed}, failure); rejectErr != nil {
+			return nil, 0, rejectErr
+		}
+		if malformed < 2 {
+			return nil, 0, fmt.Errorf("no G1 points left in transcript %s", file.Name)
+		}
+		opts.Warn("G1 point %d is malformed: the SRS ends at G1 point %d", malformed, malformed-1)
+		srs.Pk.G1 = srs.Pk.G1[:malformed]
+	}
+	srs.Vk.G1 = srs.Pk.G1[0]
+
+	opts.Audit.Record(audit.Event{Kind: audit.KindSetupFile, File: file.Name, Points: len(srs.Pk.G1), Decision: "used"})
+
+	fmt.Printf("> a^1*G1: %s %s\n", srs.Pk.G1[1].X.String(), srs.Pk.G1[1].Y.String())
+
+	if checker != nil {
+		switch {
+		case malformed >= 0:
+			// The points after the malformed one were checked too, so the
+			// fused checks don't apply
+			err = verify.SRS(srs, opts)
+		case err == nil:
+			err = checker.Finish(srs)
+		}
+		// Under the lenient validation the SRS ends before a point failing
+		if err = verify.Recover(srs, opts, err); err != nil {
+			return nil, 0, fmt.Errorf("%w: %w", verify.ErrFailed, err)
+		}
+		fmt.Println("SRS verified: all G1 points are in the subgroup and are consecutive powers of tau")
+	} else if err != nil {
+		return nil, 0, err
+	}
+
+	return srs, len(srs.Pk.G1), nil
+}
+
+// ExtractTauG2 returns an SRS with only the verifying key, read from the G2
+// powers of the largest sub-ceremony of the transcript.
+func ExtractTauG2(files []input.File) (kzg.SRS, error) {
+	file, _, err := findTranscript(files)
+	if err != nil {
+		return nil, err
+	}
+	content, err := readTranscriptFile(file)
+	if err != nil {
+		return nil, err
+	}
+	selected, err := selectTranscript(content.Transcripts, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	_, _, gen1Aff, _ := bls12381.Generators()
+
+	srs := new(bls381Kzg.SRS)
+	srs.Vk.G1 = gen1Aff
+	if err = readG2Powers(content.Transcripts[selected], srs); err != nil {
+		return nil, fmt.Errorf("failed to read transcript %s: %w", file.Name, err)
+	}
+
+	return srs, nil
+}
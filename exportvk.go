@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	blsKzg "github.com/consensys/gnark-crypto/ecc/bls12-377/kzg"
+	bls381Kzg "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	bwKzg "github.com/consensys/gnark-crypto/ecc/bw6-761/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/srsio"
+)
+
+// vkG1JSON is a G1 point with hex coordinates.
+type vkG1JSON struct {
+	X string `json:"x"`
+	Y string `json:"y"`
+}
+
+// vkG2JSON is a G2 point with the hex components of its coordinates, two for
+// bn254 and bls12-377 whose G2 is over a quadratic extension, one for bw6-761.
+type vkG2JSON struct {
+	X []string `json:"x"`
+	Y []string `json:"y"`
+}
+
+// vkJSON is the verifying key of an SRS for verifiers which can't read the
+// gnark encodings.
+type vkJSON struct {
+	Curve string `json:"curve"`
+	// Points is the number of G1 points of the SRS, its degree plus one.
+	Points int        `json:"points,omitempty"`
+	G1     vkG1JSON   `json:"g1"`
+	G2     []vkG2JSON `json:"g2"`
+	// SRSDigest is the canonical digest of the SRS, see srsio.Digest.
+	SRSDigest string `json:"srs_digest,omitempty"`
+	// CeremonyHash is the hash of the ceremony the SRS comes from, as given.
+	CeremonyHash string `json:"ceremony_hash,omitempty"`
+}
+
+// exportVkJSON prints the verifying key of an SRS file as JSON, with the
+// affine coordinates of its points in hex.
+func exportVkJSON(args []string) error {
+	flags := flag.NewFlagSet("export-vk-json", flag.ExitOnError)
+	out := flags.String("o", "", "file to write the JSON to (default: the standard output)")
+	ceremonyHash := flags.String("ceremony-hash", "", "hash of the ceremony the SRS comes from, e.g. of its last transcript, recorded as given")
+	batchSize := flags.Int("batch-size", srsio.DefaultCompareBatch, "number of points decoded at once to compute the SRS digest")
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s export-vk-json [flags] <SRS file>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	args = parseInterspersed(flags, args)
+
+	if len(args) < 1 {
+		flags.Usage()
+		return errUsage
+	}
+
+	r, err := srsio.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	srs, err := r.Vk()
+	if err != nil {
+		return err
+	}
+
+	vk, err := newVkJSON(srs)
+	if err != nil {
+		return err
+	}
+	vk.Curve = string(curveNames[r.Curve])
+	vk.Points = r.NbPoints
+	vk.CeremonyHash = *ceremonyHash
+
+	digest, err := srsio.Digest(r, *batchSize)
+	if err != nil {
+		return err
+	}
+	vk.SRSDigest = hex.EncodeToString(digest)
+
+	encoded, err := json.MarshalIndent(vk, "", "  ")
+	if err != nil {
+		return err
+	}
+	encoded = append(encoded, '\n')
+
+	if *out == "" {
+		os.Stdout.Write(encoded)
+		return nil
+	}
+
+	if err = os.WriteFile(*out, encoded, 0o644); err != nil {
+		return fmt.Errorf("failed to write verifying key: %w", err)
+	}
+	fmt.Printf("Verifying key written to %s\n", *out)
+
+	return nil
+}
+
+// newVkJSON returns the points of the verifying key of the SRS.
+func newVkJSON(srs kzg.SRS) (vkJSON, error) {
+	var vk vkJSON
+	switch s := srs.(type) {
+	case *bnKzg.SRS:
+		vk.G1 = vkG1JSON{X: hexElement(&s.Vk.G1.X), Y: hexElement(&s.Vk.G1.Y)}
+		for i := range s.Vk.G2 {
+			p := &s.Vk.G2[i]
+			vk.G2 = append(vk.G2, vkG2JSON{X: hexElements(&p.X.A0, &p.X.A1), Y: hexElements(&p.Y.A0, &p.Y.A1)})
+		}
+	case *blsKzg.SRS:
+		vk.G1 = vkG1JSON{X: hexElement(&s.Vk.G1.X), Y: hexElement(&s.Vk.G1.Y)}
+		for i := range s.Vk.G2 {
+			p := &s.Vk.G2[i]
+			vk.G2 = append(vk.G2, vkG2JSON{X: hexElements(&p.X.A0, &p.X.A1), Y: hexElements(&p.Y.A0, &p.Y.A1)})
+		}
+	case *bls381Kzg.SRS:
+		vk.G1 = vkG1JSON{X: hexElement(&s.Vk.G1.X), Y: hexElement(&s.Vk.G1.Y)}
+		for i := range s.Vk.G2 {
+			p := &s.Vk.G2[i]
+			vk.G2 = append(vk.G2, vkG2JSON{X: hexElements(&p.X.A0, &p.X.A1), Y: hexElements(&p.Y.A0, &p.Y.A1)})
+		}
+	case *bwKzg.SRS:
+		vk.G1 = vkG1JSON{X: hexElement(&s.Vk.G1.X), Y: hexElement(&s.Vk.G1.Y)}
+		for i := range s.Vk.G2 {
+			p := &s.Vk.G2[i]
+			vk.G2 = append(vk.G2, vkG2JSON{X: hexElements(&p.X), Y: hexElements(&p.Y)})
+		}
+	default:
+		return vk, fmt.Errorf("unsupported SRS type %T", srs)
+	}
+
+	return vk, nil
+}
+
+func hexElement(e textElement) string {
+	return "0x" + e.Text(16)
+}
+
+func hexElements(components ...textElement) []string {
+	res := make([]string, len(components))
+	for i, c := range components {
+		res[i] = hexElement(c)
+	}
+
+	return res
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377"
+	blsKzg "github.com/consensys/gnark-crypto/ecc/bls12-377/kzg"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381"
+	bls381Kzg "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761"
+	bwKzg "github.com/consensys/gnark-crypto/ecc/bw6-761/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/srsio"
+	"linea/aztec-srs-to-gnark/verify"
+)
+
+// extendedSetups are the protocols whose importers can extend an existing
+// SRS, see config.Options.Extend.
+var extendedSetups = []ProtocolName{AztecProtocol, AleoProtocol, CeloProtocol}
+
+// extendBatchSize is the number of G1 points of the extended SRS copied at
+// once, bounding the memory of the copy.
+const extendBatchSize = 1 << 20
+
+// openExtended opens the existing SRS file at path, of the setup of the
+// protocol on the curve, extended by the conversion. Its G1 points must be
+// the first powers of τ of the ceremony.
+func openExtended(path string, protocol ProtocolName, curve CurveName) (*srsio.Reader, error) {
+	if !slices.Contains(extendedSetups, protocol) {
+		return nil, fmt.Errorf("the %s setup can't be extended, only the %v ones", protocol, extendedSetups)
+	}
+
+	info, skipped, err := srsio.ReadSkipInfo(path)
+	if err != nil {
+		return nil, err
+	}
+	if skipped && !info.Prefix {
+		return nil, fmt.Errorf("%s lacks G1 points of the ceremony before its last one, see its skip info, it can't be extended", path)
+	}
+	slice, sliced, err := srsio.ReadSliceInfo(path)
+	if err != nil {
+		return nil, err
+	}
+	if sliced && slice.From != 0 {
+		return nil, fmt.Errorf("%s is a slice of %s from point %d, it can't be extended", path, slice.Source, slice.From)
+	}
+
+	r, err := srsio.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if curveNames[r.Curve] != curve {
+		r.Close()
+		return nil, fmt.Errorf("%s is a %s SRS, not a %s one", path, r.Curve, curve)
+	}
+	if r.NbPoints < 2 {
+		r.Close()
+		return nil, fmt.Errorf("%s holds less than 2 G1 points", path)
+	}
+
+	return r, nil
+}
+
+// extendSRS copies the G1 points and the verifying key of the extended SRS r
+// into srs, whose first points weren't read from the setup, and checks with a
+// pairing that the first point read continues the powers of τ of r.
+func extendSRS(srs kzg.SRS, r *srsio.Reader) error {
+	n := r.NbPoints
+
+	existing, err := r.Vk()
+	if err != nil {
+		return err
+	}
+
+	var next kzg.SRS
+	switch s := srs.(type) {
+	case *bnKzg.SRS:
+		e := existing.(*bnKzg.SRS)
+		if err = copyExtended(s.Pk.G1, r, func(part kzg.SRS) []bn254.G1Affine { return part.(*bnKzg.SRS).Pk.G1 }); err != nil {
+			return err
+		}
+		// τG2 is only in the setup files holding the first points
+		if !s.Vk.G2[1].IsInfinity() && !s.Vk.G2[1].Equal(&e.Vk.G2[1]) {
+			return errTauMismatch(r)
+		}
+		s.Vk = e.Vk
+		next = &bnKzg.SRS{Vk: e.Vk, Pk: bnKzg.ProvingKey{G1: s.Pk.G1[n:]}}
+	case *blsKzg.SRS:
+		e := existing.(*blsKzg.SRS)
+		if err = copyExtended(s.Pk.G1, r, func(part kzg.SRS) []bls12377.G1Affine { return part.(*blsKzg.SRS).Pk.G1 }); err != nil {
+			return err
+		}
+		if !s.Vk.G2[1].IsInfinity() && !s.Vk.G2[1].Equal(&e.Vk.G2[1]) {
+			return errTauMismatch(r)
+		}
+		s.Vk = e.Vk
+		next = &blsKzg.SRS{Vk: e.Vk, Pk: blsKzg.ProvingKey{G1: s.Pk.G1[n:]}}
+	case *bls381Kzg.SRS:
+		e := existing.(*bls381Kzg.SRS)
+		if err = copyExtended(s.Pk.G1, r, func(part kzg.SRS) []bls12381.G1Affine { return part.(*bls381Kzg.SRS).Pk.G1 }); err != nil {
+			return err
+		}
+		if !s.Vk.G2[1].IsInfinity() && !s.Vk.G2[1].Equal(&e.Vk.G2[1]) {
+			return errTauMismatch(r)
+		}
+		s.Vk = e.Vk
+		next = &bls381Kzg.SRS{Vk: e.Vk, Pk: bls381Kzg.ProvingKey{G1: s.Pk.G1[n:]}}
+	case *bwKzg.SRS:
+		e := existing.(*bwKzg.SRS)
+		if err = copyExtended(s.Pk.G1, r, func(part kzg.SRS) []bw6761.G1Affine { return part.(*bwKzg.SRS).Pk.G1 }); err != nil {
+			return err
+		}
+		if !s.Vk.G2[1].IsInfinity() && !s.Vk.G2[1].Equal(&e.Vk.G2[1]) {
+			return errTauMismatch(r)
+		}
+		s.Vk = e.Vk
+		next = &bwKzg.SRS{Vk: e.Vk, Pk: bwKzg.ProvingKey{G1: s.Pk.G1[n:]}}
+	default:
+		return fmt.Errorf("unsupported SRS type %T", srs)
+	}
+
+	last, err := r.Range(n-1, n)
+	if err != nil {
+		return err
+	}
+	if err = verify.Adjacent(last, next); err != nil {
+		return fmt.Errorf("G1 point %d of the setup doesn't continue the extended SRS: %w", n, err)
+	}
+	fmt.Printf("The G1 points of the setup from %d continue the %d points of the extended SRS\n", n, n)
+
+	return nil
+}
+
+// copyExtended copies the G1 points of the extended SRS r into the first
+// points of dst, which must hold more.
+func copyExtended[P any](dst []P, r *srsio.Reader, points func(kzg.SRS) []P) error {
+	n := r.NbPoints
+	if len(dst) <= n {
+		return fmt.Errorf("the setup files hold no G1 points after the %d of the extended SRS", n)
+	}
+
+	for from := 0; from < n; from += extendBatchSize {
+		part, err := r.Range(from, min(from+extendBatchSize, n))
+		if err != nil {
+			return err
+		}
+		copy(dst[from:], points(part))
+	}
+
+	return nil
+}
+
+// errTauMismatch reports a setup whose τG2 isn't the one of the extended SRS.
+func errTauMismatch(r *srsio.Reader) error {
+	return fmt.Errorf("τG2 of the setup differs from the one of the extended %s SRS, they aren't of the same ceremony", r.Curve)
+}
+
+// extendWith extends the extended SRS r with srs, and verifies the points
+// appended when opts asks to. It returns the number of G1 points of srs, which
+// the lenient validation may have dropped the last ones of.
+func extendWith(srs kzg.SRS, r *srsio.Reader, opts config.Options) (int, error) {
+	if err := extendSRS(srs, r); err != nil {
+		return 0, err
+	}
+
+	if opts.Verify {
+		// The last point of r verifies the seam with the first one appended
+		if err := verify.Recover(srs, opts, verify.SRSFrom(srs, r.NbPoints-1, opts)); err != nil {
+			return 0, fmt.Errorf("%w: %w", verify.ErrFailed, err)
+		}
+		fmt.Printf("SRS verified: the G1 points from %d are in the subgroup and are consecutive powers of tau\n", r.NbPoints-1)
+	}
+
+	n, err := g1Len(srs)
+	if err != nil {
+		return 0, err
+	}
+	if n <= r.NbPoints {
+		return 0, fmt.Errorf("no G1 points left after the %d of the extended SRS", r.NbPoints)
+	}
+
+	return n, nil
+}
+
+// g1Len returns the number of G1 points of the SRS.
+func g1Len(srs kzg.SRS) (int, error) {
+	switch s := srs.(type) {
+	case *bnKzg.SRS:
+		return len(s.Pk.G1), nil
+	case *blsKzg.SRS:
+		return len(s.Pk.G1), nil
+	case *bls381Kzg.SRS:
+		return len(s.Pk.G1), nil
+	case *bwKzg.SRS:
+		return len(s.Pk.G1), nil
+	}
+
+	return 0, fmt.Errorf("unsupported SRS type %T", srs)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"linea/aztec-srs-to-gnark/input"
+)
+
+// extractG2 prints τG2 of a setup, seeking directly to the G2 section of its
+// files: the first Aztec transcript, the Aleo G2 file or the Celo chunk 0.
+func extractG2(args []string) error {
+	flags := flag.NewFlagSet("extract-g2", flag.ExitOnError)
+	out := flags.String("o", "", "file to also write the verifying key to, as JSON with hex coordinates")
+	profile := flags.String("profile", "", profileUsage)
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s extract-g2 [flags] <protocol> <setup files directory>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	args = parseInterspersed(flags, args)
+	if err := applyProfile(*profile); err != nil {
+		return err
+	}
+
+	if len(args) < 2 {
+		flags.Usage()
+		return errUsage
+	}
+
+	setup, ok := supportedG2Extractions[ProtocolName(args[0])]
+	if !ok {
+		protocols := make([]string, 0, len(supportedG2Extractions))
+		for protocol := range supportedG2Extractions {
+			protocols = append(protocols, string(protocol))
+		}
+		return fmt.Errorf("unsupported protocol, use one of: %s", strings.Join(protocols, ", "))
+	}
+
+	files, err := input.Dir(args[1])
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	srs, err := setup.extract(files)
+	if err != nil {
+		return err
+	}
+
+	vk, err := newVkJSON(srs)
+	if err != nil {
+		return err
+	}
+	vk.Curve = string(setup.curve)
+
+	fmt.Printf("τG2 of the %s %s setup, read in %v:\n", args[0], setup.curve, time.Since(start).Round(time.Millisecond))
+	fmt.Printf("> X: %s\n", strings.Join(vk.G2[1].X, ", "))
+	fmt.Printf("> Y: %s\n", strings.Join(vk.G2[1].Y, ", "))
+
+	if *out == "" {
+		return nil
+	}
+
+	encoded, err := json.MarshalIndent(vk, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(*out, append(encoded, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write verifying key: %w", err)
+	}
+	fmt.Printf("Verifying key written to %s\n", *out)
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// bucket is a token bucket shared by all the transfers, holding up to a
+// second of transfer.
+type bucket struct {
+	rate float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(rate int64) *bucket {
+	return &bucket{rate: float64(rate), tokens: float64(rate), last: time.Now()}
+}
+
+// burst is the largest read that is let through at once.
+func (b *bucket) burst() int {
+	return max(int(b.rate), 1)
+}
+
+// take removes n tokens from the bucket and waits until the bucket is no
+// longer in debt. Concurrent transfers queue behind each other's debt.
+func (b *bucket) take(ctx context.Context, n int) error {
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens = min(b.rate, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens -= float64(n)
+	wait := time.Duration(-b.tokens / b.rate * float64(time.Second))
+	b.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// limitedBody is a response body read at the rate of the bucket.
+type limitedBody struct {
+	io.ReadCloser
+	ctx    context.Context
+	bucket *bucket
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if len(p) > l.bucket.burst() {
+		p = p[:l.bucket.burst()]
+	}
+
+	n, err := l.ReadCloser.Read(p)
+	if n > 0 {
+		if waitErr := l.bucket.take(l.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+
+	return n, err
+}
+
+// ParseRate parses a bandwidth in bytes per second, with an optional K, M or G
+// suffix for KiB/s, MiB/s and GiB/s, e.g. "512K" or "2.5M".
+func ParseRate(s string) (int64, error) {
+	number := strings.TrimSpace(s)
+	unit := 1.0
+	if number != "" {
+		switch strings.ToUpper(number[len(number)-1:]) {
+		case "K":
+			unit = 1 << 10
+		case "M":
+			unit = 1 << 20
+		case "G":
+			unit = 1 << 30
+		}
+		if unit != 1 {
+			number = number[:len(number)-1]
+		}
+	}
+
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid bandwidth '%s', expected bytes per second with an optional K, M or G suffix", s)
+	}
+
+	return int64(value * unit), nil
+}
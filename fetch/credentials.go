@@ -0,0 +1,280 @@
+package fetch
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Credentials authorize the requests to a source.
+type Credentials interface {
+	Authorize(req *http.Request) error
+}
+
+// registration associates credentials with the URLs they are used for.
+type registration struct {
+	match func(u *url.URL) bool
+	creds Credentials
+}
+
+var (
+	registryMu sync.Mutex
+	registry   []registration
+)
+
+// RegisterCredentials uses the credentials for the requests to the URLs
+// matched by match. The credentials registered last take precedence.
+func RegisterCredentials(match func(u *url.URL) bool, creds Credentials) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	registry = append(registry, registration{match, creds})
+}
+
+func credentialsFor(u *url.URL) Credentials {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for i := len(registry) - 1; i >= 0; i-- {
+		if registry[i].match(u) {
+			return registry[i].creds
+		}
+	}
+
+	return nil
+}
+
+// RegisterEnvCredentials registers the credentials of the cloud storages
+// given by the environment variables of their own tools, if any: the S3
+// requests are signed with AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// AWS_SESSION_TOKEN, the Google Cloud Storage ones carry
+// GOOGLE_OAUTH_ACCESS_TOKEN and the Azure Blob Storage ones the
+// AZURE_STORAGE_SAS_TOKEN shared access signature.
+func RegisterEnvCredentials() {
+	if key, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); key != "" && secret != "" {
+		endpoint := os.Getenv("AWS_ENDPOINT_URL")
+		RegisterCredentials(func(u *url.URL) bool { return isS3Host(u.Host, endpoint) }, &S3Credentials{
+			AccessKeyID:     key,
+			SecretAccessKey: secret,
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+			Region:          firstNonEmpty(os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION")),
+		})
+	}
+
+	if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+		RegisterCredentials(func(u *url.URL) bool { return u.Host == "storage.googleapis.com" }, BearerToken(token))
+	}
+
+	if sas := os.Getenv("AZURE_STORAGE_SAS_TOKEN"); sas != "" {
+		RegisterCredentials(func(u *url.URL) bool { return isAzureHost(u.Host) }, SASToken(sas))
+	}
+}
+
+func isS3Host(host, endpoint string) bool {
+	if endpoint != "" {
+		if u, err := url.Parse(endpoint); err == nil && u.Host == host {
+			return true
+		}
+	}
+
+	return strings.HasSuffix(host, ".amazonaws.com") && (strings.HasPrefix(host, "s3") || strings.Contains(host, ".s3"))
+}
+
+func isAzureHost(host string) bool {
+	return strings.HasSuffix(host, ".blob.core.windows.net")
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
+
+// BearerToken authorizes requests with an OAuth 2.0 access token, as used by
+// Google Cloud Storage.
+type BearerToken string
+
+func (t BearerToken) Authorize(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+string(t))
+	return nil
+}
+
+// SASToken authorizes requests with an Azure shared access signature.
+type SASToken string
+
+func (t SASToken) Authorize(req *http.Request) error {
+	sas := strings.TrimPrefix(string(t), "?")
+	if req.URL.RawQuery == "" {
+		req.URL.RawQuery = sas
+	} else {
+		req.URL.RawQuery += "&" + sas
+	}
+
+	return nil
+}
+
+// S3Credentials sign requests with AWS Signature Version 4.
+type S3Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Region of the bucket, taken from the host name or us-east-1 when empty.
+	Region string
+}
+
+// unsignedPayload is the payload hash of the requests whose body isn't signed.
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// Authorize signs the request. The payload isn't signed unless its hash is
+// already set in the X-Amz-Content-Sha256 header, so requests with a body can
+// be signed before it's streamed.
+func (c *S3Credentials) Authorize(req *http.Request) error {
+	if c.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.SessionToken)
+	}
+	payloadHash := req.Header.Get("X-Amz-Content-Sha256")
+	if payloadHash == "" {
+		payloadHash = unsignedPayload
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+	c.sign(req, time.Now(), "s3", payloadHash)
+
+	return nil
+}
+
+// sign signs the request at t for the service with AWS Signature Version 4,
+// covering the host and all the headers of the request, and the payload by its
+// hash.
+func (c *S3Credentials) sign(req *http.Request, t time.Time, service, payloadHash string) {
+	t = t.UTC()
+	amzDate := t.Format("20060102T150405Z")
+	scope := fmt.Sprintf("%s/%s/%s/aws4_request", t.Format("20060102"), c.region(req.URL.Host), service)
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	values := map[string]string{"host": host}
+	for name, vs := range req.Header {
+		// Sequential spaces are folded, multiple values joined by commas
+		folded := make([]string, len(vs))
+		for i, v := range vs {
+			folded[i] = strings.Join(strings.Fields(v), " ")
+		}
+		values[strings.ToLower(name)] = strings.Join(folded, ",")
+	}
+	signed := make([]string, 0, len(values))
+	for name := range values {
+		signed = append(signed, name)
+	}
+	sort.Strings(signed)
+
+	var headers strings.Builder
+	for _, name := range signed {
+		fmt.Fprintf(&headers, "%s:%s\n", name, values[name])
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI(req.URL.Path),
+		canonicalQuery(req.URL.Query()),
+		headers.String(),
+		strings.Join(signed, ";"),
+		payloadHash,
+	}, "\n")
+
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hex.EncodeToString(requestHash[:])}, "\n")
+
+	key := []byte("AWS4" + c.SecretAccessKey)
+	for _, part := range strings.Split(scope, "/") {
+		key = hmacSHA256(key, part)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.AccessKeyID, scope, strings.Join(signed, ";"), hex.EncodeToString(hmacSHA256(key, stringToSign))))
+}
+
+// region returns the configured region, or the one in hosts like
+// bucket.s3.eu-west-2.amazonaws.com.
+func (c *S3Credentials) region(host string) string {
+	if c.Region != "" {
+		return c.Region
+	}
+
+	parts := strings.Split(strings.TrimSuffix(host, ".amazonaws.com"), ".")
+	for i, part := range parts {
+		if part == "s3" && i+1 < len(parts) {
+			return parts[i+1]
+		}
+		if region, ok := strings.CutPrefix(part, "s3-"); ok {
+			return region
+		}
+	}
+
+	return "us-east-1"
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// canonicalURI encodes every segment of the path with RFC 3986 escaping, as S3
+// does, without normalizing it.
+func canonicalURI(path string) string {
+	if path == "" {
+		return "/"
+	}
+
+	segments := strings.Split(path, "/")
+	for i := range segments {
+		segments[i] = uriEscape(segments[i])
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// canonicalQuery encodes the query sorted by key then value, with RFC 3986
+// escaping.
+func canonicalQuery(values url.Values) string {
+	type pair struct{ key, value string }
+	var pairs []pair
+	for key, vs := range values {
+		for _, v := range vs {
+			pairs = append(pairs, pair{uriEscape(key), uriEscape(v)})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].key != pairs[j].key {
+			return pairs[i].key < pairs[j].key
+		}
+		return pairs[i].value < pairs[j].value
+	})
+
+	encoded := make([]string, len(pairs))
+	for i, p := range pairs {
+		encoded[i] = p.key + "=" + p.value
+	}
+
+	return strings.Join(encoded, "&")
+}
+
+// uriEscape escapes all the characters but the unreserved ones of RFC 3986.
+func uriEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
@@ -0,0 +1,76 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"linea/aztec-srs-to-gnark/parallel"
+)
+
+// Download is a setup file of a ceremony to download.
+type Download struct {
+	URL string
+	// Name of the downloaded file, the last element of the URL path when empty.
+	Name string
+	// Mirrors are other URLs of the same file, cross-compared with URL, see Mirrored.
+	Mirrors []string
+	// Verify checks the integrity of the downloaded file, skipped when nil.
+	Verify func(path string) error
+}
+
+// All downloads the files into dir, at most opts.Parallelism at a time. Files that
+// are already present and pass the verification aren't downloaded again, and
+// files failing the verification are removed so that the next run fetches
+// them again.
+func All(ctx context.Context, downloads []Download, dir string, opts Options) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create download directory: %w", err)
+	}
+
+	return parallel.Run(len(downloads), opts.Parallelism, func(i int) error {
+		d := downloads[i]
+
+		name := d.Name
+		if name == "" {
+			var err error
+			if name, err = FileName(d.URL); err != nil {
+				return err
+			}
+		}
+		dest := filepath.Join(dir, name)
+
+		if _, err := os.Stat(dest); err == nil {
+			if err = verify(d, dest); err == nil {
+				fmt.Printf("Already downloaded %s\n", dest)
+				return nil
+			}
+			fmt.Printf("Downloading %s again: %v\n", dest, err)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to check %s: %w", dest, err)
+		}
+
+		fmt.Printf("Downloading %s\n", d.URL)
+		if err := Mirrored(ctx, append([]string{d.URL}, d.Mirrors...), dest, opts); err != nil {
+			return fmt.Errorf("failed to download %s: %w", d.URL, err)
+		}
+
+		if err := verify(d, dest); err != nil {
+			os.Remove(dest)
+			return fmt.Errorf("downloaded file %s is corrupted: %w", dest, err)
+		}
+		fmt.Printf("Downloaded %s\n", dest)
+
+		return nil
+	})
+}
+
+func verify(d Download, path string) error {
+	if d.Verify == nil {
+		return nil
+	}
+
+	return d.Verify(path)
+}
@@ -0,0 +1,11 @@
+package fetch
+
+import (
+	"net/http"
+	"time"
+)
+
+// Sign exports sign to the tests, which sign at the dates of the test vectors.
+func (c *S3Credentials) Sign(req *http.Request, t time.Time, service, payloadHash string) {
+	c.sign(req, t, service, payloadHash)
+}
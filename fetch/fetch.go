@@ -0,0 +1,326 @@
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/parallel"
+)
+
+const (
+	// resumeOverlap is the number of already downloaded bytes downloaded again
+	// and compared when resuming, to make sure the partial file is intact.
+	resumeOverlap = 1 << 20
+	// maxBackoff bounds the delay between retries.
+	maxBackoff = 5 * time.Minute
+)
+
+// Options of the downloads.
+type Options struct {
+	// Parallelism is the number of files downloaded at the same time.
+	Parallelism int
+	// Retries is the number of times a failed download is retried.
+	Retries int
+	// Backoff is the delay before the first retry, doubled on every next one.
+	Backoff time.Duration
+	// Quorum is the number of mirrors that must serve identical data for a
+	// file available from several mirrors.
+	Quorum int
+	// PartSize is the size of the parts large files are uploaded in,
+	// DefaultPartSize when zero.
+	PartSize int64
+}
+
+// download is the state of a single file being fetched.
+type download struct {
+	urls []string
+	dest string
+	done chan struct{}
+	err  error
+}
+
+// Files starts downloading the sources into dir, at most opts.Parallelism at
+// a time and in the given order, and returns them as setup files. Every source
+// lists the URLs of the mirrors of the file, see Mirrored. Opening a file
+// blocks until its download finishes, so the conversion can start on the
+// first files while the later ones are still being fetched.
+func Files(ctx context.Context, sources [][]string, dir string, opts Options) ([]input.File, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create download directory: %w", err)
+	}
+
+	files := make([]input.File, len(sources))
+	downloads := make([]*download, len(sources))
+	for i, urls := range sources {
+		name, err := FileName(urls[0])
+		if err != nil {
+			return nil, err
+		}
+
+		d := &download{
+			urls: urls,
+			dest: filepath.Join(dir, name),
+			done: make(chan struct{}),
+		}
+		downloads[i] = d
+
+		files[i] = input.New(name, contentLength(ctx, urls[0]), func() (io.ReadCloser, error) {
+			select {
+			case <-d.done:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+
+			if d.err != nil {
+				return nil, d.err
+			}
+
+			return os.Open(d.dest)
+		})
+	}
+
+	go parallel.Run(len(downloads), opts.Parallelism, func(i int) error {
+		d := downloads[i]
+		defer close(d.done)
+
+		fmt.Printf("Downloading %s\n", d.urls[0])
+		if d.err = Mirrored(ctx, d.urls, d.dest, opts); d.err != nil {
+			d.err = fmt.Errorf("failed to download %s: %w", d.urls[0], d.err)
+			return d.err
+		}
+		fmt.Printf("Downloaded %s\n", d.dest)
+
+		return nil
+	})
+
+	return files, nil
+}
+
+// File downloads rawURL into dest, retrying failed transfers as set by opts.
+// The data is written to a ".part" file which is renamed to dest once the
+// download completes; an interrupted download is resumed from the ".part"
+// file, by this or by a later call.
+func File(ctx context.Context, rawURL, dest string, opts Options) error {
+	return retry(ctx, "Download of "+rawURL, opts, func() error {
+		return resume(ctx, rawURL, dest)
+	})
+}
+
+// retry calls fn until it succeeds, it fails with a permanent error or the
+// retries set by opts are exhausted. The transfer names what fn does in the
+// messages of the retries, e.g. "Download of <URL>".
+func retry(ctx context.Context, transfer string, opts Options, fn func() error) error {
+	backoff := max(opts.Backoff, time.Millisecond)
+
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+
+		var status statusError
+		if attempt >= opts.Retries || ctx.Err() != nil || errors.Is(err, errRemoteChanged) ||
+			(errors.As(err, &status) && status.permanent()) {
+			return err
+		}
+
+		delay := min(backoff<<attempt, maxBackoff)
+		fmt.Printf("%s failed: %v, retrying in %s\n", transfer, err, delay)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// resume continues downloading rawURL into the ".part" file of dest. The part
+// is only kept if the remote file is unchanged since the part was started and
+// the last resumeOverlap bytes of the part match the remote data.
+func resume(ctx context.Context, rawURL, dest string) error {
+	partPath := dest + ".part"
+	validatorPath := partPath + ".validator"
+
+	part, err := os.OpenFile(partPath, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	defer part.Close()
+
+	info, err := part.Stat()
+	if err != nil {
+		return err
+	}
+
+	offset := info.Size()
+	validator, err := os.ReadFile(validatorPath)
+	if err != nil || len(validator) == 0 {
+		// Without a validator there is no way to know the part is still current.
+		offset = 0
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+
+	start := max(offset-resumeOverlap, 0)
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", start))
+		req.Header.Set("If-Range", string(validator))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case offset > 0 && resp.StatusCode == http.StatusPartialContent:
+		if err = checkOverlap(resp, part, start, offset); err != nil {
+			// Start over on the next attempt.
+			os.Remove(validatorPath)
+			return err
+		}
+		fmt.Printf("Resuming %s from %d bytes\n", rawURL, offset)
+	case resp.StatusCode == http.StatusOK:
+		// A new download, or the remote file changed and is sent in full.
+		offset = 0
+		if err = os.WriteFile(validatorPath, []byte(responseValidator(resp)), 0o644); err != nil {
+			return err
+		}
+	default:
+		os.Remove(validatorPath)
+		return statusError{resp.StatusCode, resp.Status}
+	}
+
+	if err = part.Truncate(offset); err != nil {
+		return err
+	}
+	if _, err = part.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(part, resp.Body); err != nil {
+		return err
+	}
+
+	if err = part.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Rename(partPath, dest); err != nil {
+		return err
+	}
+	os.Remove(validatorPath)
+
+	return nil
+}
+
+// checkOverlap compares the beginning of the partial content, starting at
+// start, with the part already downloaded up to offset.
+func checkOverlap(resp *http.Response, part *os.File, start, offset int64) error {
+	var from int64
+	if _, err := fmt.Sscanf(resp.Header.Get("Content-Range"), "bytes %d-", &from); err != nil || from != start {
+		return fmt.Errorf("unexpected content range '%s'", resp.Header.Get("Content-Range"))
+	}
+
+	remote := make([]byte, offset-start)
+	if _, err := io.ReadFull(resp.Body, remote); err != nil {
+		return fmt.Errorf("failed to read overlap: %w", err)
+	}
+
+	local := make([]byte, offset-start)
+	if _, err := part.ReadAt(local, start); err != nil {
+		return fmt.Errorf("failed to read partial file: %w", err)
+	}
+
+	if !bytes.Equal(local, remote) {
+		return errors.New("partial file doesn't match the remote file")
+	}
+
+	return nil
+}
+
+// responseValidator returns the value identifying the version of the remote
+// file, used in If-Range to resume only an unchanged file.
+func responseValidator(resp *http.Response) string {
+	// Weak ETags aren't allowed in If-Range.
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+
+	return resp.Header.Get("Last-Modified")
+}
+
+// errRemoteChanged is returned when a file changes while it's being transferred.
+var errRemoteChanged = errors.New("remote file changed during the transfer")
+
+// statusError is an unexpected HTTP response status.
+type statusError struct {
+	code   int
+	status string
+}
+
+func (e statusError) Error() string {
+	return "unexpected status: " + e.status
+}
+
+// permanent reports whether retrying the request is pointless.
+func (e statusError) permanent() bool {
+	return e.code >= 400 && e.code < 500 && e.code != http.StatusRequestTimeout && e.code != http.StatusTooManyRequests
+}
+
+// FileName returns the name of the file the URL points to.
+func FileName(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL '%s': %w", rawURL, err)
+	}
+
+	name := path.Base(u.Path)
+	if name == "." || name == "/" {
+		return "", fmt.Errorf("URL '%s' doesn't point to a file", rawURL)
+	}
+	// The name is joined to the download directory, where it must be a plain
+	// file name, which on Windows also excludes the \ separator.
+	if name != filepath.Base(name) || !filepath.IsLocal(name) {
+		return "", fmt.Errorf("URL '%s' points to '%s', which isn't a valid file name", rawURL, name)
+	}
+
+	return name, nil
+}
+
+// contentLength asks the server for the size of the file, returning
+// input.UnknownSize if it isn't reported.
+func contentLength(ctx context.Context, rawURL string) int64 {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, rawURL, nil)
+	if err != nil {
+		return input.UnknownSize
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return input.UnknownSize
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || resp.ContentLength < 0 {
+		return input.UnknownSize
+	}
+
+	return resp.ContentLength
+}
@@ -0,0 +1,110 @@
+package fetch
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/parallel"
+)
+
+// mirrorChunkSize is the size of the chunks compared between mirrors.
+const mirrorChunkSize = 8 << 20
+
+// Mirrored downloads the file served by all the mirrors into dest. The file is
+// split into chunks spread over the mirrors, and a chunk is only accepted once
+// opts.Quorum mirrors served identical data, so a single corrupted or
+// compromised mirror can't alter the file. Mirrors are asked in turn until
+// the quorum is reached.
+func Mirrored(ctx context.Context, mirrors []string, dest string, opts Options) error {
+	if len(mirrors) == 1 {
+		return File(ctx, mirrors[0], dest, opts)
+	}
+
+	quorum := min(max(opts.Quorum, 1), len(mirrors))
+
+	size := int64(input.UnknownSize)
+	for _, mirror := range mirrors {
+		n := contentLength(ctx, mirror)
+		if n == input.UnknownSize {
+			continue
+		}
+		if size != input.UnknownSize && n != size {
+			return fmt.Errorf("mirrors disagree on the size of the file: %d and %d bytes (%s)", size, n, mirror)
+		}
+		size = n
+	}
+	if size == input.UnknownSize {
+		return errors.New("none of the mirrors reports the size of the file")
+	}
+
+	partPath := dest + ".part"
+	part, err := os.Create(partPath)
+	if err != nil {
+		return err
+	}
+	defer part.Close()
+
+	if err = part.Truncate(size); err != nil {
+		return err
+	}
+
+	nChunks := int((size + mirrorChunkSize - 1) / mirrorChunkSize)
+	err = parallel.Run(nChunks, len(mirrors), func(i int) error {
+		from := int64(i) * mirrorChunkSize
+		data, err := mirroredChunk(ctx, mirrors, i, from, min(mirrorChunkSize, size-from), quorum, opts)
+		if err != nil {
+			return err
+		}
+
+		_, err = part.WriteAt(data, from)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = part.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(partPath, dest)
+}
+
+// mirroredChunk downloads the chunk from the mirrors, starting with a
+// different one for every chunk, until quorum of them agree on its content.
+func mirroredChunk(ctx context.Context, mirrors []string, chunk int, from, length int64, quorum int, opts Options) ([]byte, error) {
+	votes := make(map[[sha256.Size]byte][]string)
+
+	var failures []string
+	for i := range mirrors {
+		mirror := mirrors[(chunk+i)%len(mirrors)]
+
+		data := make([]byte, length)
+		if err := Range(ctx, mirror, from, data, opts); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", mirror, err))
+			continue
+		}
+
+		sum := sha256.Sum256(data)
+		votes[sum] = append(votes[sum], mirror)
+
+		if len(votes[sum]) >= quorum {
+			if len(votes) > 1 {
+				fmt.Printf("WARNING: mirrors disagree on bytes %d-%d, using the data of %s\n",
+					from, from+length-1, strings.Join(votes[sum], ", "))
+			}
+			return data, nil
+		}
+	}
+
+	if len(votes) > 1 {
+		return nil, fmt.Errorf("mirrors disagree on bytes %d-%d and no %d of them agree", from, from+length-1, quorum)
+	}
+
+	return nil, fmt.Errorf("failed to get bytes %d-%d from %d mirrors: %s", from, from+length-1, quorum, strings.Join(failures, "; "))
+}
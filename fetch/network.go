@@ -0,0 +1,102 @@
+package fetch
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+)
+
+// client performs all the requests of the package.
+var client = &http.Client{Transport: &authTransport{base: http.DefaultTransport}}
+
+// Network configures the connections to the sources.
+type Network struct {
+	// Proxy is the URL of the HTTP(S) proxy, the HTTP_PROXY, HTTPS_PROXY and
+	// NO_PROXY environment variables are used when empty.
+	Proxy string
+	// CACert is a PEM file with additional certificate authorities to trust,
+	// e.g. the one of a TLS inspecting corporate proxy.
+	CACert string
+	// BandwidthLimit caps the total download rate in bytes per second, shared
+	// by all the transfers. Zero means unlimited.
+	BandwidthLimit int64
+	// CloudCredentials authorizes the requests to the cloud storages with the
+	// credentials of the environment, see RegisterEnvCredentials.
+	CloudCredentials bool
+}
+
+// envCredentials registers the credentials of the environment once.
+var envCredentials sync.Once
+
+// Setup applies the network configuration to all the following requests.
+func Setup(n Network) error {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if n.Proxy != "" {
+		proxy, err := url.Parse(n.Proxy)
+		if err != nil {
+			return fmt.Errorf("invalid proxy URL '%s': %w", n.Proxy, err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if n.CACert != "" {
+		pem, err := os.ReadFile(n.CACert)
+		if err != nil {
+			return fmt.Errorf("failed to read CA certificates: %w", err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in %s", n.CACert)
+		}
+
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	var limit *bucket
+	if n.BandwidthLimit > 0 {
+		limit = newBucket(n.BandwidthLimit)
+	}
+
+	client = &http.Client{Transport: &authTransport{base: transport, limit: limit}}
+
+	if n.CloudCredentials {
+		envCredentials.Do(RegisterEnvCredentials)
+	}
+
+	return nil
+}
+
+// authTransport authorizes the requests with the credentials registered for
+// their URL and limits the rate the responses are read at.
+type authTransport struct {
+	base  http.RoundTripper
+	limit *bucket
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if creds := credentialsFor(req.URL); creds != nil {
+		// A RoundTripper must not modify the request.
+		req = req.Clone(req.Context())
+		if err := creds.Authorize(req); err != nil {
+			return nil, fmt.Errorf("failed to authorize request to %s: %w", req.URL.Host, err)
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || t.limit == nil {
+		return resp, err
+	}
+
+	resp.Body = &limitedBody{ReadCloser: resp.Body, ctx: req.Context(), bucket: t.limit}
+
+	return resp, nil
+}
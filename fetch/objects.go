@@ -0,0 +1,198 @@
+package fetch
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+)
+
+// IsObjectLocation reports whether location is an s3:// or gs:// location.
+func IsObjectLocation(location string) bool {
+	return strings.HasPrefix(location, "s3://") || strings.HasPrefix(location, "gs://")
+}
+
+// ObjectURL returns the HTTPS URL of an s3://bucket/key or gs://bucket/key
+// object, other locations are returned unchanged. S3 objects are addressed in
+// the region of AWS_REGION, or on the AWS_ENDPOINT_URL of S3 compatible storages.
+func ObjectURL(location string) string {
+	bucket, key, ok := splitObjectLocation(location)
+	if !ok {
+		return location
+	}
+
+	return bucketURL(location[:2], bucket) + escapeKey(key)
+}
+
+// ListObjects returns the HTTPS URLs of the objects directly under the prefix
+// of an s3:// or gs:// location, sorted by key.
+func ListObjects(ctx context.Context, location string) ([]string, error) {
+	bucket, prefix, ok := splitObjectLocation(location)
+	if !ok {
+		return nil, fmt.Errorf("invalid object storage location '%s'", location)
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	var (
+		keys []string
+		err  error
+	)
+	if strings.HasPrefix(location, "s3://") {
+		keys, err = listS3(ctx, bucket, prefix)
+	} else {
+		keys, err = listGCS(ctx, bucket, prefix)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", location, err)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no objects found in %s", location)
+	}
+
+	sort.Strings(keys)
+
+	base := bucketURL(location[:2], bucket)
+	urls := make([]string, len(keys))
+	for i, key := range keys {
+		urls[i] = base + escapeKey(key)
+	}
+
+	return urls, nil
+}
+
+func splitObjectLocation(location string) (bucket, key string, ok bool) {
+	if !IsObjectLocation(location) {
+		return "", "", false
+	}
+
+	bucket, key, _ = strings.Cut(location[len("s3://"):], "/")
+
+	return bucket, key, bucket != ""
+}
+
+// bucketURL returns the base URL of the objects of the bucket, ending with a slash.
+func bucketURL(scheme, bucket string) string {
+	if scheme == "gs" {
+		return "https://storage.googleapis.com/" + bucket + "/"
+	}
+
+	if endpoint := os.Getenv("AWS_ENDPOINT_URL"); endpoint != "" {
+		// S3 compatible storages are addressed by path.
+		return strings.TrimSuffix(endpoint, "/") + "/" + bucket + "/"
+	}
+
+	if region := firstNonEmpty(os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION")); region != "" {
+		return "https://" + bucket + ".s3." + region + ".amazonaws.com/"
+	}
+
+	return "https://" + bucket + ".s3.amazonaws.com/"
+}
+
+// escapeKey escapes the segments of an object key.
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i := range segments {
+		segments[i] = url.PathEscape(segments[i])
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// listS3 lists the keys under the prefix with the ListObjectsV2 API.
+func listS3(ctx context.Context, bucket, prefix string) ([]string, error) {
+	var keys []string
+
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}, "delimiter": {"/"}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		var page struct {
+			Contents []struct {
+				Key string
+			}
+			IsTruncated           bool
+			NextContinuationToken string
+		}
+		if err := getXML(ctx, bucketURL("s3", bucket)+"?"+query.Encode(), &page); err != nil {
+			return nil, err
+		}
+
+		for _, object := range page.Contents {
+			if !strings.HasSuffix(object.Key, "/") {
+				keys = append(keys, object.Key)
+			}
+		}
+
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return keys, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// listGCS lists the keys under the prefix with the JSON API of Cloud Storage.
+func listGCS(ctx context.Context, bucket, prefix string) ([]string, error) {
+	var keys []string
+
+	token := ""
+	for {
+		query := url.Values{"prefix": {prefix}, "delimiter": {"/"}, "fields": {"items(name),nextPageToken"}}
+		if token != "" {
+			query.Set("pageToken", token)
+		}
+
+		var page struct {
+			Items []struct {
+				Name string `json:"name"`
+			} `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if err := JSON(ctx, "https://storage.googleapis.com/storage/v1/b/"+url.PathEscape(bucket)+"/o?"+query.Encode(), &page); err != nil {
+			return nil, err
+		}
+
+		for _, object := range page.Items {
+			if !strings.HasSuffix(object.Name, "/") {
+				keys = append(keys, object.Name)
+			}
+		}
+
+		if page.NextPageToken == "" {
+			return keys, nil
+		}
+		token = page.NextPageToken
+	}
+}
+
+// getXML fetches rawURL and decodes the XML document into v.
+func getXML(ctx context.Context, rawURL string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch %s: unexpected status: %s", rawURL, resp.Status)
+	}
+
+	if err = xml.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", rawURL, err)
+	}
+
+	return nil
+}
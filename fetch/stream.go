@@ -0,0 +1,150 @@
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"linea/aztec-srs-to-gnark/input"
+)
+
+// Stream returns the urls as setup files read directly over HTTP, so they are
+// never stored locally. A transfer interrupted while a file is being parsed
+// is continued from where it stopped, retrying as set by opts.
+func Stream(ctx context.Context, urls []string, opts Options) ([]input.File, error) {
+	files := make([]input.File, len(urls))
+	for i, rawURL := range urls {
+		name, err := FileName(rawURL)
+		if err != nil {
+			return nil, err
+		}
+
+		files[i] = input.New(name, contentLength(ctx, rawURL), func() (io.ReadCloser, error) {
+			s := &stream{ctx: ctx, url: rawURL, opts: opts}
+			if err := retry(ctx, "Download of "+rawURL, opts, s.connect); err != nil {
+				return nil, fmt.Errorf("failed to stream %s: %w", rawURL, err)
+			}
+
+			return s, nil
+		})
+	}
+
+	return files, nil
+}
+
+// stream is the body of a remote file, reconnecting when the transfer breaks.
+type stream struct {
+	ctx  context.Context
+	url  string
+	opts Options
+
+	body      io.ReadCloser
+	offset    int64
+	validator string
+}
+
+// Read reads the body, continuing the transfer from the current offset when it breaks.
+func (s *stream) Read(p []byte) (int, error) {
+	n, err := s.body.Read(p)
+	s.offset += int64(n)
+	if err == nil || err == io.EOF {
+		return n, err
+	}
+
+	s.body.Close()
+	s.body = http.NoBody
+
+	fmt.Printf("Transfer of %s broke at %d bytes: %v\n", s.url, s.offset, err)
+	if err = retry(s.ctx, "Download of "+s.url, s.opts, s.connect); err != nil {
+		return n, fmt.Errorf("failed to continue streaming %s: %w", s.url, err)
+	}
+
+	return n, nil
+}
+
+// Close closes the current transfer.
+func (s *stream) Close() error {
+	if s.body == nil {
+		return nil
+	}
+
+	return s.body.Close()
+}
+
+// connect requests the rest of the file, from the current offset.
+func (s *stream) connect() error {
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return err
+	}
+
+	if s.offset > 0 {
+		if s.validator == "" {
+			// The file can't be resumed without knowing it's unchanged.
+			return errRemoteChanged
+		}
+
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", s.offset))
+		req.Header.Set("If-Range", s.validator)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case s.offset == 0 && resp.StatusCode == http.StatusOK:
+		s.validator = responseValidator(resp)
+	case s.offset > 0 && resp.StatusCode == http.StatusPartialContent:
+		var from int64
+		if _, err = fmt.Sscanf(resp.Header.Get("Content-Range"), "bytes %d-", &from); err != nil || from != s.offset {
+			resp.Body.Close()
+			return fmt.Errorf("unexpected content range '%s'", resp.Header.Get("Content-Range"))
+		}
+	case s.offset > 0 && resp.StatusCode == http.StatusOK:
+		resp.Body.Close()
+		return errRemoteChanged
+	default:
+		resp.Body.Close()
+		return statusError{resp.StatusCode, resp.Status}
+	}
+
+	s.body = resp.Body
+
+	return nil
+}
+
+// Range downloads len(buf) bytes of rawURL starting at offset into buf,
+// retrying as set by opts.
+func Range(ctx context.Context, rawURL string, offset int64, buf []byte, opts Options) error {
+	return retry(ctx, "Download of "+rawURL, opts, func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+int64(len(buf))-1))
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		switch resp.StatusCode {
+		case http.StatusPartialContent:
+		case http.StatusOK:
+			// The server ignored the range and sends the whole file.
+			if _, err = io.CopyN(io.Discard, resp.Body, offset); err != nil {
+				return err
+			}
+		default:
+			return statusError{resp.StatusCode, resp.Status}
+		}
+
+		_, err = io.ReadFull(resp.Body, buf)
+
+		return err
+	})
+}
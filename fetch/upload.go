@@ -0,0 +1,220 @@
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// DefaultPartSize is the size of the parts large files are uploaded in.
+const DefaultPartSize = 64 << 20
+
+// Upload copies the file at path to the destination, an s3:// or gs://
+// object location or the URL of the object, e.g. of an Azure blob, with
+// requests authorized by the registered credentials. Files larger than
+// opts.PartSize are uploaded to S3 in a multipart upload and to Azure in
+// blocks, every part being retried like the downloads; the other storages,
+// e.g. Cloud Storage, take them in a single request.
+func Upload(ctx context.Context, path, destination string, opts Options) error {
+	rawURL := ObjectURL(destination)
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid destination '%s': %w", destination, err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to get file info of %s: %w", path, err)
+	}
+	size := info.Size()
+
+	partSize := opts.PartSize
+	if partSize <= 0 {
+		partSize = DefaultPartSize
+	}
+	s3 := strings.HasPrefix(destination, "s3://") || isS3Host(u.Host, os.Getenv("AWS_ENDPOINT_URL"))
+	azure := isAzureHost(u.Host)
+
+	switch {
+	case size > partSize && s3:
+		err = uploadS3Parts(ctx, rawURL, f, size, partSize, opts)
+	case size > partSize && azure:
+		err = uploadAzureBlocks(ctx, rawURL, f, size, partSize, opts)
+	default:
+		header := make(http.Header)
+		if azure {
+			header.Set("X-Ms-Blob-Type", "BlockBlob")
+		}
+		err = retry(ctx, "Upload to "+rawURL, opts, func() error {
+			return send(ctx, http.MethodPut, rawURL, io.NewSectionReader(f, 0, size), size, header, nil)
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to upload %s to %s: %w", path, destination, err)
+	}
+
+	return nil
+}
+
+// send sends a request with the n bytes of body and checks that it succeeds.
+// The response headers are passed to handle with the body, when it is set.
+func send(ctx context.Context, method, rawURL string, body io.Reader, n int64, header http.Header, handle func(header http.Header, body io.Reader) error) error {
+	if n == 0 {
+		body = http.NoBody
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = n
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return statusError{resp.StatusCode, resp.Status}
+	}
+	if handle != nil {
+		return handle(resp.Header, resp.Body)
+	}
+
+	return nil
+}
+
+// withQuery appends the query to the one of the URL.
+func withQuery(rawURL, query string) string {
+	if strings.Contains(rawURL, "?") {
+		return rawURL + "&" + query
+	}
+	return rawURL + "?" + query
+}
+
+// s3Part is a part of a multipart upload, by the ETag S3 returned for it.
+type s3Part struct {
+	PartNumber int
+	ETag       string
+}
+
+// uploadS3Parts uploads the file of the size to the S3 object of rawURL in
+// parts of partSize bytes, aborting the upload when a part fails.
+func uploadS3Parts(ctx context.Context, rawURL string, f io.ReaderAt, size, partSize int64, opts Options) error {
+	var initiated struct {
+		UploadID string `xml:"UploadId"`
+	}
+	err := retry(ctx, "Upload to "+rawURL, opts, func() error {
+		return send(ctx, http.MethodPost, withQuery(rawURL, "uploads"), nil, 0, nil, func(_ http.Header, body io.Reader) error {
+			return xml.NewDecoder(body).Decode(&initiated)
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start multipart upload: %w", err)
+	}
+	uploadURL := withQuery(rawURL, "uploadId="+url.QueryEscape(initiated.UploadID))
+
+	var parts []s3Part
+	for offset, number := int64(0), 1; offset < size; offset, number = offset+partSize, number+1 {
+		n := min(partSize, size-offset)
+		part := s3Part{PartNumber: number}
+		err = retry(ctx, fmt.Sprintf("Upload of part %d to %s", number, rawURL), opts, func() error {
+			partURL := withQuery(uploadURL, fmt.Sprintf("partNumber=%d", number))
+			return send(ctx, http.MethodPut, partURL, io.NewSectionReader(f, offset, n), n, nil, func(header http.Header, _ io.Reader) error {
+				part.ETag = header.Get("ETag")
+				return nil
+			})
+		})
+		if err != nil {
+			abortS3Upload(uploadURL)
+			return fmt.Errorf("failed to upload part %d: %w", number, err)
+		}
+		parts = append(parts, part)
+	}
+
+	completion, err := xml.Marshal(struct {
+		XMLName xml.Name `xml:"CompleteMultipartUpload"`
+		Parts   []s3Part `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return err
+	}
+	err = retry(ctx, "Upload to "+rawURL, opts, func() error {
+		return send(ctx, http.MethodPost, uploadURL, bytes.NewReader(completion), int64(len(completion)), nil, func(_ http.Header, body io.Reader) error {
+			// S3 reports some failures with a success status and an error document
+			var result struct {
+				XMLName xml.Name
+				Code    string
+				Message string
+			}
+			if err := xml.NewDecoder(body).Decode(&result); err != nil {
+				return fmt.Errorf("failed to decode the completion of the upload: %w", err)
+			}
+			if result.XMLName.Local == "Error" {
+				return fmt.Errorf("%s: %s", result.Code, result.Message)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		abortS3Upload(uploadURL)
+		return fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+
+	return nil
+}
+
+// abortS3Upload aborts a multipart upload, so S3 doesn't keep its parts. It
+// isn't bound to the context of the upload, which may be canceled.
+func abortS3Upload(uploadURL string) {
+	if err := send(context.Background(), http.MethodDelete, uploadURL, nil, 0, nil, nil); err != nil {
+		fmt.Printf("WARNING: failed to abort the multipart upload, its parts may be kept: %v\n", err)
+	}
+}
+
+// uploadAzureBlocks uploads the file of the size to the Azure blob of rawURL
+// in blocks of partSize bytes, committed once they are all uploaded.
+func uploadAzureBlocks(ctx context.Context, rawURL string, f io.ReaderAt, size, partSize int64, opts Options) error {
+	var blockList strings.Builder
+	blockList.WriteString(`<?xml version="1.0" encoding="utf-8"?><BlockList>`)
+	for offset, number := int64(0), 1; offset < size; offset, number = offset+partSize, number+1 {
+		n := min(partSize, size-offset)
+		// The IDs of the blocks of a blob have the same length
+		id := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%08d", number)))
+		err := retry(ctx, fmt.Sprintf("Upload of block %d to %s", number, rawURL), opts, func() error {
+			blockURL := withQuery(rawURL, "comp=block&blockid="+url.QueryEscape(id))
+			return send(ctx, http.MethodPut, blockURL, io.NewSectionReader(f, offset, n), n, nil, nil)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to upload block %d: %w", number, err)
+		}
+		fmt.Fprintf(&blockList, "<Latest>%s</Latest>", id)
+	}
+	blockList.WriteString("</BlockList>")
+
+	body := blockList.String()
+	err := retry(ctx, "Upload to "+rawURL, opts, func() error {
+		return send(ctx, http.MethodPut, withQuery(rawURL, "comp=blocklist"), strings.NewReader(body), int64(len(body)), nil, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to commit the blocks: %w", err)
+	}
+
+	return nil
+}
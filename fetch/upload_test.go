@@ -0,0 +1,124 @@
+package fetch_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"linea/aztec-srs-to-gnark/fetch"
+)
+
+// fakeS3 stores the objects put to it, in a single request or in the parts of
+// a multipart upload, and the Authorization headers of the requests.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	parts   map[int][]byte
+	auth    []string
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.auth = append(s.auth, r.Header.Get("Authorization"))
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query := r.URL.Query()
+
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		s.parts = make(map[int][]byte)
+		fmt.Fprint(w, "<InitiateMultipartUploadResult><UploadId>upload 1</UploadId></InitiateMultipartUploadResult>")
+	case r.Method == http.MethodPut && query.Get("uploadId") == "upload 1":
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		s.parts[number] = body
+		w.Header().Set("ETag", fmt.Sprintf(`"etag %d"`, number))
+	case r.Method == http.MethodPost && query.Get("uploadId") == "upload 1":
+		var completion struct {
+			Parts []struct {
+				PartNumber int
+				ETag       string
+			} `xml:"Part"`
+		}
+		if err = xml.Unmarshal(body, &completion); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var object []byte
+		for i, part := range completion.Parts {
+			if part.PartNumber != i+1 || part.ETag != fmt.Sprintf(`"etag %d"`, i+1) {
+				fmt.Fprint(w, "<Error><Code>InvalidPart</Code><Message>unexpected part</Message></Error>")
+				return
+			}
+			object = append(object, s.parts[part.PartNumber]...)
+		}
+		s.objects[r.URL.Path] = object
+		fmt.Fprint(w, "<CompleteMultipartUploadResult></CompleteMultipartUploadResult>")
+	case r.Method == http.MethodPut && len(query) == 0:
+		s.objects[r.URL.Path] = body
+	default:
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+	}
+}
+
+// TestUpload uploads files to S3 in a single request and in parts, signed
+// with the credentials of the environment once Setup is asked for them.
+func TestUpload(t *testing.T) {
+	s3 := &fakeS3{objects: make(map[string][]byte)}
+	server := httptest.NewServer(s3)
+	defer server.Close()
+
+	t.Setenv("AWS_ENDPOINT_URL", server.URL)
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	if err := fetch.Setup(fetch.Network{CloudCredentials: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	opts := fetch.Options{PartSize: 10}
+	for _, size := range []int{7, 35} {
+		data := bytes.Repeat([]byte("0123456789abcdef"), 3)[:size]
+		path := filepath.Join(dir, fmt.Sprintf("srs %d", size))
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := fetch.Upload(context.Background(), path, fmt.Sprintf("s3://bucket/outputs/srs %d", size), opts); err != nil {
+			t.Fatal(err)
+		}
+		if got := s3.objects[fmt.Sprintf("/bucket/outputs/srs %d", size)]; !bytes.Equal(got, data) {
+			keys := make([]string, 0, len(s3.objects))
+			for key := range s3.objects {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			t.Fatalf("the object of %d bytes is %q, the objects are %v", size, got, keys)
+		}
+	}
+
+	// 1 request for the small file, 1 + 4 parts + 1 for the large one
+	if len(s3.auth) != 7 {
+		t.Fatalf("%d requests sent, not 7", len(s3.auth))
+	}
+	for _, auth := range s3.auth {
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") {
+			t.Fatalf("request authorized with %q, not signed with the credentials of the environment", auth)
+		}
+	}
+}
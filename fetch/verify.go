@@ -0,0 +1,123 @@
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"linea/aztec-srs-to-gnark/digest"
+)
+
+// Selection restricts the setup files of a ceremony to download.
+type Selection struct {
+	// Degree is the log2 of the number of points needed, all of them when 0.
+	Degree int
+	// Transcripts is the number of the first transcripts needed, for
+	// ceremonies publishing their points in transcripts, all of them when 0.
+	Transcripts int
+	// Contribution is the number of the contribution for ceremonies
+	// publishing the state after every contribution.
+	Contribution int
+	// Response selects the response of the contribution instead of its challenge.
+	Response bool
+	// Attestation is the URL or the path of the record with the checksums of the files.
+	Attestation string
+}
+
+// VerifyDigest returns a verification checking the size of a downloaded file
+// and that its digest of the algorithm is one of the checksums. A negative
+// size isn't checked.
+func VerifyDigest(algorithm digest.Algorithm, checksums []string, size int64) func(path string) error {
+	return func(path string) error {
+		sum, n, err := algorithm.File(path)
+		if err != nil {
+			return err
+		}
+
+		if size >= 0 && n != size {
+			return fmt.Errorf("size mismatch: expected %d bytes, got %d", size, n)
+		}
+
+		return digest.Check(algorithm, filepath.Base(path), sum, checksums...)
+	}
+}
+
+// Read returns the content of location, which is either an HTTP(S) URL or a path.
+func Read(ctx context.Context, location string) ([]byte, error) {
+	if u, err := url.Parse(location); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return os.ReadFile(location)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", location, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: unexpected status: %s", location, resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// JSON fetches rawURL and decodes the JSON document into v.
+func JSON(ctx context.Context, rawURL string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch %s: unexpected status: %s", rawURL, resp.Status)
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", rawURL, err)
+	}
+
+	return nil
+}
+
+// PostJSON sends v encoded as JSON to rawURL.
+func PostJSON(ctx context.Context, rawURL string, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode request to %s: %w", rawURL, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post to %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("failed to post to %s: unexpected status: %s", rawURL, resp.Status)
+	}
+
+	return nil
+}
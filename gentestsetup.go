@@ -0,0 +1,104 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"os"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/celo"
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/srsio"
+	"linea/aztec-srs-to-gnark/testsetup"
+)
+
+// genTestSetup writes tiny setup files of a ceremony with known secrets, for
+// testing the importers end to end. Such a setup must never be used in
+// production since its secrets are public.
+func genTestSetup(args []string) error {
+	flags := flag.NewFlagSet("gen-test-setup", flag.ExitOnError)
+	tauHex := flags.String("tau", "2a", "hex encoded tau")
+	filesN := flags.Int("files", 2, "number of transcripts or G1 setup files, aztec and aleo only, or of sub-ceremonies of twice as many points as the previous one, ethereum only")
+	pointsN := flags.Int("points", 4, "number of G1 points of every transcript, G1 setup file or chunk, or of G2 points of the ptau file, rounded down to a power of 2, and of the ppot and zcash files, which hold twice as many G1 points but one, or of G1 points of the zksync and halo2 files, rounded down to a power of 2")
+	response := flags.Bool("response", false, "write response files instead of challenge files, celo and zcash only")
+	expected := flags.String("expected", "", "file to write the SRS the conversion of the setup must give to, in the canonical format")
+	profile := flags.String("profile", "", "celo profile of the setup, whose chunk points override -points, see convert (default: plumo with chunks of -points points)")
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s gen-test-setup [flags] <protocol> <directory>\n", os.Args[0])
+		fmt.Println("The celo setup has the chunks of the profile of -points points, the last one missing one, which convert -profile converts.")
+		flags.PrintDefaults()
+	}
+	args = parseInterspersed(flags, args)
+
+	if len(args) < 2 {
+		flags.Usage()
+		return errUsage
+	}
+
+	secrets := testsetup.DefaultSecrets()
+	var ok bool
+	if secrets.Tau, ok = new(big.Int).SetString(strings.TrimPrefix(*tauHex, "0x"), 16); !ok {
+		fmt.Println("ERROR: -tau must be a hex encoded number")
+		flags.Usage()
+		return errUsage
+	}
+
+	var (
+		srs kzg.SRS
+		err error
+	)
+	switch ProtocolName(args[0]) {
+	case AztecProtocol:
+		srs, err = testsetup.Aztec(args[1], *filesN, *pointsN, secrets)
+	case AleoProtocol:
+		srs, err = testsetup.Aleo(args[1], *filesN, *pointsN, secrets)
+	case EthereumProtocol:
+		srs, err = testsetup.Ethereum(args[1], *filesN, *pointsN, secrets)
+	case PtauProtocol:
+		srs, err = testsetup.Ptau(args[1], bits.Len(uint(*pointsN))-1, secrets)
+	case PPoTProtocol:
+		srs, err = testsetup.PPoT(args[1], *pointsN, secrets)
+	case ZcashProtocol:
+		srs, err = testsetup.Zcash(args[1], *pointsN, *response, secrets)
+	case ZksyncProtocol:
+		srs, err = testsetup.Zksync(args[1], bits.Len(uint(*pointsN))-1, secrets)
+	case Halo2Protocol:
+		srs, err = testsetup.Halo2(args[1], bits.Len(uint(*pointsN))-1, secrets)
+	case CeloProtocol:
+		ceremony := celo.Plumo
+		if *profile != "" {
+			var p Profile
+			if p, err = loadProfile(*profile); err != nil {
+				break
+			}
+			if p.Celo == nil {
+				err = fmt.Errorf("%s is a profile of the %s ceremony, not of a celo one", *profile, p.Protocol)
+				break
+			}
+			ceremony = *p.Celo
+		}
+		ceremony.ChunkG1PointsN = *pointsN
+		ceremony.G1PointsN = *pointsN*ceremony.ChunksN - 1
+		srs, err = testsetup.Celo(args[1], ceremony, *response, secrets)
+	default:
+		return fmt.Errorf("unsupported protocol %s, use one of %s, %s, %s, %s, %s, %s, %s, %s, %s", args[0], AztecProtocol, AleoProtocol, CeloProtocol, EthereumProtocol, Halo2Protocol, PPoTProtocol, PtauProtocol, ZcashProtocol, ZksyncProtocol)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Setup files written to %s\n", args[1])
+
+	if *expected != "" {
+		opts := config.Tune(config.Options{IOParallelism: 1}, "")
+		if _, err = writeOutput(*expected, srs, srsio.FormatCanonical, opts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
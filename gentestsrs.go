@@ -0,0 +1,123 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	blsKzg "github.com/consensys/gnark-crypto/ecc/bls12-377/kzg"
+	bls381Kzg "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	bwKzg "github.com/consensys/gnark-crypto/ecc/bw6-761/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/srsio"
+)
+
+// alignedFormat is the memdump padded so its G1 points start on a page, as
+// written by convert -align 4096, listed with the output formats by gen-test-srs.
+const alignedFormat = "memdump-aligned"
+
+// testAlignment is the alignment of the G1 points of the alignedFormat files.
+const testAlignment = 4096
+
+// genTestSRS writes a small SRS with a known tau in all the output formats,
+// for testing the code loading SRS files. Such an SRS must never be used in
+// production since tau is public.
+func genTestSRS(args []string) error {
+	flags := flag.NewFlagSet("gen-test-srs", flag.ExitOnError)
+	curve := flags.String("curve", string(BN254Curve), fmt.Sprintf("curve of the SRS, one of %s, %s, %s, %s", BN254Curve, BLS12377Curve, BLS12381Curve, BW6761Curve))
+	size := flags.Uint64("size", 1024, "number of G1 points")
+	tauHex := flags.String("tau", "", "hex encoded tau (required)")
+	dir := flags.String("dir", ".", "directory to write the SRS files into")
+	formats := flags.String("formats", strings.Join(formatNames(), ","), fmt.Sprintf("comma separated output formats, %s being a memdump whose G1 points start at a multiple of %d bytes", alignedFormat, testAlignment))
+	force := flags.Bool("force", false, "overwrite the existing SRS files, named after the curve and the size only, whatever their tau")
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s gen-test-srs [flags]\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	tau, ok := new(big.Int).SetString(strings.TrimPrefix(*tauHex, "0x"), 16)
+	if !ok {
+		fmt.Println("ERROR: -tau must be a hex encoded number")
+		flags.Usage()
+		return errUsage
+	}
+
+	var (
+		srs kzg.SRS
+		err error
+	)
+	switch CurveName(*curve) {
+	case BN254Curve:
+		srs, err = bnKzg.NewSRS(*size, tau)
+	case BLS12377Curve:
+		srs, err = blsKzg.NewSRS(*size, tau)
+	case BLS12381Curve:
+		srs, err = bls381Kzg.NewSRS(*size, tau)
+	case BW6761Curve:
+		srs, err = bwKzg.NewSRS(*size, tau)
+	default:
+		return fmt.Errorf("unsupported curve %s", *curve)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to generate SRS: %w", err)
+	}
+
+	// The names don't tell the tau of the SRS, the files of another one
+	// aren't replaced silently.
+	var paths []string
+	var outputFormats []srsio.Format
+	var aligned []bool
+	for _, name := range splitList(*formats) {
+		format := srsio.FormatMemDump
+		if name != alignedFormat {
+			if format, err = srsio.ParseFormat(name); err != nil {
+				return err
+			}
+		}
+
+		path := filepath.Join(*dir, fmt.Sprintf("kzg_srs_canonical_%d_%s_test.%s", *size-1, *curve, name))
+		if _, err = os.Stat(path); err == nil && !*force {
+			return fmt.Errorf("%s already exists, set -force to overwrite it", path)
+		}
+		paths = append(paths, path)
+		outputFormats = append(outputFormats, format)
+		aligned = append(aligned, name == alignedFormat)
+	}
+
+	if err = os.MkdirAll(*dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	opts := config.Tune(config.Options{IOParallelism: 1}, "")
+
+	for i, path := range paths {
+		opts.Align = 0
+		if aligned[i] {
+			opts.Align = testAlignment
+		}
+		if _, err = writeOutput(path, srs, outputFormats[i], opts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// formatNames returns the names of the formats gen-test-srs writes: the
+// output formats and the aligned memdump.
+func formatNames() []string {
+	names := make([]string, len(srsio.Formats), len(srsio.Formats)+1)
+	for i, format := range srsio.Formats {
+		names[i] = string(format)
+	}
+
+	return append(names, alignedFormat)
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"linea/aztec-srs-to-gnark/aleo"
+	"linea/aztec-srs-to-gnark/aztec"
+	"linea/aztec-srs-to-gnark/celo"
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/digest"
+	"linea/aztec-srs-to-gnark/ethereum"
+	"linea/aztec-srs-to-gnark/halo2"
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/ppot"
+	"linea/aztec-srs-to-gnark/ptau"
+	"linea/aztec-srs-to-gnark/srsio"
+	"linea/aztec-srs-to-gnark/testsetup"
+	"linea/aztec-srs-to-gnark/zcash"
+	"linea/aztec-srs-to-gnark/zksync"
+)
+
+// goldenCeremony is the Celo ceremony of the test setups: the Plumo one with 2
+// points per chunk.
+var goldenCeremony = celo.Ceremony{
+	ChunkG1PointsN: 2,
+	G1PointsN:      2*celo.TotalChunks - 1,
+	ChunksN:        celo.TotalChunks,
+	FullChunksN:    celo.ChunkHalfwayPoint,
+	Hash:           digest.BLAKE2b512,
+}
+
+// goldenCases are the test setups converted by TestGolden, one per importer
+// and layout of the setup files.
+var goldenCases = []struct {
+	name      string
+	generate  func(dir string, secrets testsetup.Secrets) error
+	construct ConstructSetup
+}{
+	{"aztec", func(dir string, secrets testsetup.Secrets) error {
+		_, err := testsetup.Aztec(dir, 2, 4, secrets)
+		return err
+	}, aztec.TranslateBn254SRS},
+	{"aleo", func(dir string, secrets testsetup.Secrets) error {
+		_, err := testsetup.Aleo(dir, 2, 4, secrets)
+		return err
+	}, aleo.TranslateBls12377SRS},
+	{"celo-challenge", func(dir string, secrets testsetup.Secrets) error {
+		_, err := testsetup.Celo(dir, goldenCeremony, false, secrets)
+		return err
+	}, goldenCeremony.Translate},
+	{"celo-response", func(dir string, secrets testsetup.Secrets) error {
+		_, err := testsetup.Celo(dir, goldenCeremony, true, secrets)
+		return err
+	}, goldenCeremony.Translate},
+	{"ethereum", func(dir string, secrets testsetup.Secrets) error {
+		_, err := testsetup.Ethereum(dir, 2, 4, secrets)
+		return err
+	}, ethereum.TranslateBls12381SRS},
+	{"halo2", func(dir string, secrets testsetup.Secrets) error {
+		_, err := testsetup.Halo2(dir, 3, secrets)
+		return err
+	}, halo2.TranslateBn254SRS},
+	{"ppot", func(dir string, secrets testsetup.Secrets) error {
+		_, err := testsetup.PPoT(dir, 4, secrets)
+		return err
+	}, ppot.TranslateBn254SRS},
+	{"ptau", func(dir string, secrets testsetup.Secrets) error {
+		_, err := testsetup.Ptau(dir, 2, secrets)
+		return err
+	}, ptau.TranslateBn254SRS},
+	{"zcash-challenge", func(dir string, secrets testsetup.Secrets) error {
+		_, err := testsetup.Zcash(dir, 4, false, secrets)
+		return err
+	}, zcash.TranslateBls12381SRS},
+	{"zcash-response", func(dir string, secrets testsetup.Secrets) error {
+		_, err := testsetup.Zcash(dir, 4, true, secrets)
+		return err
+	}, zcash.TranslateBls12381SRS},
+	{"zksync", func(dir string, secrets testsetup.Secrets) error {
+		_, err := testsetup.Zksync(dir, 3, secrets)
+		return err
+	}, zksync.TranslateBn254SRS},
+}
+
+// update rewrites the golden digests with the ones of the outputs.
+var update = flag.Bool("update", false, "rewrite testsetup/golden.sha256 with the digests of the outputs")
+
+// TestGolden converts the test setups of every importer and compares the
+// outputs, in every format, against the golden digests checked in with the
+// testsetup package, so a change of the importers or of the writers that
+// alters an SRS is caught. The canonical digest of the SRS of every setup is
+// checked, and the SHA256 of the output file of every format.
+func TestGolden(t *testing.T) {
+	digests := make(map[string]string)
+	for _, c := range goldenCases {
+		goldenDigests(t, filepath.Join(t.TempDir(), c.name), c.name, c.generate, c.construct, digests)
+	}
+
+	names := make([]string, 0, len(digests))
+	for name := range digests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if *update {
+		var b strings.Builder
+		for _, name := range names {
+			fmt.Fprintf(&b, "%s  %s\n", digests[name], name)
+		}
+		if err := os.WriteFile(filepath.Join("testsetup", "golden.sha256"), []byte(b.String()), 0o644); err != nil {
+			t.Fatalf("failed to write the golden digests: %v", err)
+		}
+		t.Logf("the digests of the %d outputs written to testsetup/golden.sha256", len(names))
+		return
+	}
+
+	golden, err := testsetup.Golden()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		switch expected, ok := golden[name]; {
+		case !ok:
+			t.Errorf("%s: no golden digest", name)
+		case expected != digests[name]:
+			t.Errorf("%s: %s, the golden digest is %s", name, digests[name], expected)
+		}
+	}
+	for name := range golden {
+		if _, ok := digests[name]; !ok {
+			t.Errorf("%s: golden digest of no output", name)
+		}
+	}
+}
+
+// goldenDigests generates the test setup into dir, converts it and records the
+// digests of the outputs under the name of the setup.
+func goldenDigests(t *testing.T, dir, name string, generate func(dir string, secrets testsetup.Secrets) error, construct ConstructSetup, digests map[string]string) {
+	t.Helper()
+
+	if err := generate(dir, testsetup.DefaultSecrets()); err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+
+	files, err := input.Dir(dir)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+
+	opts := config.Tune(config.Options{}, dir)
+	opts.Mappings = new(config.Mappings)
+	srs, _, err := construct(files, opts)
+	defer func() {
+		if err := opts.Mappings.Release(); err != nil {
+			t.Errorf("failed to release off-heap memory: %v", err)
+		}
+	}()
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+
+	for _, format := range srsio.Formats {
+		path := filepath.Join(dir, fmt.Sprintf("%s.%s", name, format))
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatalf("failed to create SRS file: %v", err)
+		}
+
+		hw := srsio.NewHashingWriter(f)
+		err = srsio.Write(hw, srs, format, opts)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			t.Fatalf("failed to write SRS file %s: %v", path, err)
+		}
+		digests[fmt.Sprintf("%s.%s", name, format)] = hw.Checksums().SHA256
+
+		// The canonical digest is the same in all the formats, the one of the
+		// canonical file is kept.
+		if format != srsio.FormatCanonical {
+			continue
+		}
+		r, err := srsio.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		digest, err := srsio.Digest(r, srsio.DefaultCompareBatch)
+		r.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		digests[name] = hex.EncodeToString(digest)
+	}
+}
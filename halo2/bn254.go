@@ -0,0 +1,342 @@
+// Package halo2 reads the KZG params of halo2 on bn254, the ParamsKZG of the
+// PSE fork serialized by ParamsKZG::write, e.g. the kzg_bn254_<k>.srs files
+// many halo2 projects ship.
+package halo2
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/audit"
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/offheap"
+	"linea/aztec-srs-to-gnark/verify"
+)
+
+const (
+	// Ext is the extension of the params files.
+	Ext = ".srs"
+	// G1PointSize is the size of a G1 point in the raw format: x and y.
+	G1PointSize = 2 * fp.Bytes
+	// G2PointSize is the size of a G2 point in the raw format: x.c0, x.c1,
+	// y.c0 and y.c1.
+	G2PointSize = 4 * fp.Bytes
+	// kSize is the size of k, little endian, starting the file.
+	kSize = 4
+	// maxK bounds the k of a file.
+	maxK = 32
+	// g1ReadBatch is the number of G1 points read at once.
+	g1ReadBatch = 1 << 12
+)
+
+// modulus is the modulus of the base field of bn254, as the limbs of an
+// fp.Element.
+var modulus = func() (m fp.Element) {
+	for i, word := range fp.Modulus().Bits() {
+		m[i] = uint64(word)
+	}
+	return m
+}()
+
+// rawSize returns the size of the params of k written in the raw format,
+// SerdeFormat::RawBytes, the one of ParamsKZG::write:
+//
+//	[k: u32] [g: 2^k G1 points] [g_lagrange: 2^k G1 points] [g2] [s_g2]
+//
+// the coordinates being in the Montgomery form, as little endian limbs.
+func rawSize(k uint32) int64 {
+	return kSize + 2*(int64(1)<<k)*G1PointSize + 2*G2PointSize
+}
+
+// processedSize returns the size of the params of k written in the
+// compressed format, SerdeFormat::Processed.
+func processedSize(k uint32) int64 {
+	return kSize + 2*(int64(1)<<k)*G1PointSize/2 + 2*G2PointSize/2
+}
+
+// findParams returns the params file among the files, the only one, e.g. read
+// from the standard input, or the only one of extension Ext, and the other
+// files.
+func findParams(files []input.File) (input.File, []input.File, error) {
+	if len(files) == 1 {
+		return files[0], nil, nil
+	}
+
+	var found, others []input.File
+	for _, file := range files {
+		if strings.EqualFold(filepath.Ext(file.Name), Ext) {
+			found = append(found, file)
+		} else {
+			others = append(others, file)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return input.File{}, nil, fmt.Errorf("no %s file found", Ext)
+	case 1:
+		return found[0], others, nil
+	}
+
+	return input.File{}, nil, fmt.Errorf("several %s files found: %s and %s", Ext, found[0].Name, found[1].Name)
+}
+
+// readK reads k, the log2 of the number of points, starting the file, and
+// checks the size of the file against it when it is known.
+func readK(r io.Reader, size int64) (uint32, error) {
+	var k uint32
+	if err := binary.Read(r, binary.LittleEndian, &k); err != nil {
+		return 0, fmt.Errorf("failed to read k: %w", err)
+	}
+	if k < 1 || k > maxK {
+		return 0, fmt.Errorf("invalid k %d", k)
+	}
+
+	switch size {
+	case input.UnknownSize, rawSize(k):
+		return k, nil
+	case processedSize(k):
+		return 0, errors.New("the points are compressed (SerdeFormat::Processed), which isn't supported: write the params in the raw format of ParamsKZG::write")
+	}
+
+	return 0, fmt.Errorf("size of %d bytes isn't the %d of the params of k = %d", size, rawSize(k), k)
+}
+
+// decodeMontgomery decodes coordinates in the raw format of halo2curves: in
+// the Montgomery form, as little endian 64-bit limbs like fp.Element.
+func decodeMontgomery(data []byte, coordinates ...*fp.Element) error {
+	for i, c := range coordinates {
+		for j := range c {
+			c[j] = binary.LittleEndian.Uint64(data[i*fp.Bytes+j*8:])
+		}
+
+		// The limbs are compared from the most significant one
+		reduced := false
+		for j := len(c) - 1; j >= 0; j-- {
+			if c[j] != modulus[j] {
+				reduced = c[j] < modulus[j]
+				break
+			}
+		}
+		if !reduced {
+			return fmt.Errorf("coordinate %d isn't smaller than the modulus", i)
+		}
+	}
+
+	return nil
+}
+
+// readG1Points reads the G1 points in batches, checks that they are on the
+// curve and passes every batch to the verification checks. It returns the
+// number of points read before an error.
+func readG1Points(r io.Reader, points []bn254.G1Affine, checks *verify.Pipeline[bn254.G1Affine], progress *config.Progress) (int, error) {
+	buf := make([]byte, g1ReadBatch*G1PointSize)
+
+	for from := 0; from < len(points); from += g1ReadBatch {
+		batch := points[from:min(from+g1ReadBatch, len(points))]
+		data := buf[:len(batch)*G1PointSize]
+
+		if _, err := io.ReadFull(r, data); err != nil {
+			return from, fmt.Errorf("failed to read G1 points %d-%d: %w", from, from+len(batch)-1, err)
+		}
+		for i := range batch {
+			p := &batch[i]
+			if err := decodeMontgomery(data[i*G1PointSize:], &p.X, &p.Y); err != nil {
+				return from + i, fmt.Errorf("invalid G1 point %d: %w", from+i, err)
+			}
+			if !p.IsOnCurve() {
+				return from + i, fmt.Errorf("G1 point %d is not on the curve", from+i)
+			}
+		}
+
+		checks.Check(from, batch)
+		progress.Add(len(batch))
+	}
+
+	return len(points), nil
+}
+
+// readG2Powers reads g2 and s_g2, the generator and τG2 ending the file, into
+// the verifying key.
+func readG2Powers(r io.Reader, srs *bnKzg.SRS) error {
+	var data [2 * G2PointSize]byte
+	if _, err := io.ReadFull(r, data[:]); err != nil {
+		return fmt.Errorf("failed to read s_g2: %w", err)
+	}
+	for i := range srs.Vk.G2 {
+		p := &srs.Vk.G2[i]
+		if err := decodeMontgomery(data[i*G2PointSize:], &p.X.A0, &p.X.A1, &p.Y.A0, &p.Y.A1); err != nil {
+			return fmt.Errorf("invalid G2 power %d: %w", i, err)
+		}
+		if !p.IsOnCurve() {
+			return fmt.Errorf("G2 power %d is not on the curve", i)
+		}
+	}
+	if _, _, _, g2Gen := bn254.Generators(); !srs.Vk.G2[0].Equal(&g2Gen) {
+		return errors.New("g2 isn't the generator")
+	}
+	srs.Vk.Lines[0] = bn254.PrecomputeLines(srs.Vk.G2[0])
+	srs.Vk.Lines[1] = bn254.PrecomputeLines(srs.Vk.G2[1])
+
+	fmt.Printf("> a^1*G2: %s %s\n", srs.Vk.G2[1].X.String(), srs.Vk.G2[1].Y.String())
+
+	return nil
+}
+
+// TranslateBn254SRS reads the params of halo2 and constructs a KZG SRS from g,
+// the G1 powers of τ: all of them, or with opts.Degree the first 2^opts.Degree
+// ones. The other points of g and g_lagrange, the Lagrange basis gnark
+// computes itself, are seeked over to reach g2 and s_g2 in a local file. The
+// file is read once in its order, so it can be streamed, e.g. from the
+// standard input. The other files and the G1 points that can't be read are
+// rejected with opts.Reject: under the lenient validation the SRS ends before
+// the first point that can't be read.
+func TranslateBn254SRS(files []input.File, opts config.Options) (kzg.SRS, int, error) {
+	file, others, err := findParams(files)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, other := range others {
+		opts.Ignore(other.Name, "not a "+Ext+" file")
+		opts.Audit.Record(audit.Event{Kind: audit.KindSetupFile, File: other.Name, Decision: "ignored: not a " + Ext + " file"})
+	}
+
+	fmt.Printf("Processing file %s\n", file.Name)
+	f, err := file.Open()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open %s: %w", file.Name, err)
+	}
+	defer f.Close()
+
+	k, err := readK(f, file.Size)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read %s: %w", file.Name, err)
+	}
+	g1PowersN := 1 << k
+	n := g1PowersN
+	if opts.Degree > 0 {
+		if 1<<opts.Degree > n {
+			return nil, 0, fmt.Errorf("%s holds %d points, less than 2^%d", file.Name, n, opts.Degree)
+		}
+		n = 1 << opts.Degree
+	}
+	fmt.Printf("> k = %d, %d G1 powers\n", k, g1PowersN)
+
+	srs := new(bnKzg.SRS)
+	if srs.Pk.G1, err = offheap.Make[bn254.G1Affine](n, opts); err != nil {
+		return nil, 0, err
+	}
+	opts.Progress.SetTotal(n)
+
+	var (
+		checker *verify.Bn254Checker
+		checks  *verify.Pipeline[bn254.G1Affine]
+	)
+	if opts.Verify {
+		if checker, err = verify.NewBn254Checker(); err != nil {
+			return nil, 0, err
+		}
+		checks = verify.NewPipeline(checker.Batch, opts.Workers)
+	}
+
+	// The G1 points are read in batches, without a buffer, so the ones after
+	// the points read, and g_lagrange, are seeked over to reach g2 in a local
+	// file
+	g1Sections := &io.LimitedReader{R: f, N: 2 * int64(g1PowersN) * G1PointSize}
+	read, err := readG1Points(g1Sections, srs.Pk.G1, checks, opts.Progress)
+	failedFrom := -1
+	if err != nil {
+		// The points before the first one that can't be read are kept
+		if read < 2 {
+			checks.Wait()
+			return nil, 0, fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+		if err = opts.Reject(config.Skip{File: file.Name, Offset: audit.Index(read), Points: n - read}, err); err != nil {
+			checks.Wait()
+			return nil, 0, err
+		}
+		opts.Warn("G1 points from %d can't be read: the SRS ends at G1 point %d", read, read-1)
+		failedFrom = read
+	}
+	if err = input.Skip(f, g1Sections.N); err == nil {
+		err = readG2Powers(f, srs)
+	}
+	if err != nil {
+		checks.Wait()
+		return nil, 0, fmt.Errorf("failed to read %s: %w", file.Name, err)
+	}
+
+	if failedFrom >= 0 {
+		srs.Pk.G1 = srs.Pk.G1[:failedFrom]
+	}
+	opts.Audit.Record(audit.Event{Kind: audit.KindSetupFile, File: file.Name, Points: len(srs.Pk.G1), Decision: "used"})
+	srs.Vk.G1 = srs.Pk.G1[0]
+
+	if _, _, g1Gen, _ := bn254.Generators(); !srs.Vk.G1.Equal(&g1Gen) {
+		checks.Wait()
+		return nil, 0, fmt.Errorf("the first G1 power of %s isn't the generator", file.Name)
+	}
+
+	fmt.Printf("> a^1*G1: %s %s\n", srs.Pk.G1[1].X.String(), srs.Pk.G1[1].Y.String())
+
+	if checker != nil {
+		err = checks.Wait()
+		switch {
+		case failedFrom >= 0:
+			// The points were checked up to the failed one, so the fused checks
+			// don't apply
+			err = verify.SRS(srs, opts)
+		case err == nil:
+			err = checker.Finish(srs)
+		}
+		// Under the lenient validation the SRS ends before a point failing
+		if err = verify.Recover(srs, opts, err); err != nil {
+			return nil, 0, fmt.Errorf("%w: %w", verify.ErrFailed, err)
+		}
+		fmt.Println("SRS verified: all G1 points are in the subgroup and are consecutive powers of tau")
+	}
+
+	return srs, len(srs.Pk.G1), nil
+}
+
+// ExtractTauG2 returns an SRS with only the verifying key, read from g2 and
+// s_g2 at the end of the params file. The G1 points are skipped over without
+// being parsed.
+func ExtractTauG2(files []input.File) (kzg.SRS, error) {
+	file, _, err := findParams(files)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", file.Name, err)
+	}
+	defer f.Close()
+
+	k, err := readK(f, file.Size)
+	if err == nil {
+		err = input.Skip(f, 2*(int64(1)<<k)*G1PointSize)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to skip the G1 points of %s: %w", file.Name, err)
+	}
+
+	srs := new(bnKzg.SRS)
+	_, _, srs.Vk.G1, _ = bn254.Generators()
+	if err = readG2Powers(f, srs); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+	}
+
+	return srs, nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+
+	"linea/aztec-srs-to-gnark/srsio"
+)
+
+// hash prints the canonical digest of SRS files, which is the same for an SRS
+// whatever the format of its file.
+func hash(args []string) error {
+	flags := flag.NewFlagSet("hash", flag.ExitOnError)
+	batchSize := flags.Int("batch-size", srsio.DefaultCompareBatch, "number of points decoded at once")
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s hash [flags] <SRS file>...\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() < 1 {
+		flags.Usage()
+		return errUsage
+	}
+
+	// Like sha256sum, the files that can't be hashed are reported once all the
+	// others are.
+	failures := 0
+	for _, name := range flags.Args() {
+		r, err := srsio.Open(name)
+		if err != nil {
+			fmt.Println(err)
+			failures++
+			continue
+		}
+
+		digest, err := srsio.Digest(r, *batchSize)
+		r.Close()
+		if err != nil {
+			fmt.Printf("%s: %v\n", name, err)
+			failures++
+			continue
+		}
+
+		// The layout of sha256sum
+		fmt.Printf("%s  %s\n", hex.EncodeToString(digest), name)
+	}
+
+	if failures != 0 {
+		return fmt.Errorf("%d of the %d files can't be hashed", failures, flags.NArg())
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	blsKzg "github.com/consensys/gnark-crypto/ecc/bls12-377/kzg"
+	bls381Kzg "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	bwKzg "github.com/consensys/gnark-crypto/ecc/bw6-761/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/srsio"
+)
+
+// headElement is a base field element in decimal and in hexadecimal.
+type headElement struct {
+	Dec string `json:"dec"`
+	Hex string `json:"hex"`
+}
+
+// headPoint is an affine point, its coordinates have one element for G1 and
+// the components of the extension field for G2.
+type headPoint struct {
+	X []headElement `json:"x"`
+	Y []headElement `json:"y"`
+}
+
+// headOutput is the output of the head command.
+type headOutput struct {
+	Curve    string      `json:"curve"`
+	NbPoints int         `json:"points"`
+	G1       []headPoint `json:"g1"`
+	G2       []headPoint `json:"g2"`
+}
+
+// textElement is a field element of any curve.
+type textElement interface {
+	Text(base int) string
+}
+
+// head prints the first G1 points and both G2 points of an SRS file, to be
+// compared with the values published by a ceremony.
+func head(args []string) error {
+	flags := flag.NewFlagSet("head", flag.ExitOnError)
+	n := flags.Int("n", 10, "number of first G1 points to print")
+	asJSON := flags.Bool("json", false, "print the points as JSON")
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s head <SRS file> [flags]\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	positional := parseInterspersed(flags, args)
+
+	if len(positional) != 1 {
+		flags.Usage()
+		return errUsage
+	}
+
+	r, err := srsio.Open(positional[0])
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	srs, err := r.Range(0, min(max(*n, 0), r.NbPoints))
+	if err != nil {
+		return err
+	}
+
+	out := headOutput{Curve: string(curveNames[r.Curve]), NbPoints: r.NbPoints}
+	if out.G1, out.G2, err = headPoints(srs); err != nil {
+		return err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+
+	fmt.Printf("Curve:  %s\n", out.Curve)
+	fmt.Printf("Points: %d\n", out.NbPoints)
+	for i, p := range out.G1 {
+		printHeadPoint(fmt.Sprintf("G1[%d]", i), p)
+	}
+	for i, p := range out.G2 {
+		printHeadPoint(fmt.Sprintf("G2[%d]", i), p)
+	}
+
+	return nil
+}
+
+// headPoints returns the G1 points and the two G2 points of the verifying key.
+func headPoints(srs kzg.SRS) (g1, g2 []headPoint, err error) {
+	g1 = []headPoint{}
+	switch s := srs.(type) {
+	case *bnKzg.SRS:
+		for i := range s.Pk.G1 {
+			g1 = append(g1, headPoint{X: elements(&s.Pk.G1[i].X), Y: elements(&s.Pk.G1[i].Y)})
+		}
+		for i := range s.Vk.G2 {
+			p := &s.Vk.G2[i]
+			g2 = append(g2, headPoint{X: elements(&p.X.A0, &p.X.A1), Y: elements(&p.Y.A0, &p.Y.A1)})
+		}
+	case *blsKzg.SRS:
+		for i := range s.Pk.G1 {
+			g1 = append(g1, headPoint{X: elements(&s.Pk.G1[i].X), Y: elements(&s.Pk.G1[i].Y)})
+		}
+		for i := range s.Vk.G2 {
+			p := &s.Vk.G2[i]
+			g2 = append(g2, headPoint{X: elements(&p.X.A0, &p.X.A1), Y: elements(&p.Y.A0, &p.Y.A1)})
+		}
+	case *bls381Kzg.SRS:
+		for i := range s.Pk.G1 {
+			g1 = append(g1, headPoint{X: elements(&s.Pk.G1[i].X), Y: elements(&s.Pk.G1[i].Y)})
+		}
+		for i := range s.Vk.G2 {
+			p := &s.Vk.G2[i]
+			g2 = append(g2, headPoint{X: elements(&p.X.A0, &p.X.A1), Y: elements(&p.Y.A0, &p.Y.A1)})
+		}
+	case *bwKzg.SRS:
+		// The G2 points of bw6-761 are over the base field.
+		for i := range s.Pk.G1 {
+			g1 = append(g1, headPoint{X: elements(&s.Pk.G1[i].X), Y: elements(&s.Pk.G1[i].Y)})
+		}
+		for i := range s.Vk.G2 {
+			g2 = append(g2, headPoint{X: elements(&s.Vk.G2[i].X), Y: elements(&s.Vk.G2[i].Y)})
+		}
+	default:
+		return nil, nil, fmt.Errorf("unsupported SRS type %T", srs)
+	}
+
+	return g1, g2, nil
+}
+
+func elements(components ...textElement) []headElement {
+	res := make([]headElement, len(components))
+	for i, c := range components {
+		res[i] = headElement{Dec: c.Text(10), Hex: hexElement(c)}
+	}
+
+	return res
+}
+
+// printHeadPoint prints the coordinates of the point, the components of an
+// extension field element suffixed by their index.
+func printHeadPoint(name string, p headPoint) {
+	fmt.Printf("%s:\n", name)
+	for _, coord := range []struct {
+		name     string
+		elements []headElement
+	}{{"x", p.X}, {"y", p.Y}} {
+		for i, e := range coord.elements {
+			label := coord.name
+			if len(coord.elements) > 1 {
+				label = fmt.Sprintf("%s.a%d", coord.name, i)
+			}
+			fmt.Printf("  %-4s = %s\n         %s\n", label, e.Dec, e.Hex)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+
+	"linea/aztec-srs-to-gnark/srsio"
+)
+
+// idSchemes are the identifiers id computes, by name.
+var idSchemes = map[string]string{
+	"gnark": "canonical digest of this tool, see hash",
+	"aztec": "SHA256 of the G1 points in the layout of the flat g1.dat of Aztec Ignition (bn254 only)",
+}
+
+// id prints the identifiers the ecosystem of a ceremony uses for its CRS,
+// computed from an SRS file, so a conversion can be matched against them.
+func id(args []string) error {
+	flags := flag.NewFlagSet("id", flag.ExitOnError)
+	scheme := flags.String("scheme", "", "identifier to compute, one of gnark, aztec (default: all the ones the curve supports)")
+	points := flags.Int("points", 0, "number of G1 points the aztec identifier covers, as downloaded by Barretenberg (0 - all of them)")
+	batchSize := flags.Int("batch-size", srsio.DefaultCompareBatch, "number of points decoded at once")
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s id [flags] <SRS file>\n", os.Args[0])
+		flags.PrintDefaults()
+		fmt.Println("Identifiers:")
+		for _, name := range []string{"gnark", "aztec"} {
+			fmt.Printf("\t%s: %s\n", name, idSchemes[name])
+		}
+	}
+	args = parseInterspersed(flags, args)
+
+	if len(args) < 1 {
+		flags.Usage()
+		return errUsage
+	}
+	if _, ok := idSchemes[*scheme]; *scheme != "" && !ok {
+		fmt.Printf("ERROR: unknown identifier '%s'\n", *scheme)
+		flags.Usage()
+		return errUsage
+	}
+
+	r, err := srsio.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	fmt.Printf("%s: %s, %d points\n", args[0], curveNames[r.Curve], r.NbPoints)
+
+	if *scheme == "" || *scheme == "gnark" {
+		digest, err := srsio.Digest(r, *batchSize)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("> gnark: %s\n", hex.EncodeToString(digest))
+	}
+
+	if *scheme == "aztec" || *scheme == "" && curveNames[r.Curve] == BN254Curve {
+		n := *points
+		if n == 0 {
+			n = r.NbPoints
+		}
+
+		digest, err := srsio.AztecDigest(r, n, *batchSize)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("> aztec (g1.dat, %d points): %s\n", n, hex.EncodeToString(digest))
+	}
+
+	return nil
+}
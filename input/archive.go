@@ -0,0 +1,191 @@
+package input
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+)
+
+// compressedTarballs are the extensions of the compressed tarballs, whose
+// entries can only be read in order by decompressing the whole archive.
+var compressedTarballs = []string{".tar.gz", ".tgz", ".tar.bz2", ".tar.xz", ".tar.zst"}
+
+// IsArchive tells if the file at path is an archive of setup files from its
+// extension, a compressed tarball included.
+func IsArchive(archivePath string) bool {
+	switch path.Ext(archivePath) {
+	case ".tar", ".zip":
+		return true
+	}
+
+	return slices.ContainsFunc(compressedTarballs, func(ext string) bool {
+		return strings.HasSuffix(archivePath, ext)
+	})
+}
+
+// Archive lists the regular files of a tar or zip archive of a setup
+// directory, at any depth, named by their base names and sorted by name. The
+// files are read from the archive through its fs.FS without extracting them,
+// and the archive stays open while they are. The entries of a zip archive may
+// be compressed, a tarball must not be.
+func Archive(archivePath string) ([]File, error) {
+	for _, ext := range compressedTarballs {
+		if strings.HasSuffix(archivePath, ext) {
+			return nil, fmt.Errorf("%s is a compressed tarball, whose files can't be read without decompressing it all: decompress it into a .tar first", archivePath)
+		}
+	}
+
+	var (
+		fsys  fs.FS
+		paths []string
+		err   error
+	)
+	switch path.Ext(archivePath) {
+	case ".zip":
+		fsys, paths, err = openZip(archivePath)
+	case ".tar":
+		fsys, paths, err = openTar(archivePath)
+	default:
+		return nil, fmt.Errorf("%s is neither a setup directory nor a .tar or .zip archive of one", archivePath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive %s: %w", archivePath, err)
+	}
+
+	files := make([]File, 0, len(paths))
+	for _, p := range paths {
+		info, err := fs.Stat(fsys, p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get file info of %s: %w", p, err)
+		}
+
+		files = append(files, New(path.Base(p), info.Size(), func() (io.ReadCloser, error) {
+			return fsys.Open(p)
+		}))
+	}
+
+	slices.SortFunc(files, func(a, b File) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	for i := 1; i < len(files); i++ {
+		if files[i].Name == files[i-1].Name {
+			return nil, fmt.Errorf("archive %s holds several files named %s", archivePath, files[i].Name)
+		}
+	}
+
+	return files, nil
+}
+
+// openZip opens the zip archive and lists its regular files.
+func openZip(archivePath string) (fs.FS, []string, error) {
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var paths []string
+	err = fs.WalkDir(r, ".", func(p string, entry fs.DirEntry, err error) error {
+		if err == nil && entry.Type().IsRegular() {
+			paths = append(paths, p)
+		}
+		return err
+	})
+	if err != nil {
+		r.Close()
+		return nil, nil, err
+	}
+
+	return r, paths, nil
+}
+
+// openTar indexes the regular files of the tarball, which are then read at
+// their offsets in it.
+func openTar(archivePath string) (fs.FS, []string, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	t := &tarFS{file: f, entries: make(map[string]tarEntry)}
+	var paths []string
+	tr := tar.NewReader(f)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		for key := range header.PAXRecords {
+			if strings.HasPrefix(key, "GNU.sparse.") {
+				f.Close()
+				return nil, nil, fmt.Errorf("%s is a sparse file", header.Name)
+			}
+		}
+
+		// The reader stops right after the header of the entry, at its content.
+		offset, err := f.Seek(0, io.SeekCurrent)
+		if err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+
+		name := path.Clean(strings.TrimPrefix(header.Name, "/"))
+		if !fs.ValidPath(name) {
+			f.Close()
+			return nil, nil, fmt.Errorf("invalid path %s", header.Name)
+		}
+		t.entries[name] = tarEntry{info: header.FileInfo(), offset: offset}
+		paths = append(paths, name)
+	}
+
+	return t, paths, nil
+}
+
+// tarFS is the file system of the regular files of an uncompressed tarball.
+type tarFS struct {
+	file    *os.File
+	entries map[string]tarEntry
+}
+
+type tarEntry struct {
+	info   fs.FileInfo
+	offset int64
+}
+
+func (t *tarFS) Open(name string) (fs.File, error) {
+	entry, ok := t.entries[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return &tarFile{SectionReader: io.NewSectionReader(t.file, entry.offset, entry.info.Size()), info: entry.info}, nil
+}
+
+// tarFile is a regular file of a tarball, read at its offset in the tarball
+// which stays open.
+type tarFile struct {
+	*io.SectionReader
+	info fs.FileInfo
+}
+
+func (f *tarFile) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+func (f *tarFile) Close() error {
+	return nil
+}
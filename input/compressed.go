@@ -0,0 +1,79 @@
+package input
+
+import (
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// decompressFunc wraps the reader of a compressed file with its decompression.
+type decompressFunc func(r io.Reader) (io.Reader, error)
+
+// decompressors decompress the setup files by extension, the mirrors of the
+// ceremonies often ship them compressed.
+var decompressors = map[string]decompressFunc{
+	".gz": func(r io.Reader) (io.Reader, error) {
+		return gzip.NewReader(r)
+	},
+	".bz2": func(r io.Reader) (io.Reader, error) {
+		return bzip2.NewReader(r), nil
+	},
+	".zst": func(r io.Reader) (io.Reader, error) {
+		// The decoder runs goroutines until it is closed
+		d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	},
+	".xz": func(r io.Reader) (io.Reader, error) {
+		return xz.NewReader(r)
+	},
+}
+
+// decompressing is the reader of a compressed file, closing the decompression
+// when it holds resources, then the file.
+type decompressing struct {
+	io.Reader
+	file io.Closer
+}
+
+func (d decompressing) Close() error {
+	if c, ok := d.Reader.(io.Closer); ok {
+		c.Close()
+	}
+
+	return d.file.Close()
+}
+
+// decompressed returns the setup file of the compressed file at path, which is
+// decompressed while it is read and named without the extension. Its size is
+// UnknownSize rather than counted by decompressing the whole file: the
+// importers read such files until their end or assume the layout of the
+// ceremony, and reject them when only the sizes tell the layouts apart.
+func decompressed(name, path string, decompress decompressFunc) File {
+	file := New(strings.TrimSuffix(name, filepath.Ext(name)), UnknownSize, func() (io.ReadCloser, error) {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+
+		r, err := decompress(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to decompress %s: %w", name, err)
+		}
+
+		return decompressing{r, f}, nil
+	})
+	file.Path = path
+
+	return file
+}
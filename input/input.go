@@ -0,0 +1,239 @@
+package input
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// UnknownSize is the size of a file whose length isn't known in advance.
+const UnknownSize = -1
+
+// File is a single setup file consumed by the importers.
+type File struct {
+	// Name is the base name of the file, importers use it for ordering and classification.
+	Name string
+	// Size is the length of the file in bytes or UnknownSize.
+	Size int64
+	// Path of the file on the local disk, empty for the files that aren't stored
+	// locally. It is the compressed file for the files decompressed while read.
+	Path string
+
+	open func() (io.ReadCloser, error)
+}
+
+// New creates a setup file read with the open function.
+func New(name string, size int64, open func() (io.ReadCloser, error)) File {
+	return File{Name: name, Size: size, open: open}
+}
+
+// Open opens the file for reading. For remote files it blocks until the file is available.
+func (f File) Open() (io.ReadCloser, error) {
+	return f.open()
+}
+
+// Dir lists the regular files of the directory, sorted by name. The symbolic
+// links to regular files are listed like the files. The gzip, bzip2, zstd and
+// xz compressed files are decompressed while they are read, and listed without
+// their extension. A tar or zip archive of the directory is listed by Archive,
+// and any other regular file in place of the directory is the only file
+// listed, for the ceremonies of a single setup file.
+func Dir(dir string) ([]File, error) {
+	if info, err := os.Stat(dir); err == nil && info.Mode().IsRegular() {
+		if IsArchive(dir) {
+			return Archive(dir)
+		}
+		return []File{local(filepath.Base(dir), dir, info.Size())}, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read setup directory '%s': %w", dir, err)
+	}
+
+	files := make([]File, 0, len(entries))
+	for _, entry := range entries {
+		info, err := Info(dir, entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get file info of %s: %w", entry.Name(), err)
+		}
+
+		if !info.Mode().IsRegular() {
+			continue
+		}
+
+		files = append(files, local(entry.Name(), filepath.Join(dir, entry.Name()), info.Size()))
+	}
+
+	return files, nil
+}
+
+// Paths lists the setup files of the paths, given in place of a setup
+// directory, like the files of a directory.
+func Paths(paths []string) ([]File, error) {
+	files := make([]File, 0, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get file info of %s: %w", path, err)
+		}
+		if !info.Mode().IsRegular() {
+			return nil, fmt.Errorf("%s isn't a regular file", path)
+		}
+
+		files = append(files, local(filepath.Base(path), path, info.Size()))
+	}
+	slices.SortFunc(files, func(a, b File) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return files, nil
+}
+
+// local returns the local file of the path and size, decompressed while it is
+// read when its extension is the one of a compressed file.
+func local(name, path string, size int64) File {
+	if decompress, ok := decompressors[filepath.Ext(name)]; ok {
+		return decompressed(name, path, decompress)
+	}
+
+	file := New(name, size, func() (io.ReadCloser, error) {
+		return os.Open(path)
+	})
+	file.Path = path
+
+	return file
+}
+
+// Stdin returns the setup file read from the standard input, of unknown size,
+// for the ceremonies made of a single file. It can only be opened once.
+func Stdin() File {
+	var opened atomic.Bool
+	return New("stdin", UnknownSize, func() (io.ReadCloser, error) {
+		if opened.Swap(true) {
+			return nil, errors.New("the standard input can only be read once")
+		}
+		return io.NopCloser(os.Stdin), nil
+	})
+}
+
+// Info returns the file info of the entry of the directory, following it when
+// it is a symbolic link.
+func Info(dir string, entry fs.DirEntry) (fs.FileInfo, error) {
+	if entry.Type()&fs.ModeSymlink == 0 {
+		return entry.Info()
+	}
+
+	return os.Stat(filepath.Join(dir, entry.Name()))
+}
+
+// Skip discards the next n bytes of r, seeking over them when r is a local file.
+func Skip(r io.Reader, n int64) error {
+	if s, ok := r.(io.Seeker); ok {
+		_, err := s.Seek(n, io.SeekCurrent)
+		return err
+	}
+
+	_, err := io.CopyN(io.Discard, r, n)
+	return err
+}
+
+// Digests computes the SHA256 of the setup files while the importers read
+// them, so that no file is read twice.
+type Digests struct {
+	mu   sync.Mutex
+	sums map[string]string
+}
+
+// NewDigests creates an empty set of digests.
+func NewDigests() *Digests {
+	return &Digests{sums: make(map[string]string)}
+}
+
+// Wrap returns the files hashing their content as it is read. The rest of a
+// file is read and hashed when it is closed, so the digest always covers
+// the whole file.
+func (d *Digests) Wrap(files []File) []File {
+	wrapped := make([]File, len(files))
+	for i, file := range files {
+		wrapped[i] = file
+		wrapped[i].open = func() (io.ReadCloser, error) {
+			r, err := file.open()
+			if err != nil {
+				return nil, err
+			}
+
+			return &hashingReader{ReadCloser: r, hash: sha256.New(), done: func(sum string) {
+				d.mu.Lock()
+				d.sums[file.Name] = sum
+				d.mu.Unlock()
+			}}, nil
+		}
+	}
+
+	return wrapped
+}
+
+// Complete hashes the files that haven't been read.
+func (d *Digests) Complete(files []File) error {
+	for _, file := range d.Wrap(files) {
+		d.mu.Lock()
+		_, ok := d.sums[file.Name]
+		d.mu.Unlock()
+		if ok {
+			continue
+		}
+
+		r, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", file.Name, err)
+		}
+		if err = r.Close(); err != nil {
+			return fmt.Errorf("failed to hash %s: %w", file.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Sums returns the hex encoded SHA256 of the files read so far by name.
+func (d *Digests) Sums() map[string]string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return maps.Clone(d.sums)
+}
+
+type hashingReader struct {
+	io.ReadCloser
+	hash hash.Hash
+	done func(sum string)
+}
+
+func (r *hashingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.hash.Write(p[:n])
+
+	return n, err
+}
+
+func (r *hashingReader) Close() error {
+	if _, err := io.Copy(r.hash, r.ReadCloser); err != nil {
+		r.ReadCloser.Close()
+		return err
+	}
+	r.done(hex.EncodeToString(r.hash.Sum(nil)))
+
+	return r.ReadCloser.Close()
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	blsKzg "github.com/consensys/gnark-crypto/ecc/bls12-377/kzg"
+	bls381Kzg "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	bwKzg "github.com/consensys/gnark-crypto/ecc/bw6-761/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/srsio"
+	"linea/aztec-srs-to-gnark/verify"
+)
+
+// inspect prints the layout and the verifying key of an SRS file together
+// with its first points. Only these parts of the file are read.
+func inspect(args []string) error {
+	flags := flag.NewFlagSet("inspect", flag.ExitOnError)
+	pointsN := flags.Int("points", 2, "number of first G1 points to print")
+	circuitSpec := flags.String("circuit", "", "check that the SRS fits the PLONK setup of a circuit, given as <curve>:<constraints>[:<public variables>]")
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s inspect [flags] <SRS file>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() < 1 {
+		flags.Usage()
+		return errUsage
+	}
+
+	var circuit *verify.Circuit
+	if *circuitSpec != "" {
+		var err error
+		if circuit, err = parseCircuit(*circuitSpec); err != nil {
+			return err
+		}
+	}
+
+	r, err := srsio.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	fmt.Printf("Format: %s\n", r.Format)
+	if r.Prefix != 0 {
+		fmt.Printf("        aligned, the memdump follows a prefix of %d bytes\n", r.Prefix)
+	}
+	fmt.Printf("Curve:  %s\n", curveNames[r.Curve])
+	fmt.Printf("Size:   %d bytes\n", r.Size)
+	fmt.Printf("Points: %d (max degree %d)\n", r.NbPoints, r.NbPoints-1)
+
+	srs, err := r.Range(0, min(max(*pointsN, 0), r.NbPoints))
+	if err != nil {
+		return err
+	}
+
+	printSRS(srs)
+
+	if circuit != nil {
+		if err = verify.Compatibility(r.Curve, r.NbPoints, circuit); err != nil {
+			return fmt.Errorf("incompatible circuit: %w", err)
+		}
+		fmt.Printf("Compatible with the circuit: it needs %d of the %d points\n",
+			verify.RequiredPoints(circuit.NbConstraints, circuit.NbPublicVariables), r.NbPoints)
+	}
+
+	return nil
+}
+
+// parseCircuit parses the sizes of a circuit given as
+// <curve>:<constraints>[:<public variables>].
+func parseCircuit(spec string) (*verify.Circuit, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("invalid circuit %q, expected <curve>:<constraints>[:<public variables>]", spec)
+	}
+
+	circuit := new(verify.Circuit)
+	found := false
+	for id, name := range curveNames {
+		if name == CurveName(parts[0]) {
+			circuit.Curve, found = id, true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("unsupported curve %s of the circuit", parts[0])
+	}
+
+	var err error
+	if circuit.NbConstraints, err = strconv.Atoi(parts[1]); err != nil || circuit.NbConstraints < 1 {
+		return nil, fmt.Errorf("invalid number of constraints %q", parts[1])
+	}
+	if len(parts) == 3 {
+		if circuit.NbPublicVariables, err = strconv.Atoi(parts[2]); err != nil || circuit.NbPublicVariables < 0 {
+			return nil, fmt.Errorf("invalid number of public variables %q", parts[2])
+		}
+	}
+
+	return circuit, nil
+}
+
+// printSRS prints the verifying key and the G1 points of the SRS.
+func printSRS(srs kzg.SRS) {
+	switch s := srs.(type) {
+	case *bnKzg.SRS:
+		fmt.Printf("> Vk.G1:    %s\n> Vk.G2[0]: %s\n> Vk.G2[1]: %s\n", s.Vk.G1.String(), s.Vk.G2[0].String(), s.Vk.G2[1].String())
+		for i := range s.Pk.G1 {
+			fmt.Printf("> G1[%d]: %s\n", i, s.Pk.G1[i].String())
+		}
+	case *blsKzg.SRS:
+		fmt.Printf("> Vk.G1:    %s\n> Vk.G2[0]: %s\n> Vk.G2[1]: %s\n", s.Vk.G1.String(), s.Vk.G2[0].String(), s.Vk.G2[1].String())
+		for i := range s.Pk.G1 {
+			fmt.Printf("> G1[%d]: %s\n", i, s.Pk.G1[i].String())
+		}
+	case *bls381Kzg.SRS:
+		fmt.Printf("> Vk.G1:    %s\n> Vk.G2[0]: %s\n> Vk.G2[1]: %s\n", s.Vk.G1.String(), s.Vk.G2[0].String(), s.Vk.G2[1].String())
+		for i := range s.Pk.G1 {
+			fmt.Printf("> G1[%d]: %s\n", i, s.Pk.G1[i].String())
+		}
+	case *bwKzg.SRS:
+		fmt.Printf("> Vk.G1:    %s\n> Vk.G2[0]: %s\n> Vk.G2[1]: %s\n", s.Vk.G1.String(), s.Vk.G2[0].String(), s.Vk.G2[1].String())
+		for i := range s.Pk.G1 {
+			fmt.Printf("> G1[%d]: %s\n", i, s.Pk.G1[i].String())
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/digest"
+	"linea/aztec-srs-to-gnark/encrypt"
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/journal"
+	"linea/aztec-srs-to-gnark/srsio"
+)
+
+// journalPath returns the path of the journal of the SRS file, the one of the
+// decrypted file for an encrypted one.
+func journalPath(path string) string {
+	for _, scheme := range []encrypt.Scheme{encrypt.SchemeAge, encrypt.SchemeGPG} {
+		path = strings.TrimSuffix(path, scheme.Ext())
+	}
+
+	return path + ".journal.jsonl"
+}
+
+// recordInputs records the run and the inputs of a conversion into srs in a
+// journal, with the running hashes of its points. When the conversion extends
+// the SRS file at extended, its points being the first n ones of srs, the
+// inputs of the last conversion of its journal come first, their running
+// hashes checked against the points copied from it.
+func recordInputs(srs kzg.SRS, run journal.Entry, inputs []journal.Entry, extended string, n int) (*journal.Journal, *journal.Chain, error) {
+	curve, err := journal.Curve(srs)
+	if err != nil {
+		return nil, nil, err
+	}
+	record, chain := new(journal.Journal), journal.NewChain(curve)
+	record.Record(run)
+
+	if extended != "" {
+		entries, err := journal.Read(journalPath(extended))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		carried := journal.LastRun(entries)
+		if len(carried) == 0 {
+			// Only the SRS itself is known of the inputs of its points
+			carried = []journal.Entry{{Kind: journal.KindExtended, File: filepath.Base(extended), Points: n}}
+		}
+		for _, e := range carried {
+			if e.Kind != journal.KindInput && e.Kind != journal.KindExtended {
+				continue
+			}
+			if e.End() > n {
+				return nil, nil, fmt.Errorf("the journal of %s records %s up to point %d, past its %d points", extended, e.File, e.End(), n)
+			}
+
+			recorded := e.OutputHash
+			if err = record.RecordPoints(e, chain, srs); err != nil {
+				return nil, nil, err
+			}
+			if recorded != "" && chain.Sum() != recorded {
+				return nil, nil, fmt.Errorf("the points of %s up to %d aren't the ones its journal records for %s", extended, e.End(), e.File)
+			}
+		}
+	}
+
+	for _, e := range inputs {
+		if err = record.RecordPoints(e, chain, srs); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return record, chain, nil
+}
+
+// appendJournal records the output written with the points of srs after its
+// inputs, and appends the entries to the journal of the output.
+func appendJournal(record *journal.Journal, chain *journal.Chain, srs kzg.SRS, output string, sums srsio.Checksums) error {
+	n, err := g1Len(srs)
+	if err != nil {
+		return err
+	}
+
+	e := journal.Entry{Kind: journal.KindOutput, File: filepath.Base(output), SHA256: sums.SHA256, Points: n}
+	if err = record.RecordPoints(e, chain, srs); err != nil {
+		return err
+	}
+
+	path := journalPath(output)
+	if err = record.Append(path); err != nil {
+		return err
+	}
+	fmt.Printf("Journal appended to %s\n", path)
+
+	return nil
+}
+
+// checkJournal checks the chain of the journal of an SRS file, and the
+// running hashes of the last conversion it records against the points of the
+// file, and optionally the hashes of its inputs against a setup directory.
+func checkJournal(args []string) error {
+	flags := flag.NewFlagSet("check-journal", flag.ExitOnError)
+	journalFile := flags.String("journal", "", "journal file (default: <SRS file>.journal.jsonl)")
+	setupDir := flags.String("setup", "", "setup directory whose files are checked against the hashes of the inputs of the last conversion")
+	batchSize := flags.Int("batch-size", srsio.DefaultCompareBatch, "number of points hashed at once")
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s check-journal [flags] <SRS file>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 || *batchSize < 1 {
+		flags.Usage()
+		return errUsage
+	}
+	path := flags.Arg(0)
+	if *journalFile == "" {
+		*journalFile = journalPath(path)
+	}
+
+	entries, err := journal.Read(*journalFile)
+	if err != nil {
+		return err
+	}
+	run := journal.LastRun(entries)
+	if len(run) == 0 {
+		return fmt.Errorf("%s records no conversion", *journalFile)
+	}
+	runs := 0
+	for _, e := range entries {
+		if e.Kind == journal.KindRun {
+			runs++
+		}
+	}
+	fmt.Printf("%s: the %d entries are chained, conversions recorded: %d\n", *journalFile, len(entries), runs)
+	fmt.Printf("Last conversion: %s %s from %s at %s\n", run[0].Protocol, run[0].Curve, run[0].File, run[0].Time.Format("2006-01-02 15:04:05 MST"))
+
+	r, err := srsio.Open(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	failures := 0
+	chain := journal.NewChain(r.Curve)
+	for _, e := range run[1:] {
+		if e.Kind == journal.KindOutput && e.Points != r.NbPoints {
+			fmt.Printf("MISMATCH: the journal records %d points for %s, the SRS holds %d\n", e.Points, e.File, r.NbPoints)
+			failures++
+			break
+		}
+		if e.End() > r.NbPoints {
+			fmt.Printf("MISMATCH: the journal records %s up to point %d, the SRS holds %d points\n", e.File, e.End(), r.NbPoints)
+			failures++
+			break
+		}
+
+		for chain.End < e.End() {
+			part, err := r.Range(chain.End, min(chain.End+*batchSize, e.End()))
+			if err == nil {
+				err = chain.Extend(part)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if sum := chain.Sum(); sum != e.OutputHash {
+			fmt.Printf("MISMATCH: the points up to %d aren't the ones the journal records for %s\n", e.End(), e.File)
+			failures++
+			break
+		}
+		if e.Kind != journal.KindOutput {
+			continue
+		}
+
+		if e.SHA256 != "" && !strings.HasSuffix(path, encrypt.SchemeAge.Ext()) && !strings.HasSuffix(path, encrypt.SchemeGPG.Ext()) {
+			sum, _, err := digest.SHA256.File(path)
+			if err != nil {
+				return err
+			}
+			if sum != e.SHA256 {
+				fmt.Printf("MISMATCH: the SHA256 of %s is %s, the journal records %s\n", path, sum, e.SHA256)
+				failures++
+			}
+		}
+	}
+	if failures == 0 {
+		fmt.Printf("The %d points of %s are the ones the journal records for its %d inputs\n", r.NbPoints, path, countInputs(run))
+	}
+
+	if *setupDir != "" {
+		failures += checkJournalInputs(run, *setupDir)
+	}
+
+	if failures != 0 {
+		return fmt.Errorf("the journal doesn't match: %d failures", failures)
+	}
+
+	return nil
+}
+
+// countInputs returns the number of inputs and extended SRS of the entries.
+func countInputs(entries []journal.Entry) int {
+	n := 0
+	for _, e := range entries {
+		if e.Kind == journal.KindInput || e.Kind == journal.KindExtended {
+			n++
+		}
+	}
+
+	return n
+}
+
+// checkJournalInputs checks the SHA256 of the files of the setup directory
+// against the ones of the inputs of the entries, returning the number of
+// mismatches.
+func checkJournalInputs(entries []journal.Entry, dir string) int {
+	files, err := input.Dir(dir)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	byName := make(map[string]input.File, len(files))
+	for _, file := range files {
+		byName[file.Name] = file
+	}
+
+	failures, checked := 0, 0
+	for _, e := range entries {
+		if e.Kind != journal.KindInput || e.SHA256 == "" {
+			continue
+		}
+		file, ok := byName[e.File]
+		if !ok {
+			fmt.Printf("MISSING: %s isn't in %s\n", e.File, dir)
+			failures++
+			continue
+		}
+
+		r, err := file.Open()
+		if err != nil {
+			fmt.Println(err)
+			failures++
+			continue
+		}
+		sum, _, err := digest.SHA256.Reader(r)
+		r.Close()
+		if err != nil {
+			fmt.Printf("%s: %v\n", e.File, err)
+			failures++
+			continue
+		}
+		if sum != e.SHA256 {
+			fmt.Printf("MISMATCH: the SHA256 of %s is %s, the journal records %s\n", e.File, sum, e.SHA256)
+			failures++
+			continue
+		}
+		checked++
+	}
+	if failures == 0 {
+		fmt.Printf("The %d inputs in %s are the files the journal records\n", checked, dir)
+	}
+
+	return failures
+}
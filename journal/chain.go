@@ -0,0 +1,129 @@
+package journal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	blsKzg "github.com/consensys/gnark-crypto/ecc/bls12-377/kzg"
+	bls381Kzg "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	bwKzg "github.com/consensys/gnark-crypto/ecc/bw6-761/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+)
+
+// chainDomain separates the running output hashes from other hashes.
+const chainDomain = "gnark-mpc-kzg-srs/journal-chain/v1"
+
+// Chain is the running hash of the G1 points of an SRS, extended entry after
+// entry of the journal in the order of the points: it starts as the SHA256 of
+// the domain separator and of the name of the curve, and the points of each
+// entry replace it by the SHA256 of it and of their uncompressed encodings.
+// The points of an entry may be hashed in several batches.
+type Chain struct {
+	sum [sha256.Size]byte
+	h   hash.Hash
+	// End is the index after the last point hashed.
+	End     int
+	pending bool
+}
+
+// NewChain returns the chain of the SRS of the curve before any point.
+func NewChain(curve ecc.ID) *Chain {
+	c := &Chain{h: sha256.New()}
+	c.h.Write([]byte(chainDomain))
+	c.h.Write([]byte{byte(len(curve.String()))})
+	c.h.Write([]byte(curve.String()))
+	c.h.Sum(c.sum[:0])
+
+	c.h.Reset()
+	c.h.Write(c.sum[:])
+
+	return c
+}
+
+// Extend hashes the G1 points of srs, which are the points of the SRS from
+// c.End on.
+func (c *Chain) Extend(srs kzg.SRS) error {
+	var n int
+	switch s := srs.(type) {
+	case *bnKzg.SRS:
+		for i := range s.Pk.G1 {
+			b := s.Pk.G1[i].RawBytes()
+			c.h.Write(b[:])
+		}
+		n = len(s.Pk.G1)
+	case *blsKzg.SRS:
+		for i := range s.Pk.G1 {
+			b := s.Pk.G1[i].RawBytes()
+			c.h.Write(b[:])
+		}
+		n = len(s.Pk.G1)
+	case *bls381Kzg.SRS:
+		for i := range s.Pk.G1 {
+			b := s.Pk.G1[i].RawBytes()
+			c.h.Write(b[:])
+		}
+		n = len(s.Pk.G1)
+	case *bwKzg.SRS:
+		for i := range s.Pk.G1 {
+			b := s.Pk.G1[i].RawBytes()
+			c.h.Write(b[:])
+		}
+		n = len(s.Pk.G1)
+	default:
+		return fmt.Errorf("unsupported SRS type %T", srs)
+	}
+
+	c.End += n
+	c.pending = c.pending || n != 0
+
+	return nil
+}
+
+// Sum ends the points of an entry and returns the running hash, hex encoded.
+// It is unchanged when no point was hashed since the previous entry.
+func (c *Chain) Sum() string {
+	if c.pending {
+		c.h.Sum(c.sum[:0])
+		c.h.Reset()
+		c.h.Write(c.sum[:])
+		c.pending = false
+	}
+
+	return hex.EncodeToString(c.sum[:])
+}
+
+// Curve returns the curve of the SRS.
+func Curve(srs kzg.SRS) (ecc.ID, error) {
+	switch srs.(type) {
+	case *bnKzg.SRS:
+		return ecc.BN254, nil
+	case *blsKzg.SRS:
+		return ecc.BLS12_377, nil
+	case *bls381Kzg.SRS:
+		return ecc.BLS12_381, nil
+	case *bwKzg.SRS:
+		return ecc.BW6_761, nil
+	}
+
+	return ecc.UNKNOWN, fmt.Errorf("unsupported SRS type %T", srs)
+}
+
+// Slice returns the G1 points [from, to) of the SRS, with its verifying key.
+func Slice(srs kzg.SRS, from, to int) (kzg.SRS, error) {
+	switch s := srs.(type) {
+	case *bnKzg.SRS:
+		return &bnKzg.SRS{Vk: s.Vk, Pk: bnKzg.ProvingKey{G1: s.Pk.G1[from:to]}}, nil
+	case *blsKzg.SRS:
+		return &blsKzg.SRS{Vk: s.Vk, Pk: blsKzg.ProvingKey{G1: s.Pk.G1[from:to]}}, nil
+	case *bls381Kzg.SRS:
+		return &bls381Kzg.SRS{Vk: s.Vk, Pk: bls381Kzg.ProvingKey{G1: s.Pk.G1[from:to]}}, nil
+	case *bwKzg.SRS:
+		return &bwKzg.SRS{Vk: s.Vk, Pk: bwKzg.ProvingKey{G1: s.Pk.G1[from:to]}}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported SRS type %T", srs)
+}
@@ -0,0 +1,260 @@
+// Package journal keeps the append-only journal of the inputs of the
+// conversions to an SRS file: every setup file, transcript or chunk processed
+// with its hash, and the running hash of the G1 points of the output up to its
+// last point. The entries are hash chained, so that the journal can't be
+// edited without breaking the chain, and the running hashes tie the bytes of
+// the inputs to the points they produced, for the audits of an SRS and the
+// extensions of it.
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/audit"
+)
+
+// Kinds of the entries.
+const (
+	// KindRun starts the entries of a conversion.
+	KindRun = "run"
+	// KindInput is a setup file, transcript or chunk the points of the output
+	// were read from.
+	KindInput = "input"
+	// KindExtended is an SRS extended by the conversion, without a journal.
+	KindExtended = "extended"
+	// KindOutput is the SRS file written, it ends the entries of a conversion.
+	KindOutput = "output"
+)
+
+// Entry is a record of the journal.
+type Entry struct {
+	Seq  int       `json:"seq"`
+	Time time.Time `json:"time"`
+	Kind string    `json:"kind"`
+	// Protocol and Curve are the setup converted by a run.
+	Protocol string `json:"protocol,omitempty"`
+	Curve    string `json:"curve,omitempty"`
+	// File is the setup directory of a run, the name of an input, or the name
+	// of the output.
+	File string `json:"file,omitempty"`
+	// SHA256 is the hash of the whole file, in hex.
+	SHA256 string `json:"sha256,omitempty"`
+	// Participant is the contributor of an input, e.g. the signer of a
+	// transcript.
+	Participant string `json:"participant,omitempty"`
+	// Offset is the index in the SRS of the first point read from the input.
+	Offset int `json:"offset,omitempty"`
+	Points int `json:"points,omitempty"`
+	// OutputHash is the running hash of the G1 points of the output up to the
+	// last point of the input, see Chain, or of all of them for the output.
+	OutputHash string `json:"output_hash,omitempty"`
+	// Prev is the hash of the previous entry of the journal.
+	Prev string `json:"prev,omitempty"`
+	// Hash is the SHA256 of the JSON encoding of the entry without it.
+	Hash string `json:"hash"`
+}
+
+// End returns the index after the last point of the entry.
+func (e Entry) End() int {
+	return e.Offset + e.Points
+}
+
+// hash returns the hash of the entry, its Hash set aside.
+func (e Entry) hash() (string, error) {
+	e.Hash = ""
+	encoded, err := json.Marshal(e)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode journal entry: %w", err)
+	}
+	sum := sha256.Sum256(encoded)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Journal is the entries of a conversion being recorded, chained on the ones
+// of the journal file when they are appended to it.
+type Journal struct {
+	entries []Entry
+}
+
+// Record timestamps the entry and records it.
+func (j *Journal) Record(e Entry) {
+	e.Time = time.Now().UTC()
+	j.entries = append(j.entries, e)
+}
+
+// RecordPoints extends the chain to the last point of the entry and records
+// it with the running hash, the points being the ones of srs.
+func (j *Journal) RecordPoints(e Entry, chain *Chain, srs kzg.SRS) error {
+	if e.End() > chain.End {
+		points, err := Slice(srs, chain.End, e.End())
+		if err != nil {
+			return err
+		}
+		if err = chain.Extend(points); err != nil {
+			return err
+		}
+	}
+	e.OutputHash = chain.Sum()
+	j.Record(e)
+
+	return nil
+}
+
+// Entries returns the entries recorded.
+func (j *Journal) Entries() []Entry {
+	return j.entries
+}
+
+// Append numbers and chains the entries recorded after the ones of the journal
+// file, whose chain is checked, and appends them to it, creating it if needed.
+func (j *Journal) Append(path string) error {
+	existing, err := Read(path)
+	if err != nil {
+		return err
+	}
+	seq, prev := 0, ""
+	if len(existing) != 0 {
+		last := existing[len(existing)-1]
+		seq, prev = last.Seq+1, last.Hash
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer f.Close()
+
+	var buf []byte
+	for _, e := range j.entries {
+		e.Seq, e.Prev = seq, prev
+		if e.Hash, err = e.hash(); err != nil {
+			return err
+		}
+		seq, prev = seq+1, e.Hash
+
+		line, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to encode journal entry: %w", err)
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+
+	// A single write keeps the entries of a conversion together.
+	if _, err = f.Write(buf); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+
+	return f.Close()
+}
+
+// Read reads the entries of the journal file and checks their chain. A
+// missing file has no entries.
+func Read(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	var entries []Entry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var e Entry
+		if err = json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("failed to decode line %d of journal %s: %w", line, path, err)
+		}
+		entries = append(entries, e)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	if err = Check(entries); err != nil {
+		return nil, fmt.Errorf("journal %s: %w", path, err)
+	}
+
+	return entries, nil
+}
+
+// Check checks the chain of the entries: their numbers follow each other, each
+// one holds the hash of the previous one, and its own hash.
+func Check(entries []Entry) error {
+	for i, e := range entries {
+		if i > 0 && e.Seq != entries[i-1].Seq+1 {
+			return fmt.Errorf("entry %d follows entry %d", e.Seq, entries[i-1].Seq)
+		}
+		prev := ""
+		if i > 0 {
+			prev = entries[i-1].Hash
+		}
+		if e.Prev != prev {
+			return fmt.Errorf("entry %d isn't chained to the previous one", e.Seq)
+		}
+		sum, err := e.hash()
+		if err != nil {
+			return err
+		}
+		if sum != e.Hash {
+			return fmt.Errorf("entry %d was modified: its hash is %s, not %s", e.Seq, sum, e.Hash)
+		}
+	}
+
+	return nil
+}
+
+// LastRun returns the entries of the last conversion of the journal, from its
+// run entry on.
+func LastRun(entries []Entry) []Entry {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Kind == KindRun {
+			return entries[i:]
+		}
+	}
+
+	return nil
+}
+
+// Inputs returns the entries of the inputs of an SRS of n G1 points, in the
+// order of their points: the setup files, transcripts and chunks of the audit
+// events which were used, e.g. for τG2 alone, with their SHA256 in sums, by name. The points past
+// the SRS, e.g. dropped with a missing chunk before them, aren't counted.
+func Inputs(events []audit.Event, sums map[string]string, n int) []Entry {
+	var entries []Entry
+	for _, event := range events {
+		if !strings.HasPrefix(event.Decision, "used") || event.Offset > n || (event.Offset == n && event.Points != 0) {
+			continue
+		}
+		entries = append(entries, Entry{
+			Kind:        KindInput,
+			File:        event.File,
+			SHA256:      sums[event.File],
+			Participant: event.Participant,
+			Offset:      event.Offset,
+			Points:      min(event.Points, n-event.Offset),
+		})
+	}
+	// The events are recorded as the files are read, in parallel
+	slices.SortStableFunc(entries, func(a, b Entry) int { return cmp.Compare(a.Offset, b.Offset) })
+
+	return entries
+}
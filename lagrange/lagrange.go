@@ -0,0 +1,159 @@
+package lagrange
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+
+	blsKzg "github.com/consensys/gnark-crypto/ecc/bls12-377/kzg"
+	bls381Kzg "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	bwKzg "github.com/consensys/gnark-crypto/ecc/bw6-761/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+)
+
+// MaxDomain returns the size of the largest domain an SRS of n points can be
+// converted to, the largest power of 2 not above n, or 0 when n is 0.
+func MaxDomain(n int) int {
+	if n <= 0 {
+		return 0
+	}
+
+	return 1 << (bits.Len(uint(n)) - 1)
+}
+
+// ToLagrange returns the SRS in Lagrange basis on the domain of the given size,
+// a power of 2: its G1 points are Lᵢ(τ)·G1 for the Lagrange polynomials Lᵢ of
+// the domain, computed from the first size powers of τ of srs, and it keeps
+// the verifying key of srs.
+func ToLagrange(srs kzg.SRS, size int) (kzg.SRS, error) {
+	if size <= 0 || bits.OnesCount(uint(size)) != 1 {
+		return nil, fmt.Errorf("domain size %d is not a power of 2", size)
+	}
+
+	var err error
+	switch s := srs.(type) {
+	case *bnKzg.SRS:
+		if err = checkSize(len(s.Pk.G1), size); err != nil {
+			return nil, err
+		}
+		res := &bnKzg.SRS{Vk: s.Vk}
+		if res.Pk.G1, err = bnKzg.ToLagrangeG1(s.Pk.G1[:size]); err != nil {
+			return nil, fmt.Errorf("failed to convert to Lagrange basis: %w", err)
+		}
+		return res, nil
+	case *blsKzg.SRS:
+		if err = checkSize(len(s.Pk.G1), size); err != nil {
+			return nil, err
+		}
+		res := &blsKzg.SRS{Vk: s.Vk}
+		if res.Pk.G1, err = blsKzg.ToLagrangeG1(s.Pk.G1[:size]); err != nil {
+			return nil, fmt.Errorf("failed to convert to Lagrange basis: %w", err)
+		}
+		return res, nil
+	case *bls381Kzg.SRS:
+		if err = checkSize(len(s.Pk.G1), size); err != nil {
+			return nil, err
+		}
+		res := &bls381Kzg.SRS{Vk: s.Vk}
+		if res.Pk.G1, err = bls381Kzg.ToLagrangeG1(s.Pk.G1[:size]); err != nil {
+			return nil, fmt.Errorf("failed to convert to Lagrange basis: %w", err)
+		}
+		return res, nil
+	case *bwKzg.SRS:
+		if err = checkSize(len(s.Pk.G1), size); err != nil {
+			return nil, err
+		}
+		res := &bwKzg.SRS{Vk: s.Vk}
+		if res.Pk.G1, err = bwKzg.ToLagrangeG1(s.Pk.G1[:size]); err != nil {
+			return nil, fmt.Errorf("failed to convert to Lagrange basis: %w", err)
+		}
+		return res, nil
+	default:
+		return nil, fmt.Errorf("unsupported SRS type %T", srs)
+	}
+}
+
+// ToMonomial returns the SRS in monomial basis of an SRS in Lagrange basis on
+// the domain of its number of points, a power of 2, reverting ToLagrange.
+func ToMonomial(srs kzg.SRS) (kzg.SRS, error) {
+	var err error
+	switch s := srs.(type) {
+	case *bnKzg.SRS:
+		if err = checkDomain(len(s.Pk.G1)); err != nil {
+			return nil, err
+		}
+		res := &bnKzg.SRS{Vk: s.Vk}
+		if res.Pk.G1, err = bnKzg.ToLagrangeG1(s.Pk.G1); err != nil {
+			return nil, fmt.Errorf("failed to convert from Lagrange basis: %w", err)
+		}
+		res.Pk.G1 = reverseScaled(res.Pk.G1)
+		return res, nil
+	case *blsKzg.SRS:
+		if err = checkDomain(len(s.Pk.G1)); err != nil {
+			return nil, err
+		}
+		res := &blsKzg.SRS{Vk: s.Vk}
+		if res.Pk.G1, err = blsKzg.ToLagrangeG1(s.Pk.G1); err != nil {
+			return nil, fmt.Errorf("failed to convert from Lagrange basis: %w", err)
+		}
+		res.Pk.G1 = reverseScaled(res.Pk.G1)
+		return res, nil
+	case *bls381Kzg.SRS:
+		if err = checkDomain(len(s.Pk.G1)); err != nil {
+			return nil, err
+		}
+		res := &bls381Kzg.SRS{Vk: s.Vk}
+		if res.Pk.G1, err = bls381Kzg.ToLagrangeG1(s.Pk.G1); err != nil {
+			return nil, fmt.Errorf("failed to convert from Lagrange basis: %w", err)
+		}
+		res.Pk.G1 = reverseScaled(res.Pk.G1)
+		return res, nil
+	case *bwKzg.SRS:
+		if err = checkDomain(len(s.Pk.G1)); err != nil {
+			return nil, err
+		}
+		res := &bwKzg.SRS{Vk: s.Vk}
+		if res.Pk.G1, err = bwKzg.ToLagrangeG1(s.Pk.G1); err != nil {
+			return nil, fmt.Errorf("failed to convert from Lagrange basis: %w", err)
+		}
+		res.Pk.G1 = reverseScaled(res.Pk.G1)
+		return res, nil
+	default:
+		return nil, fmt.Errorf("unsupported SRS type %T", srs)
+	}
+}
+
+// reverseScaled returns the points pⱼ = n·q₋ⱼ of the n points q, indices
+// modulo n. With q = ToLagrangeG1(Lᵢ(τ)·G1), qₖ = 1/n·Σᵢ ω⁻ⁱᵏ·Lᵢ(τ)·G1, so
+// pⱼ = Σᵢ ωⁱʲ·Lᵢ(τ)·G1 = τʲ·G1.
+func reverseScaled[T any, P interface {
+	*T
+	ScalarMultiplication(*T, *big.Int) *T
+}](q []T) []T {
+	n := len(q)
+	scale := big.NewInt(int64(n))
+
+	res := make([]T, n)
+	for j := range res {
+		P(&res[j]).ScalarMultiplication(&q[(n-j)%n], scale)
+	}
+
+	return res
+}
+
+func checkDomain(n int) error {
+	if n <= 0 || bits.OnesCount(uint(n)) != 1 {
+		return fmt.Errorf("the SRS has %d points, not a power of 2 domain size", n)
+	}
+
+	return nil
+}
+
+func checkSize(n, size int) error {
+	if n < size {
+		return fmt.Errorf("the SRS has %d points, less than the domain size %d", n, size)
+	}
+
+	return nil
+}
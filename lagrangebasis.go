@@ -0,0 +1,107 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/lagrange"
+	"linea/aztec-srs-to-gnark/srsio"
+)
+
+// lagrangeBasis converts an existing SRS file to the Lagrange basis of a
+// domain, or back to the monomial basis.
+func lagrangeBasis(args []string) error {
+	var opts config.Options
+
+	flags := flag.NewFlagSet("lagrange", flag.ExitOnError)
+	out := flags.String("o", "", "output SRS file (required)")
+	domain := flags.String("domain", "", "size of the domain, a power of 2 written n or 2^k (default: the largest one not above the number of points)")
+	monomial := flags.Bool("monomial", false, "convert an SRS in Lagrange basis, on the domain of its number of points, back to the monomial basis")
+	format := flags.String("format", "", fmt.Sprintf("output format, one of %v (default: the format of the input)", srsio.Formats))
+	flags.IntVar(&opts.Workers, "workers", 0, "number of CPU workers (0 - auto)")
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s lagrange [flags] <input SRS file>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	args = parseInterspersed(flags, args)
+
+	if len(args) < 1 || *out == "" {
+		flags.Usage()
+		return errUsage
+	}
+
+	r, err := srsio.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	outputFormat := r.Format
+	if *format != "" {
+		if outputFormat, err = srsio.ParseFormat(*format); err != nil {
+			return err
+		}
+	}
+
+	size := lagrange.MaxDomain(r.NbPoints)
+	if *domain != "" {
+		if size, err = parseDomain(*domain); err != nil {
+			return err
+		}
+	}
+
+	if *monomial && size != r.NbPoints {
+		return fmt.Errorf("an SRS in Lagrange basis on a domain of size %d must have %d points, not %d", size, size, r.NbPoints)
+	}
+	if size > r.NbPoints {
+		return fmt.Errorf("the SRS has %d points, less than the domain size %d", r.NbPoints, size)
+	}
+
+	srs, err := r.Range(0, size)
+	if err != nil {
+		return err
+	}
+
+	if *monomial {
+		fmt.Printf("Converting %s from the Lagrange basis on the domain of size %d\n", args[0], size)
+		srs, err = lagrange.ToMonomial(srs)
+	} else {
+		fmt.Printf("Converting the first %d points of %s to Lagrange basis\n", size, args[0])
+		srs, err = lagrange.ToLagrange(srs, size)
+	}
+	if err != nil {
+		return err
+	}
+
+	opts.IOParallelism = 1
+	opts = config.Tune(opts, "")
+
+	if _, err = writeOutput(*out, srs, outputFormat, opts); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// parseDomain parses a domain size written as n or 2^k.
+func parseDomain(s string) (int, error) {
+	if exp, ok := strings.CutPrefix(s, "2^"); ok {
+		k, err := strconv.Atoi(exp)
+		if err != nil || k < 0 || k > 62 {
+			return 0, fmt.Errorf("invalid domain size %q", s)
+		}
+		return 1 << k, nil
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 || n&(n-1) != 0 {
+		return 0, fmt.Errorf("domain size %q is not a power of 2", s)
+	}
+
+	return n, nil
+}
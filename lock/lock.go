@@ -0,0 +1,52 @@
+// Package lock provides advisory locks on files, so that concurrent
+// conversions into the same location fail instead of interleaving their writes.
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrLocked is returned when the lock is held by another process.
+var ErrLocked = errors.New("locked by another process")
+
+// File is an acquired lock.
+type File struct {
+	path string
+	file *os.File
+}
+
+// Acquire takes the lock of the lock file at path, created if needed, without
+// waiting for it. The lock file records the process holding it.
+func Acquire(path string) (*File, error) {
+	file, err := acquire(path)
+	if errors.Is(err, ErrLocked) {
+		if holder, _ := os.ReadFile(path); len(holder) != 0 {
+			return nil, fmt.Errorf("%s is %w, %s", path, ErrLocked, holder)
+		}
+		return nil, fmt.Errorf("%s is %w", path, ErrLocked)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+
+	if err = file.Truncate(0); err == nil {
+		_, err = fmt.Fprintf(file, "pid %d", os.Getpid())
+	}
+	if err != nil {
+		release(path, file)
+		return nil, fmt.Errorf("failed to write lock file %s: %w", path, err)
+	}
+
+	return &File{path: path, file: file}, nil
+}
+
+// Release removes the lock file and releases the lock.
+func (l *File) Release() error {
+	if err := release(l.path, l.file); err != nil {
+		return fmt.Errorf("failed to release the lock %s: %w", l.path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+//go:build !unix
+
+package lock
+
+import (
+	"errors"
+	"os"
+)
+
+// acquire creates the lock file, which must not exist. The lock file of a
+// process that didn't release its lock must be removed by hand.
+func acquire(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		return nil, ErrLocked
+	}
+
+	return file, err
+}
+
+func release(path string, file *os.File) error {
+	err := file.Close()
+	if removeErr := os.Remove(path); err == nil {
+		err = removeErr
+	}
+
+	return err
+}
@@ -0,0 +1,49 @@
+//go:build unix
+
+package lock
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// acquire locks the lock file with flock, which the system releases when the
+// process exits. As the lock file is removed on release, a lock taken on a
+// file removed in the meantime is taken again on the new one.
+func acquire(path string) (*os.File, error) {
+	for {
+		file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+			file.Close()
+			if errors.Is(err, syscall.EWOULDBLOCK) {
+				return nil, ErrLocked
+			}
+			return nil, err
+		}
+
+		locked, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		if current, err := os.Stat(path); err == nil && os.SameFile(locked, current) {
+			return file, nil
+		}
+		file.Close()
+	}
+}
+
+func release(path string, file *os.File) error {
+	// The file is removed while locked, so no other process locks it anymore.
+	err := os.Remove(path)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
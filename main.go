@@ -1,19 +1,34 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"sort"
 
+	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark-crypto/kzg"
 
 	"linea/aztec-srs-to-gnark/aleo"
 	"linea/aztec-srs-to-gnark/aztec"
 	"linea/aztec-srs-to-gnark/celo"
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/ethereum"
+	"linea/aztec-srs-to-gnark/fetch"
+	"linea/aztec-srs-to-gnark/halo2"
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/manifest"
+	"linea/aztec-srs-to-gnark/ppot"
+	"linea/aztec-srs-to-gnark/ptau"
+	"linea/aztec-srs-to-gnark/zcash"
+	"linea/aztec-srs-to-gnark/zksync"
 )
 
 // ConstructSetup is a func to construct Gnark compatible KZG SRS
-// from a directory containing setup files.
-type ConstructSetup func(setupDir string) (kzg.SRS, int, error)
+// from the setup files.
+type ConstructSetup func(files []input.File, opts config.Options) (kzg.SRS, int, error)
 
 type ProtocolName string
 type CurveName string
@@ -22,9 +37,23 @@ const (
 	AztecProtocol ProtocolName = "aztec"
 	AleoProtocol  ProtocolName = "aleo"
 	CeloProtocol  ProtocolName = "celo"
+	PPoTProtocol  ProtocolName = "ppot"
+	// The Ethereum KZG ceremony of EIP-4844
+	EthereumProtocol ProtocolName = "ethereum"
+	// The .ptau files of snarkjs
+	PtauProtocol ProtocolName = "ptau"
+	// The challenge and response files of powersoftau, the Sapling MPC of Zcash
+	ZcashProtocol ProtocolName = "zcash"
+	// The universal setup of Matter Labs, in the CRS format of bellman_ce
+	ZksyncProtocol ProtocolName = "zksync"
+	// The KZG params of halo2, the ParamsKZG of PSE
+	Halo2Protocol ProtocolName = "halo2"
+	// The flat CRS of Aztec Ignition, as Barretenberg downloads it
+	BarretenbergProtocol ProtocolName = "barretenberg"
 
 	BN254Curve    CurveName = "bn254"
 	BLS12377Curve CurveName = "bls12377"
+	BLS12381Curve CurveName = "bls12381"
 	BW6761Curve   CurveName = "bw6761"
 )
 
@@ -32,47 +61,162 @@ var supportedSetups = map[ProtocolName]map[CurveName]ConstructSetup{
 	AztecProtocol: {BN254Curve: aztec.TranslateBn254SRS},
 	AleoProtocol:  {BLS12377Curve: aleo.TranslateBls12377SRS},
 	CeloProtocol:  {BW6761Curve: celo.TranslateBw6761SRS},
+
+	EthereumProtocol:     {BLS12381Curve: ethereum.TranslateBls12381SRS},
+	PtauProtocol:         {BN254Curve: ptau.TranslateBn254SRS},
+	ZcashProtocol:        {BLS12381Curve: zcash.TranslateBls12381SRS},
+	ZksyncProtocol:       {BN254Curve: zksync.TranslateBn254SRS},
+	Halo2Protocol:        {BN254Curve: halo2.TranslateBn254SRS},
+	BarretenbergProtocol: {BN254Curve: aztec.TranslateFlatCRS},
+	PPoTProtocol:         {BN254Curve: ppot.TranslateBn254SRS},
+}
+
+var supportedManifests = map[ProtocolName]map[CurveName]manifest.Describer{
+	AztecProtocol: {BN254Curve: aztec.Describe},
+	AleoProtocol:  {BLS12377Curve: aleo.Describe},
+	CeloProtocol:  {BW6761Curve: celo.Describe},
+}
+
+// ExtractTauG2 is a func to read the verifying key of a setup from the G2
+// sections of its files alone.
+type ExtractTauG2 func(files []input.File) (kzg.SRS, error)
+
+var supportedG2Extractions = map[ProtocolName]struct {
+	curve   CurveName
+	extract ExtractTauG2
+}{
+	AztecProtocol: {BN254Curve, aztec.ExtractTauG2},
+	AleoProtocol:  {BLS12377Curve, aleo.ExtractTauG2},
+	CeloProtocol:  {BW6761Curve, celo.ExtractTauG2},
+
+	EthereumProtocol: {BLS12381Curve, ethereum.ExtractTauG2},
+	PtauProtocol:     {BN254Curve, ptau.ExtractTauG2},
+	ZcashProtocol:    {BLS12381Curve, zcash.ExtractTauG2},
+	PPoTProtocol:     {BN254Curve, ppot.ExtractTauG2},
+	ZksyncProtocol:   {BN254Curve, zksync.ExtractTauG2},
+	Halo2Protocol:    {BN254Curve, halo2.ExtractTauG2},
+}
+
+// Respond is a func to contribute to a challenge file of a ceremony and write
+// the response file, returning the hash of the challenge.
+type Respond func(challenge io.ReadSeeker, response io.Writer, opts config.Options) ([]byte, error)
+
+var supportedResponses = map[ProtocolName]map[CurveName]Respond{
+	PPoTProtocol:  {BN254Curve: ppot.Respond},
+	ZcashProtocol: {BLS12381Curve: zcash.Respond},
+}
+
+// ListSetupFiles is a func to list the published setup files of a ceremony.
+type ListSetupFiles func(ctx context.Context, sel fetch.Selection) ([]fetch.Download, error)
+
+var supportedDownloads = map[ProtocolName]map[CurveName]ListSetupFiles{
+	AztecProtocol: {BN254Curve: aztec.Downloads},
+	AleoProtocol:  {BLS12377Curve: aleo.Downloads},
+	PPoTProtocol:  {BN254Curve: ppot.Downloads},
+}
+
+// curveNames maps the curves of the SRS files to their names on the command line.
+var curveNames = map[ecc.ID]CurveName{
+	ecc.BN254:     BN254Curve,
+	ecc.BLS12_377: BLS12377Curve,
+	ecc.BLS12_381: BLS12381Curve,
+	ecc.BW6_761:   BW6761Curve,
+}
+
+// command is a subcommand of the tool. When it returns an error, the tool
+// prints it and exits with status 1, or with the status of an exitStatus.
+type command struct {
+	run         func(args []string) error
+	description string
+}
+
+// exitStatus ends the tool with the status once the command has reported why,
+// e.g. by printing its usage.
+type exitStatus int
+
+func (s exitStatus) Error() string {
+	return fmt.Sprintf("exit status %d", int(s))
+}
+
+// errUsage is returned by the commands after printing their usage, on missing
+// or invalid arguments, with the status of the flag errors.
+const errUsage = exitStatus(2)
+
+var commands = map[string]command{
+	"check-journal":       {checkJournal, "check the hash chain of the journal of an SRS file and its running hashes against the points of the file"},
+	"check-prefix":        {checkPrefix, "check that an SRS file is a prefix of another one"},
+	"clean":               {clean, "list and prune the conversion cache and the leftover spill files by age and size"},
+	"compare-remote":      {compareRemote, "check that an SRS file matches a published conversion listed in a signed registry"},
+	"contribute":          {contribute, "apply a fresh secret to an SRS file as a participant of an MPC ceremony"},
+	"coordinate":          {coordinate, "coordinate an MPC ceremony, verifying and sequencing the contributions"},
+	"convert":             {convert, "convert the setup files of a ceremony into a gnark SRS file"},
+	"convert-pair":        {convertPair, "convert the setups of the inner and outer curves of a recursion stack, e.g. aleo and celo, into a pair of SRS files"},
+	"convert-format":      {convertFormat, "write an SRS file in another format, in shards or in Lagrange basis"},
+	"cross-check":         {crossCheck, "convert the SRS of a curve from two independent sources and check that they agree point by point"},
+	"diff":                {diff, "compare the verifying keys and the G1 points of two SRS files"},
+	"download":            {download, "download the published setup files of a ceremony"},
+	"embed":               {embed, "write a small truncation of an SRS file with a Go file embedding it as a test fixture"},
+	"estimate":            {estimate, "predict the degree, output sizes, peak memory and duration of a conversion from the setup file headers"},
+	"export-vk-json":      {exportVkJSON, "print the verifying key of an SRS file as JSON with hex coordinates"},
+	"extract-g2":          {extractG2, "print τG2 of a setup read directly from the G2 section of its files"},
+	"gen-test-setup":      {genTestSetup, "write tiny setup files of a ceremony with known secrets, for testing the importers"},
+	"gen-test-srs":        {genTestSRS, "generate a small SRS with a known tau for tests"},
+	"hash":                {hash, "print the canonical digest of SRS files, independent of their format"},
+	"id":                  {id, "print the identifiers the ecosystem of a ceremony uses for its CRS, e.g. the Aztec g1.dat hash, of an SRS file"},
+	"head":                {head, "print the first G1 points and the G2 points of an SRS file in decimal and hexadecimal"},
+	"inspect":             {inspect, "print the layout and the verifying key of an SRS file"},
+	"lagrange":            {lagrangeBasis, "convert an SRS file to the Lagrange basis of a domain, or back to the monomial basis"},
+	"manifest":            {describeSetup, "describe the setup files of a ceremony directory, to pin the files a conversion uses"},
+	"merge":               {merge, "concatenate sharded SRS files, e.g. written by slice, into a single SRS file"},
+	"plonk-setup":         {plonkSetup, "run the PLONK setup of a compiled circuit on SRS converted from a setup, cached by its domain, and write its keys"},
+	"prove-test":          {proveTest, "commit to polynomials with an SRS file, open and verify them, with timings"},
+	"registry":            {registryCommand, "show the registry of known-good SRS in use or update it from a newer signed registry"},
+	"repair":              {repair, "recompute the derived parts of the verifying keys of SRS files and rewrite them"},
+	"respond":             {respond, "contribute to a challenge file of a powersoftau ceremony, ppot or zcash, and write the response file"},
+	"serve":               {serve, "serve the SRS files of a directory over HTTP"},
+	"slice":               {slice, "extract a range of the powers of an SRS file with its verifying key"},
+	"stats":               {stats, "report the on-curve, subgroup, infinity and duplicate point counts of an SRS file"},
+	"verify-contribution": {verifyContribution, "check that an SRS file is a valid contribution on top of another one"},
+	"version-migrate":     {versionMigrate, "rewrite an SRS file written by an older gnark-crypto version in the current layout"},
 }
 
 func main() {
-	args := os.Args
-	if len(args) < 4 || args[1] == "-h" || args[1] == "--help" {
-		fmt.Printf("Usage: %s <protocol> <curve> <setup files directory>\n", args[0])
+	if len(os.Args) < 2 || os.Args[1] == "-h" || os.Args[1] == "-help" || os.Args[1] == "--help" {
+		usage()
 		return
 	}
 
-	translateFunc, ok := supportedSetups[ProtocolName(args[1])][CurveName(args[2])]
+	cmd, ok := commands[os.Args[1]]
+	args := os.Args[2:]
 	if !ok {
-		fmt.Println("ERROR: Unsupported protocol or curve, use one of:")
-
-		for protocol := range supportedSetups {
-			for curve := range supportedSetups[protocol] {
-				fmt.Printf("\t%s %s\n", protocol, curve)
-			}
-		}
-
-		return
+		// Without a command the arguments are the ones of convert, as before commands existed.
+		cmd.run, args = convert, os.Args[1:]
 	}
 
-	srs, pointsNum, err := translateFunc(args[3])
-	if err != nil {
-		fmt.Println(err)
-		return
+	if err := cmd.run(args); err != nil {
+		var status exitStatus
+		if errors.As(err, &status) {
+			os.Exit(int(status))
+		}
+		fmt.Printf("ERROR: %v\n", err)
+		os.Exit(1)
 	}
+}
 
-	resultFileName := fmt.Sprintf("kzg_srs_canonical_%d_%s_%s.memdump", pointsNum-1, args[2], args[1])
+func usage() {
+	fmt.Printf("Usage: %s <command> [flags] [arguments]\n\nCommands:\n", os.Args[0])
 
-	f, err := os.Create(resultFileName)
-	if err != nil {
-		fmt.Printf("Failed to create output SRS file: %v\n", err)
-		return
+	names := make([]string, 0, len(commands))
+	width := 0
+	for name := range commands {
+		names = append(names, name)
+		width = max(width, len(name))
 	}
+	sort.Strings(names)
 
-	err = srs.WriteDump(f)
-	if err != nil {
-		fmt.Printf("Failed to write SRS to file: %v\n", err)
-		return
+	for _, name := range names {
+		fmt.Printf("\t%-*s %s\n", width, name, commands[name].description)
 	}
 
-	fmt.Printf("\nSRS successfully created: %s\n", resultFileName)
+	fmt.Printf("\n'%s [flags] <protocol> <curve> <setup files directory>' is the same as convert.\n", os.Args[0])
 }
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/manifest"
+)
+
+// describeSetup writes a manifest of the setup files of a ceremony directory:
+// their names, sizes, hashes and detected sections. Passed to convert with
+// -manifest, it pins the files the conversion must use.
+func describeSetup(args []string) error {
+	flags := flag.NewFlagSet("manifest", flag.ExitOnError)
+	out := flags.String("o", "", "file to write the manifest to (default: the standard output)")
+	profile := flags.String("profile", "", profileUsage)
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s manifest [flags] <protocol> <curve> <setup files directory>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	args = parseInterspersed(flags, args)
+	if err := applyProfile(*profile); err != nil {
+		return err
+	}
+
+	if len(args) < 3 {
+		flags.Usage()
+		return errUsage
+	}
+
+	describe, ok := supportedManifests[ProtocolName(args[0])][CurveName(args[1])]
+	if !ok {
+		fmt.Println("ERROR: Unsupported protocol or curve, use one of:")
+
+		for protocol := range supportedManifests {
+			for curve := range supportedManifests[protocol] {
+				fmt.Printf("\t%s %s\n", protocol, curve)
+			}
+		}
+
+		return errUsage
+	}
+
+	files, err := input.Dir(args[2])
+	if err != nil {
+		return err
+	}
+
+	m, err := manifest.Describe(args[0], args[1], files, describe)
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		return m.Encode(os.Stdout)
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		return fmt.Errorf("failed to create manifest: %w", err)
+	}
+	defer f.Close()
+
+	if err = m.Encode(f); err != nil {
+		return err
+	}
+
+	var used, points int
+	for _, f := range m.Files {
+		if f.Kind != manifest.KindIgnored {
+			used++
+			points += f.Points
+		}
+	}
+	fmt.Printf("Manifest of %d setup files written to %s: %d used, %d G1 points\n", len(m.Files), *out, used, points)
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"linea/aztec-srs-to-gnark/input"
+)
+
+// KindIgnored is the kind of the files the importer of the setup skips.
+const KindIgnored = "ignored"
+
+// Section is a part of a setup file.
+type Section struct {
+	Name   string `json:"name"`
+	Offset int64  `json:"offset"`
+	Size   int64  `json:"size"`
+	// Points is the number of points of the section, if it holds points.
+	Points int `json:"points,omitempty"`
+}
+
+// File describes a setup file of a ceremony.
+type File struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	// Kind is what the importer uses the file for, e.g. "transcript", "g1" or
+	// "chunk", or KindIgnored.
+	Kind string `json:"kind"`
+	// Index is the number of the transcript or chunk in the ceremony.
+	Index *int `json:"index,omitempty"`
+	// Points is the number of powers of tau in G1 the file contributes.
+	Points   int       `json:"points"`
+	Sections []Section `json:"sections,omitempty"`
+}
+
+// Manifest describes the setup files of a ceremony.
+type Manifest struct {
+	Protocol string `json:"protocol"`
+	Curve    string `json:"curve"`
+	Files    []File `json:"files"`
+}
+
+// Describer detects the kinds and the sections of the setup files from their
+// names, sizes and headers, filling descs. The names and sizes of the
+// descriptions are already set.
+type Describer func(files []input.File, descs []File) error
+
+// Describe hashes the setup files and describes them with the describer.
+func Describe(protocol, curve string, files []input.File, describe Describer) (*Manifest, error) {
+	digests := input.NewDigests()
+	if err := digests.Complete(files); err != nil {
+		return nil, err
+	}
+	sums := digests.Sums()
+
+	descs, err := Detect(files, describe)
+	if err != nil {
+		return nil, err
+	}
+	for i := range descs {
+		descs[i].SHA256 = sums[descs[i].Name]
+	}
+
+	return &Manifest{Protocol: protocol, Curve: curve, Files: descs}, nil
+}
+
+// Detect describes the setup files with the describer without hashing them,
+// from their headers alone.
+func Detect(files []input.File, describe Describer) ([]File, error) {
+	descs := make([]File, len(files))
+	for i, file := range files {
+		descs[i] = File{Name: file.Name, Size: file.Size}
+	}
+	if err := describe(files, descs); err != nil {
+		return nil, err
+	}
+
+	return descs, nil
+}
+
+// Read reads a manifest written by Encode.
+func Read(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var m Manifest
+	if err = json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+
+	return &m, nil
+}
+
+// Encode writes the manifest as indented JSON.
+func (m *Manifest) Encode(w io.Writer) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	if _, err = w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	return nil
+}
+
+// Pin returns the files listed in the manifest and not ignored, in its order,
+// after checking their sizes. The content of the files is checked with Check
+// once they are read.
+func (m *Manifest) Pin(files []input.File) ([]input.File, error) {
+	byName := make(map[string]input.File, len(files))
+	for _, file := range files {
+		byName[file.Name] = file
+	}
+
+	var pinned []input.File
+	for _, desc := range m.Files {
+		if desc.Kind == KindIgnored {
+			continue
+		}
+
+		file, ok := byName[desc.Name]
+		if !ok {
+			return nil, fmt.Errorf("setup file %s of the manifest is missing", desc.Name)
+		}
+		if file.Size != input.UnknownSize && file.Size != desc.Size {
+			return nil, fmt.Errorf("setup file %s is %d bytes long, the manifest expects %d", desc.Name, file.Size, desc.Size)
+		}
+		pinned = append(pinned, file)
+	}
+
+	if len(pinned) == 0 {
+		return nil, fmt.Errorf("the manifest pins no setup file")
+	}
+
+	return pinned, nil
+}
+
+// Check checks the SHA256 of the pinned files, hex encoded by name as
+// computed by input.Digests, against the manifest.
+func (m *Manifest) Check(sums map[string]string) error {
+	for _, desc := range m.Files {
+		if desc.Kind == KindIgnored {
+			continue
+		}
+
+		sum, ok := sums[desc.Name]
+		if !ok {
+			return fmt.Errorf("setup file %s of the manifest wasn't read", desc.Name)
+		}
+		if sum != desc.SHA256 {
+			return fmt.Errorf("SHA256 of setup file %s is %s, the manifest expects %s", desc.Name, sum, desc.SHA256)
+		}
+	}
+
+	return nil
+}
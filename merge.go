@@ -0,0 +1,177 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"linea/aztec-srs-to-gnark/srsio"
+	"linea/aztec-srs-to-gnark/verify"
+)
+
+// shard is an SRS file merged with the others.
+type shard struct {
+	name string
+	r    *srsio.Reader
+	info srsio.SliceInfo
+	// sliced reports whether the file has a slice info.
+	sliced bool
+}
+
+// merge concatenates sharded SRS files, e.g. written by slice, back into a
+// single SRS file after checking that they are continuous.
+func merge(args []string) error {
+	flags := flag.NewFlagSet("merge", flag.ExitOnError)
+	out := flags.String("o", "", "output SRS file (required)")
+	samples := flags.Int("samples", 4, "number of random consecutive points of every shard checked to be consecutive powers of tau, besides the boundaries")
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s merge [flags] <SRS file>...\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	args = parseInterspersed(flags, args)
+
+	if len(args) < 1 || *out == "" {
+		flags.Usage()
+		return errUsage
+	}
+
+	shards := make([]shard, len(args))
+	for i, name := range args {
+		r, err := srsio.Open(name)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+
+		info, sliced, err := srsio.ReadSliceInfo(name)
+		if err != nil {
+			return err
+		}
+
+		shards[i] = shard{name: name, r: r, info: info, sliced: sliced}
+
+		// The encoded points are concatenated as is.
+		if first := shards[0]; r.Format != first.r.Format || r.Curve != first.r.Curve {
+			return fmt.Errorf("%s is a %s %s SRS but %s is a %s %s one, convert the shards to the same format first",
+				name, r.Format, r.Curve, first.name, first.r.Format, first.r.Curve)
+		}
+	}
+
+	info, err := orderShards(shards)
+	if err != nil {
+		return err
+	}
+
+	if err = checkContinuity(shards, *samples); err != nil {
+		return err
+	}
+
+	readers := make([]*srsio.Reader, len(shards))
+	for i := range shards {
+		readers[i] = shards[i].r
+	}
+
+	if _, err = writeOutputWith(*out, func(w io.Writer) error {
+		return srsio.Concat(w, readers)
+	}); err != nil {
+		return err
+	}
+
+	// The merged SRS is itself a slice unless it restores the whole source.
+	if info != nil && (info.From != 0 || info.To != info.SourcePoints) {
+		infoPath, err := srsio.WriteSliceInfo(*out, *info)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Points [%d, %d) of %s merged, slice info written to %s\n", info.From, info.To, info.Source, infoPath)
+	}
+
+	return nil
+}
+
+// orderShards sorts the shards by the ranges of their slice infos, when they
+// all have one, and checks that the ranges follow each other. It returns the
+// slice info of the merged SRS, nil when the shards keep the order given.
+func orderShards(shards []shard) (*srsio.SliceInfo, error) {
+	for _, s := range shards {
+		if !s.sliced {
+			fmt.Printf("WARNING: %s has no slice info, the shards are merged in the order given\n", s.name)
+			return nil, nil
+		}
+	}
+
+	sort.SliceStable(shards, func(i, j int) bool { return shards[i].info.From < shards[j].info.From })
+
+	merged := shards[0].info
+	merged.To = merged.From
+	for _, s := range shards {
+		fmt.Printf("%s: points [%d, %d) of %s\n", s.name, s.info.From, s.info.To, s.info.Source)
+
+		if s.info.SourcePoints != merged.SourcePoints || filepath.Base(s.info.Source) != filepath.Base(merged.Source) {
+			return nil, fmt.Errorf("%s is a slice of %s with %d points, not of %s with %d points", s.name, s.info.Source, s.info.SourcePoints, merged.Source, merged.SourcePoints)
+		}
+		if s.info.To-s.info.From != s.r.NbPoints {
+			return nil, fmt.Errorf("%s holds %d points, not the %d of its slice info", s.name, s.r.NbPoints, s.info.To-s.info.From)
+		}
+		if s.info.From != merged.To {
+			if s.info.From < merged.To {
+				return nil, fmt.Errorf("%s overlaps the previous shards, which end at point %d", s.name, merged.To)
+			}
+			return nil, fmt.Errorf("the points [%d, %d) are missing before %s", merged.To, s.info.From, s.name)
+		}
+		merged.To = s.info.To
+	}
+
+	return &merged, nil
+}
+
+// checkContinuity checks with pairings that the last point of every shard and
+// the first point of the next one, as well as random consecutive points of
+// every shard, are consecutive powers of tau.
+func checkContinuity(shards []shard, samples int) error {
+	for i, s := range shards {
+		if s.r.NbPoints == 0 {
+			return fmt.Errorf("%s holds no points", s.name)
+		}
+
+		if s.r.NbPoints > 1 {
+			for range samples {
+				j := rand.IntN(s.r.NbPoints - 1)
+				if err := checkAdjacent(s.r, j, s.r, j+1); err != nil {
+					return fmt.Errorf("%s: points %d and %d: %w", s.name, j, j+1, err)
+				}
+			}
+		}
+
+		if i > 0 {
+			prev := shards[i-1]
+			if err := checkAdjacent(prev.r, prev.r.NbPoints-1, s.r, 0); err != nil {
+				return fmt.Errorf("%s doesn't continue %s: %w", s.name, prev.name, err)
+			}
+		}
+	}
+
+	fmt.Printf("Shards checked to be continuous at their %d boundaries and %d sampled points each\n", len(shards)-1, samples)
+
+	return nil
+}
+
+// checkAdjacent checks that the point i of a and the point j of b are
+// consecutive powers of tau.
+func checkAdjacent(a *srsio.Reader, i int, b *srsio.Reader, j int) error {
+	prev, err := a.Range(i, i+1)
+	if err != nil {
+		return err
+	}
+	next, err := b.Range(j, j+1)
+	if err != nil {
+		return err
+	}
+
+	return verify.Adjacent(prev, next)
+}
@@ -0,0 +1,133 @@
+package mpc
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"unsafe"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	bls381Kzg "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/verify"
+)
+
+func contributeBls12381(srs *bls381Kzg.SRS, s *secret, opts config.Options) (Proof, error) {
+	if len(srs.Pk.G1) < 2 {
+		return Proof{}, errors.New("SRS has less than 2 G1 points")
+	}
+
+	prevTauG1 := srs.Pk.G1[1].Bytes()
+	prevTauG2 := srs.Vk.G2[1].Bytes()
+
+	tau := s.tau
+	p, err := s.alloc(unsafe.Sizeof(fr.Element{}))
+	if err != nil {
+		return Proof{}, err
+	}
+	tauFr := (*fr.Element)(p)
+	tauFr.SetBigInt(tau)
+
+	// G1[i] = τ'^i·G1[i]
+	err = batches(len(srs.Pk.G1), opts, func(from, to int) error {
+		var power fr.Element
+		defer power.SetZero()
+		power.Exp(*tauFr, big.NewInt(int64(from)))
+
+		var scalar big.Int
+		defer erase(&scalar)
+
+		points := make([]bls12381.G1Jac, to-from)
+		for i := range points {
+			points[i].FromAffine(&srs.Pk.G1[from+i])
+			points[i].ScalarMultiplication(&points[i], power.BigInt(&scalar))
+			power.Mul(&power, tauFr)
+		}
+		copy(srs.Pk.G1[from:to], bls12381.BatchJacobianToAffineG1(points))
+
+		return nil
+	})
+	if err != nil {
+		return Proof{}, err
+	}
+
+	srs.Vk.G2[1].ScalarMultiplication(&srs.Vk.G2[1], tau)
+	srs.Vk.Lines[1] = bls12381.PrecomputeLines(srs.Vk.G2[1])
+
+	_, _, gen1Aff, _ := bls12381.Generators()
+
+	var tauG1 bls12381.G1Affine
+	tauG1.ScalarMultiplication(&gen1Aff, tau)
+	tauG1Bytes := tauG1.Bytes()
+
+	h, err := bls12381.HashToG2(challenge(prevTauG1[:], prevTauG2[:], tauG1Bytes[:]), proofDST)
+	if err != nil {
+		return Proof{}, fmt.Errorf("failed to hash to G2: %w", err)
+	}
+
+	var tauH bls12381.G2Affine
+	tauH.ScalarMultiplication(&h, tau)
+	tauHBytes := tauH.Bytes()
+
+	return Proof{Curve: ecc.BLS12_381.String(), TauG1: tauG1Bytes[:], TauH: tauHBytes[:]}, nil
+}
+
+func verifyBls12381(prev, next *bls381Kzg.SRS, proof Proof, opts config.Options) error {
+	if proof.Curve != ecc.BLS12_381.String() {
+		return fmt.Errorf("proof is for curve %s, not %s", proof.Curve, ecc.BLS12_381)
+	}
+	if len(prev.Pk.G1) < 2 || len(next.Pk.G1) < 2 {
+		return errors.New("SRS has less than 2 G1 points")
+	}
+
+	var tauG1 bls12381.G1Affine
+	if _, err := tauG1.SetBytes(proof.TauG1); err != nil {
+		return fmt.Errorf("invalid proof τ'·G1: %w", err)
+	}
+	var tauH bls12381.G2Affine
+	if _, err := tauH.SetBytes(proof.TauH); err != nil {
+		return fmt.Errorf("invalid proof τ'·H: %w", err)
+	}
+	if tauG1.IsInfinity() {
+		return fmt.Errorf("%w: contribution secret is zero", verify.ErrFailed)
+	}
+
+	prevTauG1 := prev.Pk.G1[1].Bytes()
+	prevTauG2 := prev.Vk.G2[1].Bytes()
+	h, err := bls12381.HashToG2(challenge(prevTauG1[:], prevTauG2[:], proof.TauG1), proofDST)
+	if err != nil {
+		return fmt.Errorf("failed to hash to G2: %w", err)
+	}
+
+	_, _, gen1Aff, _ := bls12381.Generators()
+
+	checks := []struct {
+		name   string
+		a1, a2 bls12381.G1Affine
+		b1, b2 bls12381.G2Affine
+	}{
+		// e(τ'·G1, H) = e(G1, τ'·H)
+		{"proof of knowledge", tauG1, gen1Aff, h, tauH},
+		// e(ττ'·G1, H) = e(τ·G1, τ'·H)
+		{"τ·G1 ratio", next.Pk.G1[1], prev.Pk.G1[1], h, tauH},
+		// e(τ'·G1, τ·G2) = e(G1, τ'τ·G2)
+		{"τ·G2 ratio", tauG1, gen1Aff, prev.Vk.G2[1], next.Vk.G2[1]},
+	}
+	for _, c := range checks {
+		var neg bls12381.G1Affine
+		neg.Neg(&c.a2)
+
+		ok, err := bls12381.PairingCheck([]bls12381.G1Affine{c.a1, neg}, []bls12381.G2Affine{c.b1, c.b2})
+		if err != nil {
+			return fmt.Errorf("failed to compute pairing: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("%w: %s check failed", verify.ErrFailed, c.name)
+		}
+	}
+
+	return verify.SRS(next, opts)
+}
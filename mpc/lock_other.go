@@ -0,0 +1,11 @@
+//go:build !unix
+
+package mpc
+
+// lockedAlloc allocates size bytes on the heap, memory cannot be locked on
+// this platform.
+func lockedAlloc(size int) ([]byte, bool, error) {
+	return make([]byte, size), false, nil
+}
+
+func lockedFree([]byte, bool) {}
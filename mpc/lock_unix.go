@@ -0,0 +1,23 @@
+//go:build unix
+
+package mpc
+
+import "syscall"
+
+// lockedAlloc maps size bytes of anonymous memory and locks them into RAM,
+// reporting whether the lock succeeded.
+func lockedAlloc(size int) ([]byte, bool, error) {
+	mem, err := syscall.Mmap(-1, 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return mem, syscall.Mlock(mem) == nil, nil
+}
+
+func lockedFree(mem []byte, locked bool) {
+	if locked {
+		syscall.Munlock(mem)
+	}
+	syscall.Munmap(mem)
+}
@@ -0,0 +1,329 @@
+package mpc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377"
+	blsKzg "github.com/consensys/gnark-crypto/ecc/bls12-377/kzg"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381"
+	bls381Kzg "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761"
+	bwKzg "github.com/consensys/gnark-crypto/ecc/bw6-761/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/parallel"
+	"linea/aztec-srs-to-gnark/verify"
+)
+
+// proofDST is the domain separation tag of the hash to G2 of the proofs.
+var proofDST = []byte("GNARK-MPC-KZG-SRS-CONTRIBUTION-V1")
+
+// Proof proves the knowledge of the secret τ' of a contribution, which turned
+// an SRS of powers of τ into an SRS of powers of τ·τ'.
+type Proof struct {
+	Curve string `json:"curve"`
+	// TauG1 is τ'·G1, compressed.
+	TauG1 HexBytes `json:"tau_g1"`
+	// TauH is τ'·H, compressed, where H is hashed to G2 from the τ·G1 and τ·G2
+	// of the SRS before the contribution and from TauG1.
+	TauH HexBytes `json:"tau_h"`
+	// Beacon is set when the secret was derived from a random beacon.
+	Beacon *Beacon `json:"beacon,omitempty"`
+}
+
+// maxBeaconExp bounds the length of the hash chain of a beacon.
+const maxBeaconExp = 63
+
+// Beacon is a publicly verifiable random value, e.g. a future block hash,
+// whose hash chain gives the secret of the final contribution of a ceremony.
+type Beacon struct {
+	Value HexBytes `json:"value"`
+	// IterationsExp is the base 2 logarithm of the number of SHA256 iterations
+	// of the hash chain, which delays the knowledge of the secret.
+	IterationsExp int `json:"iterations_exp"`
+}
+
+// secret derives the secret scalar of the curve from the beacon: the last hash
+// of the chain is expanded to 64 bytes reduced modulo the order of the curve.
+func (b Beacon) secret(curve ecc.ID) (*big.Int, error) {
+	if b.IterationsExp < 0 || b.IterationsExp > maxBeaconExp {
+		return nil, fmt.Errorf("invalid beacon iterations exponent %d", b.IterationsExp)
+	}
+
+	h := sha256.Sum256(b.Value)
+	for i := uint64(1); i < uint64(1)<<b.IterationsExp; i++ {
+		h = sha256.Sum256(h[:])
+	}
+
+	lo := sha256.Sum256(append(h[:], 0))
+	hi := sha256.Sum256(append(h[:], 1))
+
+	wide := append(hi[:], lo[:]...)
+	tau := new(big.Int).SetBytes(wide)
+	clear(wide)
+	clear(h[:])
+	clear(lo[:])
+	clear(hi[:])
+	tau.Mod(tau, curve.ScalarField())
+	if tau.Sign() == 0 {
+		tau.SetInt64(1)
+	}
+
+	return tau, nil
+}
+
+// HexBytes are bytes encoded in hex in JSON documents.
+type HexBytes []byte
+
+func (b HexBytes) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(b)), nil
+}
+
+func (b *HexBytes) UnmarshalText(text []byte) error {
+	decoded, err := hex.DecodeString(string(text))
+	if err != nil {
+		return err
+	}
+	*b = decoded
+
+	return nil
+}
+
+// WriteProof writes the proof as a JSON document.
+func WriteProof(path string, proof Proof) error {
+	data, err := json.MarshalIndent(proof, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode contribution proof: %w", err)
+	}
+
+	if err = os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write contribution proof: %w", err)
+	}
+
+	return nil
+}
+
+// ReadProof reads a proof written by WriteProof.
+func ReadProof(path string) (Proof, error) {
+	var proof Proof
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return proof, fmt.Errorf("failed to read contribution proof: %w", err)
+	}
+
+	if err = json.Unmarshal(data, &proof); err != nil {
+		return proof, fmt.Errorf("failed to decode contribution proof: %w", err)
+	}
+
+	return proof, nil
+}
+
+// Contribute applies a fresh random secret τ' to the SRS in place, multiplying
+// its i-th G1 point and τ·G2 by τ'^i and τ', and returns the proof of the
+// contribution. The secret is kept in memory locked into RAM when possible and
+// wiped before returning, with the intermediate scalars derived from it.
+func Contribute(srs kzg.SRS, opts config.Options) (Proof, error) {
+	curve, err := curveOf(srs)
+	if err != nil {
+		return Proof{}, err
+	}
+
+	s, err := newSecret()
+	if err != nil {
+		return Proof{}, err
+	}
+	defer s.destroy()
+	if !s.locked {
+		fmt.Println("WARNING: failed to lock the secret into memory, it may be written to swap")
+	}
+
+	// τ' is uniform in [1, r).
+	if err = s.sample(curve.ScalarField()); err != nil {
+		return Proof{}, err
+	}
+
+	return contribute(srs, s, opts)
+}
+
+// ContributeBeacon applies the secret derived from the beacon to the SRS in
+// place, as the final deterministic contribution of a ceremony. Anyone can
+// recompute the secret, so the proof records the beacon.
+func ContributeBeacon(srs kzg.SRS, beacon Beacon, opts config.Options) (Proof, error) {
+	curve, err := curveOf(srs)
+	if err != nil {
+		return Proof{}, err
+	}
+
+	tau, err := beacon.secret(curve)
+	if err != nil {
+		return Proof{}, err
+	}
+	defer erase(tau)
+
+	s, err := newSecret()
+	if err != nil {
+		return Proof{}, err
+	}
+	defer s.destroy()
+	s.set(tau)
+
+	proof, err := contribute(srs, s, opts)
+	if err != nil {
+		return Proof{}, err
+	}
+	proof.Beacon = &beacon
+
+	return proof, nil
+}
+
+func contribute(srs kzg.SRS, s *secret, opts config.Options) (Proof, error) {
+	switch srs := srs.(type) {
+	case *bnKzg.SRS:
+		return contributeBn254(srs, s, opts)
+	case *blsKzg.SRS:
+		return contributeBls12377(srs, s, opts)
+	case *bls381Kzg.SRS:
+		return contributeBls12381(srs, s, opts)
+	case *bwKzg.SRS:
+		return contributeBw6761(srs, s, opts)
+	default:
+		return Proof{}, fmt.Errorf("unsupported SRS type %T", srs)
+	}
+}
+
+func curveOf(srs kzg.SRS) (ecc.ID, error) {
+	switch srs.(type) {
+	case *bnKzg.SRS:
+		return ecc.BN254, nil
+	case *blsKzg.SRS:
+		return ecc.BLS12_377, nil
+	case *bls381Kzg.SRS:
+		return ecc.BLS12_381, nil
+	case *bwKzg.SRS:
+		return ecc.BW6_761, nil
+	default:
+		return ecc.UNKNOWN, fmt.Errorf("unsupported SRS type %T", srs)
+	}
+}
+
+// erase overwrites the words of a secret scalar.
+func erase(x *big.Int) {
+	clear(x.Bits())
+	x.SetInt64(0)
+}
+
+// challenge is the message hashed to G2 into the H of a proof.
+func challenge(prevTauG1, prevTauG2, tauG1 []byte) []byte {
+	msg := make([]byte, 0, len(prevTauG1)+len(prevTauG2)+len(tauG1))
+	msg = append(msg, prevTauG1...)
+	msg = append(msg, prevTauG2...)
+
+	return append(msg, tauG1...)
+}
+
+// batches splits [0, n) into batches of opts.BatchSize processed in parallel.
+func batches(n int, opts config.Options, fn func(from, to int) error) error {
+	batchSize := opts.BatchSize
+	if batchSize < 1 {
+		batchSize = config.DefaultBatchSize
+	}
+
+	nBatches := (n + batchSize - 1) / batchSize
+
+	return parallel.Run(nBatches, opts.Workers, func(i int) error {
+		return fn(i*batchSize, min((i+1)*batchSize, n))
+	})
+}
+
+// VerifyContribution checks that next is a valid contribution on top of prev:
+// the proof proves the knowledge of a τ' binding the two SRS, τ·G1 and τ·G2 of
+// next are the ones of prev multiplied by τ', and next is an SRS of
+// consecutive powers of its τ. The τ' of a beacon contribution is recomputed
+// from the beacon. Only τ·G1 and τ·G2 of prev are used, so it
+// may hold only its first 2 points.
+func VerifyContribution(prev, next kzg.SRS, proof Proof, opts config.Options) error {
+	if proof.Beacon != nil {
+		curve, err := curveOf(next)
+		if err != nil {
+			return err
+		}
+
+		tau, err := proof.Beacon.secret(curve)
+		if err != nil {
+			return err
+		}
+
+		if err = checkBeacon(next, proof, tau); err != nil {
+			return err
+		}
+	}
+
+	switch p := prev.(type) {
+	case *bnKzg.SRS:
+		n, ok := next.(*bnKzg.SRS)
+		if !ok {
+			return fmt.Errorf("SRS curves differ")
+		}
+		return verifyBn254(p, n, proof, opts)
+	case *blsKzg.SRS:
+		n, ok := next.(*blsKzg.SRS)
+		if !ok {
+			return fmt.Errorf("SRS curves differ")
+		}
+		return verifyBls12377(p, n, proof, opts)
+	case *bls381Kzg.SRS:
+		n, ok := next.(*bls381Kzg.SRS)
+		if !ok {
+			return fmt.Errorf("SRS curves differ")
+		}
+		return verifyBls12381(p, n, proof, opts)
+	case *bwKzg.SRS:
+		n, ok := next.(*bwKzg.SRS)
+		if !ok {
+			return fmt.Errorf("SRS curves differ")
+		}
+		return verifyBw6761(p, n, proof, opts)
+	default:
+		return fmt.Errorf("unsupported SRS type %T", prev)
+	}
+}
+
+// checkBeacon checks that τ'·G1 of the proof is derived from its beacon.
+func checkBeacon(srs kzg.SRS, proof Proof, tau *big.Int) error {
+	var tauG1 []byte
+	switch srs.(type) {
+	case *bnKzg.SRS:
+		_, _, gen1Aff, _ := bn254.Generators()
+		b := gen1Aff.ScalarMultiplication(&gen1Aff, tau).Bytes()
+		tauG1 = b[:]
+	case *blsKzg.SRS:
+		_, _, gen1Aff, _ := bls12377.Generators()
+		b := gen1Aff.ScalarMultiplication(&gen1Aff, tau).Bytes()
+		tauG1 = b[:]
+	case *bls381Kzg.SRS:
+		_, _, gen1Aff, _ := bls12381.Generators()
+		b := gen1Aff.ScalarMultiplication(&gen1Aff, tau).Bytes()
+		tauG1 = b[:]
+	case *bwKzg.SRS:
+		_, _, gen1Aff, _ := bw6761.Generators()
+		b := gen1Aff.ScalarMultiplication(&gen1Aff, tau).Bytes()
+		tauG1 = b[:]
+	}
+
+	if !bytes.Equal(tauG1, proof.TauG1) {
+		return fmt.Errorf("%w: contribution secret isn't derived from the beacon", verify.ErrFailed)
+	}
+
+	return nil
+}
@@ -0,0 +1,235 @@
+package mpc_test
+
+import (
+	"bytes"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	blsKzg "github.com/consensys/gnark-crypto/ecc/bls12-377/kzg"
+	bls381Kzg "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	bwKzg "github.com/consensys/gnark-crypto/ecc/bw6-761/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/mpc"
+	"linea/aztec-srs-to-gnark/srsio"
+)
+
+// curves are the curves of the SRS a contribution can be applied to.
+var curves = []ecc.ID{ecc.BN254, ecc.BLS12_377, ecc.BLS12_381, ecc.BW6_761}
+
+// newSRS returns the SRS of 8 powers of τ on the curve.
+func newSRS(t *testing.T, curve ecc.ID, tau int64) kzg.SRS {
+	t.Helper()
+
+	var srs kzg.SRS
+	var err error
+	switch curve {
+	case ecc.BN254:
+		srs, err = bnKzg.NewSRS(8, big.NewInt(tau))
+	case ecc.BLS12_377:
+		srs, err = blsKzg.NewSRS(8, big.NewInt(tau))
+	case ecc.BLS12_381:
+		srs, err = bls381Kzg.NewSRS(8, big.NewInt(tau))
+	case ecc.BW6_761:
+		srs, err = bwKzg.NewSRS(8, big.NewInt(tau))
+	default:
+		t.Fatalf("unsupported curve %s", curve)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return srs
+}
+
+// encode returns the SRS in the canonical format, to compare SRS.
+func encode(t *testing.T, srs kzg.SRS) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := srsio.Write(&buf, srs, srsio.FormatCanonical, config.Options{}); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// TestContribute applies a contribution to the SRS of every curve and checks
+// it is verified on top of the SRS it was applied to.
+func TestContribute(t *testing.T) {
+	for _, curve := range curves {
+		t.Run(curve.String(), func(t *testing.T) {
+			prev := newSRS(t, curve, 42)
+			next := newSRS(t, curve, 42)
+
+			proof, err := mpc.Contribute(next, config.Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if proof.Curve != curve.String() {
+				t.Fatalf("proof is for curve %s, not %s", proof.Curve, curve)
+			}
+			if bytes.Equal(encode(t, next), encode(t, prev)) {
+				t.Fatal("the contribution didn't change the SRS")
+			}
+
+			if err = mpc.VerifyContribution(prev, next, proof, config.Options{}); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+// tamper adds the generator to the i-th G1 point of the SRS.
+func tamper(srs kzg.SRS, i int) {
+	switch srs := srs.(type) {
+	case *bnKzg.SRS:
+		srs.Pk.G1[i].Add(&srs.Pk.G1[i], &srs.Pk.G1[0])
+	case *blsKzg.SRS:
+		srs.Pk.G1[i].Add(&srs.Pk.G1[i], &srs.Pk.G1[0])
+	case *bls381Kzg.SRS:
+		srs.Pk.G1[i].Add(&srs.Pk.G1[i], &srs.Pk.G1[0])
+	case *bwKzg.SRS:
+		srs.Pk.G1[i].Add(&srs.Pk.G1[i], &srs.Pk.G1[0])
+	}
+}
+
+// TestVerifyContribution checks that a contribution is only verified on top
+// of the SRS it was applied to, with its own proof and untouched points.
+func TestVerifyContribution(t *testing.T) {
+	beacon := mpc.Beacon{Value: mpc.HexBytes("block hash"), IterationsExp: 2}
+
+	cases := []struct {
+		name string
+		// contribute applies a contribution to next and returns the SRS and
+		// the proof to verify on top of prev.
+		contribute func(t *testing.T, curve ecc.ID, prev, next kzg.SRS) (kzg.SRS, kzg.SRS, mpc.Proof, error)
+		valid      bool
+	}{
+		{"valid", func(t *testing.T, curve ecc.ID, prev, next kzg.SRS) (kzg.SRS, kzg.SRS, mpc.Proof, error) {
+			proof, err := mpc.Contribute(next, config.Options{})
+			return prev, next, proof, err
+		}, true},
+		{"wrong previous SRS", func(t *testing.T, curve ecc.ID, prev, next kzg.SRS) (kzg.SRS, kzg.SRS, mpc.Proof, error) {
+			proof, err := mpc.Contribute(next, config.Options{})
+			return newSRS(t, curve, 43), next, proof, err
+		}, false},
+		{"tampered point", func(t *testing.T, curve ecc.ID, prev, next kzg.SRS) (kzg.SRS, kzg.SRS, mpc.Proof, error) {
+			proof, err := mpc.Contribute(next, config.Options{})
+			tamper(next, 5)
+			return prev, next, proof, err
+		}, false},
+		{"tampered τ·G1", func(t *testing.T, curve ecc.ID, prev, next kzg.SRS) (kzg.SRS, kzg.SRS, mpc.Proof, error) {
+			proof, err := mpc.Contribute(next, config.Options{})
+			tamper(next, 1)
+			return prev, next, proof, err
+		}, false},
+		{"forged proof", func(t *testing.T, curve ecc.ID, prev, next kzg.SRS) (kzg.SRS, kzg.SRS, mpc.Proof, error) {
+			proof, err := mpc.Contribute(next, config.Options{})
+			if err != nil {
+				return nil, nil, proof, err
+			}
+			// τ'·H of another secret doesn't prove the knowledge of τ'
+			other, err := mpc.Contribute(newSRS(t, curve, 42), config.Options{})
+			proof.TauH = other.TauH
+			return prev, next, proof, err
+		}, false},
+		{"proof of another contribution", func(t *testing.T, curve ecc.ID, prev, next kzg.SRS) (kzg.SRS, kzg.SRS, mpc.Proof, error) {
+			if _, err := mpc.Contribute(next, config.Options{}); err != nil {
+				return nil, nil, mpc.Proof{}, err
+			}
+			proof, err := mpc.Contribute(newSRS(t, curve, 42), config.Options{})
+			return prev, next, proof, err
+		}, false},
+		{"beacon", func(t *testing.T, curve ecc.ID, prev, next kzg.SRS) (kzg.SRS, kzg.SRS, mpc.Proof, error) {
+			proof, err := mpc.ContributeBeacon(next, beacon, config.Options{})
+			return prev, next, proof, err
+		}, true},
+		{"secret not derived from the beacon", func(t *testing.T, curve ecc.ID, prev, next kzg.SRS) (kzg.SRS, kzg.SRS, mpc.Proof, error) {
+			proof, err := mpc.Contribute(next, config.Options{})
+			proof.Beacon = &beacon
+			return prev, next, proof, err
+		}, false},
+	}
+
+	for _, curve := range curves {
+		for _, c := range cases {
+			t.Run(curve.String()+"/"+c.name, func(t *testing.T) {
+				prev, next, proof, err := c.contribute(t, curve, newSRS(t, curve, 42), newSRS(t, curve, 42))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				err = mpc.VerifyContribution(prev, next, proof, config.Options{})
+				if c.valid && err != nil {
+					t.Fatal(err)
+				}
+				if !c.valid && err == nil {
+					t.Fatal("invalid contribution verified")
+				}
+			})
+		}
+	}
+}
+
+// TestContributeBeacon checks that the contribution of a beacon is the same
+// for everyone, is recorded in the proof and is verified from the proof file.
+func TestContributeBeacon(t *testing.T) {
+	beacon := mpc.Beacon{Value: mpc.HexBytes("block hash"), IterationsExp: 3}
+
+	for _, curve := range curves {
+		t.Run(curve.String(), func(t *testing.T) {
+			prev := newSRS(t, curve, 42)
+			next := newSRS(t, curve, 42)
+			proof, err := mpc.ContributeBeacon(next, beacon, config.Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			again := newSRS(t, curve, 42)
+			if _, err = mpc.ContributeBeacon(again, beacon, config.Options{}); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(encode(t, again), encode(t, next)) {
+				t.Fatal("the contributions of the same beacon differ")
+			}
+
+			other := newSRS(t, curve, 42)
+			if _, err = mpc.ContributeBeacon(other, mpc.Beacon{Value: mpc.HexBytes("other block hash"), IterationsExp: 3}, config.Options{}); err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Equal(encode(t, other), encode(t, next)) {
+				t.Fatal("the contributions of different beacons are the same")
+			}
+
+			path := filepath.Join(t.TempDir(), "proof.json")
+			if err = mpc.WriteProof(path, proof); err != nil {
+				t.Fatal(err)
+			}
+			read, err := mpc.ReadProof(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if read.Beacon == nil || !bytes.Equal(read.Beacon.Value, beacon.Value) || read.Beacon.IterationsExp != beacon.IterationsExp {
+				t.Fatalf("the proof records the beacon %+v, not %+v", read.Beacon, beacon)
+			}
+			if err = mpc.VerifyContribution(prev, next, read, config.Options{}); err != nil {
+				t.Fatal(err)
+			}
+
+			// The beacon is hashed with the number of iterations of the proof
+			read.Beacon.IterationsExp++
+			if err = mpc.VerifyContribution(prev, next, read, config.Options{}); err == nil {
+				t.Fatal("contribution verified with another number of iterations of the beacon")
+			}
+		})
+	}
+
+	if _, err := mpc.ContributeBeacon(newSRS(t, ecc.BN254, 42), mpc.Beacon{Value: beacon.Value, IterationsExp: 64}, config.Options{}); err == nil {
+		t.Fatal("beacon of 2^64 iterations accepted")
+	}
+}
@@ -0,0 +1,123 @@
+package mpc
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"unsafe"
+)
+
+// secretSize is the size of the memory of a secret: the sampled bytes, the
+// words of the scalars and the field elements derived from it.
+const secretSize = 1024
+
+// errSecretExhausted is returned when the values derived from a secret don't
+// fit in its memory.
+var errSecretExhausted = errors.New("secret memory exhausted")
+
+// freeSecret releases the memory of a secret, replaced by the tests to check
+// that the memory is wiped first.
+var freeSecret = lockedFree
+
+// secret is a contribution secret held in memory locked into RAM, so it is
+// never written to swap, and wiped by destroy. Only the values stored in this
+// memory and the intermediate scalars of the contribution, wiped after use,
+// are under control: the Go runtime and the curve arithmetic may still leave
+// copies on the goroutine stacks.
+type secret struct {
+	mem    []byte
+	off    uintptr
+	locked bool
+
+	// tau is the secret scalar, with its words in mem.
+	tau *big.Int
+}
+
+// newSecret allocates the memory of a secret, locked when the platform allows
+// it. A failure to lock is reported by the locked field, not as an error, as
+// the limits of locked memory are often low for unprivileged users.
+func newSecret() (*secret, error) {
+	mem, locked, err := lockedAlloc(secretSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate secret memory: %w", err)
+	}
+
+	s := &secret{mem: mem, locked: locked}
+	if s.tau, err = s.newInt(); err != nil {
+		s.destroy()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// alloc returns size bytes of the memory of the secret aligned on 8 bytes, or
+// errSecretExhausted when they don't fit.
+func (s *secret) alloc(size uintptr) (unsafe.Pointer, error) {
+	s.off = (s.off + 7) &^ 7
+	if s.off+size > uintptr(len(s.mem)) {
+		return nil, errSecretExhausted
+	}
+	p := unsafe.Pointer(&s.mem[s.off])
+	s.off += size
+
+	return p, nil
+}
+
+// newInt returns an integer whose words are in the memory of the secret, large
+// enough for the operations on 64 bytes values done here not to reallocate
+// them on the heap.
+func (s *secret) newInt() (*big.Int, error) {
+	const nWords = 16
+	p, err := s.alloc(nWords * unsafe.Sizeof(big.Word(0)))
+	if err != nil {
+		return nil, err
+	}
+	words := unsafe.Slice((*big.Word)(p), nWords)
+
+	return new(big.Int).SetBits(words[:0]), nil
+}
+
+// sample sets the secret to a uniform value in [1, r), reducing 64 random
+// bytes modulo r-1 for a negligible bias.
+func (s *secret) sample(r *big.Int) error {
+	p, err := s.alloc(64)
+	if err != nil {
+		return err
+	}
+	buf := unsafe.Slice((*byte)(p), 64)
+	if _, err = rand.Read(buf); err != nil {
+		return fmt.Errorf("failed to sample secret: %w", err)
+	}
+
+	raw, err := s.newInt()
+	if err != nil {
+		return err
+	}
+	q, err := s.newInt()
+	if err != nil {
+		return err
+	}
+	raw.SetBytes(buf)
+	clear(buf)
+
+	q.QuoRem(raw, new(big.Int).Sub(r, big.NewInt(1)), s.tau)
+	erase(raw)
+	erase(q)
+	s.tau.Add(s.tau, big.NewInt(1))
+
+	return nil
+}
+
+// set sets the secret to x.
+func (s *secret) set(x *big.Int) {
+	s.tau.Set(x)
+}
+
+// destroy wipes and releases the memory of the secret.
+func (s *secret) destroy() {
+	clear(s.mem)
+	freeSecret(s.mem, s.locked)
+	s.mem, s.tau = nil, nil
+}
@@ -0,0 +1,119 @@
+package mpc
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+
+	"linea/aztec-srs-to-gnark/config"
+)
+
+// recordFrees replaces freeSecret for the test, recording a copy of the
+// memory of every secret released.
+func recordFrees(t *testing.T) *[][]byte {
+	t.Helper()
+
+	var freed [][]byte
+	t.Cleanup(func() { freeSecret = lockedFree })
+	freeSecret = func(mem []byte, locked bool) {
+		freed = append(freed, bytes.Clone(mem))
+		lockedFree(mem, locked)
+	}
+
+	return &freed
+}
+
+func checkWiped(t *testing.T, freed [][]byte) {
+	t.Helper()
+
+	if len(freed) != 1 {
+		t.Fatalf("%d secrets released, expected 1", len(freed))
+	}
+	for i, b := range freed[0] {
+		if b != 0 {
+			t.Fatalf("secret memory released with a non-zero byte at %d", i)
+		}
+	}
+}
+
+func TestSecretDestroy(t *testing.T) {
+	freed := recordFrees(t)
+
+	s, err := newSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = s.sample(fr.Modulus()); err != nil {
+		t.Fatal(err)
+	}
+	if s.tau.Sign() <= 0 || s.tau.Cmp(fr.Modulus()) >= 0 {
+		t.Fatalf("secret %s out of [1, r)", s.tau)
+	}
+	if bytes.Count(s.mem, []byte{0}) == len(s.mem) {
+		t.Fatal("the secret isn't held in its memory")
+	}
+
+	s.destroy()
+	checkWiped(t, *freed)
+	if s.mem != nil || s.tau != nil {
+		t.Fatal("destroyed secret still references its memory")
+	}
+}
+
+func TestSecretExhausted(t *testing.T) {
+	recordFrees(t)
+
+	s, err := newSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.destroy()
+
+	if _, err = s.alloc(secretSize); !errors.Is(err, errSecretExhausted) {
+		t.Fatalf("allocating more than the secret memory: got %v, expected %v", err, errSecretExhausted)
+	}
+	for i := 0; ; i++ {
+		if _, err = s.newInt(); err != nil {
+			break
+		}
+		if i > secretSize {
+			t.Fatal("the secret memory is never exhausted")
+		}
+	}
+	if !errors.Is(err, errSecretExhausted) {
+		t.Fatalf("allocating in a full secret memory: got %v, expected %v", err, errSecretExhausted)
+	}
+}
+
+func TestContributeWipesSecret(t *testing.T) {
+	opts := config.Tune(config.Options{IOParallelism: 1}, "")
+
+	t.Run("success", func(t *testing.T) {
+		freed := recordFrees(t)
+
+		srs, err := bnKzg.NewSRS(8, big.NewInt(42))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = Contribute(srs, opts); err != nil {
+			t.Fatal(err)
+		}
+		checkWiped(t, *freed)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		freed := recordFrees(t)
+
+		// The secret is sampled before the SRS is found too short.
+		srs := &bnKzg.SRS{Pk: bnKzg.ProvingKey{G1: make([]bn254.G1Affine, 1)}}
+		if _, err := Contribute(srs, opts); err == nil {
+			t.Fatal("contribution to an SRS of a single point succeeded")
+		}
+		checkWiped(t, *freed)
+	})
+}
@@ -0,0 +1,16 @@
+//go:build !unix
+
+package offheap
+
+import "errors"
+
+// Supported reports whether off-heap allocation is available on this platform.
+const Supported = false
+
+func mmap(int, string) ([]byte, string, error) {
+	return nil, "", errors.New("off-heap allocation is not supported on this platform")
+}
+
+func munmap([]byte) error {
+	return nil
+}
@@ -0,0 +1,43 @@
+//go:build unix
+
+package offheap
+
+import (
+	"os"
+	"syscall"
+)
+
+// Supported reports whether off-heap allocation is available on this platform.
+const Supported = true
+
+// mmap maps size bytes of anonymous memory or, when dir is set, of a new
+// spill file created in dir.
+func mmap(size int, dir string) ([]byte, string, error) {
+	if dir == "" {
+		data, err := syscall.Mmap(-1, 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+		return data, "", err
+	}
+
+	file, err := os.CreateTemp(dir, SpillFilePattern)
+	if err != nil {
+		return nil, "", err
+	}
+	defer file.Close()
+
+	if err = file.Truncate(int64(size)); err != nil {
+		os.Remove(file.Name())
+		return nil, "", err
+	}
+
+	data, err := syscall.Mmap(int(file.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		os.Remove(file.Name())
+		return nil, "", err
+	}
+
+	return data, file.Name(), nil
+}
+
+func munmap(data []byte) error {
+	return syscall.Munmap(data)
+}
@@ -0,0 +1,68 @@
+package offheap
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"unsafe"
+
+	"linea/aztec-srs-to-gnark/config"
+)
+
+// SpillFilePattern is the name pattern of the files backing file-mapped slices.
+const SpillFilePattern = "srs-spill-*.tmp"
+
+// Mapping is the off-heap memory of a slice returned by Make.
+type Mapping struct {
+	once sync.Once
+	data []byte
+	// path of the backing spill file, empty for anonymous mappings
+	path string
+}
+
+// Release unmaps the slice and removes its spill file. The slice may not be
+// used afterwards; releasing it again does nothing.
+func (m *Mapping) Release() error {
+	var errs []error
+	m.once.Do(func() {
+		if err := munmap(m.data); err != nil {
+			errs = append(errs, err)
+		}
+
+		if m.path != "" {
+			if err := os.Remove(m.path); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		m.data = nil
+	})
+
+	return errors.Join(errs...)
+}
+
+// Make returns a zeroed slice of n elements. When opts.OffHeap is set the
+// memory is mapped outside the Go heap, so the garbage collector never scans
+// it; with opts.SpillDir the mapping is backed by a file in that directory
+// and the OS may page it out. T must not contain pointers, and the slice must
+// not be grown with append. The mapping is recorded in opts.Mappings and the
+// memory stays valid until it is released.
+func Make[T any](n int, opts config.Options) ([]T, error) {
+	if !opts.OffHeap || n == 0 {
+		return make([]T, n), nil
+	}
+	if opts.Mappings == nil {
+		return nil, errors.New("off-heap memory without mappings to release it")
+	}
+
+	var e T
+	size := int(unsafe.Sizeof(e)) * n
+
+	data, path, err := mmap(size, opts.SpillDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map %d bytes off-heap: %w", size, err)
+	}
+	opts.Mappings.Add(&Mapping{data: data, path: path})
+
+	return unsafe.Slice((*T)(unsafe.Pointer(unsafe.SliceData(data))), n), nil
+}
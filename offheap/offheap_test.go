@@ -0,0 +1,49 @@
+package offheap_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/offheap"
+)
+
+// TestRelease maps the slices of two conversions and checks that releasing the
+// mappings of one leaves the slices of the other usable.
+func TestRelease(t *testing.T) {
+	if !offheap.Supported {
+		t.Skip("off-heap allocation is not supported on this platform")
+	}
+
+	dir := t.TempDir()
+	first := config.Options{OffHeap: true, SpillDir: dir, Mappings: new(config.Mappings)}
+	second := config.Options{OffHeap: true, SpillDir: dir, Mappings: new(config.Mappings)}
+
+	a, err := offheap.Make[uint64](1024, first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a[1023] = 1
+	b, err := offheap.Make[uint64](1024, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = first.Mappings.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if paths, _ := filepath.Glob(filepath.Join(dir, offheap.SpillFilePattern)); len(paths) != 1 {
+		t.Fatalf("%d spill files left, not the one of the second conversion", len(paths))
+	}
+	b[1023] = 2
+	if b[1023] != 2 {
+		t.Fatal("the slice of the second conversion isn't usable")
+	}
+	if err = second.Mappings.Release(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = offheap.Make[uint64](1, config.Options{OffHeap: true}); err == nil {
+		t.Fatal("off-heap memory mapped without mappings to release it")
+	}
+}
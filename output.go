@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/srsio"
+)
+
+// writeOutput writes the SRS into a new file at path together with its
+// checksums manifest, and prints where they were written.
+func writeOutput(path string, srs kzg.SRS, format srsio.Format, opts config.Options) (srsio.Checksums, error) {
+	return writeOutputWith(path, func(w io.Writer) error {
+		return srsio.Write(w, srs, format, opts)
+	})
+}
+
+// writeOutputWith is writeOutput for an SRS already encoded by write.
+func writeOutputWith(path string, write func(w io.Writer) error) (srsio.Checksums, error) {
+	// The output may be a hard link to a cached output, which must not be overwritten.
+	os.Remove(path)
+	os.Remove(path + ".checksums")
+
+	f, err := os.Create(path)
+	if err != nil {
+		return srsio.Checksums{}, fmt.Errorf("failed to create output SRS file: %w", err)
+	}
+	defer f.Close()
+
+	hw := srsio.NewHashingWriter(f)
+	if err = write(hw); err != nil {
+		return srsio.Checksums{}, fmt.Errorf("failed to write SRS to file: %w", err)
+	}
+
+	if err = f.Close(); err != nil {
+		return srsio.Checksums{}, fmt.Errorf("failed to write SRS to file: %w", err)
+	}
+
+	sums := hw.Checksums()
+
+	manifestFileName, err := srsio.WriteManifest(path, sums)
+	if err != nil {
+		return srsio.Checksums{}, err
+	}
+
+	fmt.Printf("\nSRS written to %s\n", path)
+	fmt.Printf("> SHA256:  %s\n", sums.SHA256)
+	fmt.Printf("> BLAKE2b: %s\n", sums.BLAKE2b)
+	fmt.Printf("Checksums written to %s\n", manifestFileName)
+
+	return sums, nil
+}
@@ -0,0 +1,68 @@
+package parallel
+
+import "sync"
+
+// Group runs functions in goroutines with at most limit of them at the same
+// time and keeps the first returned error.
+type Group struct {
+	sem chan struct{}
+	wg  sync.WaitGroup
+
+	mu  sync.Mutex
+	err error
+}
+
+// NewGroup creates a group running at most limit functions at the same time.
+func NewGroup(limit int) *Group {
+	return &Group{sem: make(chan struct{}, max(limit, 1))}
+}
+
+// Go runs fn in a new goroutine, blocking while the limit is reached.
+func (g *Group) Go(fn func() error) {
+	g.sem <- struct{}{}
+	g.wg.Add(1)
+
+	go func() {
+		defer func() {
+			<-g.sem
+			g.wg.Done()
+		}()
+
+		if err := fn(); err != nil {
+			g.mu.Lock()
+			if g.err == nil {
+				g.err = err
+			}
+			g.mu.Unlock()
+		}
+	}()
+}
+
+// Err returns the first error returned by the functions run so far.
+func (g *Group) Err() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.err
+}
+
+// Wait blocks until all the functions return and returns the first error.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+
+	return g.Err()
+}
+
+// Run calls fn for every index in [0, n) with at most limit calls running at
+// the same time. After the first failure no new calls are started and the
+// error of the failed call is returned once the running ones finish.
+func Run(n, limit int, fn func(i int) error) error {
+	g := NewGroup(limit)
+	for i := 0; i < n && g.Err() == nil; i++ {
+		g.Go(func() error {
+			return fn(i)
+		})
+	}
+
+	return g.Wait()
+}
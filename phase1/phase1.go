@@ -0,0 +1,111 @@
+// Package phase1 packages the Groth16 phase 1 of the setups holding it, the
+// powers of τ in G1 and G2, the powers of τ multiplied by α and β in G1 and
+// βG2, as the Phase1 of the mpcsetup package of gnark, which bootstraps the
+// Groth16 phase 2 of a circuit.
+package phase1
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bw6-761"
+	"github.com/consensys/gnark-crypto/kzg"
+	bls381Mpc "github.com/consensys/gnark/backend/groth16/bls12-381/mpcsetup"
+	bnMpc "github.com/consensys/gnark/backend/groth16/bn254/mpcsetup"
+	bwMpc "github.com/consensys/gnark/backend/groth16/bw6-761/mpcsetup"
+)
+
+// SRS is a KZG SRS returned with the Groth16 phase 1 of its setup, the
+// mpcsetup Phase1 of the curve, written by its WriteTo method.
+type SRS struct {
+	kzg.SRS
+	Phase1 io.WriterTo
+}
+
+// Points are the points of the Groth16 phase 1 of a setup of N powers of τ in
+// G2: TauG1 holds at least 2N-1 powers of τ in G1, the ones past them being
+// left out, and AlphaTauG1 and BetaTauG1 the N first ones multiplied by α and
+// β.
+type Points[G1, G2 any] struct {
+	TauG1      []G1
+	TauG2      []G2
+	AlphaTauG1 []G1
+	BetaTauG1  []G1
+	BetaG2     G2
+}
+
+// check returns the number of powers of τ in G1 of the Phase1, 2N-1.
+func (p Points[G1, G2]) check() (int, error) {
+	n := len(p.TauG2)
+	switch {
+	case n < 2:
+		return 0, fmt.Errorf("the Groth16 phase 1 needs at least 2 powers of τ in G2, not %d", n)
+	case len(p.AlphaTauG1) != n || len(p.BetaTauG1) != n:
+		return 0, fmt.Errorf("%d α and %d β powers for %d powers of τ in G2", len(p.AlphaTauG1), len(p.BetaTauG1), n)
+	case len(p.TauG1) < 2*n-1:
+		return 0, fmt.Errorf("%d powers of τ in G1, the Groth16 phase 1 of %d powers of τ in G2 needs %d", len(p.TauG1), n, 2*n-1)
+	}
+
+	return 2*n - 1, nil
+}
+
+// hash returns the hash gnark computes for a Phase1: the SHA256 of its
+// encoding without its hash, which must be unset.
+func hash(p io.WriterTo) []byte {
+	h := sha256.New()
+	p.WriteTo(h) // hash.Hash never fails
+
+	return h.Sum(nil)
+}
+
+// Bn254 returns the Phase1 of the points. Its public keys, which only prove
+// the contributions of the ceremonies run with gnark, are left unset.
+func Bn254(p Points[bn254.G1Affine, bn254.G2Affine]) (*bnMpc.Phase1, error) {
+	n, err := p.check()
+	if err != nil {
+		return nil, err
+	}
+
+	phase1 := new(bnMpc.Phase1)
+	params := &phase1.Parameters
+	params.G1.Tau, params.G1.AlphaTau, params.G1.BetaTau = p.TauG1[:n], p.AlphaTauG1, p.BetaTauG1
+	params.G2.Tau, params.G2.Beta = p.TauG2, p.BetaG2
+	phase1.Hash = hash(phase1)
+
+	return phase1, nil
+}
+
+// Bls12381 returns the Phase1 of the points, see Bn254.
+func Bls12381(p Points[bls12381.G1Affine, bls12381.G2Affine]) (*bls381Mpc.Phase1, error) {
+	n, err := p.check()
+	if err != nil {
+		return nil, err
+	}
+
+	phase1 := new(bls381Mpc.Phase1)
+	params := &phase1.Parameters
+	params.G1.Tau, params.G1.AlphaTau, params.G1.BetaTau = p.TauG1[:n], p.AlphaTauG1, p.BetaTauG1
+	params.G2.Tau, params.G2.Beta = p.TauG2, p.BetaG2
+	phase1.Hash = hash(phase1)
+
+	return phase1, nil
+}
+
+// Bw6761 returns the Phase1 of the points, see Bn254.
+func Bw6761(p Points[bw6761.G1Affine, bw6761.G2Affine]) (*bwMpc.Phase1, error) {
+	n, err := p.check()
+	if err != nil {
+		return nil, err
+	}
+
+	phase1 := new(bwMpc.Phase1)
+	params := &phase1.Parameters
+	params.G1.Tau, params.G1.AlphaTau, params.G1.BetaTau = p.TauG1[:n], p.AlphaTauG1, p.BetaTauG1
+	params.G2.Tau, params.G2.Beta = p.TauG2, p.BetaG2
+	phase1.Hash = hash(phase1)
+
+	return phase1, nil
+}
@@ -0,0 +1,113 @@
+package phase1_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/kzg"
+	bls381Mpc "github.com/consensys/gnark/backend/groth16/bls12-381/mpcsetup"
+	bnMpc "github.com/consensys/gnark/backend/groth16/bn254/mpcsetup"
+	bwMpc "github.com/consensys/gnark/backend/groth16/bw6-761/mpcsetup"
+
+	"linea/aztec-srs-to-gnark/celo"
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/digest"
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/phase1"
+	"linea/aztec-srs-to-gnark/ppot"
+	"linea/aztec-srs-to-gnark/testsetup"
+	"linea/aztec-srs-to-gnark/zcash"
+)
+
+// ceremony is the Plumo ceremony with 2 points per chunk.
+var ceremony = celo.Ceremony{
+	ChunkG1PointsN: 2,
+	G1PointsN:      2*celo.TotalChunks - 1,
+	ChunksN:        celo.TotalChunks,
+	FullChunksN:    celo.ChunkHalfwayPoint,
+	Hash:           digest.BLAKE2b512,
+}
+
+// TestPhase1 exports the Groth16 phase 1 of the setups holding it and checks
+// that gnark reads it back and verifies a contribution made on top of it.
+func TestPhase1(t *testing.T) {
+	cases := []struct {
+		name      string
+		generate  func(dir string, secrets testsetup.Secrets) (kzg.SRS, error)
+		translate func(files []input.File, opts config.Options) (kzg.SRS, int, error)
+		// contribute reads the exported Phase1, contributes to it and
+		// verifies the contribution.
+		contribute func(exported []byte) error
+	}{
+		{"celo", func(dir string, secrets testsetup.Secrets) (kzg.SRS, error) {
+			return testsetup.Celo(dir, ceremony, true, secrets)
+		}, ceremony.Translate, func(exported []byte) error {
+			var current, next bwMpc.Phase1
+			if _, err := current.ReadFrom(bytes.NewReader(exported)); err != nil {
+				return err
+			}
+			if _, err := next.ReadFrom(bytes.NewReader(exported)); err != nil {
+				return err
+			}
+			next.Contribute()
+			return bwMpc.VerifyPhase1(&current, &next)
+		}},
+		{"ppot", func(dir string, secrets testsetup.Secrets) (kzg.SRS, error) {
+			return testsetup.PPoT(dir, 4, secrets)
+		}, ppot.TranslateBn254SRS, func(exported []byte) error {
+			var current, next bnMpc.Phase1
+			if _, err := current.ReadFrom(bytes.NewReader(exported)); err != nil {
+				return err
+			}
+			if _, err := next.ReadFrom(bytes.NewReader(exported)); err != nil {
+				return err
+			}
+			next.Contribute()
+			return bnMpc.VerifyPhase1(&current, &next)
+		}},
+		{"zcash", func(dir string, secrets testsetup.Secrets) (kzg.SRS, error) {
+			return testsetup.Zcash(dir, 4, true, secrets)
+		}, zcash.TranslateBls12381SRS, func(exported []byte) error {
+			var current, next bls381Mpc.Phase1
+			if _, err := current.ReadFrom(bytes.NewReader(exported)); err != nil {
+				return err
+			}
+			if _, err := next.ReadFrom(bytes.NewReader(exported)); err != nil {
+				return err
+			}
+			next.Contribute()
+			return bls381Mpc.VerifyPhase1(&current, &next)
+		}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if _, err := c.generate(dir, testsetup.DefaultSecrets()); err != nil {
+				t.Fatal(err)
+			}
+
+			files, err := input.Dir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			opts := config.Tune(config.Options{IOParallelism: 1, Phase1: true}, "")
+			srs, _, err := c.translate(files, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ext, ok := srs.(*phase1.SRS)
+			if !ok {
+				t.Fatalf("the importer returned a %T, not the Groth16 phase 1", srs)
+			}
+
+			var exported bytes.Buffer
+			if _, err = ext.Phase1.WriteTo(&exported); err != nil {
+				t.Fatal(err)
+			}
+			if err = c.contribute(exported.Bytes()); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
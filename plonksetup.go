@@ -0,0 +1,299 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/constraint"
+
+	"linea/aztec-srs-to-gnark/cache"
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/journal"
+	"linea/aztec-srs-to-gnark/lagrange"
+	"linea/aztec-srs-to-gnark/offheap"
+	"linea/aztec-srs-to-gnark/phase1"
+	"linea/aztec-srs-to-gnark/srsio"
+	"linea/aztec-srs-to-gnark/verify"
+)
+
+// plonkSetup runs the PLONK setup of gnark on a compiled constraint system,
+// plonk.Setup(ccs, srs, srsLagrange), with the two SRS it takes converted from
+// a setup: the SRS of the points the circuit needs and the SRS in Lagrange
+// basis on its domain. Both SRS files are written next to the proving and
+// verifying keys and cached by the setup files and the size of the circuit,
+// so the setup of another circuit of the same domain doesn't convert the setup
+// again.
+func plonkSetup(args []string) error {
+	var opts config.Options
+
+	flags := flag.NewFlagSet("plonk-setup", flag.ExitOnError)
+	format := flags.String("format", string(srsio.FormatMemDump), fmt.Sprintf("output format of both SRS files, one of %v", srsio.Formats))
+	outDir := flags.String("o", ".", "directory the SRS files and the keys are written to")
+	cacheDir := flags.String("cache-dir", "", "directory caching the SRS files by the hashes of the setup files, the domain of the circuit and the options")
+	flags.IntVar(&opts.Workers, "workers", 0, "number of CPU workers (0 - auto)")
+	flags.BoolVar(&opts.OffHeap, "offheap", offheap.Supported, "keep the G1 points outside the Go heap")
+	flags.BoolVar(&opts.Verify, "verify", false, "verify the points while parsing: subgroup membership and consecutive powers of tau")
+	validation := flags.String("validation", string(config.ValidationStrict), validationUsage)
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s plonk-setup [flags] <protocol> <setup files directory> <curve> <constraint system file>\n", os.Args[0])
+		fmt.Println("The constraint system is a PLONK one compiled by gnark with scs.NewBuilder and written with its WriteTo method.")
+		flags.PrintDefaults()
+	}
+	args = parseInterspersed(flags, args)
+
+	if len(args) < 4 {
+		flags.Usage()
+		return errUsage
+	}
+
+	outputFormat, err := srsio.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+	if opts.Validation, err = config.ParseValidation(*validation); err != nil {
+		return err
+	}
+
+	protocol, curve := ProtocolName(args[0]), CurveName(args[2])
+	curveID, ok := curveByName()[curve]
+	if !ok {
+		return fmt.Errorf("unsupported curve %s of the circuit", curve)
+	}
+
+	ccs, err := readConstraintSystem(args[3], curveID)
+	if err != nil {
+		return err
+	}
+	translateFunc, ok := supportedSetups[protocol][curve]
+	if !ok {
+		fmt.Printf("ERROR: the %s setup isn't on %s, use one of:\n", protocol, curve)
+
+		for protocol := range supportedSetups {
+			if _, ok := supportedSetups[protocol][curve]; ok {
+				fmt.Printf("\t%s\n", protocol)
+			}
+		}
+
+		return errUsage
+	}
+
+	required := verify.RequiredPoints(ccs.GetNbConstraints(), ccs.GetNbPublicVariables())
+	domain := required - 3
+	fmt.Printf("The circuit needs %d G1 points: a domain of %d, plus 3\n", required, domain)
+
+	keyPaths := keyFiles(*outDir, args[3])
+
+	names := []string{
+		fmt.Sprintf("kzg_srs_canonical_%d_%s_%s.%s", required-1, curve, protocol, outputFormat),
+		fmt.Sprintf("kzg_srs_lagrange_%d_%s_%s.%s", domain-1, curve, protocol, outputFormat),
+	}
+	paths := make([]string, len(names))
+	for i, name := range names {
+		paths[i] = filepath.Join(*outDir, name)
+	}
+
+	files, err := input.Dir(args[1])
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(*outDir, 0o755); err != nil {
+		return err
+	}
+
+	var (
+		outputs  *cache.Cache
+		cacheKey string
+	)
+	if *cacheDir != "" {
+		if outputs, err = cache.Open(*cacheDir); err != nil {
+			return err
+		}
+
+		params := []string{"command=plonk-setup", "protocol=" + string(protocol), "curve=" + string(curve), "format=" + string(outputFormat),
+			fmt.Sprintf("verify=%t", opts.Verify), fmt.Sprintf("points=%d", required)}
+		if opts.Lenient() {
+			params = append(params, "validation="+string(opts.Validation))
+		}
+		if cacheKey, err = outputs.Key(files, params...); err != nil {
+			return fmt.Errorf("failed to compute cache key: %w", err)
+		}
+
+		entryLock, err := outputs.Lock(cacheKey)
+		if err != nil {
+			return err
+		}
+		defer releaseLock(entryLock)
+
+		restored, ok, err := outputs.Restore(cacheKey, *outDir)
+		if err != nil {
+			return err
+		}
+		if ok {
+			fmt.Printf("SRS files restored from cache: %s\n", strings.Join(restored, ", "))
+
+			srs, err := readSRS(paths[0])
+			if err != nil {
+				return err
+			}
+			srsLagrange, err := readSRS(paths[1])
+			if err != nil {
+				return err
+			}
+
+			return writeKeys(keyPaths, ccs, srs, srsLagrange)
+		}
+	}
+
+	if err = validateSetup(protocol, curve, files, opts.Validation); err != nil {
+		return err
+	}
+
+	opts = config.Tune(opts, args[1])
+	opts.Skipped = new(config.Skips)
+	opts.Mappings = new(config.Mappings)
+	srs, _, err := translateFunc(files, opts)
+	defer func() {
+		if err := opts.Mappings.Release(); err != nil {
+			fmt.Printf("WARNING: failed to release off-heap memory: %v\n", err)
+		}
+	}()
+	skipped := opts.Skipped.List()
+	if len(skipped) != 0 {
+		printSkipped(skipped)
+	}
+	if err != nil {
+		return err
+	}
+	if len(skipped) != 0 {
+		return fmt.Errorf("%d parts of the setup were skipped, the SRS of a PLONK setup must hold all the points", len(skipped))
+	}
+	if ext, ok := srs.(*phase1.SRS); ok {
+		srs = ext.SRS
+	}
+
+	if err = verify.VerifyCompatibility(srs, verify.Circuit{Curve: curveID, NbConstraints: ccs.GetNbConstraints(), NbPublicVariables: ccs.GetNbPublicVariables()}); err != nil {
+		return fmt.Errorf("incompatible circuit: %w", err)
+	}
+
+	if srs, err = journal.Slice(srs, 0, required); err != nil {
+		return err
+	}
+	if _, err = writeOutput(paths[0], srs, outputFormat, opts); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nConverting the first %d points to Lagrange basis\n", domain)
+	srsLagrange, err := lagrange.ToLagrange(srs, domain)
+	if err != nil {
+		return err
+	}
+	if _, err = writeOutput(paths[1], srsLagrange, outputFormat, opts); err != nil {
+		return err
+	}
+
+	if outputs != nil {
+		cached := append(paths[:0:0], paths...)
+		for _, path := range paths {
+			cached = append(cached, path+".checksums")
+		}
+		if err = outputs.Store(cacheKey, cached...); err != nil {
+			fmt.Printf("WARNING: failed to cache the SRS files: %v\n", err)
+		}
+	}
+
+	return writeKeys(keyPaths, ccs, srs, srsLagrange)
+}
+
+// curveByName returns the curves by their names.
+func curveByName() map[CurveName]ecc.ID {
+	ids := make(map[CurveName]ecc.ID, len(curveNames))
+	for id, name := range curveNames {
+		ids[name] = id
+	}
+
+	return ids
+}
+
+// readConstraintSystem reads the PLONK constraint system compiled by gnark on
+// the curve from the file at path.
+func readConstraintSystem(path string, curve ecc.ID) (constraint.ConstraintSystem, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ccs := plonk.NewCS(curve)
+	if _, err = ccs.ReadFrom(bufio.NewReader(f)); err != nil {
+		return nil, fmt.Errorf("failed to read the constraint system %s, compiled on %s for PLONK: %w", path, curveNames[curve], err)
+	}
+
+	return ccs, nil
+}
+
+// readSRS reads all the points of the SRS file at path.
+func readSRS(path string) (kzg.SRS, error) {
+	r, err := srsio.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return r.Range(0, r.NbPoints)
+}
+
+// keyFiles returns the paths of the proving and verifying keys written into
+// dir for the constraint system file ccsPath, named after it.
+func keyFiles(dir, ccsPath string) [2]string {
+	name := strings.TrimSuffix(filepath.Base(ccsPath), filepath.Ext(ccsPath))
+	return [2]string{filepath.Join(dir, name+".pk"), filepath.Join(dir, name+".vk")}
+}
+
+// writeKeys runs the PLONK setup of the constraint system and writes its
+// proving and verifying keys to paths.
+func writeKeys(paths [2]string, ccs constraint.ConstraintSystem, srs, srsLagrange kzg.SRS) error {
+	fmt.Printf("\nRunning the PLONK setup of the circuit\n")
+	pk, vk, err := plonk.Setup(ccs, srs, srsLagrange)
+	if err != nil {
+		return fmt.Errorf("PLONK setup failed: %w", err)
+	}
+
+	for i, key := range []io.WriterTo{pk, vk} {
+		if err = writeKey(paths[i], key); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Proving key written to %s\n", paths[0])
+	fmt.Printf("Verifying key written to %s\n", paths[1])
+
+	return nil
+}
+
+// writeKey writes the key into a new file at path.
+func writeKey(path string, key io.WriterTo) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	if _, err = key.WriteTo(w); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err = w.Flush(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,105 @@
+// Package powersoftau holds what the contributions of powersoftau, the phase 1
+// software of the Sapling MPC of Zcash, and of its fork run by the Perpetual
+// Powers of Tau ceremony have in common across curves: the hashes and the
+// random generator their proofs of knowledge of the secrets are made with.
+package powersoftau
+
+import (
+	"encoding/binary"
+	"math"
+	"math/big"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/chacha20"
+)
+
+// The personalizations of the proofs of knowledge of τ, α and β.
+const (
+	TauPersonalization byte = iota
+	AlphaPersonalization
+	BetaPersonalization
+)
+
+// ProofHash returns the hash a proof of knowledge of a secret x hashes to G2:
+// the BLAKE2b of the personalization, of the hash of the challenge and of
+// g1^s and g1^{sx}, uncompressed.
+func ProofHash(personalization byte, challengeHash, g1S, g1SX []byte) []byte {
+	h, _ := blake2b.New512(nil) // no key, never fails
+	h.Write([]byte{personalization})
+	h.Write(challengeHash)
+	h.Write(g1S)
+	h.Write(g1SX)
+
+	return h.Sum(nil)
+}
+
+// Rng is the ChaChaRng of the rand crate 0.4, which hash_to_g2 of powersoftau
+// seeds with the first 32 bytes of a hash to draw a point in G2: ChaCha20
+// keyed by these bytes read as 8 big endian words, with a zero nonce and
+// counter.
+type Rng struct {
+	stream *chacha20.Cipher
+}
+
+// NewRng returns the generator seeded with the first 32 bytes of h.
+func NewRng(h []byte) *Rng {
+	// ChaCha20 reads the words of its key little endian
+	var key [chacha20.KeySize]byte
+	for i := 0; i < len(key); i += 4 {
+		binary.LittleEndian.PutUint32(key[i:], binary.BigEndian.Uint32(h[i:]))
+	}
+	stream, _ := chacha20.NewUnauthenticatedCipher(key[:], make([]byte, chacha20.NonceSize)) // the sizes are valid
+
+	return &Rng{stream: stream}
+}
+
+// Uint32 returns the next word of the key stream.
+func (r *Rng) Uint32() uint32 {
+	var w [4]byte
+	r.stream.XORKeyStream(w[:], w[:])
+
+	return binary.LittleEndian.Uint32(w[:])
+}
+
+// Uint64 returns the next 2 words, the first one being the most significant,
+// as the default next_u64 of the Rng trait of rand 0.4.
+func (r *Rng) Uint64() uint64 {
+	hi := r.Uint32()
+	return uint64(hi)<<32 | uint64(r.Uint32())
+}
+
+// Bool returns the least significant bit of the next word.
+func (r *Rng) Bool() bool {
+	return r.Uint32()&1 == 1
+}
+
+// Element draws the little endian limbs of an element of the field of the
+// modulus as the pairing crates do, in the Montgomery form of gnark-crypto
+// too: limbs with the bits past the size of the modulus cleared, drawn again
+// until they are smaller than the modulus.
+func (r *Rng) Element(limbs []uint64, modulus *big.Int) {
+	shave := 64*len(limbs) - modulus.BitLen()
+	for {
+		for i := range limbs {
+			limbs[i] = r.Uint64()
+		}
+		limbs[len(limbs)-1] &= math.MaxUint64 >> shave
+
+		if less(limbs, modulus) {
+			return
+		}
+	}
+}
+
+// less tells whether the little endian limbs are smaller than the modulus.
+func less(limbs []uint64, modulus *big.Int) bool {
+	word := new(big.Int)
+	for i := len(limbs) - 1; i >= 0; i-- {
+		m := word.Rsh(modulus, uint(64*i)).Uint64()
+		if limbs[i] != m {
+			return limbs[i] < m
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,45 @@
+package powersoftau_test
+
+import (
+	"math/big"
+	"testing"
+
+	"linea/aztec-srs-to-gnark/powersoftau"
+)
+
+// TestRng checks the generator against the first words of the ChaChaRng of
+// rand 0.4 seeded with zeros, the ChaCha20 key stream of the zero key, and
+// the order of the words of its 64-bit values.
+func TestRng(t *testing.T) {
+	want := []uint32{0xade0b876, 0x903df1a0, 0xe56a5d40, 0x28bd8653, 0xb819d2bd, 0x1aed8da0, 0xccef36a8, 0xc70d778b}
+
+	rng := powersoftau.NewRng(make([]byte, 32))
+	for i, w := range want {
+		if got := rng.Uint32(); got != w {
+			t.Fatalf("word %d is %#x, not %#x", i, got, w)
+		}
+	}
+
+	rng = powersoftau.NewRng(make([]byte, 32))
+	if got := rng.Uint64(); got != uint64(want[0])<<32|uint64(want[1]) {
+		t.Fatalf("the first 64-bit value is %#x, not the first word followed by the second", got)
+	}
+}
+
+// TestElement checks that the drawn elements are smaller than the modulus,
+// which doesn't use the most significant bits of the limbs.
+func TestElement(t *testing.T) {
+	modulus := big.NewInt(1)
+	modulus.Lsh(modulus, 125).Sub(modulus, big.NewInt(3))
+
+	rng := powersoftau.NewRng(make([]byte, 32))
+	limbs := make([]uint64, 2)
+	for range 1000 {
+		rng.Element(limbs, modulus)
+		value := new(big.Int).SetUint64(limbs[1])
+		value.Lsh(value, 64).Or(value, new(big.Int).SetUint64(limbs[0]))
+		if value.Cmp(modulus) >= 0 {
+			t.Fatalf("%v isn't smaller than the modulus", value)
+		}
+	}
+}
@@ -0,0 +1,418 @@
+// Package ppot downloads and reads the challenge files of the Perpetual Powers
+// of Tau ceremony on bn254, run with a fork of the powersoftau software of
+// Zcash, and writes the responses contributing to them.
+package ppot
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/audit"
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/digest"
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/offheap"
+	"linea/aztec-srs-to-gnark/phase1"
+	"linea/aztec-srs-to-gnark/verify"
+)
+
+const (
+	// Powers is the number of powers of τ in G2 of the ceremony, which has
+	// twice as many but one in G1. The files of unknown size hold them.
+	Powers = 1 << 28
+	// HashAlgorithm is the algorithm of the hash starting every file, of the
+	// previous response in a challenge and of the challenge in a response.
+	HashAlgorithm = digest.BLAKE2b512
+	// HashSize is the size of the hash starting every file.
+	HashSize = 64
+	// G1PointSize is the size of an uncompressed G1 point: x and y, big endian.
+	G1PointSize = 2 * fp.Bytes
+	// G2PointSize is the size of an uncompressed G2 point: x.c1, x.c0, y.c1
+	// and y.c0, big endian.
+	G2PointSize = 4 * fp.Bytes
+	// PublicKeySize is the size of the public key ending a response file: τ,
+	// α and β in G1 twice and in G2 once, uncompressed.
+	PublicKeySize = 6*G1PointSize + 3*G2PointSize
+	// g1ReadBatch is the number of G1 points read at once.
+	g1ReadBatch = 1 << 12
+)
+
+// challengeSize returns the size of a challenge file of powers powers of τ in
+// G2:
+//
+//	[hash of the previous response] [tau_g1: 2N-1] [tau_g2: N] [alpha_g1: N] [beta_g1: N] [beta_g2]
+//
+// the points being uncompressed.
+func challengeSize(powers int64) int64 {
+	return HashSize + (4*powers-1)*G1PointSize + (powers+1)*G2PointSize
+}
+
+// responseSize returns the size of a response file of powers powers of τ in
+// G2: the points of the challenge compressed, then the public key.
+func responseSize(powers int64) int64 {
+	return HashSize + (4*powers-1)*G1PointSize/2 + (powers+1)*G2PointSize/2 + PublicKeySize
+}
+
+// powersOf returns the number of powers of τ in G2 of a challenge file by its
+// size, Powers when it is unknown.
+func powersOf(size int64) (int, error) {
+	if size == input.UnknownSize {
+		return Powers, nil
+	}
+
+	// The sizes grow linearly with the number of powers
+	for _, layout := range []struct {
+		name string
+		size func(int64) int64
+	}{{"challenge", challengeSize}, {"response", responseSize}} {
+		base, perPower := layout.size(0), layout.size(1)-layout.size(0)
+		if size <= base || (size-base)%perPower != 0 || (size-base)/perPower < 2 {
+			continue
+		}
+		if layout.name == "response" {
+			return 0, errors.New("it is a response file, whose points are compressed: convert the challenge of the next contribution, which holds the same points")
+		}
+		return int((size - base) / perPower), nil
+	}
+
+	return 0, fmt.Errorf("size of %d bytes isn't the one of a challenge file", size)
+}
+
+// findChallenge returns the challenge file among the files, the only one, e.g.
+// read from the standard input, or the only one named challenge*, and the
+// other files.
+func findChallenge(files []input.File) (input.File, []input.File, error) {
+	if len(files) == 1 {
+		return files[0], nil, nil
+	}
+
+	var found, others []input.File
+	for _, file := range files {
+		if strings.HasPrefix(file.Name, "challenge") {
+			found = append(found, file)
+		} else {
+			others = append(others, file)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return input.File{}, nil, errors.New("no challenge file found")
+	case 1:
+		return found[0], others, nil
+	}
+
+	return input.File{}, nil, fmt.Errorf("several challenge files found: %s and %s", found[0].Name, found[1].Name)
+}
+
+// decodeCoordinates decodes big endian coordinates, which must be smaller than
+// the modulus: the flags of the point at infinity or of a compressed point,
+// in the most significant bits, aren't.
+func decodeCoordinates(data []byte, coordinates ...*fp.Element) error {
+	for i, c := range coordinates {
+		var err error
+		if *c, err = fp.BigEndian.Element((*[fp.Bytes]byte)(data[i*fp.Bytes:])); err != nil {
+			return fmt.Errorf("coordinate %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// readG1Points reads the G1 points in batches, checks that they are on the
+// curve and passes every batch to the verification checks. It returns the
+// number of points read before an error.
+func readG1Points(r io.Reader, points []bn254.G1Affine, checks *verify.Pipeline[bn254.G1Affine], progress *config.Progress) (int, error) {
+	buf := make([]byte, g1ReadBatch*G1PointSize)
+
+	for from := 0; from < len(points); from += g1ReadBatch {
+		batch := points[from:min(from+g1ReadBatch, len(points))]
+		data := buf[:len(batch)*G1PointSize]
+
+		if _, err := io.ReadFull(r, data); err != nil {
+			return from, fmt.Errorf("failed to read G1 points %d-%d: %w", from, from+len(batch)-1, err)
+		}
+		for i := range batch {
+			p := &batch[i]
+			if err := decodeCoordinates(data[i*G1PointSize:], &p.X, &p.Y); err != nil {
+				return from + i, fmt.Errorf("invalid G1 point %d: %w", from+i, err)
+			}
+			if !p.IsOnCurve() {
+				return from + i, fmt.Errorf("G1 point %d is not on the curve", from+i)
+			}
+		}
+
+		checks.Check(from, batch)
+		progress.Add(len(batch))
+	}
+
+	return len(points), nil
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// readG2Points reads the uncompressed G2 points, checking that they are on the
+// curve.
+func readG2Points(r io.Reader, points []bn254.G2Affine) error {
+	var data [G2PointSize]byte
+	for i := range points {
+		if _, err := io.ReadFull(r, data[:]); err != nil {
+			return fmt.Errorf("failed to read G2 point %d: %w", i, err)
+		}
+		p := &points[i]
+		if err := decodeCoordinates(data[:], &p.X.A1, &p.X.A0, &p.Y.A1, &p.Y.A0); err != nil {
+			return fmt.Errorf("invalid G2 point %d: %w", i, err)
+		}
+		if !p.IsOnCurve() {
+			return fmt.Errorf("G2 point %d is not on the curve", i)
+		}
+	}
+
+	return nil
+}
+
+// readG2Powers reads the first powers of τ in G2 into tauG2, at least the
+// generator and τG2, which are set in the verifying key.
+func readG2Powers(r io.Reader, tauG2 []bn254.G2Affine, srs *bnKzg.SRS) error {
+	if err := readG2Points(r, tauG2); err != nil {
+		return fmt.Errorf("failed to read the powers of τ in G2: %w", err)
+	}
+	copy(srs.Vk.G2[:], tauG2)
+	if _, _, _, g2Gen := bn254.Generators(); !srs.Vk.G2[0].Equal(&g2Gen) {
+		return errors.New("the first G2 power isn't the generator")
+	}
+	srs.Vk.Lines[0] = bn254.PrecomputeLines(srs.Vk.G2[0])
+	srs.Vk.Lines[1] = bn254.PrecomputeLines(srs.Vk.G2[1])
+
+	fmt.Printf("> a^1*G2: %s %s\n", srs.Vk.G2[1].X.String(), srs.Vk.G2[1].Y.String())
+
+	return nil
+}
+
+// readPhase1 reads the Groth16 phase 1 points following the powers of τ in G1
+// of a challenge of powers powers of τ in G2: the powers of τ in G2, setting
+// the verifying key, the α and β powers in G1 and βG2. They are only checked
+// to be on the curve.
+func readPhase1(r io.Reader, powers int, srs *bnKzg.SRS, opts config.Options) (phase1.Points[bn254.G1Affine, bn254.G2Affine], error) {
+	p := phase1.Points[bn254.G1Affine, bn254.G2Affine]{TauG1: srs.Pk.G1}
+	br := bufio.NewReaderSize(r, g1ReadBatch*G1PointSize)
+
+	var err error
+	if p.TauG2, err = offheap.Make[bn254.G2Affine](powers, opts); err != nil {
+		return p, err
+	}
+	if err = readG2Powers(br, p.TauG2, srs); err != nil {
+		return p, err
+	}
+	for _, section := range []struct {
+		name   string
+		points *[]bn254.G1Affine
+	}{{"α", &p.AlphaTauG1}, {"β", &p.BetaTauG1}} {
+		if *section.points, err = offheap.Make[bn254.G1Affine](powers, opts); err != nil {
+			return p, err
+		}
+		if _, err = readG1Points(br, *section.points, nil, nil); err != nil {
+			return p, fmt.Errorf("failed to read the %s powers: %w", section.name, err)
+		}
+	}
+	var betaG2 [1]bn254.G2Affine
+	if err = readG2Points(br, betaG2[:]); err != nil {
+		return p, fmt.Errorf("failed to read βG2: %w", err)
+	}
+	p.BetaG2 = betaG2[0]
+
+	return p, nil
+}
+
+// TranslateBn254SRS reads a challenge file of the ceremony and constructs a
+// KZG SRS from its powers of τ: all the G1 ones, or with opts.Degree the
+// first 2^opts.Degree ones. Only the prefix of the file holding them is read,
+// the other G1 powers are seeked over to reach τG2 in a local file. The other
+// files and the G1 points that can't be read are rejected with opts.Reject:
+// under the lenient validation the SRS ends before the first point that
+// can't be read. With opts.Phase1 the whole challenge is read and a
+// *phase1.SRS is returned, with the mpcsetup Phase1 of gnark of its Groth16
+// phase 1.
+func TranslateBn254SRS(files []input.File, opts config.Options) (kzg.SRS, int, error) {
+	file, others, err := findChallenge(files)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, other := range others {
+		opts.Ignore(other.Name, "not a challenge file")
+		opts.Audit.Record(audit.Event{Kind: audit.KindSetupFile, File: other.Name, Decision: "ignored: not a challenge file"})
+	}
+
+	powers, err := powersOf(file.Size)
+	if err != nil {
+		return nil, 0, fmt.Errorf("challenge file %s: %w", file.Name, err)
+	}
+	g1PowersN := 2*powers - 1
+	n := g1PowersN
+	if opts.Phase1 && opts.Degree > 0 {
+		return nil, 0, errors.New("the Groth16 phase 1 needs all the powers of τ in G1, they can't be selected by degree")
+	}
+	if opts.Degree > 0 {
+		if 1<<opts.Degree > n {
+			return nil, 0, fmt.Errorf("challenge file %s holds %d points, less than 2^%d", file.Name, n, opts.Degree)
+		}
+		n = 1 << opts.Degree
+	}
+
+	fmt.Printf("Processing challenge file %s\n", file.Name)
+	fmt.Printf("> %d G1 and %d G2 powers, reading %d G1 powers\n", g1PowersN, powers, n)
+	f, err := file.Open()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open %s: %w", file.Name, err)
+	}
+	defer f.Close()
+
+	// The hash at the beginning of the file is only recorded
+	hash := make([]byte, HashSize)
+	if _, err = io.ReadFull(f, hash); err != nil {
+		return nil, 0, fmt.Errorf("failed to read the hash of %s: %w", file.Name, err)
+	}
+
+	srs := new(bnKzg.SRS)
+	if srs.Pk.G1, err = offheap.Make[bn254.G1Affine](n, opts); err != nil {
+		return nil, 0, err
+	}
+	opts.Progress.SetTotal(n)
+
+	var (
+		checker *verify.Bn254Checker
+		checks  *verify.Pipeline[bn254.G1Affine]
+	)
+	if opts.Verify {
+		if checker, err = verify.NewBn254Checker(); err != nil {
+			return nil, 0, err
+		}
+		checks = verify.NewPipeline(checker.Batch, opts.Workers)
+	}
+
+	// The G1 points after the ones read are skipped to reach τG2, a batch
+	// failing to decode being read whole
+	counted := &countingReader{r: f}
+	read, err := readG1Points(counted, srs.Pk.G1, checks, opts.Progress)
+	failedFrom := -1
+	if err != nil {
+		// The points before the first one that can't be read are kept
+		if read < 2 || opts.Phase1 {
+			checks.Wait()
+			return nil, 0, fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+		if err = opts.Reject(config.Skip{File: file.Name, Offset: audit.Index(read), Points: n - read}, err); err != nil {
+			checks.Wait()
+			return nil, 0, err
+		}
+		opts.Warn("G1 points from %d can't be read: the SRS ends at G1 point %d", read, read-1)
+		failedFrom = read
+	}
+	var points phase1.Points[bn254.G1Affine, bn254.G2Affine]
+	if err = input.Skip(f, int64(g1PowersN)*G1PointSize-counted.n); err == nil {
+		if opts.Phase1 {
+			points, err = readPhase1(f, powers, srs, opts)
+		} else {
+			err = readG2Powers(f, srs.Vk.G2[:], srs)
+		}
+	}
+	if err != nil {
+		checks.Wait()
+		return nil, 0, fmt.Errorf("failed to read %s: %w", file.Name, err)
+	}
+
+	if failedFrom >= 0 {
+		srs.Pk.G1 = srs.Pk.G1[:failedFrom]
+	}
+	opts.Audit.Record(audit.Event{Kind: audit.KindSetupFile, File: file.Name, Points: len(srs.Pk.G1), Hash: hex.EncodeToString(hash), HashAlgorithm: string(HashAlgorithm), Decision: "used"})
+	srs.Vk.G1 = srs.Pk.G1[0]
+
+	if _, _, g1Gen, _ := bn254.Generators(); !srs.Vk.G1.Equal(&g1Gen) {
+		checks.Wait()
+		return nil, 0, fmt.Errorf("the first G1 power of %s isn't the generator", file.Name)
+	}
+
+	fmt.Printf("> a^1*G1: %s %s\n", srs.Pk.G1[1].X.String(), srs.Pk.G1[1].Y.String())
+
+	if checker != nil {
+		err = checks.Wait()
+		switch {
+		case failedFrom >= 0:
+			// The points were checked up to the failed one, so the fused checks
+			// don't apply
+			err = verify.SRS(srs, opts)
+		case err == nil:
+			err = checker.Finish(srs)
+		}
+		// Under the lenient validation the SRS ends before a point failing
+		if err = verify.Recover(srs, opts, err); err != nil {
+			return nil, 0, fmt.Errorf("%w: %w", verify.ErrFailed, err)
+		}
+		fmt.Println("SRS verified: all G1 points are in the subgroup and are consecutive powers of tau")
+	}
+
+	if opts.Phase1 {
+		// Under the lenient validation the verification may end the SRS
+		points.TauG1 = srs.Pk.G1
+		p, err := phase1.Bn254(points)
+		if err != nil {
+			return nil, 0, err
+		}
+		fmt.Printf("Read the Groth16 phase 1 of %d powers of τ in G2\n", powers)
+		return &phase1.SRS{SRS: srs, Phase1: p}, len(srs.Pk.G1), nil
+	}
+
+	return srs, len(srs.Pk.G1), nil
+}
+
+// ExtractTauG2 returns an SRS with only the verifying key, read from the
+// first powers of τ in G2 of the challenge file. The G1 points are skipped
+// over without being parsed.
+func ExtractTauG2(files []input.File) (kzg.SRS, error) {
+	file, _, err := findChallenge(files)
+	if err != nil {
+		return nil, err
+	}
+	powers, err := powersOf(file.Size)
+	if err != nil {
+		return nil, fmt.Errorf("challenge file %s: %w", file.Name, err)
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", file.Name, err)
+	}
+	defer f.Close()
+
+	// [hash] [tau_g1 points] [tau_g2 points], the first of which is the generator
+	if err = input.Skip(f, HashSize+int64(2*powers-1)*G1PointSize); err != nil {
+		return nil, fmt.Errorf("failed to skip the G1 points of %s: %w", file.Name, err)
+	}
+
+	srs := new(bnKzg.SRS)
+	_, _, srs.Vk.G1, _ = bn254.Generators()
+	if err = readG2Powers(f, srs.Vk.G2[:], srs); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+	}
+
+	return srs, nil
+}
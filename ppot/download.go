@@ -0,0 +1,64 @@
+package ppot
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"linea/aztec-srs-to-gnark/digest"
+	"linea/aztec-srs-to-gnark/fetch"
+)
+
+const (
+	// ChallengeURL is the location of the challenge file of a contribution.
+	ChallengeURL = "https://ppot.blob.core.windows.net/public/challenge_%04d"
+	// ResponseURL is the location of the response file of a contribution.
+	ResponseURL = "https://ppot.blob.core.windows.net/public/response_%04d"
+)
+
+// attestationHash matches a BLAKE2b-512 hash in an attestation, which prints
+// it as 16 groups of 8 hex digits spread over several lines.
+var attestationHash = regexp.MustCompile(`(?:[0-9a-fA-F]{8}\s*){15}[0-9a-fA-F]{8}`)
+
+// Downloads lists the challenge or the response file of the contribution. The
+// file is checked against the BLAKE2b hashes published in the attestation of
+// the contribution.
+func Downloads(ctx context.Context, sel fetch.Selection) ([]fetch.Download, error) {
+	if sel.Contribution <= 0 {
+		return nil, fmt.Errorf("the number of the contribution is required")
+	}
+	if sel.Attestation == "" {
+		return nil, fmt.Errorf("the attestation of the contribution is required to verify the file")
+	}
+
+	attestation, err := fetch.Read(ctx, sel.Attestation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attestation: %w", err)
+	}
+
+	checksums := AttestationHashes(string(attestation))
+	if len(checksums) == 0 {
+		return nil, fmt.Errorf("no BLAKE2b hashes found in the attestation %s", sel.Attestation)
+	}
+
+	url := ChallengeURL
+	if sel.Response {
+		url = ResponseURL
+	}
+
+	return []fetch.Download{{
+		URL:    fmt.Sprintf(url, sel.Contribution),
+		Verify: fetch.VerifyDigest(digest.BLAKE2b512, checksums, -1),
+	}}, nil
+}
+
+// AttestationHashes extracts the BLAKE2b hashes from the text of an attestation.
+func AttestationHashes(attestation string) []string {
+	var hashes []string
+	for _, match := range attestationHash.FindAllString(attestation, -1) {
+		hashes = append(hashes, strings.Join(strings.Fields(match), ""))
+	}
+
+	return hashes
+}
@@ -0,0 +1,4 @@
+package ppot
+
+// HashToG2 exports hashToG2 to the tests.
+var HashToG2 = hashToG2
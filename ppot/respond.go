@@ -0,0 +1,293 @@
+package ppot
+
+import (
+	"bufio"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/parallel"
+	"linea/aztec-srs-to-gnark/powersoftau"
+)
+
+const (
+	// compressedLargest flags a compressed point of the larger y of its x in
+	// the encoding of pairing_ce, smallest ones having no flag.
+	compressedLargest byte = 0b10 << 6
+	// compressedInfinity flags the point at infinity in the encoding of
+	// pairing_ce.
+	compressedInfinity byte = 0b01 << 6
+	// gnarkFlags masks the flags of gnark-crypto in the first byte of a
+	// compressed point.
+	gnarkFlags byte = 0b11 << 6
+	// gnarkLargest and gnarkInfinity are the flags of gnark-crypto of the
+	// larger y of an x and of the point at infinity.
+	gnarkLargest  byte = 0b11 << 6
+	gnarkInfinity byte = 0b01 << 6
+)
+
+// g2Cofactor is the cofactor of G2, 2q - r, by which pairing_ce multiplies the
+// points drawn by hash_to_g2, bit by bit. The cofactor clearing of
+// gnark-crypto multiplies by another multiple of it.
+var g2Cofactor, _ = new(big.Int).SetString("30644e72e131a029b85045b68181585e06ceecda572a2489345f2299c0f9fa8d", 16)
+
+// secrets are the secrets of a contribution.
+type secrets struct {
+	tau, alpha, beta fr.Element
+}
+
+// Respond contributes fresh secrets to a challenge file of the ceremony and
+// writes the response file: the hash of the challenge, its points multiplied
+// by the secrets, compressed as pairing_ce does, and the public key of the
+// contribution, which proves the knowledge of the secrets. The challenge is
+// read twice, to hash it and to multiply its points on opts.Workers
+// goroutines. It returns the hash of the challenge.
+func Respond(challenge io.ReadSeeker, response io.Writer, opts config.Options) ([]byte, error) {
+	var s secrets
+	for _, x := range []*fr.Element{&s.tau, &s.alpha, &s.beta} {
+		if _, err := x.SetRandom(); err != nil {
+			return nil, fmt.Errorf("failed to draw the secrets: %w", err)
+		}
+	}
+
+	size, err := challenge.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read challenge: %w", err)
+	}
+	powers, err := powersOf(size)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = challenge.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read challenge: %w", err)
+	}
+	h := HashAlgorithm.New()
+	if _, err = io.Copy(h, challenge); err != nil {
+		return nil, fmt.Errorf("failed to hash challenge: %w", err)
+	}
+	challengeHash := h.Sum(nil)
+	if _, err = challenge.Seek(HashSize, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read challenge: %w", err)
+	}
+
+	r := bufio.NewReaderSize(challenge, g1ReadBatch*G1PointSize)
+	w := bufio.NewWriter(response)
+	w.Write(challengeHash)
+
+	var one fr.Element
+	one.SetOne()
+	for _, section := range []struct {
+		name  string
+		g2    bool
+		n     int
+		coeff fr.Element
+	}{
+		{"powers of τ in G1", false, 2*powers - 1, one},
+		{"powers of τ in G2", true, powers, one},
+		{"α powers", false, powers, s.alpha},
+		{"β powers", false, powers, s.beta},
+		{"βG2", true, 1, s.beta},
+	} {
+		if section.g2 {
+			err = transformG2(r, w, section.n, section.coeff, s.tau, opts.Workers)
+		} else {
+			err = transformG1(r, w, section.n, section.coeff, s.tau, opts.Workers)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to contribute to the %s: %w", section.name, err)
+		}
+	}
+
+	if err = writePublicKey(w, challengeHash, s); err != nil {
+		return nil, err
+	}
+	if err = w.Flush(); err != nil {
+		return nil, fmt.Errorf("failed to write response: %w", err)
+	}
+
+	return challengeHash, nil
+}
+
+// compressed converts a point compressed by gnark-crypto to the encoding of
+// pairing_ce, which has the same layout with other flags.
+func compressed(encoded []byte) []byte {
+	flags := encoded[0] & gnarkFlags
+	encoded[0] &^= gnarkFlags
+	switch flags {
+	case gnarkLargest:
+		encoded[0] |= compressedLargest
+	case gnarkInfinity:
+		encoded[0] |= compressedInfinity
+	}
+
+	return encoded
+}
+
+// scalars returns the n scalars coeff·τ^i from the i-th power of τ, advancing
+// coeff to the n-th one past them.
+func scalars(coeff *fr.Element, tau fr.Element, n int) []big.Int {
+	s := make([]big.Int, n)
+	for i := range s {
+		coeff.BigInt(&s[i])
+		coeff.Mul(coeff, &tau)
+	}
+
+	return s
+}
+
+// transformG1 reads the n G1 points of a section of the challenge, multiplies
+// the i-th one by coeff·τ^i on workers goroutines and writes them compressed.
+func transformG1(r io.Reader, w io.Writer, n int, coeff, tau fr.Element, workers int) error {
+	points := make([]bn254.G1Affine, min(g1ReadBatch, n))
+
+	for from := 0; from < n; from += g1ReadBatch {
+		batch := points[:min(g1ReadBatch, n-from)]
+
+		if _, err := readG1Points(r, batch, nil, nil); err != nil {
+			return fmt.Errorf("G1 points from %d: %w", from, err)
+		}
+		s := scalars(&coeff, tau, len(batch))
+		_ = parallel.Run(len(batch), workers, func(i int) error {
+			batch[i].ScalarMultiplication(&batch[i], &s[i])
+			return nil
+		})
+
+		for i := range batch {
+			encoded := batch[i].Bytes()
+			w.Write(compressed(encoded[:]))
+		}
+	}
+
+	return nil
+}
+
+// transformG2 reads the n G2 points of a section of the challenge, multiplies
+// the i-th one by coeff·τ^i on workers goroutines and writes them compressed.
+func transformG2(r io.Reader, w io.Writer, n int, coeff, tau fr.Element, workers int) error {
+	points := make([]bn254.G2Affine, min(g1ReadBatch, n))
+
+	for from := 0; from < n; from += g1ReadBatch {
+		batch := points[:min(g1ReadBatch, n-from)]
+
+		if err := readG2Points(r, batch); err != nil {
+			return fmt.Errorf("G2 points from %d: %w", from, err)
+		}
+		s := scalars(&coeff, tau, len(batch))
+		_ = parallel.Run(len(batch), workers, func(i int) error {
+			batch[i].ScalarMultiplication(&batch[i], &s[i])
+			return nil
+		})
+
+		for i := range batch {
+			encoded := batch[i].Bytes()
+			w.Write(compressed(encoded[:]))
+		}
+	}
+
+	return nil
+}
+
+// writePublicKey writes the public key of the contribution of the secrets to
+// the challenge of the hash: for τ, α and β, g1^s of a random s and g1^{sx}
+// of the secret x, then for each, g2^{s'x} of the g2^{s'} hashed from them.
+// It is the proof of knowledge of the secrets the response is verified with.
+func writePublicKey(w io.Writer, challengeHash []byte, s secrets) error {
+	var g1 [6]bn254.G1Affine
+	var g2 [3]bn254.G2Affine
+	_, _, g1Gen, _ := bn254.Generators()
+	for i, secret := range []struct {
+		personalization byte
+		x               fr.Element
+	}{
+		{powersoftau.TauPersonalization, s.tau},
+		{powersoftau.AlphaPersonalization, s.alpha},
+		{powersoftau.BetaPersonalization, s.beta},
+	} {
+		x := secret.x.BigInt(new(big.Int))
+		r, err := rand.Int(rand.Reader, fr.Modulus())
+		if err != nil {
+			return fmt.Errorf("failed to draw the public key: %w", err)
+		}
+		g1S, g1SX := &g1[2*i], &g1[2*i+1]
+		g1S.ScalarMultiplication(&g1Gen, r)
+		g1SX.ScalarMultiplication(g1S, x)
+
+		rawS, rawSX := g1S.RawBytes(), g1SX.RawBytes()
+		g2S := hashToG2(powersoftau.ProofHash(secret.personalization, challengeHash, rawS[:], rawSX[:]))
+		g2[i].ScalarMultiplication(&g2S, x)
+	}
+
+	for i := range g1 {
+		raw := g1[i].RawBytes()
+		w.Write(raw[:])
+	}
+	for i := range g2 {
+		raw := g2[i].RawBytes()
+		w.Write(raw[:])
+	}
+
+	return nil
+}
+
+// hashToG2 returns the point of G2 hash_to_g2 of the ceremony draws from the
+// hash: the point of the first random x on the curve, with the largest or the
+// smallest of its y as a random bool tells, multiplied by the cofactor.
+func hashToG2(h []byte) bn254.G2Affine {
+	// b of the twist, y² - x³ of the generator
+	_, _, _, g2Gen := bn254.Generators()
+	var b, x3 bn254.E2
+	b.Square(&g2Gen.Y)
+	x3.Square(&g2Gen.X).Mul(&x3, &g2Gen.X)
+	b.Sub(&b, &x3)
+
+	rng := powersoftau.NewRng(h)
+	for {
+		var p bn254.G2Affine
+		for _, c := range []*fp.Element{&p.X.A0, &p.X.A1} {
+			var limbs [fp.Limbs]uint64
+			rng.Element(limbs[:], fp.Modulus())
+			*c = fp.Element(limbs)
+		}
+		greatest := rng.Bool()
+
+		var y2 bn254.E2
+		y2.Square(&p.X).Mul(&y2, &p.X).Add(&y2, &b)
+		if y2.Legendre() == -1 {
+			continue
+		}
+		p.Y.Sqrt(&y2)
+		if p.Y.LexicographicallyLargest() != greatest {
+			p.Y.Neg(&p.Y)
+		}
+		if p.IsInfinity() {
+			continue
+		}
+
+		return mulBits(&p, g2Cofactor)
+	}
+}
+
+// mulBits returns p multiplied by k with a double-and-add, which, unlike the
+// scalar multiplication of gnark-crypto, doesn't assume that p is in G2.
+func mulBits(p *bn254.G2Affine, k *big.Int) bn254.G2Affine {
+	var acc bn254.G2Jac
+	acc.FromAffine(p)
+	for i := k.BitLen() - 2; i >= 0; i-- {
+		acc.DoubleAssign()
+		if k.Bit(i) == 1 {
+			acc.AddMixed(p)
+		}
+	}
+
+	var res bn254.G2Affine
+	res.FromJacobian(&acc)
+
+	return res
+}
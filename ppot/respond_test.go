@@ -0,0 +1,185 @@
+package ppot_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	bnMpc "github.com/consensys/gnark/backend/groth16/bn254/mpcsetup"
+
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/phase1"
+	"linea/aztec-srs-to-gnark/powersoftau"
+	"linea/aztec-srs-to-gnark/ppot"
+	"linea/aztec-srs-to-gnark/testsetup"
+	"linea/aztec-srs-to-gnark/verify"
+)
+
+// powers is the number of powers of τ in G2 of the test challenge.
+const powers = 4
+
+// decodeCompressed decodes the point compressed as pairing_ce does, whose
+// flags of the larger y and of the point at infinity are 0b10 and 0b01.
+func decodeCompressed(t *testing.T, data []byte, p interface{ SetBytes([]byte) (int, error) }) {
+	t.Helper()
+	encoded := append([]byte(nil), data...)
+	switch flags := encoded[0] >> 6; flags {
+	case 0b10:
+		encoded[0] |= 0b11 << 6
+	case 0b00:
+		encoded[0] |= 0b10 << 6
+	}
+	if _, err := p.SetBytes(encoded); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// sameRatio tells whether b1 / a1 in G1 is b2 / a2 in G2.
+func sameRatio(t *testing.T, a1, b1 bn254.G1Affine, a2, b2 bn254.G2Affine) bool {
+	t.Helper()
+	var negB1 bn254.G1Affine
+	negB1.Neg(&b1)
+	ok, err := bn254.PairingCheck([]bn254.G1Affine{a1, negB1}, []bn254.G2Affine{b2, a2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ok
+}
+
+// TestRespond contributes to a challenge and checks the response as the
+// coordinator of the ceremony does: the response starts with the hash of the
+// challenge, its public key proves the knowledge of the secrets, which
+// multiply the points of the challenge, and its points are powers of τ.
+func TestRespond(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := testsetup.PPoT(dir, powers, testsetup.DefaultSecrets()); err != nil {
+		t.Fatal(err)
+	}
+	challengePath := filepath.Join(dir, "challenge_0001")
+	challenge, err := os.Open(challengePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer challenge.Close()
+
+	var response bytes.Buffer
+	opts := config.Tune(config.Options{IOParallelism: 1, Phase1: true}, "")
+	challengeHash, err := ppot.Respond(challenge, &response, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := input.Dir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srs, _, err := ppot.TranslateBn254SRS(files, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := srs.(*phase1.SRS).Phase1.(*bnMpc.Phase1).Parameters
+
+	h := ppot.HashAlgorithm.New()
+	data, err := os.ReadFile(challengePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Write(data)
+	if got := response.Bytes()[:ppot.HashSize]; !bytes.Equal(got, h.Sum(nil)) || !bytes.Equal(got, challengeHash) {
+		t.Fatalf("the response starts with %x, not with the hash %x of the challenge", got, h.Sum(nil))
+	}
+
+	// The points of the challenge, compressed, then the public key
+	r := bytes.NewReader(response.Bytes()[ppot.HashSize:])
+	var after bnMpc.Phase1
+	params := &after.Parameters
+	params.G1.Tau = make([]bn254.G1Affine, 2*powers-1)
+	params.G2.Tau = make([]bn254.G2Affine, powers)
+	params.G1.AlphaTau = make([]bn254.G1Affine, powers)
+	params.G1.BetaTau = make([]bn254.G1Affine, powers)
+	var g1Data [ppot.G1PointSize / 2]byte
+	var g2Data [ppot.G2PointSize / 2]byte
+	for _, section := range []struct {
+		g1 []bn254.G1Affine
+		g2 []bn254.G2Affine
+	}{
+		{g1: params.G1.Tau}, {g2: params.G2.Tau}, {g1: params.G1.AlphaTau}, {g1: params.G1.BetaTau}, {g2: []bn254.G2Affine{{}}},
+	} {
+		for i := range section.g1 {
+			r.Read(g1Data[:])
+			decodeCompressed(t, g1Data[:], &section.g1[i])
+		}
+		for i := range section.g2 {
+			r.Read(g2Data[:])
+			decodeCompressed(t, g2Data[:], &section.g2[i])
+		}
+		if len(section.g2) == 1 {
+			params.G2.Beta = section.g2[0]
+		}
+	}
+	if r.Len() != ppot.PublicKeySize {
+		t.Fatalf("the points are followed by %d bytes, not by the public key", r.Len())
+	}
+
+	afterSRS := new(bnKzg.SRS)
+	afterSRS.Pk.G1, afterSRS.Vk.G1 = params.G1.Tau, params.G1.Tau[0]
+	copy(afterSRS.Vk.G2[:], params.G2.Tau)
+	if err = verify.SRS(afterSRS, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	// τ, α and β in G1 twice, then in G2, uncompressed
+	var g1 [6]bn254.G1Affine
+	var g2 [3]bn254.G2Affine
+	for i := range g1 {
+		var raw [ppot.G1PointSize]byte
+		r.Read(raw[:])
+		if _, err = g1[i].SetBytes(raw[:]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := range g2 {
+		var raw [ppot.G2PointSize]byte
+		r.Read(raw[:])
+		if _, err = g2[i].SetBytes(raw[:]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var g2S [3]bn254.G2Affine
+	for i, personalization := range []byte{powersoftau.TauPersonalization, powersoftau.AlphaPersonalization, powersoftau.BetaPersonalization} {
+		rawS, rawSX := g1[2*i].RawBytes(), g1[2*i+1].RawBytes()
+		g2S[i] = ppot.HashToG2(powersoftau.ProofHash(personalization, challengeHash, rawS[:], rawSX[:]))
+		if !g2S[i].IsInSubGroup() {
+			t.Fatalf("the point hashed to G2 of the secret %d isn't in G2", i)
+		}
+		if !sameRatio(t, g1[2*i], g1[2*i+1], g2S[i], g2[i]) {
+			t.Fatalf("the public key doesn't prove the knowledge of the secret %d", i)
+		}
+	}
+
+	for _, c := range []struct {
+		name          string
+		before, after bn254.G1Affine
+		secret        int
+	}{
+		{"τG1", before.G1.Tau[1], params.G1.Tau[1], 0},
+		{"α", before.G1.AlphaTau[0], params.G1.AlphaTau[0], 1},
+		{"β", before.G1.BetaTau[0], params.G1.BetaTau[0], 2},
+	} {
+		if !sameRatio(t, c.before, c.after, g2S[c.secret], g2[c.secret]) {
+			t.Fatalf("%s isn't multiplied by the secret of the public key", c.name)
+		}
+	}
+	if !sameRatio(t, g1[4], g1[5], before.G2.Beta, params.G2.Beta) {
+		t.Fatal("βG2 isn't multiplied by the secret β of the public key")
+	}
+	if !sameRatio(t, g1[0], g1[1], before.G2.Tau[1], params.G2.Tau[1]) {
+		t.Fatal("τG2 isn't multiplied by the secret τ of the public key")
+	}
+}
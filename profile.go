@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"linea/aztec-srs-to-gnark/aztec"
+	"linea/aztec-srs-to-gnark/celo"
+)
+
+// Profile holds the constants of a ceremony: the number of transcripts or
+// chunks, of points, the hash algorithms, etc. Variants of a supported ceremony run
+// with the same software are converted with a profile of their own. The sizes
+// of the points aren't part of it, they follow from the curve and the
+// encoding of the protocol.
+type Profile struct {
+	Protocol ProtocolName    `json:"protocol"`
+	Aztec    *aztec.Ceremony `json:"aztec,omitempty"`
+	Celo     *celo.Ceremony  `json:"celo,omitempty"`
+}
+
+// profiles are the built-in profiles, the ones of the published ceremonies.
+var profiles = map[string]func() Profile{
+	"ignition": func() Profile {
+		ceremony := aztec.Ignition
+		return Profile{Protocol: AztecProtocol, Aztec: &ceremony}
+	},
+	"plumo": func() Profile {
+		ceremony := celo.Plumo
+		return Profile{Protocol: CeloProtocol, Celo: &ceremony}
+	},
+}
+
+// profileUsage is the usage of the -profile flag of the commands reading setup files.
+const profileUsage = "ceremony profile, the name of a built-in one (ignition, plumo) or a JSON file overriding the constants of the one of its protocol"
+
+// loadProfile returns the built-in profile of the name, or reads the profile
+// file at path. The fields of the file override the ones of the built-in
+// profile of its protocol, e.g. {"protocol": "celo", "celo": {"chunks": 64}}.
+func loadProfile(name string) (Profile, error) {
+	if builtin, ok := profiles[name]; ok {
+		return builtin(), nil
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return Profile{}, fmt.Errorf("%s is neither a built-in profile (%s) nor a profile file: %w", name, strings.Join(profileNames(), ", "), err)
+	}
+
+	var header struct {
+		Protocol ProtocolName `json:"protocol"`
+	}
+	if err = json.Unmarshal(data, &header); err != nil {
+		return Profile{}, fmt.Errorf("invalid profile %s: %w", name, err)
+	}
+
+	var profile Profile
+	switch header.Protocol {
+	case AztecProtocol:
+		profile = profiles["ignition"]()
+	case CeloProtocol:
+		profile = profiles["plumo"]()
+	default:
+		return Profile{}, fmt.Errorf("invalid profile %s: no profile for the protocol %q, only for %s and %s", name, header.Protocol, AztecProtocol, CeloProtocol)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(&profile); err != nil {
+		return Profile{}, fmt.Errorf("invalid profile %s: %w", name, err)
+	}
+
+	if err = profile.validate(); err != nil {
+		return Profile{}, fmt.Errorf("invalid profile %s: %w", name, err)
+	}
+
+	return profile, nil
+}
+
+// validate checks that the profile holds consistent constants of its protocol.
+func (p Profile) validate() error {
+	switch {
+	case p.Protocol == AztecProtocol && p.Aztec != nil && p.Celo == nil:
+		return p.Aztec.Validate()
+	case p.Protocol == CeloProtocol && p.Celo != nil && p.Aztec == nil:
+		return p.Celo.Validate()
+	}
+
+	return fmt.Errorf("a %s profile holds the constants of the %s ceremony only", p.Protocol, p.Protocol)
+}
+
+// apply makes the commands read the setup files of the protocol of the
+// profile with its constants.
+func (p Profile) apply() {
+	switch p.Protocol {
+	case AztecProtocol:
+		supportedSetups[AztecProtocol][BN254Curve] = p.Aztec.Translate
+		supportedManifests[AztecProtocol][BN254Curve] = p.Aztec.Describe
+		supportedG2Extractions[AztecProtocol] = struct {
+			curve   CurveName
+			extract ExtractTauG2
+		}{BN254Curve, p.Aztec.ExtractTauG2}
+		supportedDownloads[AztecProtocol][BN254Curve] = p.Aztec.Downloads
+	case CeloProtocol:
+		supportedSetups[CeloProtocol][BW6761Curve] = p.Celo.Translate
+		supportedManifests[CeloProtocol][BW6761Curve] = p.Celo.Describe
+		supportedG2Extractions[CeloProtocol] = struct {
+			curve   CurveName
+			extract ExtractTauG2
+		}{BW6761Curve, p.Celo.ExtractTauG2}
+	}
+}
+
+// applyProfile loads the profile given to a -profile flag, if any, and applies it.
+func applyProfile(name string) error {
+	if name == "" {
+		return nil
+	}
+
+	profile, err := loadProfile(name)
+	if err != nil {
+		return err
+	}
+	profile.apply()
+
+	return nil
+}
+
+// profileNames returns the names of the built-in profiles.
+func profileNames() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
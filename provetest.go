@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"linea/aztec-srs-to-gnark/srsio"
+	"linea/aztec-srs-to-gnark/verify"
+)
+
+// defaultProveTestDegree bounds the degree of the polynomials committed to by
+// default, so large SRS are tested in seconds.
+const defaultProveTestDegree = 1<<16 - 1
+
+// proveTest commits to polynomials with an SRS file, opens and verifies them,
+// and proves and verifies a sample PLONK circuit with it, checking end to end
+// that the SRS works with KZG and PLONK provers and verifiers.
+func proveTest(args []string) error {
+	flags := flag.NewFlagSet("prove-test", flag.ExitOnError)
+	degree := flags.Int("degree", 0, fmt.Sprintf("degree of the polynomials committed to, -1 for the whole SRS (default: min(%d, number of points - 1))", defaultProveTestDegree))
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s prove-test [flags] <SRS file>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	args = parseInterspersed(flags, args)
+
+	if len(args) < 1 {
+		flags.Usage()
+		return errUsage
+	}
+
+	r, err := srsio.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	switch {
+	case *degree == 0:
+		*degree = min(defaultProveTestDegree, r.NbPoints-1)
+	case *degree < 0:
+		*degree = r.NbPoints - 1
+	}
+	if *degree >= r.NbPoints {
+		return fmt.Errorf("the SRS has %d points, the degree must be below %d", r.NbPoints, r.NbPoints)
+	}
+
+	// The PLONK circuit needs a few more points than the smallest SRS.
+	plonkPoints, err := verify.PlonkPoints(r.Curve)
+	if err != nil {
+		return err
+	}
+	srs, err := r.Range(0, min(max(*degree+1, plonkPoints), r.NbPoints))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Committing to polynomials of degree %d with %s (%s)\n", *degree, args[0], curveNames[r.Curve])
+
+	t, err := verify.RoundTrip(srs, *degree)
+	if err != nil {
+		fmt.Printf("FAILED: %v\n", err)
+		return exitStatus(1)
+	}
+
+	fmt.Printf("> commit (2 polynomials): %v\n", t.Commit)
+	fmt.Printf("> open:                   %v\n", t.Open)
+	fmt.Printf("> verify:                 %v\n", t.Verify)
+	fmt.Printf("> batch open:             %v\n", t.BatchOpen)
+	fmt.Printf("> batch verify:           %v\n", t.BatchVerify)
+	fmt.Println("OK: the openings are accepted and a wrong one is rejected")
+
+	fmt.Printf("\nProving x³ + x + 5 = 35 with the PLONK of gnark on a domain of %d\n", plonkPoints-3)
+
+	pt, err := verify.PlonkRoundTrip(srs)
+	if err != nil {
+		fmt.Printf("FAILED: %v\n", err)
+		return exitStatus(1)
+	}
+
+	fmt.Printf("> setup:                  %v\n", pt.Setup)
+	fmt.Printf("> prove:                  %v\n", pt.Prove)
+	fmt.Printf("> verify:                 %v\n", pt.Verify)
+	fmt.Println("OK: the proof is accepted and rejected for another public input")
+
+	return nil
+}
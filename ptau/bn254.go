@@ -0,0 +1,441 @@
+// Package ptau reads the .ptau files of snarkjs, the container of the powers
+// of τ of the bn254 ceremonies of the Hermez, Polygon zkEVM or Semaphore
+// projects among others.
+package ptau
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/audit"
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/offheap"
+	"linea/aztec-srs-to-gnark/verify"
+)
+
+const (
+	// Ext is the extension of the ptau files.
+	Ext = ".ptau"
+	// magic starts every ptau file.
+	magic = "ptau"
+	// readBufferSize is the size of the buffer used to read the G1 points.
+	readBufferSize = 4 << 20
+	// g1ReadBatch is the number of G1 points read at once.
+	g1ReadBatch = 1 << 12
+	// g1PointSize is the size of a G1 point: x and y.
+	g1PointSize = 2 * fp.Bytes
+	// g2PointSize is the size of a G2 point: x.c0, x.c1, y.c0 and y.c1.
+	g2PointSize = 4 * fp.Bytes
+)
+
+// Sections of a ptau file read by the importer, the others are skipped.
+const (
+	sectionHeader = 1
+	sectionTauG1  = 2
+	sectionTauG2  = 3
+)
+
+// errDone stops the scan of the sections once the ones needed are read.
+var errDone = errors.New("done")
+
+// modulus is the modulus of the base field of bn254, as the limbs of an
+// fp.Element.
+var modulus = func() (m fp.Element) {
+	for i, word := range fp.Modulus().Bits() {
+		m[i] = uint64(word)
+	}
+	return m
+}()
+
+// header is the content of the header section of a ptau file.
+type header struct {
+	// Power is the log2 of the number of G2 powers of the file, it holds twice
+	// as many G1 powers but one.
+	Power int
+	// CeremonyPower is the power of the ceremony the file was reduced from,
+	// 0 for the files written before it was recorded.
+	CeremonyPower int
+}
+
+// g1PowersN returns the number of G1 powers of the file.
+func (h header) g1PowersN() int {
+	return 1<<(h.Power+1) - 1
+}
+
+// readHeader reads the header section: the size of the field elements, the
+// modulus of the base field, which must be the one of bn254, and the powers.
+func readHeader(section io.Reader) (header, error) {
+	var n8 uint32
+	if err := binary.Read(section, binary.LittleEndian, &n8); err != nil {
+		return header{}, fmt.Errorf("failed to read header: %w", err)
+	}
+	if n8 != fp.Bytes {
+		return header{}, fmt.Errorf("the field elements have %d bytes, not the %d of bn254", n8, fp.Bytes)
+	}
+	var q [fp.Bytes]byte
+	if _, err := io.ReadFull(section, q[:]); err != nil {
+		return header{}, fmt.Errorf("failed to read header: %w", err)
+	}
+	var field fp.Element
+	for i := range field {
+		field[i] = binary.LittleEndian.Uint64(q[i*8:])
+	}
+	if field != modulus {
+		return header{}, errors.New("the field isn't the base field of bn254")
+	}
+
+	var powers [2]uint32
+	if err := binary.Read(section, binary.LittleEndian, &powers[0]); err != nil {
+		return header{}, fmt.Errorf("failed to read power: %w", err)
+	}
+	// The ceremony power was added to the header later
+	if err := binary.Read(section, binary.LittleEndian, &powers[1]); err != nil && !errors.Is(err, io.EOF) {
+		return header{}, fmt.Errorf("failed to read ceremony power: %w", err)
+	}
+	if powers[0] < 1 || powers[0] > 30 {
+		return header{}, fmt.Errorf("invalid power %d", powers[0])
+	}
+
+	return header{Power: int(powers[0]), CeremonyPower: int(powers[1])}, nil
+}
+
+// scan reads the magic and the sections of a ptau file in their order, calling
+// visit with every section; what visit doesn't read of the section is skipped.
+// The scan stops early without an error when visit returns errDone.
+func scan(f io.Reader, visit func(kind uint32, section *io.LimitedReader) error) error {
+	var start struct {
+		Magic     [4]byte
+		Version   uint32
+		SectionsN uint32
+	}
+	if err := binary.Read(f, binary.LittleEndian, &start); err != nil {
+		return fmt.Errorf("failed to read the start of the file: %w", err)
+	}
+	if string(start.Magic[:]) != magic {
+		return errors.New("not a ptau file: wrong magic")
+	}
+
+	for i := uint32(0); i < start.SectionsN; i++ {
+		var section struct {
+			Kind uint32
+			Size uint64
+		}
+		if err := binary.Read(f, binary.LittleEndian, &section); err != nil {
+			return fmt.Errorf("failed to read section %d: %w", i, err)
+		}
+
+		r := &io.LimitedReader{R: f, N: int64(section.Size)}
+		if err := visit(section.Kind, r); err != nil {
+			if errors.Is(err, errDone) {
+				return nil
+			}
+			return err
+		}
+		if err := input.Skip(f, r.N); err != nil {
+			return fmt.Errorf("failed to skip section %d: %w", section.Kind, err)
+		}
+	}
+
+	return nil
+}
+
+// decodeMontgomery decodes coordinates stored the way snarkjs does: in the
+// Montgomery form, as little endian 64-bit limbs like fp.Element.
+func decodeMontgomery(data []byte, coordinates ...*fp.Element) error {
+	for i, c := range coordinates {
+		for j := range c {
+			c[j] = binary.LittleEndian.Uint64(data[i*fp.Bytes+j*8:])
+		}
+
+		// The limbs are compared from the most significant one
+		reduced := false
+		for j := len(c) - 1; j >= 0; j-- {
+			if c[j] != modulus[j] {
+				reduced = c[j] < modulus[j]
+				break
+			}
+		}
+		if !reduced {
+			return fmt.Errorf("coordinate %d isn't smaller than the modulus", i)
+		}
+	}
+
+	return nil
+}
+
+// readG1Points reads the G1 points in batches, checks that they are on the
+// curve and passes every batch to the verification checks. It returns the
+// number of points read before an error.
+func readG1Points(r io.Reader, points []bn254.G1Affine, checks *verify.Pipeline[bn254.G1Affine], progress *config.Progress) (int, error) {
+	buf := make([]byte, g1ReadBatch*g1PointSize)
+
+	for from := 0; from < len(points); from += g1ReadBatch {
+		batch := points[from:min(from+g1ReadBatch, len(points))]
+		data := buf[:len(batch)*g1PointSize]
+
+		if _, err := io.ReadFull(r, data); err != nil {
+			return from, fmt.Errorf("failed to read G1 points %d-%d: %w", from, from+len(batch)-1, err)
+		}
+		for i := range batch {
+			p := &batch[i]
+			if err := decodeMontgomery(data[i*g1PointSize:], &p.X, &p.Y); err != nil {
+				return from + i, fmt.Errorf("invalid G1 point %d: %w", from+i, err)
+			}
+			if !p.IsOnCurve() {
+				return from + i, fmt.Errorf("G1 point %d is not on the curve", from+i)
+			}
+		}
+
+		checks.Check(from, batch)
+		progress.Add(len(batch))
+	}
+
+	return len(points), nil
+}
+
+// readG2Powers reads the generator and τG2, the first points of the tauG2
+// section, into the verifying key.
+func readG2Powers(section io.Reader, srs *bnKzg.SRS) error {
+	var data [2 * g2PointSize]byte
+	if _, err := io.ReadFull(section, data[:]); err != nil {
+		return fmt.Errorf("failed to read τG2: %w", err)
+	}
+	for i := range srs.Vk.G2 {
+		p := &srs.Vk.G2[i]
+		if err := decodeMontgomery(data[i*g2PointSize:], &p.X.A0, &p.X.A1, &p.Y.A0, &p.Y.A1); err != nil {
+			return fmt.Errorf("invalid G2 power %d: %w", i, err)
+		}
+		if !p.IsOnCurve() {
+			return fmt.Errorf("G2 power %d is not on the curve", i)
+		}
+	}
+	if _, _, _, g2Gen := bn254.Generators(); !srs.Vk.G2[0].Equal(&g2Gen) {
+		return errors.New("the first G2 power isn't the generator")
+	}
+	srs.Vk.Lines[0] = bn254.PrecomputeLines(srs.Vk.G2[0])
+	srs.Vk.Lines[1] = bn254.PrecomputeLines(srs.Vk.G2[1])
+
+	fmt.Printf("> a^1*G2: %s %s\n", srs.Vk.G2[1].X.String(), srs.Vk.G2[1].Y.String())
+
+	return nil
+}
+
+// findFile returns the ptau file among the files, the only one, e.g. read from
+// the standard input, or the only one of extension Ext, and the other files.
+func findFile(files []input.File) (input.File, []input.File, error) {
+	if len(files) == 1 {
+		return files[0], nil, nil
+	}
+
+	var found, others []input.File
+	for _, file := range files {
+		if strings.EqualFold(filepath.Ext(file.Name), Ext) {
+			found = append(found, file)
+		} else {
+			others = append(others, file)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return input.File{}, nil, fmt.Errorf("no %s file found", Ext)
+	case 1:
+		return found[0], others, nil
+	}
+
+	return input.File{}, nil, fmt.Errorf("several %s files found: %s and %s", Ext, found[0].Name, found[1].Name)
+}
+
+// TranslateBn254SRS reads the tauG1 and tauG2 sections of a ptau file and
+// constructs a KZG SRS from them: all the G1 powers, or with opts.Degree the
+// first 2^opts.Degree ones, only them being read. The sections are read in
+// their order, so the file can be streamed, e.g. from the standard input; the
+// other sections are skipped. The other files and the G1 points that can't be
+// read are rejected with opts.Reject: under the lenient validation the SRS
+// ends before the first point that can't be read.
+func TranslateBn254SRS(files []input.File, opts config.Options) (kzg.SRS, int, error) {
+	file, others, err := findFile(files)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, other := range others {
+		opts.Ignore(other.Name, "not a "+Ext+" file")
+		opts.Audit.Record(audit.Event{Kind: audit.KindSetupFile, File: other.Name, Decision: "ignored: not a " + Ext + " file"})
+	}
+
+	fmt.Printf("Processing file %s\n", file.Name)
+	f, err := file.Open()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open %s: %w", file.Name, err)
+	}
+	defer f.Close()
+
+	var (
+		h       *header
+		g1Read  bool
+		g2Read  bool
+		srs     = new(bnKzg.SRS)
+		checker *verify.Bn254Checker
+		checks  *verify.Pipeline[bn254.G1Affine]
+		// The first point that can't be read, -1 while none is
+		failedFrom = -1
+	)
+	if opts.Verify {
+		if checker, err = verify.NewBn254Checker(); err != nil {
+			return nil, 0, err
+		}
+		checks = verify.NewPipeline(checker.Batch, opts.Workers)
+	}
+
+	err = scan(f, func(kind uint32, section *io.LimitedReader) error {
+		if (kind == sectionTauG1 || kind == sectionTauG2) && h == nil {
+			return fmt.Errorf("section %d precedes the header", kind)
+		}
+
+		switch kind {
+		case sectionHeader:
+			read, err := readHeader(section)
+			if err != nil {
+				return err
+			}
+			h = &read
+			fmt.Printf("> power %d, %d G1 powers", h.Power, h.g1PowersN())
+			if h.CeremonyPower != 0 {
+				fmt.Printf(", of a ceremony of power %d", h.CeremonyPower)
+			}
+			fmt.Println()
+		case sectionTauG1:
+			if section.N != int64(h.g1PowersN())*g1PointSize {
+				return fmt.Errorf("the tauG1 section holds %d bytes, not the %d G1 powers of power %d", section.N, h.g1PowersN(), h.Power)
+			}
+			n := h.g1PowersN()
+			if opts.Degree > 0 {
+				if 1<<opts.Degree > n {
+					return fmt.Errorf("the file holds %d points, less than 2^%d", n, opts.Degree)
+				}
+				n = 1 << opts.Degree
+			}
+
+			var err error
+			if srs.Pk.G1, err = offheap.Make[bn254.G1Affine](n, opts); err != nil {
+				return err
+			}
+			opts.Progress.SetTotal(n)
+
+			read, err := readG1Points(bufio.NewReaderSize(section, readBufferSize), srs.Pk.G1, checks, opts.Progress)
+			if err != nil {
+				// The points before the first one that can't be read are kept
+				if read < 2 {
+					return err
+				}
+				if err = opts.Reject(config.Skip{File: file.Name, Offset: audit.Index(read), Points: n - read}, err); err != nil {
+					return err
+				}
+				opts.Warn("G1 points from %d can't be read: the SRS ends at G1 point %d", read, read-1)
+				failedFrom = read
+			}
+			g1Read = true
+		case sectionTauG2:
+			if section.N != int64(1<<h.Power)*g2PointSize {
+				return fmt.Errorf("the tauG2 section holds %d bytes, not the %d G2 powers of power %d", section.N, 1<<h.Power, h.Power)
+			}
+			if err := readG2Powers(section, srs); err != nil {
+				return err
+			}
+			g2Read = true
+		}
+
+		if g1Read && g2Read {
+			return errDone
+		}
+		return nil
+	})
+	if err == nil && !(g1Read && g2Read) {
+		err = errors.New("the file lacks the tauG1 or tauG2 section")
+	}
+	if err != nil {
+		checks.Wait()
+		return nil, 0, fmt.Errorf("failed to read %s: %w", file.Name, err)
+	}
+
+	if failedFrom >= 0 {
+		srs.Pk.G1 = srs.Pk.G1[:failedFrom]
+	}
+	opts.Audit.Record(audit.Event{Kind: audit.KindSetupFile, File: file.Name, Points: len(srs.Pk.G1), Decision: "used"})
+	srs.Vk.G1 = srs.Pk.G1[0]
+
+	if _, _, g1Gen, _ := bn254.Generators(); !srs.Vk.G1.Equal(&g1Gen) {
+		checks.Wait()
+		return nil, 0, fmt.Errorf("the first G1 power of %s isn't the generator", file.Name)
+	}
+
+	fmt.Printf("> a^1*G1: %s %s\n", srs.Pk.G1[1].X.String(), srs.Pk.G1[1].Y.String())
+
+	if checker != nil {
+		err = checks.Wait()
+		switch {
+		case failedFrom >= 0:
+			// The points were checked up to the failed one, so the fused checks
+			// don't apply
+			err = verify.SRS(srs, opts)
+		case err == nil:
+			err = checker.Finish(srs)
+		}
+		// Under the lenient validation the SRS ends before a point failing
+		if err = verify.Recover(srs, opts, err); err != nil {
+			return nil, 0, fmt.Errorf("%w: %w", verify.ErrFailed, err)
+		}
+		fmt.Println("SRS verified: all G1 points are in the subgroup and are consecutive powers of tau")
+	}
+
+	return srs, len(srs.Pk.G1), nil
+}
+
+// ExtractTauG2 returns an SRS with only the verifying key, read from the
+// tauG2 section of the ptau file. The tauG1 section is skipped.
+func ExtractTauG2(files []input.File) (kzg.SRS, error) {
+	file, _, err := findFile(files)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", file.Name, err)
+	}
+	defer f.Close()
+
+	srs := new(bnKzg.SRS)
+	_, _, srs.Vk.G1, _ = bn254.Generators()
+
+	read := false
+	err = scan(f, func(kind uint32, section *io.LimitedReader) error {
+		if kind != sectionTauG2 {
+			return nil
+		}
+		if err := readG2Powers(section, srs); err != nil {
+			return err
+		}
+		read = true
+		return errDone
+	})
+	if err == nil && !read {
+		err = errors.New("the file lacks the tauG2 section")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+	}
+
+	return srs, nil
+}
@@ -0,0 +1,107 @@
+package registry
+
+import (
+	"crypto/ed25519"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"linea/aztec-srs-to-gnark/attest"
+)
+
+// PredicateType identifies the registry predicate.
+const PredicateType = "https://github.com/distributed-lab/gnark-mpc-kzg-srs/registry/v1"
+
+// Entry is an officially published SRS.
+type Entry struct {
+	Name     string `json:"name"`
+	Protocol string `json:"protocol"`
+	Curve    string `json:"curve"`
+	Points   int    `json:"points"`
+	// Digest is the canonical digest of the SRS in hex, see srsio.Digest, so
+	// the entry matches the SRS in any format.
+	Digest string `json:"digest"`
+	// URL is where the SRS is published.
+	URL string `json:"url,omitempty"`
+}
+
+// Registry lists the known-good SRS.
+type Registry struct {
+	Version int     `json:"version"`
+	Entries []Entry `json:"entries"`
+}
+
+//go:embed registry.json
+var embedded []byte
+
+// Embedded returns the registry shipped with the tool. Being part of the
+// binary, it isn't signed.
+func Embedded() (*Registry, error) {
+	var r Registry
+	if err := json.Unmarshal(embedded, &r); err != nil {
+		return nil, fmt.Errorf("invalid embedded registry: %w", err)
+	}
+
+	return &r, nil
+}
+
+// Sign wraps the registry into an attestation signed with the key: an in-toto
+// statement whose subjects are the SRS of the entries and whose predicate is
+// the registry.
+func Sign(r *Registry, key ed25519.PrivateKey) (*attest.Envelope, error) {
+	statement := attest.Statement{
+		Type:          attest.StatementType,
+		Subject:       []attest.Subject{},
+		PredicateType: PredicateType,
+		Predicate:     r,
+	}
+	for _, e := range r.Entries {
+		statement.Subject = append(statement.Subject, attest.Subject{Name: e.Name, Digest: map[string]string{"srs": e.Digest}})
+	}
+
+	return attest.Sign(statement, key)
+}
+
+// Open returns the registry of an attestation written by Sign, after checking
+// it is signed with the key.
+func Open(data []byte, pub ed25519.PublicKey) (*Registry, error) {
+	var envelope attest.Envelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("invalid registry envelope: %w", err)
+	}
+
+	statement, err := envelope.Verify(pub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify registry: %w", err)
+	}
+	if statement.PredicateType != PredicateType {
+		return nil, fmt.Errorf("unexpected predicate type %q in registry", statement.PredicateType)
+	}
+
+	// The predicate was decoded as a generic value.
+	predicate, err := json.Marshal(statement.Predicate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry: %w", err)
+	}
+
+	var r Registry
+	if err = json.Unmarshal(predicate, &r); err != nil {
+		return nil, fmt.Errorf("invalid registry: %w", err)
+	}
+
+	return &r, nil
+}
+
+// Lookup returns the entries of the SRS on the curve with the canonical
+// digest, in hex.
+func (r *Registry) Lookup(curve, digest string) []Entry {
+	var res []Entry
+	for _, e := range r.Entries {
+		if e.Curve == curve && strings.EqualFold(e.Digest, digest) {
+			res = append(res, e)
+		}
+	}
+
+	return res
+}
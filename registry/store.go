@@ -0,0 +1,139 @@
+package registry
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// installedFile is the file of the registry installed by Install.
+const installedFile = "registry.json"
+
+// installed is the signed registry installed by Install, as fetched, with the
+// public key it was verified with, stored together so they are replaced at
+// once.
+type installed struct {
+	PublicKey string          `json:"public_key"`
+	Envelope  json.RawMessage `json:"envelope"`
+}
+
+// DefaultDir returns the directory the updated registry is installed into,
+// under the configuration directory of the user.
+func DefaultDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the configuration directory: %w", err)
+	}
+
+	return filepath.Join(dir, "gnark-mpc-kzg-srs"), nil
+}
+
+// Install stores the signed registry into dir with the public key it is
+// signed with, after checking the signature, so the registry is trusted by
+// the next runs of the tool without a new release.
+func Install(dir string, data []byte, pub ed25519.PublicKey) (*Registry, error) {
+	r, err := Open(data, pub)
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode public key: %w", err)
+	}
+
+	data, err = json.MarshalIndent(installed{
+		PublicKey: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+		Envelope:  data,
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode registry: %w", err)
+	}
+
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create registry directory: %w", err)
+	}
+	if err = writeAtomic(filepath.Join(dir, installedFile), append(data, '\n')); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Installed returns the registry installed into dir, checking its signature
+// again, or nil when none is.
+func Installed(dir string) (*Registry, error) {
+	path := filepath.Join(dir, installedFile)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read installed registry: %w", err)
+	}
+
+	var stored installed
+	if err = json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("invalid installed registry %s: %w", path, err)
+	}
+	block, _ := pem.Decode([]byte(stored.PublicKey))
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block in the key of the installed registry %s", path)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the key of the installed registry: %w", err)
+	}
+	pub, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("the key of the installed registry is a %T, not an ed25519 key", key)
+	}
+
+	r, err := Open(stored.Envelope, pub)
+	if err != nil {
+		return nil, fmt.Errorf("installed registry: %w", err)
+	}
+
+	return r, nil
+}
+
+// Current returns the registry in use: the one installed into dir when it is
+// newer than the embedded one, the embedded one otherwise. installed tells
+// which one it is.
+func Current(dir string) (r *Registry, installed bool, err error) {
+	embedded, err := Embedded()
+	if err != nil {
+		return nil, false, err
+	}
+
+	updated, err := Installed(dir)
+	if err != nil {
+		return nil, false, err
+	}
+	if updated != nil && updated.Version > embedded.Version {
+		return updated, true, nil
+	}
+
+	return embedded, false, nil
+}
+
+// writeAtomic replaces the file at path with data, so a registry is never
+// left half written.
+func writeAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return nil
+}
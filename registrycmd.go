@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"linea/aztec-srs-to-gnark/attest"
+	"linea/aztec-srs-to-gnark/fetch"
+	"linea/aztec-srs-to-gnark/registry"
+)
+
+// registryCommand shows the registry of known-good SRS in use, or updates it
+// from a newer signed registry, so newly published conversions are trusted
+// without a release of the tool.
+func registryCommand(args []string) error {
+	flags := flag.NewFlagSet("registry", flag.ExitOnError)
+	dir := flags.String("dir", "", "directory the updated registry is installed into (default: the configuration directory of the user)")
+	location := flags.String("registry", "", "URL or path of the signed registry to update from, update only")
+	keyPath := flags.String("registry-key", "", "ed25519 public key (PKIX PEM) the registry is signed with, update only")
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s registry [flags] show|update\n", os.Args[0])
+		fmt.Println("show lists the entries of the registry in use, update installs a newer signed registry.")
+		flags.PrintDefaults()
+	}
+	args = parseInterspersed(flags, args)
+
+	if len(args) < 1 || (args[0] == "update" && (*location == "" || *keyPath == "")) {
+		flags.Usage()
+		return errUsage
+	}
+
+	if *dir == "" {
+		var err error
+		if *dir, err = registry.DefaultDir(); err != nil {
+			return err
+		}
+	}
+
+	current, installed, err := registry.Current(*dir)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "show":
+		source := "embedded in the tool"
+		if installed {
+			source = "installed in " + *dir
+		}
+		fmt.Printf("Registry version %d, %s, %d entries\n", current.Version, source, len(current.Entries))
+		for _, e := range current.Entries {
+			fmt.Printf("%s: the %s %s conversion of %d points, canonical digest %s", e.Name, e.Protocol, e.Curve, e.Points, e.Digest)
+			if e.URL != "" {
+				fmt.Printf(", published at %s", e.URL)
+			}
+			fmt.Println()
+		}
+	case "update":
+		pub, err := attest.LoadPublicKey(*keyPath)
+		if err != nil {
+			return err
+		}
+		data, err := fetch.Read(context.Background(), *location)
+		if err != nil {
+			return fmt.Errorf("failed to read registry: %w", err)
+		}
+
+		fetched, err := registry.Open(data, pub)
+		if err != nil {
+			return err
+		}
+		// A registry is never downgraded, an older one could drop entries or
+		// restore ones withdrawn since.
+		if fetched.Version <= current.Version {
+			fmt.Printf("The registry is up to date: %s is version %d, the one in use version %d\n", *location, fetched.Version, current.Version)
+			return nil
+		}
+
+		if _, err = registry.Install(*dir, data, pub); err != nil {
+			return err
+		}
+		fmt.Printf("Registry updated from version %d to version %d, %d entries, installed in %s\n",
+			current.Version, fetched.Version, len(fetched.Entries), *dir)
+	default:
+		fmt.Printf("ERROR: unknown registry command %s\n", args[0])
+		flags.Usage()
+		return errUsage
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"linea/aztec-srs-to-gnark/srsio"
+)
+
+// repair recomputes the derived parts of the verifying keys of SRS files and
+// rewrites them, fixing dumps of older tools or other software which omitted
+// them.
+func repair(args []string) error {
+	flags := flag.NewFlagSet("repair", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "only report the parts of the verifying keys to repair")
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s repair [flags] <SRS file>...\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	args = parseInterspersed(flags, args)
+
+	if len(args) < 1 {
+		flags.Usage()
+		return errUsage
+	}
+
+	for _, path := range args {
+		repaired, err := srsio.RepairVk(path, *dryRun)
+		if err != nil {
+			fmt.Printf("%s: %v\n", path, err)
+			continue
+		}
+
+		switch {
+		case len(repaired) == 0:
+			fmt.Printf("%s: the verifying key is complete\n", path)
+			continue
+		case *dryRun:
+			fmt.Printf("%s: %s to repair\n", path, strings.Join(repaired, ", "))
+			continue
+		}
+		fmt.Printf("%s: repaired %s\n", path, strings.Join(repaired, ", "))
+
+		// The checksums written next to the file no longer match.
+		if _, err = os.Stat(path + ".checksums"); err != nil {
+			continue
+		}
+		sums, err := srsio.FileChecksums(path)
+		if err == nil {
+			_, err = srsio.WriteManifest(path, sums)
+		}
+		if err != nil {
+			fmt.Printf("%s: %v\n", path, err)
+			continue
+		}
+		fmt.Printf("%s: checksums updated\n", path)
+	}
+
+	return nil
+}
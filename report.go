@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"linea/aztec-srs-to-gnark/audit"
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/fetch"
+	"linea/aztec-srs-to-gnark/srsio"
+)
+
+// webhookTimeout bounds the delivery of a completion notification.
+const webhookTimeout = 30 * time.Second
+
+// runReport summarises a finished run for the completion notifications.
+type runReport struct {
+	Command  string    `json:"command"`
+	Protocol string    `json:"protocol"`
+	Curve    string    `json:"curve"`
+	Setup    string    `json:"setup"`
+	Result   string    `json:"result"`
+	Error    string    `json:"error,omitempty"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Duration float64   `json:"duration_seconds"`
+
+	Output   string `json:"output,omitempty"`
+	Manifest string `json:"manifest,omitempty"`
+	Format   string `json:"format,omitempty"`
+	NbPoints int    `json:"points,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+	SHA256   string `json:"sha256,omitempty"`
+	BLAKE2b  string `json:"blake2b,omitempty"`
+	Torrent  string `json:"torrent,omitempty"`
+	Magnet   string `json:"magnet,omitempty"`
+	// Uploaded are the locations the outputs were uploaded to.
+	Uploaded []string `json:"uploaded,omitempty"`
+	Verified bool     `json:"verified"`
+	// LoadChecked tells the output was loaded back with gnark-crypto, see
+	// srsio.CheckLoad.
+	LoadChecked bool `json:"load_checked,omitempty"`
+	FromCache   bool `json:"from_cache"`
+
+	// Signers are the Ethereum addresses the Aztec transcripts are signed by,
+	// by file name.
+	Signers map[string]string `json:"signers,omitempty"`
+
+	Validation string `json:"validation,omitempty"`
+	// Skipped are the parts of the setup skipped under the lenient validation.
+	Skipped []config.Skip `json:"skipped,omitempty"`
+	// Omitted are the ranges of the G1 points of the ceremony missing for the
+	// skipped parts, recorded with them in the SkipInfo file.
+	Omitted  []srsio.Range `json:"omitted,omitempty"`
+	SkipInfo string        `json:"skip_info,omitempty"`
+}
+
+// printSkipped lists the parts of the setup skipped under the lenient
+// validation.
+func printSkipped(skipped []config.Skip) {
+	fmt.Printf("\n%d parts of the setup skipped under the lenient validation:\n", len(skipped))
+	for _, skip := range skipped {
+		var part []string
+		if skip.File != "" {
+			part = append(part, skip.File)
+		}
+		if skip.Index != nil {
+			part = append(part, fmt.Sprintf("index %d", *skip.Index))
+		}
+		switch {
+		case skip.Offset != nil && skip.Points > 0:
+			part = append(part, srsio.Range{From: *skip.Offset, To: *skip.Offset + skip.Points}.String())
+		case skip.Offset != nil:
+			part = append(part, fmt.Sprintf("from G1 point %d", *skip.Offset))
+		}
+		fmt.Printf("> %s: %s\n", strings.Join(part, ", "), skip.Reason)
+	}
+
+	if omitted := srsio.OmittedRanges(skipped); len(omitted) != 0 {
+		ranges := make([]string, len(omitted))
+		for i, r := range omitted {
+			ranges[i] = r.String()
+		}
+		fmt.Printf("G1 points of the ceremony omitted: %s\n", strings.Join(ranges, ", "))
+	}
+}
+
+// notify writes the report into doneFile and posts it to webhook, when set.
+// Failing to notify doesn't fail the run, it is only reported.
+func (r *runReport) notify(webhook, doneFile string) {
+	r.End = time.Now()
+	r.Duration = r.End.Sub(r.Start).Seconds()
+
+	if doneFile != "" {
+		if err := writeReport(doneFile, r); err != nil {
+			fmt.Printf("WARNING: %v\n", err)
+		}
+	}
+
+	if webhook != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+		defer cancel()
+
+		if err := fetch.PostJSON(ctx, webhook, r); err != nil {
+			fmt.Printf("WARNING: failed to notify the webhook: %v\n", err)
+		}
+	}
+}
+
+// writeReport writes the report as JSON, atomically so that a pipeline
+// waiting for the file never reads a partial one.
+func writeReport(path string, r *runReport) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode run report: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write completion file: %w", err)
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// transcriptSigners returns the signers of the transcripts used, by file
+// name, from the events of the audit log.
+func transcriptSigners(events []audit.Event) map[string]string {
+	var signers map[string]string
+	for _, event := range events {
+		if event.Kind != audit.KindTranscript || event.Participant == "" || event.Decision != "used" {
+			continue
+		}
+		if signers == nil {
+			signers = make(map[string]string)
+		}
+		signers[event.File] = event.Participant
+	}
+
+	return signers
+}
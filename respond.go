@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/digest"
+)
+
+// respond contributes fresh secrets to a challenge file of a powersoftau
+// ceremony and writes the response file, to be uploaded to its coordinator.
+func respond(args []string) error {
+	var opts config.Options
+
+	flags := flag.NewFlagSet("respond", flag.ExitOnError)
+	flags.IntVar(&opts.Workers, "workers", 0, "number of CPU workers (0 - auto)")
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s respond [flags] <protocol> <curve> <challenge file> <response file>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	args = parseInterspersed(flags, args)
+
+	if len(args) < 4 {
+		flags.Usage()
+		return errUsage
+	}
+
+	curves, ok := supportedResponses[ProtocolName(args[0])]
+	if !ok {
+		protocols := make([]string, 0, len(supportedResponses))
+		for protocol := range supportedResponses {
+			protocols = append(protocols, string(protocol))
+		}
+		return fmt.Errorf("unsupported protocol, use one of: %s", strings.Join(protocols, ", "))
+	}
+	respondTo, ok := curves[CurveName(args[1])]
+	if !ok {
+		return fmt.Errorf("unsupported curve %s for the %s protocol", args[1], args[0])
+	}
+	in, out := args[2], args[3]
+
+	// Only the CPU settings matter, no setup directory is read.
+	opts.IOParallelism = 1
+	opts = config.Tune(opts, "")
+
+	challenge, err := os.Open(in)
+	if err != nil {
+		return fmt.Errorf("failed to open challenge: %w", err)
+	}
+	defer challenge.Close()
+
+	response, err := os.Create(out)
+	if err != nil {
+		return fmt.Errorf("failed to create response: %w", err)
+	}
+	// The response is hashed while it is written, the hash the participants
+	// publish and the next challenge starts with
+	h := digest.BLAKE2b512.New()
+	challengeHash, err := respondTo(challenge, io.MultiWriter(response, h), opts)
+	if err == nil {
+		err = response.Close()
+	} else {
+		response.Close()
+	}
+	if err != nil {
+		os.Remove(out)
+		return err
+	}
+
+	fmt.Printf("Contributed to the challenge of hash %s\n", hex.EncodeToString(challengeHash))
+	fmt.Printf("Response written to %s, of hash %s\n", out, hex.EncodeToString(h.Sum(nil)))
+
+	return nil
+}
@@ -0,0 +1,85 @@
+// Package revision describes the revision of the file format of a ceremony.
+// The transcripts and chunk files of the published ceremonies carry no version
+// field, a profile names the revision its files are written in and the bytes
+// the revision reserves after their header. A file longer than its header
+// announces by at most MaxHeaderExtension bytes is reported as the file of an
+// unsupported revision instead of being read as garbage points.
+package revision
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Latest is the revision of the formats of the published ceremonies, the
+// only one this build reads.
+const Latest = 1
+
+// MaxHeaderExtension bounds the number of bytes a revision of a format may
+// add after the header of its files.
+const MaxHeaderExtension = 1 << 16
+
+// ErrUnsupported is wrapped by the errors reporting a file of a revision of
+// its format which can't be read.
+var ErrUnsupported = errors.New("unsupported version")
+
+// Format is the revision of the file format of a ceremony, as written in its
+// profile.
+type Format struct {
+	// Version of the format, Latest when unset.
+	Version int `json:"version,omitempty"`
+	// HeaderExtension is the number of padding or extension bytes following
+	// the header of the files, which are skipped.
+	HeaderExtension int64 `json:"header_extension,omitempty"`
+}
+
+// UnsupportedError reports a version of a format this build doesn't read.
+type UnsupportedError struct {
+	// Format is the name of the format, e.g. "aztec transcript".
+	Format    string
+	Version   int
+	Supported []int
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("%s %d of the %s format, this build reads the versions %v", ErrUnsupported, e.Version, e.Format, e.Supported)
+}
+
+func (e *UnsupportedError) Unwrap() error {
+	return ErrUnsupported
+}
+
+// Validate checks that the version is one of the supported ones, returning
+// an *UnsupportedError otherwise, and that the header extension is in bounds.
+func (f Format) Validate(name string, supported ...int) error {
+	if version := f.version(); !slices.Contains(supported, version) {
+		return &UnsupportedError{Format: name, Version: version, Supported: supported}
+	}
+	if f.HeaderExtension < 0 || f.HeaderExtension > MaxHeaderExtension {
+		return fmt.Errorf("the header extension of the %s format must be between 0 and %d bytes, not %d", name, MaxHeaderExtension, f.HeaderExtension)
+	}
+
+	return nil
+}
+
+func (f Format) version() int {
+	if f.Version == 0 {
+		return Latest
+	}
+
+	return f.Version
+}
+
+// CheckSize reports a file of size bytes longer than the expected size of
+// its layout in the format by at most MaxHeaderExtension bytes, the file of a
+// revision of the format with a longer header, wrapping ErrUnsupported. It
+// returns nil for the other sizes, which the importers check themselves.
+func (f Format) CheckSize(name string, size, expected int64) error {
+	if extra := size - expected; extra > 0 && extra <= MaxHeaderExtension {
+		return fmt.Errorf("%w of the %s format: the file has %d bytes more than the layout of version %d, set the header_extension of the format in the profile if they are known padding",
+			ErrUnsupported, name, extra, f.version())
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+
+	"linea/aztec-srs-to-gnark/server"
+)
+
+// serve exposes the SRS files of a directory over HTTP, and gRPC with
+// -grpc-addr.
+func serve(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", ":8080", "address to listen on")
+	grpcAddr := flags.String("grpc-addr", "", "address to serve the gRPC service srs.v1.SRS on, none by default")
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s serve [flags] <SRS files directory>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() < 1 {
+		flags.Usage()
+		return errUsage
+	}
+
+	s := server.New(flags.Arg(0))
+	errs := make(chan error, 2)
+
+	if *grpcAddr != "" {
+		lis, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Serving the SRS files of %s over gRPC on %s\n", flags.Arg(0), *grpcAddr)
+		go func() { errs <- s.GRPC().Serve(lis) }()
+	}
+
+	fmt.Printf("Serving the SRS files of %s on %s\n", flags.Arg(0), *addr)
+	go func() { errs <- http.ListenAndServe(*addr, s.Handler()) }()
+
+	return <-errs
+}
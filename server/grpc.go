@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"linea/aztec-srs-to-gnark/metrics"
+	"linea/aztec-srs-to-gnark/server/srspb"
+)
+
+var (
+	grpcRequests = metrics.Default.NewCounter("srs_grpc_requests_total",
+		"Number of gRPC requests served.", "method", "code")
+	grpcRequestDuration = metrics.Default.NewHistogram("srs_grpc_request_duration_seconds",
+		"Duration of the gRPC requests.", metrics.DefaultBuckets, "method")
+	grpcSentBytes = metrics.Default.NewCounter("srs_grpc_sent_bytes_total",
+		"Number of bytes of SRS data served over gRPC.", "method")
+)
+
+// chunkSize is the size of the chunks of SRS data streamed by Get.
+const chunkSize = 1 << 20
+
+// GRPC returns a gRPC server of the service srs.v1.SRS, the counterpart of the
+// HTTP handler:
+//   - List lists the SRS files;
+//   - Get streams an SRS file, its first points or its verifying key only.
+//
+// The service is defined in proto/srs/v1/srs.proto and served with
+// reflection. The requests are recorded in the metrics served by the HTTP
+// handler.
+func (s *Server) GRPC() *grpc.Server {
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			start := time.Now()
+			resp, err := handler(ctx, req)
+			observeGRPC(info.FullMethod, start, err)
+			return resp, err
+		}),
+		grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			start := time.Now()
+			err := handler(srv, ss)
+			observeGRPC(info.FullMethod, start, err)
+			return err
+		}),
+	)
+
+	srspb.RegisterSRSServer(srv, grpcService{s: s})
+	reflection.Register(srv)
+
+	return srv
+}
+
+func observeGRPC(method string, start time.Time, err error) {
+	grpcRequests.Inc(method, status.Code(err).String())
+	grpcRequestDuration.Observe(time.Since(start).Seconds(), method)
+}
+
+// grpcService implements the gRPC service on the server.
+type grpcService struct {
+	srspb.UnimplementedSRSServer
+	s *Server
+}
+
+func (g grpcService) List(context.Context, *srspb.ListRequest) (*srspb.ListResponse, error) {
+	files, err := g.s.Files()
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to read SRS directory")
+	}
+
+	resp := &srspb.ListResponse{}
+	for _, file := range files {
+		resp.Files = append(resp.Files, &srspb.FileInfo{
+			Name:   file.Name,
+			Format: file.Format,
+			Curve:  file.Curve,
+			Points: int64(file.NbPoints),
+			Size:   file.Size,
+		})
+	}
+
+	return resp, nil
+}
+
+func (g grpcService) Get(req *srspb.GetRequest, stream grpc.ServerStreamingServer[srspb.Chunk]) error {
+	r, _, err := g.s.open(req.Name)
+	switch {
+	case errors.Is(err, errInvalidName):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, fs.ErrNotExist):
+		return status.Errorf(codes.NotFound, "%s not found", req.Name)
+	case err != nil:
+		return status.Errorf(codes.NotFound, "%s isn't an SRS file", req.Name)
+	}
+	defer r.Close()
+
+	n := r.NbPoints
+	if req.Points != 0 {
+		if req.Points < 0 || req.Points > int64(r.NbPoints) {
+			return status.Errorf(codes.InvalidArgument, "points must be between 1 and %d", r.NbPoints)
+		}
+		n = int(req.Points)
+	}
+	if req.VkOnly {
+		n = 0
+	}
+
+	slice, err := r.Slice(0, n)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	for {
+		// A new buffer per chunk, the messages sent may be used lazily
+		buf := make([]byte, chunkSize)
+		read, err := io.ReadFull(slice, buf)
+		if read > 0 {
+			if err := stream.Send(&srspb.Chunk{Data: buf[:read]}); err != nil {
+				return err
+			}
+			grpcSentBytes.Add(float64(read), srspb.SRS_Get_FullMethodName)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"linea/aztec-srs-to-gnark/metrics"
+	"linea/aztec-srs-to-gnark/server/srspb"
+	"linea/aztec-srs-to-gnark/srsio"
+)
+
+func TestGRPC(t *testing.T) {
+	dir := t.TempDir()
+	srs, err := bnKzg.NewSRS(16, big.NewInt(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file bytes.Buffer
+	if _, err = srs.WriteRawTo(&file); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, "test.srs"), file.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, "README"), []byte("not an SRS"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := New(dir).GRPC()
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := srspb.NewSRSClient(conn)
+	ctx := context.Background()
+
+	resp, err := client.List(ctx, &srspb.ListRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Files) != 1 {
+		t.Fatalf("%d files listed, expected 1", len(resp.Files))
+	}
+	if name := resp.Files[0].Name; name != "test.srs" {
+		t.Errorf("file %s listed, expected test.srs", name)
+	}
+	if points := resp.Files[0].Points; points != 16 {
+		t.Errorf("%d points listed, expected 16", points)
+	}
+
+	r, err := srsio.Open(filepath.Join(dir, "test.srs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	get := func(name string, points int64, vkOnly bool) ([]byte, error) {
+		stream, err := client.Get(ctx, &srspb.GetRequest{Name: name, Points: points, VkOnly: vkOnly})
+		if err != nil {
+			return nil, err
+		}
+
+		var data []byte
+		for {
+			chunk, err := stream.Recv()
+			if err == io.EOF {
+				return data, nil
+			} else if err != nil {
+				return nil, err
+			}
+			data = append(data, chunk.Data...)
+		}
+	}
+
+	for _, tc := range []struct {
+		points int64
+		vkOnly bool
+		n      int
+	}{{0, false, 16}, {4, false, 4}, {4, true, 0}} {
+		data, err := get("test.srs", tc.points, tc.vkOnly)
+		if err != nil {
+			t.Fatalf("points %d, vk only %t: %v", tc.points, tc.vkOnly, err)
+		}
+		slice, err := r.Slice(0, tc.n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := io.ReadAll(slice)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, expected) {
+			t.Errorf("points %d, vk only %t: %d bytes served, not the %d of the slice", tc.points, tc.vkOnly, len(data), len(expected))
+		}
+	}
+
+	for _, tc := range []struct {
+		name   string
+		points int64
+		code   codes.Code
+	}{{"missing.srs", 0, codes.NotFound}, {"README", 0, codes.NotFound}, {"../test.srs", 0, codes.InvalidArgument}, {"test.srs", 17, codes.InvalidArgument}} {
+		if _, err := get(tc.name, tc.points, false); status.Code(err) != tc.code {
+			t.Errorf("%s with %d points: got %v, expected code %s", tc.name, tc.points, err, tc.code)
+		}
+	}
+
+	var exposed strings.Builder
+	metrics.Default.WriteTo(&exposed)
+	for _, series := range []string{
+		`srs_grpc_requests_total{method="/srs.v1.SRS/Get",code="OK"} 3`,
+		`srs_grpc_requests_total{method="/srs.v1.SRS/Get",code="NotFound"} 2`,
+		`srs_grpc_requests_total{method="/srs.v1.SRS/List",code="OK"} 1`,
+	} {
+		if !strings.Contains(exposed.String(), series) {
+			t.Errorf("metric %s not exposed", series)
+		}
+	}
+}
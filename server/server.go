@@ -0,0 +1,212 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/metrics"
+	"linea/aztec-srs-to-gnark/srsio"
+)
+
+var (
+	requests = metrics.Default.NewCounter("srs_http_requests_total",
+		"Number of HTTP requests served.", "endpoint", "code")
+	requestDuration = metrics.Default.NewHistogram("srs_http_request_duration_seconds",
+		"Duration of the HTTP requests.", metrics.DefaultBuckets, "endpoint")
+	sentBytes = metrics.Default.NewCounter("srs_http_sent_bytes_total",
+		"Number of bytes of SRS data served.", "endpoint")
+)
+
+// FileInfo describes an SRS file served.
+type FileInfo struct {
+	Name     string `json:"name"`
+	Format   string `json:"format"`
+	Curve    string `json:"curve"`
+	NbPoints int    `json:"points"`
+	Size     int64  `json:"size"`
+}
+
+// Server serves the SRS files of a directory.
+type Server struct {
+	dir string
+}
+
+// New creates a server of the SRS files in dir.
+func New(dir string) *Server {
+	return &Server{dir: dir}
+}
+
+// Handler returns the HTTP handler of the server:
+//   - GET /srs lists the SRS files as JSON;
+//   - GET /srs/{name} serves the whole file;
+//   - GET /srs/{name}/vk serves an SRS with the verifying key only;
+//   - GET /srs/{name}/degree/{degree} serves the SRS truncated to the points
+//     needed for polynomials up to degree;
+//   - GET /metrics serves the metrics in the Prometheus text format.
+//
+// The files are served with range and ETag support, the truncated ones are
+// composed from the parts of the file without being materialized.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /srs", instrument("list", s.list))
+	mux.HandleFunc("GET /srs/{name}", instrument("file", s.serve(func(r *srsio.Reader, _ *http.Request) (int, error) {
+		return r.NbPoints, nil
+	})))
+	mux.HandleFunc("GET /srs/{name}/vk", instrument("vk", s.serve(func(*srsio.Reader, *http.Request) (int, error) {
+		return 0, nil
+	})))
+	mux.HandleFunc("GET /srs/{name}/degree/{degree}", instrument("degree", s.serve(func(r *srsio.Reader, req *http.Request) (int, error) {
+		degree, err := strconv.Atoi(req.PathValue("degree"))
+		if err != nil || degree < 0 || degree >= r.NbPoints {
+			return 0, fmt.Errorf("degree must be between 0 and %d", r.NbPoints-1)
+		}
+		return degree + 1, nil
+	})))
+	mux.Handle("GET /metrics", metrics.Default.Handler())
+
+	return mux
+}
+
+func (s *Server) list(w http.ResponseWriter, _ *http.Request) {
+	files, err := s.Files()
+	if err != nil {
+		http.Error(w, "failed to read SRS directory", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(files)
+}
+
+// Files lists the SRS files of the directory.
+func (s *Server) Files() ([]FileInfo, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []FileInfo{}
+	for _, entry := range entries {
+		if info, err := input.Info(s.dir, entry); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		r, err := srsio.Open(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			// Not an SRS file, e.g. a checksums manifest.
+			continue
+		}
+
+		files = append(files, FileInfo{
+			Name:     entry.Name(),
+			Format:   string(r.Format),
+			Curve:    r.Curve.String(),
+			NbPoints: r.NbPoints,
+			Size:     r.Size,
+		})
+		r.Close()
+	}
+
+	return files, nil
+}
+
+// errInvalidName is returned for a file name outside of the directory.
+var errInvalidName = errors.New("invalid file name")
+
+// open opens the SRS file of the directory of the given name.
+func (s *Server) open(name string) (*srsio.Reader, os.FileInfo, error) {
+	if name != filepath.Base(name) || name == "." || name == ".." {
+		return nil, nil, errInvalidName
+	}
+
+	path := filepath.Join(s.dir, name)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r, err := srsio.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return r, info, nil
+}
+
+// serve returns a handler serving the prefix of the SRS file holding the
+// number of points returned by points.
+func (s *Server) serve(points func(r *srsio.Reader, req *http.Request) (int, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		name := req.PathValue("name")
+		r, info, err := s.open(name)
+		if errors.Is(err, errInvalidName) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.NotFound(w, req)
+			return
+		}
+		defer r.Close()
+
+		n, err := points(r, req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		slice, err := r.Slice(0, n)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// The content only changes with the file, identified by its size and
+		// modification time, and the number of points served.
+		w.Header().Set("ETag", fmt.Sprintf(`"%x-%x-%x"`, info.Size(), info.ModTime().UnixNano(), n))
+		w.Header().Set("Content-Type", "application/octet-stream")
+
+		http.ServeContent(w, req, name, info.ModTime(), slice)
+	}
+}
+
+// recorder captures the status and the size of a response.
+type recorder struct {
+	http.ResponseWriter
+	code  int
+	bytes int64
+}
+
+func (r *recorder) WriteHeader(code int) {
+	r.code = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(p)
+	r.bytes += int64(n)
+
+	return n, err
+}
+
+// instrument records the metrics of the requests handled by h.
+func instrument(endpoint string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		rec := &recorder{ResponseWriter: w, code: http.StatusOK}
+
+		h(rec, req)
+
+		requests.Inc(endpoint, strconv.Itoa(rec.code))
+		requestDuration.Observe(time.Since(start).Seconds(), endpoint)
+		sentBytes.Add(float64(rec.bytes), endpoint)
+	}
+}
@@ -0,0 +1,5 @@
+// Package srspb holds the code generated from proto/srs/v1/srs.proto, the gRPC
+// service of the server.
+package srspb
+
+//go:generate protoc -I ../../proto --go_out=../.. --go_opt=module=linea/aztec-srs-to-gnark --go-grpc_out=../.. --go-grpc_opt=module=linea/aztec-srs-to-gnark srs/v1/srs.proto
@@ -0,0 +1,175 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: srs/v1/srs.proto
+
+package srspb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SRS_List_FullMethodName = "/srs.v1.SRS/List"
+	SRS_Get_FullMethodName  = "/srs.v1.SRS/Get"
+)
+
+// SRSClient is the client API for SRS service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SRS serves the SRS files of a directory, the counterpart of the HTTP
+// endpoints of serve.
+type SRSClient interface {
+	// List lists the SRS files.
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
+	// Get streams an SRS file, its first points only when points isn't 0, or
+	// its verifying key only with vk_only.
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Chunk], error)
+}
+
+type sRSClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSRSClient(cc grpc.ClientConnInterface) SRSClient {
+	return &sRSClient{cc}
+}
+
+func (c *sRSClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, SRS_List_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sRSClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Chunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SRS_ServiceDesc.Streams[0], SRS_Get_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GetRequest, Chunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SRS_GetClient = grpc.ServerStreamingClient[Chunk]
+
+// SRSServer is the server API for SRS service.
+// All implementations must embed UnimplementedSRSServer
+// for forward compatibility.
+//
+// SRS serves the SRS files of a directory, the counterpart of the HTTP
+// endpoints of serve.
+type SRSServer interface {
+	// List lists the SRS files.
+	List(context.Context, *ListRequest) (*ListResponse, error)
+	// Get streams an SRS file, its first points only when points isn't 0, or
+	// its verifying key only with vk_only.
+	Get(*GetRequest, grpc.ServerStreamingServer[Chunk]) error
+	mustEmbedUnimplementedSRSServer()
+}
+
+// UnimplementedSRSServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSRSServer struct{}
+
+func (UnimplementedSRSServer) List(context.Context, *ListRequest) (*ListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedSRSServer) Get(*GetRequest, grpc.ServerStreamingServer[Chunk]) error {
+	return status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedSRSServer) mustEmbedUnimplementedSRSServer() {}
+func (UnimplementedSRSServer) testEmbeddedByValue()             {}
+
+// UnsafeSRSServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SRSServer will
+// result in compilation errors.
+type UnsafeSRSServer interface {
+	mustEmbedUnimplementedSRSServer()
+}
+
+func RegisterSRSServer(s grpc.ServiceRegistrar, srv SRSServer) {
+	// If the following call pancis, it indicates UnimplementedSRSServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SRS_ServiceDesc, srv)
+}
+
+func _SRS_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SRSServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SRS_List_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SRSServer).List(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SRS_Get_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SRSServer).Get(m, &grpc.GenericServerStream[GetRequest, Chunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SRS_GetServer = grpc.ServerStreamingServer[Chunk]
+
+// SRS_ServiceDesc is the grpc.ServiceDesc for SRS service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SRS_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "srs.v1.SRS",
+	HandlerType: (*SRSServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "List",
+			Handler:    _SRS_List_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Get",
+			Handler:       _SRS_Get_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "srs/v1/srs.proto",
+}
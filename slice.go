@@ -0,0 +1,119 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/srsio"
+)
+
+// slice extracts a range of the powers of an SRS file with its verifying key,
+// e.g. to produce SRS files of specific sizes from a single master SRS.
+func slice(args []string) error {
+	var opts config.Options
+
+	flags := flag.NewFlagSet("slice", flag.ExitOnError)
+	from := flags.Int("from", 0, "index of the first G1 point extracted")
+	to := flags.Int("to", -1, "index after the last G1 point extracted (-1 - the end of the SRS)")
+	out := flags.String("o", "", "output SRS file (required)")
+	format := flags.String("format", "", fmt.Sprintf("output format, one of %v (default: the format of the input)", srsio.Formats))
+	flags.IntVar(&opts.Workers, "workers", 0, "number of CPU workers encoding the points in another format (0 - auto)")
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s slice [flags] <input SRS file>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	args = parseInterspersed(flags, args)
+
+	if len(args) < 1 || *out == "" {
+		flags.Usage()
+		return errUsage
+	}
+
+	r, err := srsio.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	if *to < 0 {
+		*to = r.NbPoints
+	}
+
+	outputFormat := r.Format
+	if *format != "" {
+		if outputFormat, err = srsio.ParseFormat(*format); err != nil {
+			return err
+		}
+	}
+
+	opts.IOParallelism = 1
+	opts = config.Tune(opts, "")
+
+	if err = writeRange(r, *out, *from, *to, outputFormat, opts); err != nil {
+		return err
+	}
+
+	infoPath, err := srsio.WriteSliceInfo(*out, srsio.SliceInfo{
+		Source:       filepath.Base(args[0]),
+		SourcePoints: r.NbPoints,
+		From:         *from,
+		To:           *to,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Points [%d, %d) of %s extracted, slice info written to %s\n", *from, *to, args[0], infoPath)
+	if *from != 0 {
+		fmt.Println("WARNING: the slice doesn't start with the generator, it isn't usable as a KZG SRS on its own")
+	}
+
+	return nil
+}
+
+// writeRange writes the SRS holding the G1 points [from, to) of r and its
+// verifying key into a new file at path. In the format of r, the output is
+// composed of the parts of the file, the points are only decoded to be
+// encoded in another format.
+func writeRange(r *srsio.Reader, path string, from, to int, format srsio.Format, opts config.Options) error {
+	if format == r.Format {
+		section, err := r.Slice(from, to)
+		if err != nil {
+			return err
+		}
+
+		_, err = writeOutputWith(path, func(w io.Writer) error {
+			_, err := io.Copy(w, section)
+			return err
+		})
+		return err
+	}
+
+	srs, err := r.Range(from, to)
+	if err != nil {
+		return err
+	}
+
+	_, err = writeOutput(path, srs, format, opts)
+	return err
+}
+
+// parseInterspersed parses the flags placed before, between and after the
+// positional arguments, which it returns.
+func parseInterspersed(flags *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		flags.Parse(args)
+		if flags.NArg() == 0 {
+			return positional
+		}
+
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
@@ -0,0 +1,120 @@
+package srsio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"unsafe"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	blsKzg "github.com/consensys/gnark-crypto/ecc/bls12-377/kzg"
+	bls381Kzg "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	bwKzg "github.com/consensys/gnark-crypto/ecc/bw6-761/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+)
+
+// alignedDumpMagic starts the prefix of an aligned memdump.
+const alignedDumpMagic = "SRSALIGN"
+
+// pointAlignment is the alignment in memory of the G1 points of all the
+// curves, their coordinates being arrays of uint64.
+var pointAlignment = int(unsafe.Alignof(bn254.G1Affine{}))
+
+// An aligned memdump is the memdump written by gnark's WriteDump after a
+// prefix padding it so the G1 points start at a multiple of the alignment in
+// the file, which can then be mapped in memory and its points used in place,
+// see MapDump:
+//
+//	"SRSALIGN" | uint64 LE size of the prefix | zeros | memdump
+//
+// gnark's ReadDump loads it from the end of the prefix, see DumpPrefix.
+
+// ValidateAlignment checks that the G1 points of a memdump can be aligned to
+// alignment bytes: a power of 2 multiple of the alignment of the points in
+// memory.
+func ValidateAlignment(alignment int) error {
+	if alignment < pointAlignment || alignment&(alignment-1) != 0 {
+		return fmt.Errorf("invalid alignment %d, use a power of 2 of at least %d bytes", alignment, pointAlignment)
+	}
+
+	return nil
+}
+
+// alignedPrefixSize returns the size of the prefix aligning the G1 points of
+// a memdump of the curve to alignment bytes.
+func alignedPrefixSize(curve ecc.ID, alignment int) (int64, error) {
+	if err := ValidateAlignment(alignment); err != nil {
+		return 0, err
+	}
+
+	for _, l := range layouts {
+		if l.curve != curve {
+			continue
+		}
+
+		// The prefix holds at least its magic and size
+		pointsOffset := 16 + l.vkSizes[FormatMemDump] + 16
+		padding := (int64(alignment) - pointsOffset%int64(alignment)) % int64(alignment)
+
+		return 16 + padding, nil
+	}
+
+	return 0, fmt.Errorf("unsupported curve %s", curve)
+}
+
+// writeAlignedDump writes the memdump of the SRS with its G1 points aligned to
+// alignment bytes.
+func writeAlignedDump(w io.Writer, srs kzg.SRS, alignment int) error {
+	var curve ecc.ID
+	switch srs.(type) {
+	case *bnKzg.SRS:
+		curve = ecc.BN254
+	case *blsKzg.SRS:
+		curve = ecc.BLS12_377
+	case *bls381Kzg.SRS:
+		curve = ecc.BLS12_381
+	case *bwKzg.SRS:
+		curve = ecc.BW6_761
+	default:
+		return fmt.Errorf("unsupported SRS type %T", srs)
+	}
+
+	size, err := alignedPrefixSize(curve, alignment)
+	if err != nil {
+		return err
+	}
+
+	prefix := make([]byte, size)
+	copy(prefix, alignedDumpMagic)
+	binary.LittleEndian.PutUint64(prefix[8:], uint64(size))
+	if _, err = w.Write(prefix); err != nil {
+		return err
+	}
+
+	return srs.WriteDump(w)
+}
+
+// DumpPrefix returns the size of the prefix of the aligned memdump r starts
+// with, 0 when it is another file. gnark's ReadDump loads an aligned memdump
+// from there.
+func DumpPrefix(r io.ReaderAt) (int64, error) {
+	var header [16]byte
+	if _, err := r.ReadAt(header[:], 0); errors.Is(err, io.EOF) {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("failed to read SRS file header: %w", err)
+	}
+	if string(header[:8]) != alignedDumpMagic {
+		return 0, nil
+	}
+
+	size := binary.LittleEndian.Uint64(header[8:])
+	if size < 16 || size > 1<<32 {
+		return 0, fmt.Errorf("invalid size %d of the prefix of an aligned memdump", size)
+	}
+
+	return int64(size), nil
+}
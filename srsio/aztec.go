@@ -0,0 +1,51 @@
+package srsio
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+// aztecPointSize is the size of a G1 point in the flat CRS of Aztec.
+const aztecPointSize = 64
+
+// AztecDigest computes the identifier the Aztec ecosystem uses for a bn254
+// CRS: the SHA256 of its first n G1 points in the layout of the flat g1.dat
+// file of the Ignition ceremony, which Barretenberg downloads a prefix of.
+// Every point is x and then y as 32-byte big endian integers, starting with the
+// generator. Like Digest, the points are decoded in batches.
+func AztecDigest(r *Reader, n, batchSize int) ([]byte, error) {
+	if r.Curve != ecc.BN254 {
+		return nil, fmt.Errorf("the Aztec CRS is on bn254, the SRS is on %s", r.Curve)
+	}
+	if n < 1 || n > r.NbPoints {
+		return nil, fmt.Errorf("the SRS has %d points, can't hash the first %d", r.NbPoints, n)
+	}
+	if batchSize < 1 {
+		batchSize = DefaultCompareBatch
+	}
+
+	h := sha256.New()
+	for from := 0; from < n; from += batchSize {
+		to := min(from+batchSize, n)
+
+		encoded, err := r.canonical(from, to)
+		if err != nil {
+			return nil, err
+		}
+
+		// header: uint32 number of points, then the points and the verifying key.
+		// The uncompressed gnark encoding of a bn254 point is the flat layout,
+		// with the 2 most significant bits of x left for the point at infinity.
+		points := encoded[4 : 4+(to-from)*aztecPointSize]
+		for i := 0; i < len(points); i += aztecPointSize {
+			if points[i]&0xc0 != 0 {
+				return nil, fmt.Errorf("G1 point %d is the point at infinity, which the flat CRS can't hold", from+i/aztecPointSize)
+			}
+		}
+		h.Write(points)
+	}
+
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,105 @@
+package srsio
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// Checksums of a written file.
+type Checksums struct {
+	Size    int64
+	SHA256  string
+	BLAKE2b string
+}
+
+// HashingWriter is a tee writer computing SHA256 and BLAKE2b-512 digests of
+// everything written through it, so the output never has to be re-read.
+type HashingWriter struct {
+	w       io.Writer
+	sha256  hash.Hash
+	blake2b hash.Hash
+	n       int64
+}
+
+// NewHashingWriter wraps w with the checksum computation.
+func NewHashingWriter(w io.Writer) *HashingWriter {
+	b2, _ := blake2b.New512(nil) // only fails for keys longer than 64 bytes
+
+	return &HashingWriter{
+		w:       w,
+		sha256:  sha256.New(),
+		blake2b: b2,
+	}
+}
+
+// Write hashes p with both algorithms concurrently with writing it to the
+// underlying writer. The caller may reuse p as soon as Write returns.
+func (h *HashingWriter) Write(p []byte) (int, error) {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		h.sha256.Write(p)
+	}()
+	go func() {
+		defer wg.Done()
+		h.blake2b.Write(p)
+	}()
+
+	n, err := h.w.Write(p)
+	wg.Wait()
+
+	h.n += int64(n)
+	if err == nil && n < len(p) {
+		err = io.ErrShortWrite
+	}
+
+	return n, err
+}
+
+// Checksums returns the digests of the data written so far.
+func (h *HashingWriter) Checksums() Checksums {
+	return Checksums{
+		Size:    h.n,
+		SHA256:  hex.EncodeToString(h.sha256.Sum(nil)),
+		BLAKE2b: hex.EncodeToString(h.blake2b.Sum(nil)),
+	}
+}
+
+// WriteManifest writes the checksums of the file at path into path + ".checksums"
+// using the BSD tag format understood by `sha256sum -c` and `b2sum -c`.
+func WriteManifest(path string, sums Checksums) (string, error) {
+	manifestPath := path + ".checksums"
+	name := filepath.Base(path)
+
+	content := fmt.Sprintf("SHA256 (%s) = %s\nBLAKE2b (%s) = %s\n", name, sums.SHA256, name, sums.BLAKE2b)
+	if err := os.WriteFile(manifestPath, []byte(content), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write checksum manifest: %w", err)
+	}
+
+	return manifestPath, nil
+}
+
+// FileChecksums computes the checksums of an existing file.
+func FileChecksums(path string) (Checksums, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Checksums{}, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	hw := NewHashingWriter(io.Discard)
+	if _, err = io.Copy(hw, f); err != nil {
+		return Checksums{}, fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+
+	return hw.Checksums(), nil
+}
@@ -0,0 +1,80 @@
+package srsio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// SameVk reports whether two SRS files hold the same verifying key. Files in
+// the same format are compared byte for byte, others once decoded.
+func SameVk(a, b *Reader) (bool, error) {
+	if a.Curve != b.Curve {
+		return false, nil
+	}
+
+	if a.Format == b.Format {
+		vkA, err := io.ReadAll(a.vkSection())
+		if err != nil {
+			return false, fmt.Errorf("failed to read verifying key: %w", err)
+		}
+		vkB, err := io.ReadAll(b.vkSection())
+		if err != nil {
+			return false, fmt.Errorf("failed to read verifying key: %w", err)
+		}
+
+		return bytes.Equal(vkA, vkB), nil
+	}
+
+	vkA, err := a.canonical(0, 0)
+	if err != nil {
+		return false, err
+	}
+	vkB, err := b.canonical(0, 0)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(vkA, vkB), nil
+}
+
+// Concat writes the SRS holding the G1 points of the shards one after the
+// other, with their verifying key. The shards must be in the same format and
+// hold the same verifying key: their encoded points are copied as is.
+func Concat(w io.Writer, shards []*Reader) error {
+	if len(shards) == 0 {
+		return errors.New("no SRS to concatenate")
+	}
+
+	first := shards[0]
+	n := 0
+	points := make([]*io.SectionReader, len(shards))
+	for i, shard := range shards {
+		if shard.Format != first.Format || shard.Curve != first.Curve {
+			return fmt.Errorf("SRS %d is a %s %s SRS, not a %s %s one", i, shard.Format, shard.Curve, first.Format, first.Curve)
+		}
+
+		same, err := SameVk(first, shard)
+		if err != nil {
+			return err
+		}
+		if !same {
+			return fmt.Errorf("the verifying key of SRS %d differs from the first one", i)
+		}
+
+		n += shard.NbPoints
+		points[i] = io.NewSectionReader(shard.file, shard.pointsOffset, int64(shard.NbPoints)*shard.PointSize())
+	}
+
+	if first.Format != FormatMemDump && n > 1<<32-1 {
+		return fmt.Errorf("%d points don't fit in the %s format", n, first.Format)
+	}
+
+	parts := assemble(first.Format, n, first.vkSection(), points...)
+	if _, err := io.Copy(w, io.NewSectionReader(parts, 0, parts.size())); err != nil {
+		return fmt.Errorf("failed to write SRS: %w", err)
+	}
+
+	return nil
+}
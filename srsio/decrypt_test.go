@@ -0,0 +1,109 @@
+package srsio_test
+
+import (
+	"bytes"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/encrypt"
+	"linea/aztec-srs-to-gnark/srsio"
+)
+
+// checkEmpty fails when the directory of the decrypted files isn't empty.
+func checkEmpty(t *testing.T, dir string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("the decrypted file %s is left in %s", entries[0].Name(), dir)
+	}
+}
+
+// TestOpenEncrypted opens an SRS file encrypted with age, decrypted into the
+// directory of srsio.DecryptDirEnv, and checks that no plaintext is left there,
+// whether the file is decrypted or not.
+func TestOpenEncrypted(t *testing.T) {
+	srs, err := bnKzg.NewSRS(16, big.NewInt(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var plain bytes.Buffer
+	if err = srsio.Write(&plain, srs, srsio.FormatCanonical, config.Options{}); err != nil {
+		t.Fatal(err)
+	}
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	identityFile := filepath.Join(dir, "identity.txt")
+	if err = os.WriteFile(identityFile, []byte(identity.String()+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	recipients, err := encrypt.ParseRecipients([]string{identity.Recipient().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var encrypted bytes.Buffer
+	w, err := recipients.Encrypt(&encrypted, "srs.canonical")
+	if err == nil {
+		_, err = w.Write(plain.Bytes())
+	}
+	if err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decryptDir := t.TempDir()
+	t.Setenv(encrypt.IdentityEnv, identityFile)
+	t.Setenv(srsio.DecryptDirEnv, decryptDir)
+
+	path := filepath.Join(dir, "srs.canonical.age")
+	if err = os.WriteFile(path, encrypted.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := srsio.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.NbPoints != 16 || r.Format != srsio.FormatCanonical {
+		t.Fatalf("decrypted SRS read as %d points in the %s format", r.NbPoints, r.Format)
+	}
+	if _, err = r.Range(0, r.NbPoints); err != nil {
+		t.Fatal(err)
+	}
+	if err = r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	checkEmpty(t, decryptDir)
+
+	// A file failing the authentication of its last chunk
+	tampered := bytes.Clone(encrypted.Bytes())
+	tampered[len(tampered)-1] ^= 1
+	if err = os.WriteFile(path, tampered, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if r, err = srsio.Open(path); err == nil {
+		r.Close()
+		t.Fatal("a tampered SRS file was decrypted")
+	}
+	checkEmpty(t, decryptDir)
+
+	t.Setenv(srsio.DecryptDirEnv, filepath.Join(decryptDir, "missing"))
+	if _, err = srsio.Open(path); err == nil || !strings.Contains(err.Error(), srsio.DecryptDirEnv) {
+		t.Fatalf("decrypted into a missing directory with %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package srsio
+
+import "bytes"
+
+// Diff is the difference between two SRS, see Compare.
+type Diff struct {
+	// SameCurve reports whether the SRS are on the same curve, the rest is
+	// only compared when they are.
+	SameCurve bool
+	SameVk    bool
+	// Common is the number of G1 points of the shorter SRS.
+	Common int
+	// Mismatches is the number of common G1 points that differ.
+	Mismatches int
+	// FirstMismatch is the index of the first common G1 point that differs,
+	// -1 when there are none.
+	FirstMismatch int
+}
+
+// Identical reports whether the SRS are identical, up to their encoding.
+func (d Diff) Identical(a, b *Reader) bool {
+	return d.SameCurve && d.SameVk && d.Mismatches == 0 && a.NbPoints == b.NbPoints
+}
+
+// Compare compares the verifying keys and the common G1 points of two SRS. Like
+// IsPrefix, the points are decoded and compared in batches, so the files may
+// be in different formats, but every common point is compared.
+func Compare(a, b *Reader, batchSize int) (Diff, error) {
+	diff := Diff{SameCurve: a.Curve == b.Curve, FirstMismatch: -1}
+	if !diff.SameCurve {
+		return diff, nil
+	}
+	if batchSize < 1 {
+		batchSize = DefaultCompareBatch
+	}
+
+	var err error
+	if diff.SameVk, err = SameVk(a, b); err != nil {
+		return diff, err
+	}
+
+	diff.Common = min(a.NbPoints, b.NbPoints)
+	pointSize := int(a.layout.g1Sizes[FormatCanonical])
+
+	for from := 0; from < diff.Common; from += batchSize {
+		to := min(from+batchSize, diff.Common)
+
+		encodedA, err := a.canonical(from, to)
+		if err != nil {
+			return diff, err
+		}
+		encodedB, err := b.canonical(from, to)
+		if err != nil {
+			return diff, err
+		}
+
+		// header: uint32 number of points, then the points and the verifying key
+		for i := from; i < to; i++ {
+			offset := 4 + (i-from)*pointSize
+			if !bytes.Equal(encodedA[offset:offset+pointSize], encodedB[offset:offset+pointSize]) {
+				if diff.Mismatches == 0 {
+					diff.FirstMismatch = i
+				}
+				diff.Mismatches++
+			}
+		}
+	}
+
+	return diff, nil
+}
@@ -0,0 +1,83 @@
+package srsio
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	blsKzg "github.com/consensys/gnark-crypto/ecc/bls12-377/kzg"
+	bls381Kzg "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	bwKzg "github.com/consensys/gnark-crypto/ecc/bw6-761/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+)
+
+// digestDomain separates the canonical digests from other hashes.
+const digestDomain = "gnark-mpc-kzg-srs/srs-digest/v1"
+
+// Digest computes the canonical digest of the SRS, independent of the format
+// of the file: the SHA256 of the domain separator, the name of the curve, the
+// number of G1 points as a big endian uint64, the G1 points and then G1, τ⁰·G2
+// and τ¹·G2 of the verifying key, all uncompressed affine points in the gnark
+// encoding. The lines precomputed from the verifying key aren't hashed, since
+// they are derived from it. The points are decoded in batches.
+func Digest(r *Reader, batchSize int) ([]byte, error) {
+	if batchSize < 1 {
+		batchSize = DefaultCompareBatch
+	}
+
+	h := sha256.New()
+	h.Write([]byte(digestDomain))
+	h.Write([]byte{byte(len(r.Curve.String()))})
+	h.Write([]byte(r.Curve.String()))
+	h.Write(binary.BigEndian.AppendUint64(nil, uint64(r.NbPoints)))
+
+	pointSize := int(r.layout.g1Sizes[FormatCanonical])
+	for from := 0; from < r.NbPoints; from += batchSize {
+		to := min(from+batchSize, r.NbPoints)
+
+		encoded, err := r.canonical(from, to)
+		if err != nil {
+			return nil, err
+		}
+
+		// header: uint32 number of points, then the points and the verifying key
+		h.Write(encoded[4 : 4+(to-from)*pointSize])
+	}
+
+	vk, err := r.Vk()
+	if err != nil {
+		return nil, err
+	}
+	vkPoints, err := vkRawBytes(vk)
+	if err != nil {
+		return nil, err
+	}
+	h.Write(vkPoints)
+
+	return h.Sum(nil), nil
+}
+
+// vkRawBytes returns the uncompressed encodings of G1, τ⁰·G2 and τ¹·G2 of the
+// verifying key.
+func vkRawBytes(srs kzg.SRS) ([]byte, error) {
+	var b []byte
+	switch s := srs.(type) {
+	case *bnKzg.SRS:
+		g1, g2, tauG2 := s.Vk.G1.RawBytes(), s.Vk.G2[0].RawBytes(), s.Vk.G2[1].RawBytes()
+		b = append(append(append(b, g1[:]...), g2[:]...), tauG2[:]...)
+	case *blsKzg.SRS:
+		g1, g2, tauG2 := s.Vk.G1.RawBytes(), s.Vk.G2[0].RawBytes(), s.Vk.G2[1].RawBytes()
+		b = append(append(append(b, g1[:]...), g2[:]...), tauG2[:]...)
+	case *bls381Kzg.SRS:
+		g1, g2, tauG2 := s.Vk.G1.RawBytes(), s.Vk.G2[0].RawBytes(), s.Vk.G2[1].RawBytes()
+		b = append(append(append(b, g1[:]...), g2[:]...), tauG2[:]...)
+	case *bwKzg.SRS:
+		g1, g2, tauG2 := s.Vk.G1.RawBytes(), s.Vk.G2[0].RawBytes(), s.Vk.G2[1].RawBytes()
+		b = append(append(append(b, g1[:]...), g2[:]...), tauG2[:]...)
+	default:
+		return nil, fmt.Errorf("unsupported SRS type %T", srs)
+	}
+
+	return b, nil
+}
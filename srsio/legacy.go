@@ -0,0 +1,149 @@
+package srsio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377"
+	blsKzg "github.com/consensys/gnark-crypto/ecc/bls12-377/kzg"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381"
+	bls381Kzg "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761"
+	bwKzg "github.com/consensys/gnark-crypto/ecc/bw6-761/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+)
+
+// legacyG2Sizes are the sizes of an uncompressed and a compressed G2 point of
+// the curves. Older gnark-crypto versions encode the verifying key as G2[0],
+// G2[1] and G1 only, without the lines precomputed from G2.
+var legacyG2Sizes = map[ecc.ID][2]int64{
+	ecc.BN254:     {bn254.SizeOfG2AffineUncompressed, bn254.SizeOfG2AffineCompressed},
+	ecc.BLS12_377: {bls12377.SizeOfG2AffineUncompressed, bls12377.SizeOfG2AffineCompressed},
+	ecc.BLS12_381: {bls12381.SizeOfG2AffineUncompressed, bls12381.SizeOfG2AffineCompressed},
+	ecc.BW6_761:   {bw6761.SizeOfG2AffineUncompressed, bw6761.SizeOfG2AffineCompressed},
+}
+
+// detectLegacy detects the layouts of the SRS files written by older
+// gnark-crypto versions, whose verifying key has no precomputed lines. The
+// verifying key of such a file is decoded and encoded again in the current
+// layout, the G1 points are the same. The layouts of the same size, e.g. of
+// bls12-377 and bls12-381, are told apart by the verifying key decoding.
+func (r *Reader) detectLegacy() (bool, error) {
+	var (
+		header [8]byte
+		failed error
+	)
+
+	for _, l := range layouts {
+		g2Sizes := legacyG2Sizes[l.curve]
+		rawVk := 2*g2Sizes[0] + l.g1Sizes[FormatCanonical]
+		compressedVk := 2*g2Sizes[1] + l.g1Sizes[FormatCompressed]
+
+		// memdump: VK, raw or compressed | marker | uint64 LE number of points | points
+		for _, vkSize := range []int64{rawVk, compressedVk} {
+			if _, err := r.file.ReadAt(header[:], vkSize); err != nil || binary.LittleEndian.Uint64(header[:]) != memDumpMarker {
+				continue
+			}
+			if _, err := r.file.ReadAt(header[:], vkSize+8); err != nil {
+				return false, fmt.Errorf("failed to read number of points: %w", err)
+			}
+
+			n := binary.LittleEndian.Uint64(header[:])
+			if holdsPoints(r.Size-vkSize-16, n, l.g1Sizes[FormatMemDump]) {
+				r.set(l, FormatMemDump, int(n), vkSize+16, 0)
+				if failed = r.migrateVk(vkSize); failed == nil {
+					return true, nil
+				}
+			}
+		}
+	}
+
+	// canonical and compressed: uint32 BE number of points | points | VK
+	if _, err := r.file.ReadAt(header[:4], 0); err != nil {
+		return false, fmt.Errorf("failed to read number of points: %w", err)
+	}
+	n := int64(binary.BigEndian.Uint32(header[:4]))
+
+	for _, l := range layouts {
+		g2Sizes := legacyG2Sizes[l.curve]
+		vkSizes := map[Format]int64{
+			FormatCanonical:  2*g2Sizes[0] + l.g1Sizes[FormatCanonical],
+			FormatCompressed: 2*g2Sizes[1] + l.g1Sizes[FormatCompressed],
+		}
+
+		for _, format := range []Format{FormatCanonical, FormatCompressed} {
+			if r.Size == 4+n*l.g1Sizes[format]+vkSizes[format] {
+				r.set(l, format, int(n), 4, 4+n*l.g1Sizes[format])
+				if failed = r.migrateVk(vkSizes[format]); failed == nil {
+					return true, nil
+				}
+			}
+		}
+	}
+
+	return failed != nil, failed
+}
+
+// migrateVk decodes the legacy verifying key of the file, of vkSize bytes,
+// and keeps its encoding in the current layout, with the precomputed lines.
+func (r *Reader) migrateVk(vkSize int64) error {
+	r.Legacy = true
+
+	dec := io.NewSectionReader(r.file, r.vkOffset, vkSize)
+	srs := kzg.NewSRS(r.Curve)
+
+	var err error
+	switch s := srs.(type) {
+	case *bnKzg.SRS:
+		d := bn254.NewDecoder(dec)
+		if err = d.Decode(&s.Vk.G2[0]); err == nil {
+			if err = d.Decode(&s.Vk.G2[1]); err == nil {
+				err = d.Decode(&s.Vk.G1)
+			}
+		}
+		s.Vk.Lines[0] = bn254.PrecomputeLines(s.Vk.G2[0])
+		s.Vk.Lines[1] = bn254.PrecomputeLines(s.Vk.G2[1])
+	case *blsKzg.SRS:
+		d := bls12377.NewDecoder(dec)
+		if err = d.Decode(&s.Vk.G2[0]); err == nil {
+			if err = d.Decode(&s.Vk.G2[1]); err == nil {
+				err = d.Decode(&s.Vk.G1)
+			}
+		}
+		s.Vk.Lines[0] = bls12377.PrecomputeLines(s.Vk.G2[0])
+		s.Vk.Lines[1] = bls12377.PrecomputeLines(s.Vk.G2[1])
+	case *bls381Kzg.SRS:
+		d := bls12381.NewDecoder(dec)
+		if err = d.Decode(&s.Vk.G2[0]); err == nil {
+			if err = d.Decode(&s.Vk.G2[1]); err == nil {
+				err = d.Decode(&s.Vk.G1)
+			}
+		}
+		s.Vk.Lines[0] = bls12381.PrecomputeLines(s.Vk.G2[0])
+		s.Vk.Lines[1] = bls12381.PrecomputeLines(s.Vk.G2[1])
+	case *bwKzg.SRS:
+		d := bw6761.NewDecoder(dec)
+		if err = d.Decode(&s.Vk.G2[0]); err == nil {
+			if err = d.Decode(&s.Vk.G2[1]); err == nil {
+				err = d.Decode(&s.Vk.G1)
+			}
+		}
+		s.Vk.Lines[0] = bw6761.PrecomputeLines(s.Vk.G2[0])
+		s.Vk.Lines[1] = bw6761.PrecomputeLines(s.Vk.G2[1])
+	default:
+		return fmt.Errorf("unsupported SRS type %T", srs)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to decode legacy verifying key: %w", err)
+	}
+
+	if r.vk, err = r.encodeVk(srs); err != nil {
+		return fmt.Errorf("failed to encode verifying key: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,194 @@
+package srsio
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	blsKzg "github.com/consensys/gnark-crypto/ecc/bls12-377/kzg"
+	bls381Kzg "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	bwKzg "github.com/consensys/gnark-crypto/ecc/bw6-761/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+)
+
+// loader is a decoder of gnark-crypto an SRS file is loaded with downstream.
+type loader struct {
+	name string
+	// limit is the number of G1 points loaded, all of them when 0.
+	limit int
+	load  func(srs kzg.SRS, r io.Reader) error
+	// mapped loads the file with MapDump instead.
+	mapped bool
+}
+
+// loaders returns the decoders of gnark-crypto for the files of the format.
+// A memdump is loaded whole and limited to its first points, the way gnark
+// loads the SRS of a circuit smaller than the file. gnark-crypto v0.15 has no
+// UnsafeReadDump, ReadDump being its unchecked decoder of a memdump. An
+// aligned memdump is also mapped with MapDump.
+func loaders(format Format, n int, aligned bool) []loader {
+	if format != FormatMemDump {
+		return []loader{{name: "UnsafeReadFrom", load: func(srs kzg.SRS, r io.Reader) error {
+			_, err := srs.UnsafeReadFrom(r)
+			return err
+		}}}
+	}
+
+	limit := max(n/2, 1)
+
+	l := []loader{
+		{name: "ReadDump", load: func(srs kzg.SRS, r io.Reader) error { return srs.ReadDump(r) }},
+		{name: fmt.Sprintf("ReadDump of %d points", limit), limit: limit, load: func(srs kzg.SRS, r io.Reader) error { return srs.ReadDump(r, limit) }},
+	}
+	if aligned {
+		l = append(l, loader{name: "MapDump", mapped: true})
+	}
+
+	return l
+}
+
+// CheckLoad loads the SRS file at path, written from srs in the format, back
+// with the decoders of gnark-crypto downstream code uses, see loaders, and
+// compares the verifying key and samples of the G1 points with srs: the
+// first and the last points, and one at a random index of each of samples
+// equal parts of the SRS. Every load holds a copy of the SRS in memory.
+func CheckLoad(path string, srs kzg.SRS, format Format, samples int) error {
+	var (
+		curve ecc.ID
+		n     int
+	)
+	switch s := srs.(type) {
+	case *bnKzg.SRS:
+		curve, n = ecc.BN254, len(s.Pk.G1)
+	case *blsKzg.SRS:
+		curve, n = ecc.BLS12_377, len(s.Pk.G1)
+	case *bls381Kzg.SRS:
+		curve, n = ecc.BLS12_381, len(s.Pk.G1)
+	case *bwKzg.SRS:
+		curve, n = ecc.BW6_761, len(s.Pk.G1)
+	default:
+		return fmt.Errorf("unsupported SRS type %T", srs)
+	}
+
+	prefix, err := filePrefix(path)
+	if err != nil {
+		return err
+	}
+
+	for _, l := range loaders(format, n, prefix != 0) {
+		loadedN := n
+		if l.limit > 0 {
+			loadedN = min(n, l.limit)
+		}
+		if err := checkLoad(path, prefix, srs, kzg.NewSRS(curve), l, sampleIndices(loadedN, samples)); err != nil {
+			return fmt.Errorf("%s of %s: %w", l.name, path, err)
+		}
+		fmt.Printf("%s loads %s: the verifying key and the sampled G1 points match\n", l.name, path)
+	}
+
+	return nil
+}
+
+// filePrefix returns the size of the prefix of the aligned memdump at path, 0
+// for another file.
+func filePrefix(path string) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open SRS file: %w", err)
+	}
+	defer f.Close()
+
+	return DumpPrefix(f)
+}
+
+// checkLoad loads the file, from the end of its prefix, into loaded with the
+// loader and compares the points at the indices with srs.
+func checkLoad(path string, prefix int64, srs, loaded kzg.SRS, l loader, indices []int) error {
+	if l.mapped {
+		m, err := MapDump(path)
+		if err != nil {
+			return err
+		}
+		defer m.Close()
+		loaded = m.SRS
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open SRS file: %w", err)
+		}
+		defer f.Close()
+
+		if _, err = f.Seek(prefix, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to read SRS file: %w", err)
+		}
+		if err = l.load(loaded, bufio.NewReaderSize(f, 1<<20)); err != nil {
+			return fmt.Errorf("failed to load SRS: %w", err)
+		}
+	}
+
+	switch s := srs.(type) {
+	case *bnKzg.SRS:
+		got := loaded.(*bnKzg.SRS)
+		return compareLoaded(s.Pk.G1, got.Pk.G1, s.Vk == got.Vk, l.limit, indices)
+	case *blsKzg.SRS:
+		got := loaded.(*blsKzg.SRS)
+		return compareLoaded(s.Pk.G1, got.Pk.G1, s.Vk == got.Vk, l.limit, indices)
+	case *bls381Kzg.SRS:
+		got := loaded.(*bls381Kzg.SRS)
+		return compareLoaded(s.Pk.G1, got.Pk.G1, s.Vk == got.Vk, l.limit, indices)
+	case *bwKzg.SRS:
+		got := loaded.(*bwKzg.SRS)
+		return compareLoaded(s.Pk.G1, got.Pk.G1, s.Vk == got.Vk, l.limit, indices)
+	default:
+		return fmt.Errorf("unsupported SRS type %T", srs)
+	}
+}
+
+// compareLoaded compares the G1 points loaded with the ones written at the
+// indices, limit being the number of points loaded, all of them when 0.
+func compareLoaded[P comparable](written, loaded []P, sameVk bool, limit int, indices []int) error {
+	if !sameVk {
+		return errors.New("the verifying key differs")
+	}
+	if expected := len(written); limit > 0 && limit < expected {
+		if len(loaded) != limit {
+			return fmt.Errorf("loaded %d G1 points, expected %d", len(loaded), limit)
+		}
+	} else if len(loaded) != expected {
+		return fmt.Errorf("loaded %d G1 points, expected %d", len(loaded), expected)
+	}
+
+	for _, i := range indices {
+		if loaded[i] != written[i] {
+			return fmt.Errorf("G1 point %d differs", i)
+		}
+	}
+
+	return nil
+}
+
+// sampleIndices returns the indices of the G1 points of an SRS of n points
+// compared: the first and the last ones, and one at a random index of each of
+// samples equal parts of the SRS, or all of them when samples >= n.
+func sampleIndices(n, samples int) []int {
+	if samples >= n {
+		indices := make([]int, n)
+		for i := range indices {
+			indices[i] = i
+		}
+		return indices
+	}
+
+	indices := []int{0, n - 1}
+	for i := range samples {
+		from, to := i*n/samples, (i+1)*n/samples
+		indices = append(indices, from+rand.IntN(to-from))
+	}
+
+	return indices
+}
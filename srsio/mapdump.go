@@ -0,0 +1,76 @@
+package srsio
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377"
+	blsKzg "github.com/consensys/gnark-crypto/ecc/bls12-377/kzg"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381"
+	bls381Kzg "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761"
+	bwKzg "github.com/consensys/gnark-crypto/ecc/bw6-761/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+)
+
+// MappedSRS is an SRS whose G1 points are the memory mapped memdump file
+// they were written to, see MapDump.
+type MappedSRS struct {
+	SRS  kzg.SRS
+	data []byte
+}
+
+// Close unmaps the file. The G1 points of the SRS can't be used afterwards.
+func (m *MappedSRS) Close() error {
+	return munmapFile(m.data)
+}
+
+// MapDump loads the memdump at path mapping it in memory read-only, the G1
+// points of the SRS being the ones of the file, without a copy: the points
+// are paged in when used and the load takes no time whatever their number.
+// The points must start at a multiple of their alignment in memory in the
+// file, which the aligned memdumps guarantee, see Write. Like gnark's
+// ReadDump, the points aren't checked, and the SRS must not be modified.
+func MapDump(path string) (*MappedSRS, error) {
+	r, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	if r.Format != FormatMemDump || r.Legacy {
+		return nil, fmt.Errorf("%s is a %s file, only a memdump can be mapped", path, r.Format)
+	}
+	if r.pointsOffset%int64(pointAlignment) != 0 {
+		return nil, fmt.Errorf("the G1 points of %s start at offset %d, which isn't a multiple of %d: convert it with -align", path, r.pointsOffset, pointAlignment)
+	}
+
+	srs, err := r.Vk()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := mmapFile(r.file, int(r.Size))
+	if err != nil {
+		return nil, fmt.Errorf("failed to map %s: %w", path, err)
+	}
+
+	points := unsafe.Pointer(unsafe.SliceData(data[r.pointsOffset:]))
+	switch s := srs.(type) {
+	case *bnKzg.SRS:
+		s.Pk.G1 = unsafe.Slice((*bn254.G1Affine)(points), r.NbPoints)
+	case *blsKzg.SRS:
+		s.Pk.G1 = unsafe.Slice((*bls12377.G1Affine)(points), r.NbPoints)
+	case *bls381Kzg.SRS:
+		s.Pk.G1 = unsafe.Slice((*bls12381.G1Affine)(points), r.NbPoints)
+	case *bwKzg.SRS:
+		s.Pk.G1 = unsafe.Slice((*bw6761.G1Affine)(points), r.NbPoints)
+	default:
+		munmapFile(data)
+		return nil, fmt.Errorf("unsupported SRS type %T", srs)
+	}
+
+	return &MappedSRS{SRS: srs, data: data}, nil
+}
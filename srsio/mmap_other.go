@@ -0,0 +1,16 @@
+//go:build !unix
+
+package srsio
+
+import (
+	"errors"
+	"os"
+)
+
+func mmapFile(*os.File, int) ([]byte, error) {
+	return nil, errors.New("mapping SRS files in memory is not supported on this platform")
+}
+
+func munmapFile([]byte) error {
+	return nil
+}
@@ -0,0 +1,80 @@
+package srsio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// DefaultCompareBatch is the number of points compared at once by IsPrefix.
+const DefaultCompareBatch = 1 << 16
+
+// IsPrefix checks that the SRS of a is a prefix of the SRS of b: both are on
+// the same curve, have the same verifying key and the G1 points of a are the
+// first points of b. The points are decoded and compared in batches, so the
+// files may be in different formats.
+func IsPrefix(a, b *Reader, batchSize int) error {
+	if a.Curve != b.Curve {
+		return fmt.Errorf("the SRS are on different curves: %s and %s", a.Curve, b.Curve)
+	}
+	if a.NbPoints > b.NbPoints {
+		return fmt.Errorf("the SRS has %d points, more than the %d points of the other", a.NbPoints, b.NbPoints)
+	}
+	if batchSize < 1 {
+		batchSize = DefaultCompareBatch
+	}
+
+	// Compare the canonical encodings, which include the verifying keys.
+	for from := 0; from < max(a.NbPoints, 1); from += batchSize {
+		to := min(from+batchSize, a.NbPoints)
+
+		encodedA, err := a.canonical(from, to)
+		if err != nil {
+			return err
+		}
+		encodedB, err := b.canonical(from, to)
+		if err != nil {
+			return err
+		}
+
+		if !bytes.Equal(encodedA, encodedB) {
+			if from == 0 && to == 0 {
+				return errors.New("the verifying keys differ")
+			}
+			return a.mismatch(from, to, encodedA, encodedB)
+		}
+	}
+
+	return nil
+}
+
+// canonical returns the canonical encoding of the SRS holding the points [from, to).
+func (r *Reader) canonical(from, to int) ([]byte, error) {
+	srs, err := r.Range(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if _, err = srs.WriteRawTo(&buf); err != nil {
+		return nil, fmt.Errorf("failed to encode SRS points [%d, %d): %w", from, to, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// mismatch locates the first difference between the canonical encodings of
+// the points [from, to) of two SRS.
+func (r *Reader) mismatch(from, to int, a, b []byte) error {
+	pointSize := int(r.layout.g1Sizes[FormatCanonical])
+
+	// header: uint32 number of points, then the points and the verifying key
+	for i := from; i < to; i++ {
+		offset := 4 + (i-from)*pointSize
+		if !bytes.Equal(a[offset:offset+pointSize], b[offset:offset+pointSize]) {
+			return fmt.Errorf("G1 point %d differs", i)
+		}
+	}
+
+	return errors.New("the verifying keys differ")
+}
@@ -0,0 +1,477 @@
+package srsio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377"
+	blsKzg "github.com/consensys/gnark-crypto/ecc/bls12-377/kzg"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381"
+	bls381Kzg "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761"
+	bwKzg "github.com/consensys/gnark-crypto/ecc/bw6-761/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/encrypt"
+)
+
+// memDumpMarker is the marker gnark writes between the VK and the points of a memdump.
+const memDumpMarker uint64 = 0xdeadbeef
+
+// layout describes the size of the SRS parts of a curve in every format.
+type layout struct {
+	curve ecc.ID
+	// size of a G1 point in the memdump, canonical and compressed formats
+	g1Sizes map[Format]int64
+	// size of the verifying key in the memdump, canonical and compressed formats
+	vkSizes map[Format]int64
+}
+
+// layouts of the curves whose SRS files can be read. The layouts of
+// bls12-377 and bls12-381 have the same sizes, the curve of their files is the
+// one of their verifying key, see choose.
+var layouts = []layout{
+	newLayout(ecc.BN254, int64(unsafe.Sizeof(bn254.G1Affine{})), bn254.SizeOfG1AffineUncompressed, bn254.SizeOfG1AffineCompressed),
+	newLayout(ecc.BLS12_377, int64(unsafe.Sizeof(bls12377.G1Affine{})), bls12377.SizeOfG1AffineUncompressed, bls12377.SizeOfG1AffineCompressed),
+	newLayout(ecc.BLS12_381, int64(unsafe.Sizeof(bls12381.G1Affine{})), bls12381.SizeOfG1AffineUncompressed, bls12381.SizeOfG1AffineCompressed),
+	newLayout(ecc.BW6_761, int64(unsafe.Sizeof(bw6761.G1Affine{})), bw6761.SizeOfG1AffineUncompressed, bw6761.SizeOfG1AffineCompressed),
+}
+
+// newLayout measures the verifying key sizes by serializing an empty SRS.
+func newLayout(curve ecc.ID, memSize, rawSize, compressedSize int64) layout {
+	empty := kzg.NewSRS(curve)
+
+	var dump, raw, compressed bytes.Buffer
+	_ = empty.WriteDump(&dump)
+	_, _ = empty.WriteRawTo(&raw)
+	_, _ = empty.WriteTo(&compressed)
+
+	return layout{
+		curve: curve,
+		g1Sizes: map[Format]int64{
+			FormatMemDump:    memSize,
+			FormatCanonical:  rawSize,
+			FormatCompressed: compressedSize,
+		},
+		vkSizes: map[Format]int64{
+			// VK, marker and slice length
+			FormatMemDump: int64(dump.Len()) - 16,
+			// slice length and VK
+			FormatCanonical:  int64(raw.Len()) - 4,
+			FormatCompressed: int64(compressed.Len()) - 4,
+		},
+	}
+}
+
+// Reader gives access to parts of an SRS file without loading all of it.
+type Reader struct {
+	file *os.File
+
+	// Format of the file.
+	Format Format
+	// Curve of the SRS.
+	Curve ecc.ID
+	// NbPoints is the number of G1 points in the file.
+	NbPoints int
+	// Size of the file in bytes.
+	Size int64
+	// Legacy is set for the files written by older gnark-crypto versions,
+	// whose verifying key is read in the current layout, see detectLegacy.
+	Legacy bool
+	// Prefix is the size of the prefix of an aligned memdump, see DumpPrefix.
+	Prefix int64
+
+	layout       layout
+	pointsOffset int64
+	vkOffset     int64
+	// vk is the verifying key of a legacy file in the current layout.
+	vk []byte
+	// plainPath is the decrypted temporary file to remove once closed, when
+	// it couldn't be removed while open.
+	plainPath string
+}
+
+// Stdin is the path of the SRS file read from the standard input.
+const Stdin = "-"
+
+// Open opens an SRS file written in any of the supported formats. The curve
+// and the format are detected from the file layout. The path Stdin reads the
+// SRS from the standard input.
+func Open(path string) (*Reader, error) {
+	if path == Stdin {
+		return openStdin()
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SRS file: %w", err)
+	}
+	file, plainPath, err := decrypted(file, path)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := NewReader(file)
+	if err != nil {
+		closeTemp(file, plainPath)
+		return nil, err
+	}
+	r.plainPath = plainPath
+
+	return r, nil
+}
+
+// DecryptDirEnv is the environment variable of the directory the encrypted
+// SRS files are decrypted into, os.TempDir() when it is unset.
+const DecryptDirEnv = "SRS_DECRYPT_DIR"
+
+// decrypted returns file when it isn't encrypted. Otherwise the whole file is
+// decrypted with the identities of encrypt.IdentityEnv into a temporary file
+// of the directory of DecryptDirEnv, since the SRS is read at offsets, and
+// closed. The temporary file is removed right away, its space being released
+// once it is closed. Where an open file can't be removed, its path is returned
+// to remove it once closed, see closeTemp. It is removed on every error.
+func decrypted(file *os.File, name string) (*os.File, string, error) {
+	header := make([]byte, 64)
+	n, err := file.ReadAt(header, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		file.Close()
+		return nil, "", fmt.Errorf("failed to read SRS file header: %w", err)
+	}
+	scheme := encrypt.Detect(name, header[:n])
+	if scheme == "" {
+		return file, "", nil
+	}
+	defer file.Close()
+
+	ids, err := encrypt.IdentitiesFromEnv()
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", name, err)
+	}
+	content, err := ids.Decrypt(io.NewSectionReader(file, 0, 1<<62), scheme)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", name, err)
+	}
+
+	plain, err := os.CreateTemp(os.Getenv(DecryptDirEnv), "srs-decrypted-*")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create temporary file for the decryption, set %s to another directory: %w", DecryptDirEnv, err)
+	}
+	plainPath := ""
+	if err = os.Remove(plain.Name()); err != nil {
+		plainPath = plain.Name()
+	}
+
+	fmt.Printf("Decrypting %s into a temporary file of %s\n", name, filepath.Dir(plain.Name()))
+	if _, err = io.Copy(plain, content); err != nil {
+		closeTemp(plain, plainPath)
+		return nil, "", fmt.Errorf("failed to decrypt %s: %w", name, err)
+	}
+
+	return plain, plainPath, nil
+}
+
+// closeTemp closes a temporary file, removing it when it wasn't removed yet.
+func closeTemp(file *os.File, path string) error {
+	err := file.Close()
+	if path != "" {
+		if rmErr := os.Remove(path); rmErr != nil && err == nil {
+			err = fmt.Errorf("failed to remove temporary file: %w", rmErr)
+		}
+	}
+
+	return err
+}
+
+// openStdin spools the SRS read from the standard input into a temporary file,
+// since the SRS is read at offsets, e.g. the verifying key at its end. The
+// temporary file is removed right away, it is kept until the reader is closed.
+func openStdin() (*Reader, error) {
+	file, err := os.CreateTemp("", "srs-stdin-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	os.Remove(file.Name())
+
+	if _, err = io.Copy(file, os.Stdin); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read SRS from the standard input: %w", err)
+	}
+	file, plainPath, err := decrypted(file, "standard input")
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := NewReader(file)
+	if err != nil {
+		closeTemp(file, plainPath)
+		return nil, fmt.Errorf("standard input: %w", err)
+	}
+	r.plainPath = plainPath
+
+	return r, nil
+}
+
+// NewReader detects the layout of the SRS stored in file. Closing the reader closes the file.
+func NewReader(file *os.File) (*Reader, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SRS file info: %w", err)
+	}
+
+	r := &Reader{file: file, Size: info.Size()}
+	if err = r.detect(); err != nil {
+		return nil, fmt.Errorf("%s: %w", file.Name(), err)
+	}
+
+	return r, nil
+}
+
+// holdsPoints tells if size bytes are exactly n points of pointSize bytes. The
+// count is read from the file, it is compared without multiplying it so a
+// corrupted one can't overflow into a match.
+func holdsPoints(size int64, n uint64, pointSize int64) bool {
+	return size >= 0 && size%pointSize == 0 && n == uint64(size/pointSize)
+}
+
+func (r *Reader) detect() error {
+	var header [8]byte
+
+	prefix, err := DumpPrefix(r.file)
+	if err != nil {
+		return err
+	}
+
+	var candidates []candidate
+	for _, l := range layouts {
+		// memdump: [aligned prefix] | VK | marker | uint64 LE number of points | points
+		vkSize := l.vkSizes[FormatMemDump]
+		if _, err := r.file.ReadAt(header[:], prefix+vkSize); err == nil && binary.LittleEndian.Uint64(header[:]) == memDumpMarker {
+			if _, err = r.file.ReadAt(header[:], prefix+vkSize+8); err != nil {
+				return fmt.Errorf("failed to read number of points: %w", err)
+			}
+
+			n := binary.LittleEndian.Uint64(header[:])
+			if holdsPoints(r.Size-prefix-vkSize-16, n, l.g1Sizes[FormatMemDump]) {
+				candidates = append(candidates, candidate{l, FormatMemDump, int(n), prefix + vkSize + 16, prefix})
+			}
+		}
+	}
+	if len(candidates) != 0 {
+		r.Prefix = prefix
+		return r.choose(candidates)
+	}
+	if prefix != 0 {
+		return errors.New("the aligned memdump holds no memdump")
+	}
+
+	// canonical and compressed: uint32 BE number of points | points | VK
+	if _, err := r.file.ReadAt(header[:4], 0); err != nil {
+		return fmt.Errorf("failed to read number of points: %w", err)
+	}
+	n := int64(binary.BigEndian.Uint32(header[:4]))
+
+	for _, format := range []Format{FormatCanonical, FormatCompressed} {
+		for _, l := range layouts {
+			if r.Size == 4+n*l.g1Sizes[format]+l.vkSizes[format] {
+				candidates = append(candidates, candidate{l, format, int(n), 4, 4 + n*l.g1Sizes[format]})
+			}
+		}
+		if len(candidates) != 0 {
+			return r.choose(candidates)
+		}
+	}
+
+	if ok, err := r.detectLegacy(); ok || err != nil {
+		return err
+	}
+
+	return errors.New("unknown SRS file layout")
+}
+
+// candidate is a layout of the size of the file.
+type candidate struct {
+	layout                 layout
+	format                 Format
+	n                      int
+	pointsOffset, vkOffset int64
+}
+
+// choose sets the layout of the file among the candidates: the only one, or
+// the first one whose verifying key holds points on its curve.
+func (r *Reader) choose(candidates []candidate) error {
+	for _, c := range candidates {
+		r.set(c.layout, c.format, c.n, c.pointsOffset, c.vkOffset)
+		if len(candidates) == 1 {
+			return nil
+		}
+		if vk, err := r.Vk(); err == nil && vkOnCurve(vk) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("the verifying key of the %s file is on none of the curves of its size", candidates[0].format)
+}
+
+// vkOnCurve tells if the generators of the verifying key are on the curve of
+// the SRS.
+func vkOnCurve(srs kzg.SRS) bool {
+	switch s := srs.(type) {
+	case *bnKzg.SRS:
+		return s.Vk.G1.IsOnCurve() && s.Vk.G2[0].IsOnCurve()
+	case *blsKzg.SRS:
+		return s.Vk.G1.IsOnCurve() && s.Vk.G2[0].IsOnCurve()
+	case *bls381Kzg.SRS:
+		return s.Vk.G1.IsOnCurve() && s.Vk.G2[0].IsOnCurve()
+	case *bwKzg.SRS:
+		return s.Vk.G1.IsOnCurve() && s.Vk.G2[0].IsOnCurve()
+	}
+
+	return false
+}
+
+func (r *Reader) set(l layout, format Format, n int, pointsOffset, vkOffset int64) {
+	r.layout = l
+	r.Curve = l.curve
+	r.Format = format
+	r.NbPoints = n
+	r.pointsOffset = pointsOffset
+	r.vkOffset = vkOffset
+}
+
+// Close closes the underlying file, removing the temporary file of a
+// decrypted SRS.
+func (r *Reader) Close() error {
+	return closeTemp(r.file, r.plainPath)
+}
+
+// PointSize returns the size of a G1 point in the file.
+func (r *Reader) PointSize() int64 {
+	return r.layout.g1Sizes[r.Format]
+}
+
+// Vk returns an SRS holding only the verifying key.
+func (r *Reader) Vk() (kzg.SRS, error) {
+	return r.Range(0, 0)
+}
+
+// Range returns an SRS holding the G1 points [from, to) and the verifying key.
+// Only this part of the file is read. The points aren't checked to be in the
+// subgroup, the same way gnark's UnsafeReadFrom and ReadDump do.
+func (r *Reader) Range(from, to int) (kzg.SRS, error) {
+	slice, err := r.Slice(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	srs := kzg.NewSRS(r.Curve)
+
+	// Feed gnark's own decoders with the encoding of an SRS holding only the range.
+	if r.Format == FormatMemDump {
+		err = srs.ReadDump(slice)
+	} else {
+		_, err = srs.UnsafeReadFrom(slice)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SRS points [%d, %d): %w", from, to, err)
+	}
+
+	return srs, nil
+}
+
+// Slice returns the encoding, in the format of the file, of an SRS holding the
+// G1 points [from, to) and the verifying key. It is composed of the parts of
+// the file and a new header, so nothing is read until the slice is.
+func (r *Reader) Slice(from, to int) (*io.SectionReader, error) {
+	if from < 0 || to > r.NbPoints || from > to {
+		return nil, fmt.Errorf("invalid range [%d, %d) of %d points", from, to, r.NbPoints)
+	}
+
+	pointSize := r.PointSize()
+	points := io.NewSectionReader(r.file, r.pointsOffset+int64(from)*pointSize, int64(to-from)*pointSize)
+	parts := assemble(r.Format, to-from, r.vkSection(), points)
+
+	return io.NewSectionReader(parts, 0, parts.size()), nil
+}
+
+// vkSection returns the part of the file holding the verifying key, or for
+// legacy files its encoding in the current layout.
+func (r *Reader) vkSection() *io.SectionReader {
+	if r.Legacy {
+		return bytesSection(r.vk)
+	}
+
+	return io.NewSectionReader(r.file, r.vkOffset, r.layout.vkSizes[r.Format])
+}
+
+// assemble composes the encoding, in the format, of an SRS of n G1 points from
+// the encodings of its verifying key and of its points.
+func assemble(format Format, n int, vk *io.SectionReader, points ...*io.SectionReader) concatenation {
+	if format == FormatMemDump {
+		var header [16]byte
+		binary.LittleEndian.PutUint64(header[:8], memDumpMarker)
+		binary.LittleEndian.PutUint64(header[8:], uint64(n))
+
+		return append(concatenation{vk, bytesSection(header[:])}, points...)
+	}
+
+	var header [4]byte
+	binary.BigEndian.PutUint32(header[:], uint32(n))
+
+	return append(append(concatenation{bytesSection(header[:])}, points...), vk)
+}
+
+func bytesSection(b []byte) *io.SectionReader {
+	return io.NewSectionReader(bytes.NewReader(b), 0, int64(len(b)))
+}
+
+// concatenation reads consecutive sections as a single one.
+type concatenation []*io.SectionReader
+
+func (c concatenation) size() int64 {
+	var size int64
+	for _, part := range c {
+		size += part.Size()
+	}
+
+	return size
+}
+
+// ReadAt reads len(p) bytes starting at off of the concatenated sections.
+func (c concatenation) ReadAt(p []byte, off int64) (int, error) {
+	var n int
+	for _, part := range c {
+		if len(p) == 0 {
+			break
+		}
+
+		if off >= part.Size() {
+			off -= part.Size()
+			continue
+		}
+
+		m, err := part.ReadAt(p[:min(int64(len(p)), part.Size()-off)], off)
+		n += m
+		if err != nil && err != io.EOF {
+			return n, err
+		}
+
+		p = p[m:]
+		off = 0
+	}
+
+	if len(p) > 0 {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
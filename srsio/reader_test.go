@@ -0,0 +1,207 @@
+package srsio_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377"
+	blsKzg "github.com/consensys/gnark-crypto/ecc/bls12-377/kzg"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381"
+	bls381Kzg "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761"
+	bwKzg "github.com/consensys/gnark-crypto/ecc/bw6-761/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/celo"
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/digest"
+	"linea/aztec-srs-to-gnark/srsio"
+	"linea/aztec-srs-to-gnark/testsetup"
+)
+
+// seedSRS returns SRS of 4 points on every curve, generated by the test
+// setups.
+func seedSRS(f *testing.F) []kzg.SRS {
+	f.Helper()
+
+	secrets := testsetup.DefaultSecrets()
+	aztec, err := testsetup.Aztec(f.TempDir(), 1, 3, secrets)
+	if err != nil {
+		f.Fatal(err)
+	}
+	aleo, err := testsetup.Aleo(f.TempDir(), 1, 3, secrets)
+	if err != nil {
+		f.Fatal(err)
+	}
+	ethereum, err := testsetup.Ethereum(f.TempDir(), 1, 4, secrets)
+	if err != nil {
+		f.Fatal(err)
+	}
+	plumo := celo.Ceremony{
+		ChunkG1PointsN: 2,
+		G1PointsN:      2*celo.TotalChunks - 1,
+		ChunksN:        celo.TotalChunks,
+		FullChunksN:    celo.ChunkHalfwayPoint,
+		Hash:           digest.BLAKE2b512,
+	}
+	plumoSRS, err := testsetup.Celo(f.TempDir(), plumo, false, secrets)
+	if err != nil {
+		f.Fatal(err)
+	}
+	plumoSRS.Pk.G1 = plumoSRS.Pk.G1[:4]
+
+	return []kzg.SRS{aztec, aleo, ethereum, plumoSRS}
+}
+
+// encode returns the encoding of the SRS in the format.
+func encode(f *testing.F, srs kzg.SRS, format srsio.Format, opts config.Options) []byte {
+	f.Helper()
+
+	var buf bytes.Buffer
+	if err := srsio.Write(&buf, srs, format, opts); err != nil {
+		f.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// checkOpen opens the SRS file of the data and reads its verifying key and
+// its first and last points. Invalid files must be reported, the points
+// announced must be in the file.
+func checkOpen(t *testing.T, data []byte) {
+	path := filepath.Join(t.TempDir(), "srs")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := srsio.Open(path)
+	if err != nil {
+		return
+	}
+	defer r.Close()
+
+	if int64(r.NbPoints) > r.Size/r.PointSize() {
+		t.Fatalf("%d points of %d bytes announced in a file of %d", r.NbPoints, r.PointSize(), r.Size)
+	}
+	if _, err = r.Vk(); err != nil {
+		return
+	}
+	n := r.NbPoints
+	if _, err = r.Range(0, min(n, 4)); err != nil {
+		return
+	}
+	_, _ = r.Range(max(n-1, 0), n)
+}
+
+// FuzzOpen opens SRS files seeded with the encodings of the test setups in
+// every format.
+func FuzzOpen(f *testing.F) {
+	for _, srs := range seedSRS(f) {
+		for _, format := range srsio.Formats {
+			f.Add(encode(f, srs, format, config.Options{}))
+		}
+		f.Add(encode(f, srs, srsio.FormatMemDump, config.Options{Align: 64}))
+	}
+
+	f.Fuzz(checkOpen)
+}
+
+// FuzzLegacy opens SRS files seeded with the encodings of the test setups in
+// the layouts of older gnark-crypto versions, see srsio.Reader.Legacy.
+func FuzzLegacy(f *testing.F) {
+	for _, srs := range seedSRS(f) {
+		for _, format := range srsio.Formats {
+			seeds := [][]byte{legacyEncode(f, srs, format, format == srsio.FormatCompressed)}
+			if format == srsio.FormatMemDump {
+				seeds = append(seeds, legacyEncode(f, srs, format, true))
+			}
+
+			for _, data := range seeds {
+				path := filepath.Join(f.TempDir(), "srs")
+				if err := os.WriteFile(path, data, 0o644); err != nil {
+					f.Fatal(err)
+				}
+				r, err := srsio.Open(path)
+				if err != nil {
+					f.Fatalf("legacy %s seed: %v", format, err)
+				}
+				r.Close()
+				if !r.Legacy || r.Format != format || r.NbPoints != 4 {
+					f.Fatalf("legacy %s seed read as a %s file of %d points, legacy %t", format, r.Format, r.NbPoints, r.Legacy)
+				}
+				f.Add(data)
+			}
+		}
+	}
+
+	f.Fuzz(checkOpen)
+}
+
+// legacyEncode returns the encoding of the SRS in the format with the
+// verifying key of older gnark-crypto versions, raw or compressed, made of
+// G2[0], G2[1] and G1 only.
+func legacyEncode(f *testing.F, srs kzg.SRS, format srsio.Format, compressed bool) []byte {
+	f.Helper()
+
+	var (
+		vk    bytes.Buffer
+		err   error
+		curve ecc.ID
+	)
+	encodeVk := func(enc interface{ Encode(any) error }, points ...any) {
+		for _, p := range points {
+			if err == nil {
+				err = enc.Encode(p)
+			}
+		}
+	}
+	switch s := srs.(type) {
+	case *bnKzg.SRS:
+		curve = ecc.BN254
+		enc := bn254.NewEncoder(&vk, bn254.RawEncoding())
+		if compressed {
+			enc = bn254.NewEncoder(&vk)
+		}
+		encodeVk(enc, &s.Vk.G2[0], &s.Vk.G2[1], &s.Vk.G1)
+	case *blsKzg.SRS:
+		curve = ecc.BLS12_377
+		enc := bls12377.NewEncoder(&vk, bls12377.RawEncoding())
+		if compressed {
+			enc = bls12377.NewEncoder(&vk)
+		}
+		encodeVk(enc, &s.Vk.G2[0], &s.Vk.G2[1], &s.Vk.G1)
+	case *bls381Kzg.SRS:
+		curve = ecc.BLS12_381
+		enc := bls12381.NewEncoder(&vk, bls12381.RawEncoding())
+		if compressed {
+			enc = bls12381.NewEncoder(&vk)
+		}
+		encodeVk(enc, &s.Vk.G2[0], &s.Vk.G2[1], &s.Vk.G1)
+	case *bwKzg.SRS:
+		curve = ecc.BW6_761
+		enc := bw6761.NewEncoder(&vk, bw6761.RawEncoding())
+		if compressed {
+			enc = bw6761.NewEncoder(&vk)
+		}
+		encodeVk(enc, &s.Vk.G2[0], &s.Vk.G2[1], &s.Vk.G1)
+	}
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	// The verifying key of the current layout is the encoding of an empty
+	// SRS without its header: the marker and the number of points of a
+	// memdump, the number of points of the other formats.
+	data := encode(f, srs, format, config.Options{})
+	vkSize := len(encode(f, kzg.NewSRS(curve), format, config.Options{}))
+	if format == srsio.FormatMemDump {
+		return append(vk.Bytes(), data[vkSize-16:]...)
+	}
+
+	return append(data[:len(data)-(vkSize-4)], vk.Bytes()...)
+}
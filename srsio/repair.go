@@ -0,0 +1,147 @@
+package srsio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377"
+	blsKzg "github.com/consensys/gnark-crypto/ecc/bls12-377/kzg"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381"
+	bls381Kzg "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761"
+	bwKzg "github.com/consensys/gnark-crypto/ecc/bw6-761/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/config"
+)
+
+// Parts of the verifying key RepairVk recomputes.
+const (
+	RepairedG1    = "Vk.G1"
+	RepairedLines = "Vk.Lines"
+)
+
+// RepairVk recomputes the parts of the verifying key of the SRS file derived
+// from the rest of the SRS: Vk.G1, the first G1 point, and the lines
+// precomputed from Vk.G2. When they differ from the ones of the file, e.g.
+// left zero by older tools, and dryRun isn't set, the verifying key is
+// rewritten in place, the G1 points are left untouched. It returns the parts
+// that differed.
+func RepairVk(path string, dryRun bool) ([]string, error) {
+	r, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Legacy {
+		r.Close()
+		return nil, errors.New("the SRS is written by an older gnark-crypto version, migrate it with version-migrate")
+	}
+
+	vk, repaired, err := r.repairedVk()
+	offset := r.vkOffset
+	r.Close()
+	if err != nil || len(repaired) == 0 || dryRun {
+		return repaired, err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SRS file: %w", err)
+	}
+
+	if _, err = file.WriteAt(vk, offset); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to write verifying key: %w", err)
+	}
+	if err = file.Sync(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to sync SRS file: %w", err)
+	}
+
+	return repaired, file.Close()
+}
+
+// repairedVk returns the encoding, in the format of the file, of the repaired
+// verifying key and the parts that differ from the file.
+func (r *Reader) repairedVk() ([]byte, []string, error) {
+	srs, err := r.Range(0, min(r.NbPoints, 1))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var repaired []string
+	switch s := srs.(type) {
+	case *bnKzg.SRS:
+		if len(s.Pk.G1) > 0 && !s.Vk.G1.Equal(&s.Pk.G1[0]) {
+			s.Vk.G1 = s.Pk.G1[0]
+			repaired = append(repaired, RepairedG1)
+		}
+		lines := [2][2][len(bn254.LoopCounter)]bn254.LineEvaluationAff{bn254.PrecomputeLines(s.Vk.G2[0]), bn254.PrecomputeLines(s.Vk.G2[1])}
+		if lines != s.Vk.Lines {
+			s.Vk.Lines = lines
+			repaired = append(repaired, RepairedLines)
+		}
+		s.Pk.G1 = nil
+	case *blsKzg.SRS:
+		if len(s.Pk.G1) > 0 && !s.Vk.G1.Equal(&s.Pk.G1[0]) {
+			s.Vk.G1 = s.Pk.G1[0]
+			repaired = append(repaired, RepairedG1)
+		}
+		lines := [2][2][len(bls12377.LoopCounter) - 1]bls12377.LineEvaluationAff{bls12377.PrecomputeLines(s.Vk.G2[0]), bls12377.PrecomputeLines(s.Vk.G2[1])}
+		if lines != s.Vk.Lines {
+			s.Vk.Lines = lines
+			repaired = append(repaired, RepairedLines)
+		}
+		s.Pk.G1 = nil
+	case *bls381Kzg.SRS:
+		if len(s.Pk.G1) > 0 && !s.Vk.G1.Equal(&s.Pk.G1[0]) {
+			s.Vk.G1 = s.Pk.G1[0]
+			repaired = append(repaired, RepairedG1)
+		}
+		lines := [2][2][len(bls12381.LoopCounter) - 1]bls12381.LineEvaluationAff{bls12381.PrecomputeLines(s.Vk.G2[0]), bls12381.PrecomputeLines(s.Vk.G2[1])}
+		if lines != s.Vk.Lines {
+			s.Vk.Lines = lines
+			repaired = append(repaired, RepairedLines)
+		}
+		s.Pk.G1 = nil
+	case *bwKzg.SRS:
+		if len(s.Pk.G1) > 0 && !s.Vk.G1.Equal(&s.Pk.G1[0]) {
+			s.Vk.G1 = s.Pk.G1[0]
+			repaired = append(repaired, RepairedG1)
+		}
+		lines := [2][2][len(bw6761.LoopCounter) - 1]bw6761.LineEvaluationAff{bw6761.PrecomputeLines(s.Vk.G2[0]), bw6761.PrecomputeLines(s.Vk.G2[1])}
+		if lines != s.Vk.Lines {
+			s.Vk.Lines = lines
+			repaired = append(repaired, RepairedLines)
+		}
+		s.Pk.G1 = nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported SRS type %T", srs)
+	}
+
+	vk, err := r.encodeVk(srs)
+	return vk, repaired, err
+}
+
+// encodeVk returns the encoding of the verifying key of an SRS with no G1
+// points, in the format of the file.
+func (r *Reader) encodeVk(srs kzg.SRS) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := Write(&buf, srs, r.Format, config.Options{Workers: 1}); err != nil {
+		return nil, err
+	}
+
+	vkSize := r.layout.vkSizes[r.Format]
+	if r.Format == FormatMemDump {
+		// VK | marker | number of points
+		return buf.Bytes()[:vkSize], nil
+	}
+
+	// number of points | VK
+	return buf.Bytes()[4 : 4+vkSize], nil
+}
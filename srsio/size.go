@@ -0,0 +1,32 @@
+package srsio
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+// Size returns the size in bytes of an SRS file of the curve with n G1 points
+// written in the format.
+func Size(curve ecc.ID, format Format, n int) (int64, error) {
+	for _, l := range layouts {
+		if l.curve != curve {
+			continue
+		}
+
+		pointSize, ok := l.g1Sizes[format]
+		if !ok {
+			return 0, fmt.Errorf("unsupported format %s", format)
+		}
+
+		size := l.vkSizes[format] + int64(n)*pointSize
+		if format == FormatMemDump {
+			// marker and uint64 number of points
+			return size + 16, nil
+		}
+		// uint32 number of points
+		return size + 4, nil
+	}
+
+	return 0, fmt.Errorf("unsupported curve %s", curve)
+}
@@ -0,0 +1,112 @@
+package srsio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+
+	"linea/aztec-srs-to-gnark/config"
+)
+
+// Range is the range [From, To) of the indices of G1 points of a ceremony.
+type Range struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+func (r Range) String() string {
+	if r.To-r.From == 1 {
+		return fmt.Sprintf("G1 point %d", r.From)
+	}
+
+	return fmt.Sprintf("G1 points %d-%d", r.From, r.To-1)
+}
+
+// OmittedRanges returns the ranges of the G1 points missing for the skips,
+// sorted and merged. The skips of no known points are left out.
+func OmittedRanges(skips []config.Skip) []Range {
+	var ranges []Range
+	for _, skip := range skips {
+		if skip.Offset != nil && skip.Points > 0 {
+			ranges = append(ranges, Range{From: *skip.Offset, To: *skip.Offset + skip.Points})
+		}
+	}
+	slices.SortFunc(ranges, func(a, b Range) int { return a.From - b.From })
+
+	var merged []Range
+	for _, r := range ranges {
+		if last := len(merged) - 1; last >= 0 && r.From <= merged[last].To {
+			merged[last].To = max(merged[last].To, r.To)
+			continue
+		}
+		merged = append(merged, r)
+	}
+
+	return merged
+}
+
+// SkipInfo records the parts of a setup skipped under the lenient validation
+// for an SRS file, so its users can tell which points of the ceremony it
+// lacks.
+type SkipInfo struct {
+	// Points is the number of G1 points of the SRS file.
+	Points int `json:"points"`
+	// Omitted are the ranges of the G1 points of the ceremony skipped.
+	Omitted []Range `json:"omitted"`
+	// Prefix tells whether the SRS holds the first Points powers of τ of the
+	// ceremony, no point skipped being before them.
+	Prefix bool `json:"prefix"`
+	// Skipped are the parts of the setup skipped.
+	Skipped []config.Skip `json:"skipped"`
+}
+
+// NewSkipInfo returns the skip info of an SRS of points G1 points converted
+// with the skips.
+func NewSkipInfo(points int, skips []config.Skip) SkipInfo {
+	omitted := OmittedRanges(skips)
+
+	return SkipInfo{
+		Points:  points,
+		Omitted: omitted,
+		Prefix:  len(omitted) == 0 || omitted[0].From >= points,
+		Skipped: skips,
+	}
+}
+
+// WriteSkipInfo writes the skip info of the file at path into path + ".skipped.json".
+func WriteSkipInfo(path string, info SkipInfo) (string, error) {
+	infoPath := path + ".skipped.json"
+
+	content, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode skip info: %w", err)
+	}
+
+	if err = os.WriteFile(infoPath, append(content, '\n'), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write skip info: %w", err)
+	}
+
+	return infoPath, nil
+}
+
+// ReadSkipInfo reads the skip info of the SRS file at path. It reports false
+// when the file has none, no part of its setup having been skipped.
+func ReadSkipInfo(path string) (SkipInfo, bool, error) {
+	content, err := os.ReadFile(path + ".skipped.json")
+	if errors.Is(err, fs.ErrNotExist) {
+		return SkipInfo{}, false, nil
+	}
+	if err != nil {
+		return SkipInfo{}, false, fmt.Errorf("failed to read skip info: %w", err)
+	}
+
+	var info SkipInfo
+	if err = json.Unmarshal(content, &info); err != nil {
+		return SkipInfo{}, false, fmt.Errorf("failed to decode skip info of %s: %w", path, err)
+	}
+
+	return info, true, nil
+}
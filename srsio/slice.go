@@ -0,0 +1,55 @@
+package srsio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// SliceInfo records which points of a source SRS a sliced SRS file holds.
+type SliceInfo struct {
+	// Source is the name of the SRS file the points were taken from.
+	Source string `json:"source"`
+	// SourcePoints is the number of G1 points of the source.
+	SourcePoints int `json:"source_points"`
+	// From and To delimit the range [From, To) of the points of the source.
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+// WriteSliceInfo writes the slice info of the file at path into path + ".slice.json".
+func WriteSliceInfo(path string, info SliceInfo) (string, error) {
+	infoPath := path + ".slice.json"
+
+	content, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode slice info: %w", err)
+	}
+
+	if err = os.WriteFile(infoPath, append(content, '\n'), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write slice info: %w", err)
+	}
+
+	return infoPath, nil
+}
+
+// ReadSliceInfo reads the slice info of the file at path, reporting whether
+// the file has one.
+func ReadSliceInfo(path string) (SliceInfo, bool, error) {
+	content, err := os.ReadFile(path + ".slice.json")
+	if errors.Is(err, fs.ErrNotExist) {
+		return SliceInfo{}, false, nil
+	}
+	if err != nil {
+		return SliceInfo{}, false, fmt.Errorf("failed to read slice info: %w", err)
+	}
+
+	var info SliceInfo
+	if err = json.Unmarshal(content, &info); err != nil {
+		return SliceInfo{}, false, fmt.Errorf("failed to decode slice info of %s: %w", path, err)
+	}
+
+	return info, true, nil
+}
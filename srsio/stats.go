@@ -0,0 +1,209 @@
+package srsio
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+
+	blsKzg "github.com/consensys/gnark-crypto/ecc/bls12-377/kzg"
+	bls381Kzg "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	bwKzg "github.com/consensys/gnark-crypto/ecc/bw6-761/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/parallel"
+)
+
+// Stats are health counts of the points of an SRS, see CollectStats.
+type Stats struct {
+	// Checked is the number of G1 points checked, all of them unless sampled.
+	Checked int
+	Sampled bool
+	// NotOnCurve is the number of checked G1 points not on the curve.
+	NotOnCurve int
+	// NotInSubgroup is the number of checked G1 points on the curve but not in
+	// the prime order subgroup.
+	NotInSubgroup int
+	// Infinity is the number of checked G1 points at infinity.
+	Infinity int
+	// Duplicates is the number of checked G1 points equal to another checked
+	// point, DuplicatePair the indices of one such pair.
+	Duplicates    int
+	DuplicatePair [2]int
+	// FirstInvalid is the index of the first checked G1 point not on the curve,
+	// not in the subgroup or at infinity, -1 when there are none.
+	FirstInvalid int
+	// VkValid reports whether the points of the verifying key are on the curve
+	// and in the subgroups.
+	VkValid bool
+}
+
+// statPoint is an affine point of any curve.
+type statPoint interface {
+	IsInfinity() bool
+	IsOnCurve() bool
+	IsInSubGroup() bool
+	Marshal() []byte
+}
+
+// pointStats are the counts of a batch, with the fingerprints of its points.
+type pointStats struct {
+	Stats
+	fingerprints [][16]byte
+}
+
+// CollectStats checks the G1 points of the SRS to be on the curve, in the
+// subgroup, not at infinity and distinct, and the points of its verifying key.
+// With samples > 0, only this number of points is checked, one at a random
+// index of each of samples equal parts of the SRS, otherwise every point is.
+// The points are decoded in batches processed by workers goroutines. Finding
+// duplicates keeps a fingerprint of every checked point in memory.
+func CollectStats(r *Reader, samples, batchSize, workers int) (Stats, error) {
+	if batchSize < 1 {
+		batchSize = DefaultCompareBatch
+	}
+
+	stats := Stats{FirstInvalid: -1, DuplicatePair: [2]int{-1, -1}}
+
+	vk, err := r.Vk()
+	if err != nil {
+		return stats, err
+	}
+	if stats.VkValid, err = vkValid(vk); err != nil {
+		return stats, err
+	}
+
+	// ranges of points checked at once
+	var ranges [][2]int
+	if samples > 0 && samples < r.NbPoints {
+		stats.Sampled = true
+		for i := range samples {
+			from, to := i*r.NbPoints/samples, (i+1)*r.NbPoints/samples
+			index := from + rand.IntN(to-from)
+			ranges = append(ranges, [2]int{index, index + 1})
+		}
+	} else {
+		for from := 0; from < r.NbPoints; from += batchSize {
+			ranges = append(ranges, [2]int{from, min(from+batchSize, r.NbPoints)})
+		}
+	}
+
+	var mu sync.Mutex
+	seen := make(map[[16]byte]int)
+
+	err = parallel.Run(len(ranges), workers, func(i int) error {
+		from, to := ranges[i][0], ranges[i][1]
+
+		srs, err := r.Range(from, to)
+		if err != nil {
+			return err
+		}
+
+		var batch pointStats
+		switch s := srs.(type) {
+		case *bnKzg.SRS:
+			batch = countPoints(from, s.Pk.G1)
+		case *blsKzg.SRS:
+			batch = countPoints(from, s.Pk.G1)
+		case *bls381Kzg.SRS:
+			batch = countPoints(from, s.Pk.G1)
+		case *bwKzg.SRS:
+			batch = countPoints(from, s.Pk.G1)
+		default:
+			return fmt.Errorf("unsupported SRS type %T", srs)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		stats.Checked += batch.Checked
+		stats.NotOnCurve += batch.NotOnCurve
+		stats.NotInSubgroup += batch.NotInSubgroup
+		stats.Infinity += batch.Infinity
+		if batch.FirstInvalid >= 0 && (stats.FirstInvalid < 0 || batch.FirstInvalid < stats.FirstInvalid) {
+			stats.FirstInvalid = batch.FirstInvalid
+		}
+
+		for j, fingerprint := range batch.fingerprints {
+			index := from + j
+			first, ok := seen[fingerprint]
+			if !ok {
+				seen[fingerprint] = index
+				continue
+			}
+
+			stats.Duplicates++
+			pair := [2]int{min(first, index), max(first, index)}
+			seen[fingerprint] = pair[0]
+			if stats.DuplicatePair[1] < 0 || pair[1] < stats.DuplicatePair[1] {
+				stats.DuplicatePair = pair
+			}
+		}
+
+		return nil
+	})
+
+	return stats, err
+}
+
+// countPoints checks the points starting at index from of the SRS.
+func countPoints[T any, P interface {
+	*T
+	statPoint
+}](from int, points []T) pointStats {
+	batch := pointStats{
+		Stats:        Stats{Checked: len(points), FirstInvalid: -1},
+		fingerprints: make([][16]byte, len(points)),
+	}
+
+	for i := range points {
+		p := P(&points[i])
+
+		invalid := true
+		switch {
+		case p.IsInfinity():
+			batch.Infinity++
+		case !p.IsOnCurve():
+			batch.NotOnCurve++
+		case !p.IsInSubGroup():
+			batch.NotInSubgroup++
+		default:
+			invalid = false
+		}
+		if invalid && batch.FirstInvalid < 0 {
+			batch.FirstInvalid = from + i
+		}
+
+		sum := sha256.Sum256(p.Marshal())
+		copy(batch.fingerprints[i][:], sum[:])
+	}
+
+	return batch
+}
+
+// vkValid checks that the points of the verifying key are on the curve and in
+// the subgroups.
+func vkValid(srs kzg.SRS) (bool, error) {
+	var points []statPoint
+	switch s := srs.(type) {
+	case *bnKzg.SRS:
+		points = []statPoint{&s.Vk.G1, &s.Vk.G2[0], &s.Vk.G2[1]}
+	case *blsKzg.SRS:
+		points = []statPoint{&s.Vk.G1, &s.Vk.G2[0], &s.Vk.G2[1]}
+	case *bls381Kzg.SRS:
+		points = []statPoint{&s.Vk.G1, &s.Vk.G2[0], &s.Vk.G2[1]}
+	case *bwKzg.SRS:
+		points = []statPoint{&s.Vk.G1, &s.Vk.G2[0], &s.Vk.G2[1]}
+	default:
+		return false, fmt.Errorf("unsupported SRS type %T", srs)
+	}
+
+	for _, p := range points {
+		if p.IsInfinity() || !p.IsOnCurve() || !p.IsInSubGroup() {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
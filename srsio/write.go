@@ -0,0 +1,234 @@
+package srsio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377"
+	blsKzg "github.com/consensys/gnark-crypto/ecc/bls12-377/kzg"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381"
+	bls381Kzg "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761"
+	bwKzg "github.com/consensys/gnark-crypto/ecc/bw6-761/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/config"
+)
+
+// Format is the serialization used for an SRS file.
+type Format string
+
+const (
+	// FormatMemDump is the raw memory representation written by gnark's WriteDump.
+	FormatMemDump Format = "memdump"
+	// FormatCanonical is the uncompressed encoding written by gnark's WriteRawTo.
+	FormatCanonical Format = "canonical"
+	// FormatCompressed is the compressed encoding written by gnark's WriteTo.
+	FormatCompressed Format = "compressed"
+)
+
+// BufferSize is the size of the buffered writer placed in front of the output.
+const BufferSize = 64 << 20
+
+// Formats lists all the supported output formats.
+var Formats = []Format{FormatMemDump, FormatCanonical, FormatCompressed}
+
+// ParseFormat validates the format name.
+func ParseFormat(name string) (Format, error) {
+	for _, format := range Formats {
+		if Format(name) == format {
+			return format, nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported output format '%s', use one of %v", name, Formats)
+}
+
+// Write serializes the SRS to w in the given format. The G1 points are
+// marshalled in batches of opts.BatchSize by opts.Workers goroutines and
+// written in order through a large buffered writer. The result is
+// byte-for-byte identical to the corresponding gnark method (WriteDump,
+// WriteRawTo or WriteTo). With opts.Align, a memdump is preceded by the
+// prefix aligning its G1 points, see writeAlignedDump.
+func Write(w io.Writer, srs kzg.SRS, format Format, opts config.Options) error {
+	bw := bufio.NewWriterSize(w, BufferSize)
+
+	var err error
+	switch format {
+	case FormatMemDump:
+		// The dump is the raw memory of the slice, there is nothing to marshal.
+		if opts.Align > 0 {
+			err = writeAlignedDump(bw, srs, opts.Align)
+		} else {
+			err = srs.WriteDump(bw)
+		}
+	case FormatCanonical, FormatCompressed:
+		err = writeEncoded(bw, srs, format == FormatCompressed, opts)
+	default:
+		err = fmt.Errorf("unsupported output format '%s'", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+func writeEncoded(w io.Writer, srs kzg.SRS, compressed bool, opts config.Options) error {
+	var err error
+
+	switch s := srs.(type) {
+	case *bnKzg.SRS:
+		if compressed {
+			err = writePoints(w, s.Pk.G1, bn254.SizeOfG1AffineCompressed, opts, func(p *bn254.G1Affine, dst []byte) {
+				b := p.Bytes()
+				copy(dst, b[:])
+			})
+		} else {
+			err = writePoints(w, s.Pk.G1, bn254.SizeOfG1AffineUncompressed, opts, func(p *bn254.G1Affine, dst []byte) {
+				b := p.RawBytes()
+				copy(dst, b[:])
+			})
+		}
+		if err == nil {
+			err = writeVk(w, &s.Vk, compressed)
+		}
+	case *blsKzg.SRS:
+		if compressed {
+			err = writePoints(w, s.Pk.G1, bls12377.SizeOfG1AffineCompressed, opts, func(p *bls12377.G1Affine, dst []byte) {
+				b := p.Bytes()
+				copy(dst, b[:])
+			})
+		} else {
+			err = writePoints(w, s.Pk.G1, bls12377.SizeOfG1AffineUncompressed, opts, func(p *bls12377.G1Affine, dst []byte) {
+				b := p.RawBytes()
+				copy(dst, b[:])
+			})
+		}
+		if err == nil {
+			err = writeVk(w, &s.Vk, compressed)
+		}
+	case *bls381Kzg.SRS:
+		if compressed {
+			err = writePoints(w, s.Pk.G1, bls12381.SizeOfG1AffineCompressed, opts, func(p *bls12381.G1Affine, dst []byte) {
+				b := p.Bytes()
+				copy(dst, b[:])
+			})
+		} else {
+			err = writePoints(w, s.Pk.G1, bls12381.SizeOfG1AffineUncompressed, opts, func(p *bls12381.G1Affine, dst []byte) {
+				b := p.RawBytes()
+				copy(dst, b[:])
+			})
+		}
+		if err == nil {
+			err = writeVk(w, &s.Vk, compressed)
+		}
+	case *bwKzg.SRS:
+		if compressed {
+			err = writePoints(w, s.Pk.G1, bw6761.SizeOfG1AffineCompressed, opts, func(p *bw6761.G1Affine, dst []byte) {
+				b := p.Bytes()
+				copy(dst, b[:])
+			})
+		} else {
+			err = writePoints(w, s.Pk.G1, bw6761.SizeOfG1AffineUncompressed, opts, func(p *bw6761.G1Affine, dst []byte) {
+				b := p.RawBytes()
+				copy(dst, b[:])
+			})
+		}
+		if err == nil {
+			err = writeVk(w, &s.Vk, compressed)
+		}
+	default:
+		err = fmt.Errorf("unsupported SRS type %T", srs)
+	}
+
+	return err
+}
+
+// vkWriter is implemented by the verifying keys of all supported curves.
+type vkWriter interface {
+	WriteTo(w io.Writer) (int64, error)
+	WriteRawTo(w io.Writer) (int64, error)
+}
+
+func writeVk(w io.Writer, vk vkWriter, compressed bool) error {
+	var err error
+	if compressed {
+		_, err = vk.WriteTo(w)
+	} else {
+		_, err = vk.WriteRawTo(w)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write verifying key: %w", err)
+	}
+
+	return nil
+}
+
+// writePoints writes the slice length followed by the encoded points, exactly as
+// gnark's Encoder does. Batches of points are encoded concurrently while the
+// already encoded batches are written in order.
+func writePoints[P any](w io.Writer, points []P, pointSize int, opts config.Options, encode func(p *P, dst []byte)) error {
+	if err := binary.Write(w, binary.BigEndian, uint32(len(points))); err != nil {
+		return fmt.Errorf("failed to write number of points: %w", err)
+	}
+
+	workers := max(opts.Workers, 1)
+	batchSize := opts.BatchSize
+	if batchSize < 1 {
+		batchSize = config.DefaultBatchSize
+	}
+
+	nBatches := (len(points) + batchSize - 1) / batchSize
+
+	// The buffers channel is both the pool of reusable batch buffers and the
+	// bound on how many batches may be in flight at the same time.
+	buffers := make(chan []byte, 2*workers)
+	for i := 0; i < cap(buffers); i++ {
+		buffers <- make([]byte, batchSize*pointSize)
+	}
+
+	results := make([]chan []byte, nBatches)
+	for i := range results {
+		results[i] = make(chan []byte, 1)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		for i := 0; i < nBatches; i++ {
+			var buf []byte
+			select {
+			case buf = <-buffers:
+			case <-done:
+				return
+			}
+
+			go func(i int, buf []byte) {
+				from := i * batchSize
+				to := min(from+batchSize, len(points))
+
+				for j := from; j < to; j++ {
+					encode(&points[j], buf[(j-from)*pointSize:])
+				}
+
+				results[i] <- buf[:(to-from)*pointSize]
+			}(i, buf)
+		}
+	}()
+
+	for i := range results {
+		buf := <-results[i]
+		if _, err := w.Write(buf); err != nil {
+			return fmt.Errorf("failed to write points batch %d: %w", i, err)
+		}
+		buffers <- buf[:cap(buf)]
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+
+	"linea/aztec-srs-to-gnark/srsio"
+)
+
+// stats prints a health summary of an SRS file, e.g. received from a third
+// party, without verifying it is made of powers of tau.
+func stats(args []string) error {
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	samples := flags.Int("samples", 0, "number of G1 points checked, spread over the SRS (0 - all of them)")
+	batchSize := flags.Int("batch-size", srsio.DefaultCompareBatch, "number of points decoded at once")
+	workers := flags.Int("workers", 0, "number of CPU workers (0 - auto)")
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s stats [flags] <SRS file>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	args = parseInterspersed(flags, args)
+
+	if len(args) < 1 {
+		flags.Usage()
+		return errUsage
+	}
+
+	r, err := srsio.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	if *workers <= 0 {
+		*workers = runtime.NumCPU()
+	}
+
+	s, err := srsio.CollectStats(r, *samples, *batchSize, *workers)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Format:  %s\n", r.Format)
+	fmt.Printf("Curve:   %s\n", curveNames[r.Curve])
+	fmt.Printf("Points:  %d (degree %d)\n", r.NbPoints, r.NbPoints-1)
+
+	if s.Sampled {
+		fmt.Printf("Checked: %d sampled G1 points\n", s.Checked)
+	} else {
+		fmt.Printf("Checked: all %d G1 points\n", s.Checked)
+	}
+
+	valid := s.Checked - s.NotOnCurve - s.NotInSubgroup - s.Infinity
+	fmt.Printf("> on the curve:      %d/%d (%s)\n", s.Checked-s.NotOnCurve, s.Checked, rate(s.Checked-s.NotOnCurve, s.Checked))
+	fmt.Printf("> in the subgroup:   %d/%d (%s)\n", valid+s.Infinity, s.Checked, rate(valid+s.Infinity, s.Checked))
+	fmt.Printf("> at infinity:       %d\n", s.Infinity)
+	if s.FirstInvalid >= 0 {
+		fmt.Printf("> first invalid:     G1[%d]\n", s.FirstInvalid)
+	}
+	if s.Duplicates > 0 {
+		fmt.Printf("> duplicates:        %d, e.g. G1[%d] = G1[%d]\n", s.Duplicates, s.DuplicatePair[0], s.DuplicatePair[1])
+	} else {
+		fmt.Printf("> duplicates:        0\n")
+	}
+
+	if s.VkValid {
+		fmt.Println("Verifying key: on the curve and in the subgroups")
+	} else {
+		fmt.Println("Verifying key: INVALID, a point is at infinity, not on the curve or not in its subgroup")
+	}
+
+	if valid == s.Checked && s.Duplicates == 0 && s.VkValid {
+		fmt.Println("Healthy")
+	} else {
+		fmt.Println("UNHEALTHY")
+	}
+
+	return nil
+}
+
+// rate formats n/total as a percentage.
+func rate(n, total int) string {
+	if total == 0 {
+		return "-"
+	}
+
+	return fmt.Sprintf("%.2f%%", 100*float64(n)/float64(total))
+}
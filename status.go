@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"linea/aztec-srs-to-gnark/config"
+)
+
+// runStatus is the progress of a running conversion, written periodically into
+// the status file so orchestrators and dashboards can poll it without parsing
+// the logs.
+type runStatus struct {
+	Command    string    `json:"command"`
+	Protocol   string    `json:"protocol"`
+	Curve      string    `json:"curve"`
+	Setup      string    `json:"setup"`
+	PID        int       `json:"pid"`
+	Start      time.Time `json:"start"`
+	Updated    time.Time `json:"updated"`
+	Stage      string    `json:"stage"`
+	StageStart time.Time `json:"stage_start"`
+
+	// PointsTotal is the number of G1 points read from the setup, unset
+	// until the importer knows it.
+	PointsProcessed int     `json:"points_processed"`
+	PointsTotal     int     `json:"points_total,omitempty"`
+	Percent         float64 `json:"percent,omitempty"`
+	// ETA is the estimated end of the reading of the points, from their rate
+	// so far.
+	ETA        *time.Time `json:"eta,omitempty"`
+	ETASeconds float64    `json:"eta_seconds,omitempty"`
+
+	Warnings []string `json:"warnings"`
+	Skipped  int      `json:"skipped,omitempty"`
+	Output   string   `json:"output,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// statusWriter writes the status of a conversion into a file every interval
+// until it is finished.
+type statusWriter struct {
+	path     string
+	progress *config.Progress
+	skipped  *config.Skips
+
+	mu     sync.Mutex
+	status runStatus
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// startStatus writes the status of the conversion into path every interval,
+// from its progress, until finish is called.
+func startStatus(path string, interval time.Duration, status runStatus, opts config.Options) *statusWriter {
+	w := &statusWriter{
+		path:     path,
+		progress: opts.Progress,
+		skipped:  opts.Skipped,
+		status:   status,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	w.status.PID = os.Getpid()
+	w.write()
+
+	go func() {
+		defer close(w.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				w.write()
+			case <-w.stop:
+				return
+			}
+		}
+	}()
+
+	return w
+}
+
+// finish stops the periodic writes and writes the final status of the
+// conversion, done or failed.
+func (w *statusWriter) finish(output, errMsg string) {
+	close(w.stop)
+	<-w.done
+
+	stage := config.StageDone
+	if errMsg != "" {
+		stage = config.StageFailed
+	}
+	w.progress.Stage(stage)
+
+	w.mu.Lock()
+	w.status.Output, w.status.Error = output, errMsg
+	w.mu.Unlock()
+
+	w.write()
+}
+
+// write writes the current status. Failing to write it doesn't fail the
+// conversion, it is only reported.
+func (w *statusWriter) write() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	state := w.progress.State()
+	now := time.Now()
+
+	s := &w.status
+	s.Updated = now
+	if state.Stage != "" {
+		s.Stage, s.StageStart = state.Stage, state.StageStart
+	}
+	s.PointsProcessed, s.PointsTotal = state.Processed, state.Total
+	s.Warnings = state.Warnings
+	if s.Warnings == nil {
+		s.Warnings = []string{}
+	}
+	s.Skipped = len(w.skipped.List())
+
+	s.Percent, s.ETA, s.ETASeconds = 0, nil, 0
+	if state.Total > 0 {
+		s.Percent = float64(state.Processed) * 100 / float64(state.Total)
+	}
+	if state.Stage == config.StageReading && state.Total > 0 && state.Processed > 0 && state.Processed < state.Total {
+		elapsed := now.Sub(state.StageStart)
+		remaining := time.Duration(float64(elapsed) * float64(state.Total-state.Processed) / float64(state.Processed))
+		eta := now.Add(remaining).Round(time.Second)
+		s.ETA, s.ETASeconds = &eta, remaining.Round(time.Second).Seconds()
+	}
+
+	if err := writeStatus(w.path, s); err != nil {
+		fmt.Printf("WARNING: %v\n", err)
+	}
+}
+
+// writeStatus writes the status as JSON, atomically so that a poller never
+// reads a partial one.
+func writeStatus(path string, s *runStatus) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode status: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write status file: %w", err)
+	}
+
+	return os.Rename(tmp, path)
+}
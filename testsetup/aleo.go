@@ -0,0 +1,55 @@
+package testsetup
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fp"
+	blsKzg "github.com/consensys/gnark-crypto/ecc/bls12-377/kzg"
+)
+
+// Aleo writes the setup files of snarkVM into dir: the G2 setup file
+// beta-h.usrs with τG2, and the setup split in piecesN G1 setup files named
+// powers-of-beta-0.usrs and so on, each holding pointsN powers of τ in G1 from
+// τ¹ after their number. The coordinates are little endian. The returned SRS
+// is the one of the whole setup, from the generator.
+func Aleo(dir string, piecesN, pointsN int, secrets Secrets) (*blsKzg.SRS, error) {
+	if piecesN < 1 || pointsN < 1 {
+		return nil, errors.New("the setup needs at least a piece of a point")
+	}
+
+	srs, err := blsKzg.NewSRS(uint64(piecesN*pointsN+1), secrets.Tau)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate SRS: %w", err)
+	}
+
+	tauG2 := &srs.Vk.G2[1]
+	if err = writeFile(dir, "beta-h.usrs", appendAleoElements(nil, &tauG2.X.A0, &tauG2.X.A1, &tauG2.Y.A0, &tauG2.Y.A1)); err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < piecesN; i++ {
+		data := binary.LittleEndian.AppendUint64(nil, uint64(pointsN))
+		for _, p := range srs.Pk.G1[1+i*pointsN : 1+(i+1)*pointsN] {
+			data = appendAleoElements(data, &p.X, &p.Y)
+		}
+
+		if err = writeFile(dir, fmt.Sprintf("powers-of-beta-%d.usrs", i), data); err != nil {
+			return nil, err
+		}
+	}
+
+	return srs, nil
+}
+
+// appendAleoElements appends little endian field elements.
+func appendAleoElements(data []byte, elements ...*fp.Element) []byte {
+	for _, e := range elements {
+		var b [fp.Bytes]byte
+		fp.LittleEndian.PutElement(&b, *e)
+		data = append(data, b[:]...)
+	}
+
+	return data
+}
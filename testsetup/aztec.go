@@ -0,0 +1,76 @@
+package testsetup
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Aztec writes the transcripts of an Ignition-like ceremony into dir, named
+// transcript00.dat and so on, each holding pointsN powers of τ in G1 from τ¹.
+// The first transcript also holds τG2, and every transcript ends with the
+// BLAKE2b hash of the rest of it. The returned SRS is the one of the whole
+// ceremony, from the generator.
+func Aztec(dir string, transcriptsN, pointsN int, secrets Secrets) (*bnKzg.SRS, error) {
+	if transcriptsN < 1 || pointsN < 1 {
+		return nil, errors.New("the ceremony needs at least a transcript of a point")
+	}
+
+	srs, err := bnKzg.NewSRS(uint64(transcriptsN*pointsN+1), secrets.Tau)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate SRS: %w", err)
+	}
+
+	for t := 0; t < transcriptsN; t++ {
+		g2PointsN := 0
+		if t == 0 {
+			g2PointsN = 2
+		}
+
+		// transcript number, number of transcripts, G1 and G2 points of the
+		// ceremony, G1 and G2 points of the transcript, index of its first point
+		header := []int32{int32(t), int32(transcriptsN), int32(transcriptsN * pointsN), 1, int32(pointsN), int32(g2PointsN), int32(t * pointsN)}
+		data, err := binary.Append(nil, binary.BigEndian, header)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range srs.Pk.G1[1+t*pointsN : 1+(t+1)*pointsN] {
+			data = appendAztecElements(data, &p.X, &p.Y)
+		}
+		if g2PointsN != 0 {
+			// The importer reads τG2 from the second G2 point
+			_, _, _, g2 := bn254.Generators()
+			for _, p := range []*bn254.G2Affine{&g2, &srs.Vk.G2[1]} {
+				data = appendAztecElements(data, &p.X.A0, &p.X.A1, &p.Y.A0, &p.Y.A1)
+			}
+		}
+
+		checksum := blake2b.Sum512(data)
+		data = append(data, checksum[:]...)
+
+		if err = writeFile(dir, fmt.Sprintf("transcript%02d.dat", t), data); err != nil {
+			return nil, err
+		}
+	}
+
+	return srs, nil
+}
+
+// appendAztecElements appends field elements as the transcripts store them:
+// four 64-bit words, the least significant first, each of them big endian.
+func appendAztecElements(data []byte, elements ...*fp.Element) []byte {
+	for _, e := range elements {
+		b := e.Bytes()
+		for i := 3; i >= 0; i-- {
+			data = append(data, b[i*8:(i+1)*8]...)
+		}
+	}
+
+	return data
+}
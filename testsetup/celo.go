@@ -0,0 +1,141 @@
+package testsetup
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bw6-761"
+	"github.com/consensys/gnark-crypto/ecc/bw6-761/fp"
+	"github.com/consensys/gnark-crypto/ecc/bw6-761/fr"
+	bwKzg "github.com/consensys/gnark-crypto/ecc/bw6-761/kzg"
+
+	"linea/aztec-srs-to-gnark/celo"
+)
+
+// Celo writes the chunk files of a Plumo-like ceremony with the constants of
+// the ceremony into dir, a single contribution to each of its chunks, named
+// 0.<chunk>.1.0xtest. The full chunks, the first ones, hold the powers of τ in
+// G1 and G2 and the α and β powers in G1, the other ones the powers of τ in G1
+// alone, and all of them end with βG2. The hashes are the ones of the hash
+// algorithm of the ceremony. With response set the files are response
+// files, with compressed points and the public key of the participant,
+// otherwise challenge files. The returned SRS is the one of the whole ceremony.
+func Celo(dir string, ceremony celo.Ceremony, response bool, secrets Secrets) (*bwKzg.SRS, error) {
+	if err := ceremony.Validate(); err != nil {
+		return nil, err
+	}
+
+	srs, err := bwKzg.NewSRS(uint64(ceremony.G1PointsN), secrets.Tau)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate SRS: %w", err)
+	}
+
+	var tau fr.Element
+	tau.SetBigInt(secrets.Tau)
+
+	var betaG2 bw6761.G2Affine
+	betaG2.ScalarMultiplicationBase(secrets.Beta)
+
+	for chunkNum := 0; chunkNum < ceremony.ChunksN; chunkNum++ {
+		from := chunkNum * ceremony.ChunkG1PointsN
+		tauG1 := srs.Pk.G1[from:min(from+ceremony.ChunkG1PointsN, ceremony.G1PointsN)]
+
+		var w celoWriter
+		w.g1(tauG1...)
+		if chunkNum < ceremony.FullChunksN {
+			tauG2 := make([]bw6761.G2Affine, len(tauG1))
+			alphaG1 := make([]bw6761.G1Affine, len(tauG1))
+			betaG1 := make([]bw6761.G1Affine, len(tauG1))
+
+			var power fr.Element
+			power.Exp(tau, big.NewInt(int64(from)))
+			for i := range tauG1 {
+				tauG2[i].ScalarMultiplicationBase(power.BigInt(new(big.Int)))
+				alphaG1[i].ScalarMultiplication(&tauG1[i], secrets.Alpha)
+				betaG1[i].ScalarMultiplication(&tauG1[i], secrets.Beta)
+				power.Mul(&power, &tau)
+			}
+
+			w.g2(tauG2...)
+			w.g1(alphaG1...)
+			w.g1(betaG1...)
+		}
+		w.g2(betaG2)
+
+		// A challenge starts with the hash of the previous response, none here,
+		// and a response with the hash of its challenge.
+		h := ceremony.Hash.New()
+		data := w.encode(h.Sum(nil), false)
+		if response {
+			h.Write(data)
+			data = w.encode(h.Sum(nil), true)
+			data = appendCeloPublicKey(data, secrets)
+		}
+
+		if err = writeFile(dir, fmt.Sprintf("0.%d.1.0xtest", chunkNum), data); err != nil {
+			return nil, err
+		}
+	}
+
+	return srs, nil
+}
+
+// celoWriter collects the coordinates of the points of a chunk file, G1 and
+// G2 points alike being on the base field.
+type celoWriter struct {
+	x, y []fp.Element
+}
+
+func (w *celoWriter) g1(points ...bw6761.G1Affine) {
+	for _, p := range points {
+		w.x, w.y = append(w.x, p.X), append(w.y, p.Y)
+	}
+}
+
+func (w *celoWriter) g2(points ...bw6761.G2Affine) {
+	for _, p := range points {
+		w.x, w.y = append(w.x, p.X), append(w.y, p.Y)
+	}
+}
+
+// encode appends the points little endian to data, compressed when set: the x
+// coordinate, with the flag of the lexicographically largest y in the most
+// significant bit of its last byte.
+func (w *celoWriter) encode(data []byte, compressed bool) []byte {
+	var b [fp.Bytes]byte
+	for i := range w.x {
+		fp.LittleEndian.PutElement(&b, w.x[i])
+		if compressed && w.y[i].LexicographicallyLargest() {
+			b[fp.Bytes-1] |= 1 << 7
+		}
+		data = append(data, b[:]...)
+
+		if !compressed {
+			fp.LittleEndian.PutElement(&b, w.y[i])
+			data = append(data, b[:]...)
+		}
+	}
+
+	return data
+}
+
+// appendCeloPublicKey appends the public key of the participant ending a
+// response file, uncompressed: for τ, α and β, the G1 generator and its
+// multiple by the secret, then their multiples of the G2 generator.
+func appendCeloPublicKey(data []byte, secrets Secrets) []byte {
+	_, _, g1, _ := bw6761.Generators()
+
+	var w celoWriter
+	for _, s := range []*big.Int{secrets.Tau, secrets.Alpha, secrets.Beta} {
+		var p bw6761.G1Affine
+		p.ScalarMultiplicationBase(s)
+		w.g1(g1, p)
+	}
+	for _, s := range []*big.Int{secrets.Tau, secrets.Alpha, secrets.Beta} {
+		var p bw6761.G2Affine
+		p.ScalarMultiplicationBase(s)
+		w.g2(p)
+	}
+
+	return w.encode(data, false)
+}
@@ -0,0 +1,83 @@
+package testsetup
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381"
+	bls381Kzg "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+)
+
+// ethereumG2Powers is the number of G2 powers of every sub-ceremony of the
+// Ethereum KZG ceremony.
+const ethereumG2Powers = 65
+
+// Ethereum writes the transcript of the Ethereum KZG ceremony into dir as
+// transcript.json: transcriptsN sub-ceremonies, the i-th one holding
+// pointsN·2^i G1 powers of τ+i from the generator and 65 G2 powers, compressed
+// as in ZCash and hex encoded. The witness of the contributions is empty. The
+// returned SRS is the one of the largest sub-ceremony.
+func Ethereum(dir string, transcriptsN, pointsN int, secrets Secrets) (*bls381Kzg.SRS, error) {
+	if transcriptsN < 1 || pointsN < 2 {
+		return nil, errors.New("the transcript needs at least a sub-ceremony of 2 points")
+	}
+
+	type powers struct {
+		G1Powers []string `json:"G1Powers"`
+		G2Powers []string `json:"G2Powers"`
+	}
+	type witness struct {
+		RunningProducts []string `json:"runningProducts"`
+		PotPubkeys      []string `json:"potPubkeys"`
+		BLSSignatures   []string `json:"blsSignatures"`
+	}
+	type transcript struct {
+		NumG1Powers int     `json:"numG1Powers"`
+		NumG2Powers int     `json:"numG2Powers"`
+		PowersOfTau powers  `json:"powersOfTau"`
+		Witness     witness `json:"witness"`
+	}
+	content := struct {
+		Transcripts                []transcript `json:"transcripts"`
+		ParticipantIDs             []string     `json:"participantIds"`
+		ParticipantECDSASignatures []string     `json:"participantEcdsaSignatures"`
+	}{ParticipantIDs: []string{}, ParticipantECDSASignatures: []string{}}
+
+	var srs *bls381Kzg.SRS
+	for i := 0; i < transcriptsN; i++ {
+		tau := new(big.Int).Add(secrets.Tau, big.NewInt(int64(i)))
+		g1PowersN := pointsN << i
+
+		var err error
+		if srs, err = bls381Kzg.NewSRS(uint64(g1PowersN), tau); err != nil {
+			return nil, fmt.Errorf("failed to generate SRS: %w", err)
+		}
+
+		t := transcript{NumG1Powers: g1PowersN, NumG2Powers: ethereumG2Powers}
+		for j := range srs.Pk.G1 {
+			b := srs.Pk.G1[j].Bytes()
+			t.PowersOfTau.G1Powers = append(t.PowersOfTau.G1Powers, "0x"+hex.EncodeToString(b[:]))
+		}
+		_, _, _, g2 := bls12381.Generators()
+		for j := 0; j < ethereumG2Powers; j++ {
+			b := g2.Bytes()
+			t.PowersOfTau.G2Powers = append(t.PowersOfTau.G2Powers, "0x"+hex.EncodeToString(b[:]))
+			g2.ScalarMultiplication(&g2, tau)
+		}
+		t.Witness = witness{RunningProducts: []string{}, PotPubkeys: []string{}, BLSSignatures: []string{}}
+		content.Transcripts = append(content.Transcripts, t)
+	}
+
+	data, err := json.Marshal(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transcript: %w", err)
+	}
+	if err = writeFile(dir, "transcript.json", data); err != nil {
+		return nil, err
+	}
+
+	return srs, nil
+}
@@ -0,0 +1,29 @@
+package testsetup
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+)
+
+// golden holds the digests of the outputs of the conversions of the test
+// setups, in the layout of sha256sum: the digest, two spaces and the name of
+// the output.
+//
+//go:embed golden.sha256
+var golden string
+
+// Golden returns the checked-in digests of the outputs of the conversions of
+// the test setups, by name of the output.
+func Golden() (map[string]string, error) {
+	digests := make(map[string]string)
+	for i, line := range strings.Split(strings.TrimSpace(golden), "\n") {
+		digest, name, ok := strings.Cut(line, "  ")
+		if !ok {
+			return nil, fmt.Errorf("line %d of the golden digests isn't a digest and a name", i+1)
+		}
+		digests[name] = digest
+	}
+
+	return digests, nil
+}
@@ -0,0 +1,40 @@
+package testsetup
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+)
+
+// Halo2 writes the params of halo2 of k into dir as kzg_bn254_<k>.srs, in the
+// raw format of ParamsKZG::write: k, the 2^k powers of τ in G1, their Lagrange
+// basis, the G2 generator and τG2, the coordinates in the Montgomery form as
+// little endian limbs, like in a ptau file. The returned SRS holds the powers
+// of τ.
+func Halo2(dir string, k int, secrets Secrets) (*bnKzg.SRS, error) {
+	if k < 1 || k > 20 {
+		return nil, errors.New("the k of the test setup must be between 1 and 20")
+	}
+
+	srs, err := bnKzg.NewSRS(uint64(1)<<k, secrets.Tau)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate SRS: %w", err)
+	}
+	lagrange, err := bnKzg.ToLagrangeG1(srs.Pk.G1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute the Lagrange basis: %w", err)
+	}
+
+	data := binary.LittleEndian.AppendUint32(nil, uint32(k))
+	data = appendPtauG1(data, srs.Pk.G1...)
+	data = appendPtauG1(data, lagrange...)
+	data = appendPtauG2(data, srs.Vk.G2[:]...)
+
+	if err = writeFile(dir, fmt.Sprintf("kzg_bn254_%d.srs", k), data); err != nil {
+		return nil, err
+	}
+
+	return srs, nil
+}
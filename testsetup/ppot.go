@@ -0,0 +1,76 @@
+package testsetup
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+
+	"linea/aztec-srs-to-gnark/ppot"
+)
+
+// PPoT writes a challenge file of the Perpetual Powers of Tau ceremony into
+// dir as challenge_0001, holding powersN powers of τ in G2: after the hash of
+// the previous response, none here, the 2·powersN-1 powers of τ in G1, the
+// powers in G2, the α and β powers in G1 and βG2, uncompressed and big
+// endian. The returned SRS holds all the G1 powers.
+func PPoT(dir string, powersN int, secrets Secrets) (*bnKzg.SRS, error) {
+	if powersN < 2 {
+		return nil, errors.New("the test setup needs at least 2 powers of τ in G2")
+	}
+
+	srs, err := bnKzg.NewSRS(uint64(2*powersN-1), secrets.Tau)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate SRS: %w", err)
+	}
+
+	data := ppot.HashAlgorithm.New().Sum(nil)
+	data = appendPPoTG1(data, srs.Pk.G1...)
+	for i := 0; i < powersN; i++ {
+		var power big.Int
+		power.Exp(secrets.Tau, big.NewInt(int64(i)), bn254.ID.ScalarField())
+		var p bn254.G2Affine
+		p.ScalarMultiplicationBase(&power)
+		data = appendPPoTG2(data, p)
+	}
+	for _, s := range []*big.Int{secrets.Alpha, secrets.Beta} {
+		for i := 0; i < powersN; i++ {
+			var p bn254.G1Affine
+			p.ScalarMultiplication(&srs.Pk.G1[i], s)
+			data = appendPPoTG1(data, p)
+		}
+	}
+	var betaG2 bn254.G2Affine
+	betaG2.ScalarMultiplicationBase(secrets.Beta)
+	data = appendPPoTG2(data, betaG2)
+
+	if err = writeFile(dir, "challenge_0001", data); err != nil {
+		return nil, err
+	}
+
+	return srs, nil
+}
+
+// appendPPoTG1 appends G1 points uncompressed: x and y, big endian, the
+// layout of RawBytes.
+func appendPPoTG1(data []byte, points ...bn254.G1Affine) []byte {
+	for _, p := range points {
+		b := p.RawBytes()
+		data = append(data, b[:]...)
+	}
+
+	return data
+}
+
+// appendPPoTG2 appends G2 points uncompressed: x.c1, x.c0, y.c1 and y.c0, big
+// endian, the layout of RawBytes.
+func appendPPoTG2(data []byte, points ...bn254.G2Affine) []byte {
+	for _, p := range points {
+		b := p.RawBytes()
+		data = append(data, b[:]...)
+	}
+
+	return data
+}
@@ -0,0 +1,114 @@
+package testsetup
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+)
+
+// Ptau writes the ptau file of snarkjs of the power into dir as
+// powersOfTau_<power>.ptau: the header, the tauG1 section of 2^(power+1)-1
+// powers of τ, the tauG2 one of 2^power powers, the alphaTauG1, betaTauG1 and
+// betaG2 sections and an empty list of contributions. The coordinates are in
+// the Montgomery form, as little endian limbs. The returned SRS holds all the
+// G1 powers.
+func Ptau(dir string, power int, secrets Secrets) (*bnKzg.SRS, error) {
+	if power < 1 || power > 20 {
+		return nil, errors.New("the power of the test setup must be between 1 and 20")
+	}
+
+	g1PowersN, g2PowersN := 1<<(power+1)-1, 1<<power
+	srs, err := bnKzg.NewSRS(uint64(g1PowersN), secrets.Tau)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate SRS: %w", err)
+	}
+
+	_, _, _, g2Gen := bn254.Generators()
+	g2Powers := make([]bn254.G2Affine, g2PowersN)
+	g2Powers[0] = g2Gen
+	for i := 1; i < g2PowersN; i++ {
+		g2Powers[i].ScalarMultiplication(&g2Powers[i-1], secrets.Tau)
+	}
+	// α and β times the powers of τ in G1, the first 2^power ones
+	scaled := func(scalar *big.Int) []bn254.G1Affine {
+		points := make([]bn254.G1Affine, g2PowersN)
+		for i := range points {
+			points[i].ScalarMultiplication(&srs.Pk.G1[i], scalar)
+		}
+		return points
+	}
+	var betaG2 bn254.G2Affine
+	betaG2.ScalarMultiplication(&g2Gen, secrets.Beta)
+
+	modulus := fp.Modulus()
+	header := binary.LittleEndian.AppendUint32(nil, fp.Bytes)
+	header = append(header, make([]byte, fp.Bytes)...)
+	modulus.FillBytes(header[4:])
+	for i, j := 4, 4+fp.Bytes-1; i < j; i, j = i+1, j-1 {
+		header[i], header[j] = header[j], header[i]
+	}
+	header = binary.LittleEndian.AppendUint32(header, uint32(power))
+	header = binary.LittleEndian.AppendUint32(header, uint32(power))
+
+	sections := [][]byte{
+		header,
+		appendPtauG1(nil, srs.Pk.G1...),
+		appendPtauG2(nil, g2Powers...),
+		appendPtauG1(nil, scaled(secrets.Alpha)...),
+		appendPtauG1(nil, scaled(secrets.Beta)...),
+		appendPtauG2(nil, betaG2),
+		// No contributions
+		binary.LittleEndian.AppendUint32(nil, 0),
+	}
+
+	data := binary.LittleEndian.AppendUint32([]byte("ptau"), 1)
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(sections)))
+	for i, section := range sections {
+		data = binary.LittleEndian.AppendUint32(data, uint32(i+1))
+		data = binary.LittleEndian.AppendUint64(data, uint64(len(section)))
+		data = append(data, section...)
+	}
+	if err = writeFile(dir, fmt.Sprintf("powersOfTau_%d.ptau", power), data); err != nil {
+		return nil, err
+	}
+
+	return srs, nil
+}
+
+// appendPtauG1 appends the coordinates of G1 points the way snarkjs writes
+// them.
+func appendPtauG1(data []byte, points ...bn254.G1Affine) []byte {
+	for i := range points {
+		data = appendMontgomery(data, &points[i].X, &points[i].Y)
+	}
+
+	return data
+}
+
+// appendPtauG2 appends the coordinates of G2 points the way snarkjs writes
+// them.
+func appendPtauG2(data []byte, points ...bn254.G2Affine) []byte {
+	for i := range points {
+		p := &points[i]
+		data = appendMontgomery(data, &p.X.A0, &p.X.A1, &p.Y.A0, &p.Y.A1)
+	}
+
+	return data
+}
+
+// appendMontgomery appends field elements in the Montgomery form, as little
+// endian limbs.
+func appendMontgomery(data []byte, elements ...*fp.Element) []byte {
+	for _, e := range elements {
+		for _, limb := range e {
+			data = binary.LittleEndian.AppendUint64(data, limb)
+		}
+	}
+
+	return data
+}
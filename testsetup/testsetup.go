@@ -0,0 +1,37 @@
+// Package testsetup fabricates tiny setup files of the supported ceremonies
+// from known secrets, in the layouts of the published ones: headers, point
+// encodings and hashes. They exercise the importers without the gigabytes of
+// the real ceremonies, and the SRS the importers must construct from them is
+// returned alongside. Such setups must never be used in production since
+// their secrets are public.
+package testsetup
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+)
+
+// Secrets are the toxic waste of a fabricated setup. Alpha and Beta are only
+// used by the ceremonies with the Groth16 phase 1 points.
+type Secrets struct {
+	Tau, Alpha, Beta *big.Int
+}
+
+// DefaultSecrets returns small known secrets.
+func DefaultSecrets() Secrets {
+	return Secrets{Tau: big.NewInt(42), Alpha: big.NewInt(5), Beta: big.NewInt(7)}
+}
+
+// writeFile writes the file of the setup into dir, creating dir if needed.
+func writeFile(dir, name string, data []byte) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create setup directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write setup file %s: %w", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package testsetup_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/aleo"
+	"linea/aztec-srs-to-gnark/aztec"
+	"linea/aztec-srs-to-gnark/celo"
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/digest"
+	"linea/aztec-srs-to-gnark/ethereum"
+	"linea/aztec-srs-to-gnark/halo2"
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/phase1"
+	"linea/aztec-srs-to-gnark/ppot"
+	"linea/aztec-srs-to-gnark/ptau"
+	"linea/aztec-srs-to-gnark/srsio"
+	"linea/aztec-srs-to-gnark/testsetup"
+	"linea/aztec-srs-to-gnark/verify"
+	"linea/aztec-srs-to-gnark/zcash"
+	"linea/aztec-srs-to-gnark/zksync"
+)
+
+// ceremony is the Plumo ceremony with 2 points per chunk.
+var ceremony = celo.Ceremony{
+	ChunkG1PointsN: 2,
+	G1PointsN:      2*celo.TotalChunks - 1,
+	ChunksN:        celo.TotalChunks,
+	FullChunksN:    celo.ChunkHalfwayPoint,
+	Hash:           digest.BLAKE2b512,
+}
+
+// gzipDir compresses the files of dir with gzip into a new directory.
+func gzipDir(t *testing.T, dir string) string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compressedDir := t.TempDir()
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		w.Write(data)
+		if err = w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(filepath.Join(compressedDir, entry.Name()+".gz"), buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return compressedDir
+}
+
+// TestRoundTrip generates the setup of every ceremony, converts it with its
+// importer and checks that the SRS is the one of the secrets and is made of
+// consecutive powers of τ. The importers reading files of unknown size convert
+// the setup compressed too.
+func TestRoundTrip(t *testing.T) {
+	cases := []struct {
+		name      string
+		generate  func(dir string, secrets testsetup.Secrets) (kzg.SRS, error)
+		translate func(files []input.File, opts config.Options) (kzg.SRS, int, error)
+		// compressed is set when the importer reads the setup compressed
+		compressed bool
+	}{
+		{"aztec", func(dir string, secrets testsetup.Secrets) (kzg.SRS, error) {
+			return testsetup.Aztec(dir, 2, 4, secrets)
+		}, aztec.TranslateBn254SRS, true},
+		{"aleo", func(dir string, secrets testsetup.Secrets) (kzg.SRS, error) {
+			return testsetup.Aleo(dir, 2, 4, secrets)
+		}, aleo.TranslateBls12377SRS, false},
+		{"celo-challenge", func(dir string, secrets testsetup.Secrets) (kzg.SRS, error) {
+			return testsetup.Celo(dir, ceremony, false, secrets)
+		}, ceremony.Translate, false},
+		{"celo-response", func(dir string, secrets testsetup.Secrets) (kzg.SRS, error) {
+			return testsetup.Celo(dir, ceremony, true, secrets)
+		}, ceremony.Translate, false},
+		{"ethereum", func(dir string, secrets testsetup.Secrets) (kzg.SRS, error) {
+			return testsetup.Ethereum(dir, 2, 4, secrets)
+		}, ethereum.TranslateBls12381SRS, true},
+		{"halo2", func(dir string, secrets testsetup.Secrets) (kzg.SRS, error) {
+			return testsetup.Halo2(dir, 3, secrets)
+		}, halo2.TranslateBn254SRS, true},
+		{"ppot", func(dir string, secrets testsetup.Secrets) (kzg.SRS, error) {
+			return testsetup.PPoT(dir, 4, secrets)
+		}, ppot.TranslateBn254SRS, false},
+		{"ptau", func(dir string, secrets testsetup.Secrets) (kzg.SRS, error) {
+			return testsetup.Ptau(dir, 2, secrets)
+		}, ptau.TranslateBn254SRS, true},
+		{"zcash-challenge", func(dir string, secrets testsetup.Secrets) (kzg.SRS, error) {
+			return testsetup.Zcash(dir, 4, false, secrets)
+		}, zcash.TranslateBls12381SRS, false},
+		{"zcash-response", func(dir string, secrets testsetup.Secrets) (kzg.SRS, error) {
+			return testsetup.Zcash(dir, 4, true, secrets)
+		}, zcash.TranslateBls12381SRS, false},
+		{"zksync", func(dir string, secrets testsetup.Secrets) (kzg.SRS, error) {
+			return testsetup.Zksync(dir, 3, secrets)
+		}, zksync.TranslateBn254SRS, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := t.TempDir()
+			expected, err := c.generate(dir, testsetup.DefaultSecrets())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			files, err := input.Dir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			opts := config.Tune(config.Options{IOParallelism: 1}, "")
+			srs, pointsN, err := c.translate(files, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ext, ok := srs.(*phase1.SRS); ok {
+				srs = ext.SRS
+			}
+
+			var want, got bytes.Buffer
+			if err = srsio.Write(&want, expected, srsio.FormatCanonical, opts); err != nil {
+				t.Fatal(err)
+			}
+			if err = srsio.Write(&got, srs, srsio.FormatCanonical, opts); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got.Bytes(), want.Bytes()) {
+				t.Fatalf("the converted SRS of %d points isn't the one of the secrets", pointsN)
+			}
+
+			if err = verify.SRS(srs, opts); err != nil {
+				t.Fatal(err)
+			}
+
+			// check converts the setup at path again
+			check := func(t *testing.T, path string) {
+				t.Helper()
+				files, err := input.Dir(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				srs, _, err := c.translate(files, opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if ext, ok := srs.(*phase1.SRS); ok {
+					srs = ext.SRS
+				}
+				var got bytes.Buffer
+				if err = srsio.Write(&got, srs, srsio.FormatCanonical, opts); err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(got.Bytes(), want.Bytes()) {
+					t.Fatalf("the SRS converted from %s isn't the one of the secrets", path)
+				}
+			}
+
+			// A single setup file is converted from its path too
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) == 1 {
+				check(t, filepath.Join(dir, entries[0].Name()))
+			}
+			if c.compressed {
+				check(t, gzipDir(t, dir))
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package testsetup
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381"
+	bls381Kzg "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+
+	"linea/aztec-srs-to-gnark/zcash"
+)
+
+// Zcash writes a challenge file of the powersoftau software of Zcash into dir,
+// holding powersN powers of τ in G2, named challenge, or with response set
+// the response file to it, named response. The file holds 2·powersN-1 powers
+// of τ in G1, the powers in G2, the α and β powers in G1 and βG2, encoded as
+// in ZCash, uncompressed in a challenge and compressed in a response, which
+// ends with the public key of the participant. The returned SRS holds all the
+// G1 powers.
+func Zcash(dir string, powersN int, response bool, secrets Secrets) (*bls381Kzg.SRS, error) {
+	if powersN < 2 {
+		return nil, errors.New("the test setup needs at least 2 powers of τ in G2")
+	}
+
+	srs, err := bls381Kzg.NewSRS(uint64(2*powersN-1), secrets.Tau)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate SRS: %w", err)
+	}
+
+	var w zcashWriter
+	w.g1(srs.Pk.G1...)
+	for i := 0; i < powersN; i++ {
+		var power big.Int
+		power.Exp(secrets.Tau, big.NewInt(int64(i)), bls12381.ID.ScalarField())
+		var p bls12381.G2Affine
+		p.ScalarMultiplicationBase(&power)
+		w.g2(p)
+	}
+	for _, s := range []*big.Int{secrets.Alpha, secrets.Beta} {
+		for i := 0; i < powersN; i++ {
+			var p bls12381.G1Affine
+			p.ScalarMultiplication(&srs.Pk.G1[i], s)
+			w.g1(p)
+		}
+	}
+	var betaG2 bls12381.G2Affine
+	betaG2.ScalarMultiplicationBase(secrets.Beta)
+	w.g2(betaG2)
+
+	// A challenge starts with the hash of the previous response, none here,
+	// and a response with the hash of its challenge.
+	h := zcash.HashAlgorithm.New()
+	data := w.encode(h.Sum(nil), false)
+	name := "challenge"
+	if response {
+		h.Write(data)
+		data = w.encode(h.Sum(nil), true)
+		data = appendZcashPublicKey(data, secrets)
+		name = "response"
+	}
+	if err = writeFile(dir, name, data); err != nil {
+		return nil, err
+	}
+
+	return srs, nil
+}
+
+// zcashWriter collects the points of a challenge or response file in their
+// order, uncompressed and compressed.
+type zcashWriter struct {
+	encoded [][2][]byte
+}
+
+func (w *zcashWriter) g1(points ...bls12381.G1Affine) {
+	for _, p := range points {
+		compressed, uncompressed := p.Bytes(), p.RawBytes()
+		w.encoded = append(w.encoded, [2][]byte{uncompressed[:], compressed[:]})
+	}
+}
+
+func (w *zcashWriter) g2(points ...bls12381.G2Affine) {
+	for _, p := range points {
+		compressed, uncompressed := p.Bytes(), p.RawBytes()
+		w.encoded = append(w.encoded, [2][]byte{uncompressed[:], compressed[:]})
+	}
+}
+
+// encode appends the points to data as in ZCash, compressed when set.
+func (w *zcashWriter) encode(data []byte, compressed bool) []byte {
+	for _, e := range w.encoded {
+		if compressed {
+			data = append(data, e[1]...)
+		} else {
+			data = append(data, e[0]...)
+		}
+	}
+
+	return data
+}
+
+// appendZcashPublicKey appends the public key of the participant ending a
+// response file, uncompressed: for τ, α and β, the G1 generator and its
+// multiple by the secret, then their multiples of the G2 generator.
+func appendZcashPublicKey(data []byte, secrets Secrets) []byte {
+	_, _, g1, _ := bls12381.Generators()
+
+	var w zcashWriter
+	for _, s := range []*big.Int{secrets.Tau, secrets.Alpha, secrets.Beta} {
+		var p bls12381.G1Affine
+		p.ScalarMultiplicationBase(s)
+		w.g1(g1, p)
+	}
+	for _, s := range []*big.Int{secrets.Tau, secrets.Alpha, secrets.Beta} {
+		var p bls12381.G2Affine
+		p.ScalarMultiplicationBase(s)
+		w.g2(p)
+	}
+
+	return w.encode(data, false)
+}
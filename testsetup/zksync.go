@@ -0,0 +1,35 @@
+package testsetup
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+)
+
+// Zksync writes a key file of the universal setup of Matter Labs into dir as
+// setup_2^<power>.key: the number of G1 points, big endian, the 2^power powers
+// of τ in G1, then the number of G2 points, 2, the generator and τG2, the
+// points uncompressed and big endian as in the challenge files of PPoT.
+func Zksync(dir string, power int, secrets Secrets) (*bnKzg.SRS, error) {
+	if power < 1 || power > 20 {
+		return nil, errors.New("the power of the test setup must be between 1 and 20")
+	}
+
+	srs, err := bnKzg.NewSRS(uint64(1)<<power, secrets.Tau)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate SRS: %w", err)
+	}
+
+	data := binary.BigEndian.AppendUint64(nil, uint64(len(srs.Pk.G1)))
+	data = appendPPoTG1(data, srs.Pk.G1...)
+	data = binary.BigEndian.AppendUint64(data, uint64(len(srs.Vk.G2)))
+	data = appendPPoTG2(data, srs.Vk.G2[:]...)
+
+	if err = writeFile(dir, fmt.Sprintf("setup_2^%d.key", power), data); err != nil {
+		return nil, err
+	}
+
+	return srs, nil
+}
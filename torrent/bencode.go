@@ -0,0 +1,181 @@
+package torrent
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// maxDepth bounds the nesting of bencoded lists and dictionaries.
+const maxDepth = 64
+
+// decoder decodes bencoded data into int64, string, []any and map[string]any
+// values. The raw encoding of the info dictionary is kept as it defines the
+// info hash of the torrent.
+type decoder struct {
+	data  []byte
+	pos   int
+	depth int
+
+	info []byte
+}
+
+func (d *decoder) value() (any, error) {
+	if d.pos >= len(d.data) {
+		return nil, errors.New("unexpected end of data")
+	}
+
+	switch c := d.data[d.pos]; {
+	case c == 'i':
+		d.pos++
+		return d.integer('e')
+	case c == 'l':
+		return d.list()
+	case c == 'd':
+		return d.dict()
+	case c >= '0' && c <= '9':
+		return d.string()
+	default:
+		return nil, fmt.Errorf("invalid bencode value type '%c' at %d", c, d.pos)
+	}
+}
+
+// integer reads a decimal integer terminated by end.
+func (d *decoder) integer(end byte) (int64, error) {
+	start := d.pos
+	for d.pos < len(d.data) && d.data[d.pos] != end {
+		d.pos++
+	}
+	if d.pos >= len(d.data) {
+		return 0, errors.New("unterminated integer")
+	}
+
+	n, err := strconv.ParseInt(string(d.data[start:d.pos]), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid integer at %d: %w", start, err)
+	}
+	d.pos++
+
+	return n, nil
+}
+
+func (d *decoder) string() (string, error) {
+	n, err := d.integer(':')
+	if err != nil {
+		return "", err
+	}
+
+	if n < 0 || n > int64(len(d.data)-d.pos) {
+		return "", fmt.Errorf("invalid string length %d at %d", n, d.pos)
+	}
+
+	s := string(d.data[d.pos : d.pos+int(n)])
+	d.pos += int(n)
+
+	return s, nil
+}
+
+func (d *decoder) list() ([]any, error) {
+	if d.depth++; d.depth > maxDepth {
+		return nil, errors.New("bencode nesting too deep")
+	}
+	defer func() { d.depth-- }()
+
+	d.pos++
+
+	var list []any
+	for d.pos < len(d.data) && d.data[d.pos] != 'e' {
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+	}
+	if d.pos >= len(d.data) {
+		return nil, errors.New("unterminated list")
+	}
+	d.pos++
+
+	return list, nil
+}
+
+func (d *decoder) dict() (map[string]any, error) {
+	if d.depth++; d.depth > maxDepth {
+		return nil, errors.New("bencode nesting too deep")
+	}
+	defer func() { d.depth-- }()
+
+	d.pos++
+
+	dict := make(map[string]any)
+	for d.pos < len(d.data) && d.data[d.pos] != 'e' {
+		key, err := d.string()
+		if err != nil {
+			return nil, fmt.Errorf("invalid dictionary key: %w", err)
+		}
+
+		start := d.pos
+		v, err := d.value()
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of '%s': %w", key, err)
+		}
+
+		if key == "info" && d.depth == 1 {
+			d.info = d.data[start:d.pos]
+		}
+
+		dict[key] = v
+	}
+	if d.pos >= len(d.data) {
+		return nil, errors.New("unterminated dictionary")
+	}
+	d.pos++
+
+	return dict, nil
+}
+
+// encode appends the bencoding of v, which holds int64, string, []any and
+// map[string]any values, to buf.
+func encode(buf []byte, v any) ([]byte, error) {
+	switch v := v.(type) {
+	case int64:
+		buf = append(buf, 'i')
+		buf = strconv.AppendInt(buf, v, 10)
+		return append(buf, 'e'), nil
+	case string:
+		buf = strconv.AppendInt(buf, int64(len(v)), 10)
+		buf = append(buf, ':')
+		return append(buf, v...), nil
+	case []any:
+		buf = append(buf, 'l')
+		for _, item := range v {
+			var err error
+			if buf, err = encode(buf, item); err != nil {
+				return nil, err
+			}
+		}
+		return append(buf, 'e'), nil
+	case map[string]any:
+		// Dictionary keys are sorted as raw strings.
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		buf = append(buf, 'd')
+		for _, key := range keys {
+			var err error
+			if buf, err = encode(buf, key); err != nil {
+				return nil, err
+			}
+			if buf, err = encode(buf, v[key]); err != nil {
+				return nil, err
+			}
+		}
+		return append(buf, 'e'), nil
+	default:
+		return nil, fmt.Errorf("can't bencode value of type %T", v)
+	}
+}
@@ -0,0 +1,136 @@
+package torrent
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+const (
+	minPieceLength = 256 << 10
+	maxPieceLength = 16 << 20
+	// targetPieces is the number of pieces the piece length is chosen for.
+	targetPieces = 2000
+)
+
+// Create builds the metainfo of a single file torrent sharing the file at path.
+// The piece length is a power of two giving about targetPieces pieces.
+func Create(path string, trackers, webSeeds []string) (*Metainfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file info of %s: %w", path, err)
+	}
+
+	pieceLength := int64(minPieceLength)
+	for pieceLength < maxPieceLength && info.Size()/pieceLength > targetPieces {
+		pieceLength *= 2
+	}
+
+	m := &Metainfo{
+		Name:        filepath.Base(path),
+		PieceLength: pieceLength,
+		Files:       []File{{Path: []string{filepath.Base(path)}, Length: info.Size()}},
+		WebSeeds:    webSeeds,
+		Trackers:    trackers,
+	}
+
+	piece := make([]byte, pieceLength)
+	for {
+		n, err := io.ReadFull(file, piece)
+		if n > 0 {
+			m.Pieces = append(m.Pieces, sha1.Sum(piece[:n]))
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+	}
+
+	encoded, err := encode(nil, m.info())
+	if err != nil {
+		return nil, err
+	}
+	m.InfoHash = sha1.Sum(encoded)
+	m.Info = encoded
+
+	return m, nil
+}
+
+// info returns the info dictionary of a single file torrent.
+func (m *Metainfo) info() map[string]any {
+	pieces := make([]byte, 0, len(m.Pieces)*sha1.Size)
+	for _, hash := range m.Pieces {
+		pieces = append(pieces, hash[:]...)
+	}
+
+	return map[string]any{
+		"name":         m.Name,
+		"piece length": m.PieceLength,
+		"pieces":       string(pieces),
+		"length":       m.Files[0].Length,
+	}
+}
+
+// Marshal encodes the metainfo of a single file torrent as a .torrent file.
+func (m *Metainfo) Marshal() ([]byte, error) {
+	if m.MultiFile || len(m.Files) != 1 {
+		return nil, fmt.Errorf("only single file torrents can be written")
+	}
+
+	root := map[string]any{"info": m.info()}
+
+	if len(m.Trackers) > 0 {
+		root["announce"] = m.Trackers[0]
+
+		tiers := make([]any, len(m.Trackers))
+		for i, tracker := range m.Trackers {
+			tiers[i] = []any{tracker}
+		}
+		root["announce-list"] = tiers
+	}
+
+	if len(m.WebSeeds) > 0 {
+		seeds := make([]any, len(m.WebSeeds))
+		for i, seed := range m.WebSeeds {
+			seeds[i] = seed
+		}
+		root["url-list"] = seeds
+	}
+
+	return encode(nil, root)
+}
+
+// MagnetLink returns the magnet link of the torrent, with its trackers and web
+// seeds. xs, when set, is the location of the .torrent file.
+func (m *Metainfo) MagnetLink(xs string) string {
+	var size int64
+	for _, file := range m.Files {
+		size += file.Length
+	}
+
+	params := url.Values{
+		"dn": {m.Name},
+		"xl": {strconv.FormatInt(size, 10)},
+		"tr": m.Trackers,
+		"ws": m.WebSeeds,
+	}
+	if xs != "" {
+		params.Set("xs", xs)
+	}
+
+	// The info hash is kept out of the encoded parameters so that its colons stay readable.
+	return "magnet:?xt=urn:btih:" + hex.EncodeToString(m.InfoHash[:]) + "&" + params.Encode()
+}
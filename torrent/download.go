@@ -0,0 +1,120 @@
+package torrent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/anacrolix/log"
+	bittorrent "github.com/anacrolix/torrent"
+	"github.com/anacrolix/torrent/metainfo"
+	"github.com/anacrolix/torrent/storage"
+
+	"linea/aztec-srs-to-gnark/input"
+)
+
+// Files starts downloading the files of the torrent into dir with a BitTorrent
+// client, from the peers found with its trackers, the DHT and the peers of the
+// magnet link, and from its web seeds (BEP 19), and returns them as setup
+// files. When the metainfo only has the info hash of a magnet link, Files
+// blocks until the info dictionary is received from the peers. Every piece is
+// checked against its SHA1 hash before it is read; reading a file blocks until
+// the pieces it is at are verified, so the conversion can start on the first
+// files while the later ones are still being fetched. The client seeds the
+// downloaded pieces and is closed once ctx is done.
+func Files(ctx context.Context, m *Metainfo, dir string) ([]input.File, error) {
+	pieceStorage := storage.NewFileOpts(storage.NewFileClientOpts{
+		ClientBaseDir: dir,
+		// The completion of the pieces isn't kept in a database in dir, they are
+		// verified again when a download is resumed.
+		PieceCompletion: storage.NewMapPieceCompletion(),
+	})
+
+	cfg := bittorrent.NewDefaultClientConfig()
+	cfg.DataDir = dir
+	cfg.DefaultStorage = pieceStorage
+	// Any free port, so that concurrent conversions don't conflict.
+	cfg.ListenPort = 0
+	// Unreachable DHT bootstrap nodes and peers are reported as errors.
+	cfg.Logger = log.Default.WithFilterLevel(log.Critical)
+
+	client, err := bittorrent.NewClient(cfg)
+	if err != nil {
+		pieceStorage.Close()
+		return nil, fmt.Errorf("failed to start BitTorrent client: %w", err)
+	}
+	stop := func() {
+		client.Close()
+		pieceStorage.Close()
+	}
+	context.AfterFunc(ctx, stop)
+
+	spec := &bittorrent.TorrentSpec{
+		InfoHash:    metainfo.Hash(m.InfoHash),
+		InfoBytes:   m.Info,
+		DisplayName: m.Name,
+		Webseeds:    m.WebSeeds,
+		PeerAddrs:   m.Peers,
+	}
+	if len(m.Trackers) > 0 {
+		spec.Trackers = [][]string{m.Trackers}
+	}
+	t, _, err := client.AddTorrentSpec(spec)
+	if err != nil {
+		stop()
+		return nil, fmt.Errorf("failed to add torrent: %w", err)
+	}
+
+	if m.Info == nil {
+		fmt.Printf("Fetching the metainfo of %x from peers\n", m.InfoHash)
+	}
+	select {
+	case <-t.GotInfo():
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to get the metainfo of the torrent: %w", ctx.Err())
+	}
+
+	var files []input.File
+	for _, f := range t.Files() {
+		// Padding files (BEP 47) align the next file on a piece
+		if strings.Contains(f.FileInfo().Attr, "p") {
+			continue
+		}
+
+		// The paths of the torrent must stay in the download directory
+		rel := filepath.FromSlash(f.Path())
+		if !filepath.IsLocal(rel) {
+			stop()
+			return nil, fmt.Errorf("torrent file %s isn't a local path", f.Path())
+		}
+
+		files = append(files, input.New(filepath.Base(rel), f.Length(), func() (io.ReadCloser, error) {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return &reader{Reader: f.NewReader(), ctx: ctx}, nil
+		}))
+	}
+	if len(files) == 0 {
+		stop()
+		return nil, errors.New("torrent has no files")
+	}
+
+	t.DownloadAll()
+
+	return files, nil
+}
+
+// reader reads a file of the torrent, waiting for its pieces until the
+// download is canceled.
+type reader struct {
+	bittorrent.Reader
+	ctx context.Context
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	return r.Reader.ReadContext(r.ctx, p)
+}
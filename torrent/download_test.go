@@ -0,0 +1,116 @@
+package torrent_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	bittorrent "github.com/anacrolix/torrent"
+	"github.com/anacrolix/torrent/metainfo"
+	"github.com/anacrolix/torrent/storage"
+
+	"linea/aztec-srs-to-gnark/torrent"
+)
+
+// seed shares the file of the torrent from dir with a BitTorrent client
+// listening on localhost, and returns its port.
+func seed(t *testing.T, m *torrent.Metainfo, dir string) string {
+	t.Helper()
+	cfg := bittorrent.NewDefaultClientConfig()
+	cfg.DataDir = dir
+	cfg.DefaultStorage = storage.NewFileOpts(storage.NewFileClientOpts{
+		ClientBaseDir:   dir,
+		PieceCompletion: storage.NewMapPieceCompletion(),
+	})
+	cfg.ListenHost = func(string) string { return "127.0.0.1" }
+	cfg.ListenPort = 0
+	cfg.NoDHT = true
+	cfg.DisableIPv6 = true
+	cfg.Seed = true
+	client, err := bittorrent.NewClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	seeded, _, err := client.AddTorrentSpec(&bittorrent.TorrentSpec{InfoHash: metainfo.Hash(m.InfoHash), InfoBytes: m.Info})
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-seeded.Complete().On():
+	case <-time.After(time.Minute):
+		t.Fatal("the seeded file isn't complete")
+	}
+
+	return strconv.Itoa(client.LocalPort())
+}
+
+// TestFiles downloads a file from a peer, with a magnet link giving only its
+// info hash and the address of the peer, and from the web seed of a .torrent.
+func TestFiles(t *testing.T) {
+	seedDir := t.TempDir()
+	data := bytes.Repeat([]byte("0123456789abcdef"), 50000)
+	path := filepath.Join(seedDir, "transcript00.dat")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.FileServer(http.Dir(seedDir)))
+	defer server.Close()
+
+	m, err := torrent.Create(path, nil, []string{server.URL + "/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := m.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	torrentFile := filepath.Join(t.TempDir(), "transcript00.dat.torrent")
+	if err = os.WriteFile(torrentFile, encoded, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The magnet link has neither the location of the .torrent nor web seeds
+	peerOnly := *m
+	peerOnly.WebSeeds = nil
+	magnet := peerOnly.MagnetLink("") + "&x.pe=127.0.0.1:" + seed(t, m, seedDir)
+
+	for _, source := range []string{magnet, torrentFile} {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		loaded, err := torrent.Load(ctx, source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files, err := torrent.Files(ctx, loaded, t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != 1 || files[0].Name != "transcript00.dat" || files[0].Size != int64(len(data)) {
+			t.Fatalf("unexpected files %v from %s", files, source)
+		}
+
+		r, err := files[0].Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf("failed to download from %s: %v", source, err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Fatalf("the file downloaded from %s isn't the seeded one", source)
+		}
+	}
+}
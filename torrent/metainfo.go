@@ -0,0 +1,253 @@
+package torrent
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"linea/aztec-srs-to-gnark/fetch"
+)
+
+// File is a file of the torrent.
+type File struct {
+	// Path of the file inside the torrent.
+	Path []string
+	// Length of the file in bytes.
+	Length int64
+	// Offset of the file in the concatenation of all the files of the torrent.
+	Offset int64
+}
+
+// Metainfo is the content of a .torrent file.
+type Metainfo struct {
+	Name        string
+	PieceLength int64
+	Pieces      [][sha1.Size]byte
+	Files       []File
+	// MultiFile reports whether the files are in a directory named Name.
+	MultiFile bool
+	// WebSeeds are the HTTP sources of the files (BEP 19).
+	WebSeeds []string
+	// Trackers are the announce URLs of the torrent.
+	Trackers []string
+	// Peers are the addresses of the peers given by a magnet link (x.pe).
+	Peers    []string
+	InfoHash [sha1.Size]byte
+	// Info is the bencoded info dictionary, nil when it is fetched from the
+	// peers of a magnet link, in which case only InfoHash, Name, WebSeeds,
+	// Trackers and Peers are set.
+	Info []byte
+}
+
+// Load reads the metainfo from a path or an HTTP(S) URL of a .torrent file, or
+// from a magnet link. The .torrent file of a magnet link is read from the
+// location of its xs parameter; without one, the info dictionary is fetched
+// from the peers when the files are downloaded.
+func Load(ctx context.Context, source string) (*Metainfo, error) {
+	if !strings.HasPrefix(source, "magnet:") {
+		data, err := fetch.Read(ctx, source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read torrent file: %w", err)
+		}
+
+		return Parse(data)
+	}
+
+	magnet, err := url.Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid magnet link: %w", err)
+	}
+	params := magnet.Query()
+
+	infoHash, err := parseInfoHash(params.Get("xt"))
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Get("xs") == "" {
+		return &Metainfo{
+			Name:     params.Get("dn"),
+			WebSeeds: params["ws"],
+			Trackers: params["tr"],
+			Peers:    params["x.pe"],
+			InfoHash: infoHash,
+		}, nil
+	}
+
+	data, err := fetch.Read(ctx, params.Get("xs"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read torrent file: %w", err)
+	}
+
+	m, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.InfoHash != infoHash {
+		return nil, fmt.Errorf("info hash of %s doesn't match the magnet link", params.Get("xs"))
+	}
+
+	m.WebSeeds = append(m.WebSeeds, params["ws"]...)
+	m.Trackers = append(m.Trackers, params["tr"]...)
+	m.Peers = params["x.pe"]
+
+	return m, nil
+}
+
+// parseInfoHash decodes the hex or base32 info hash of a magnet link.
+func parseInfoHash(xt string) (hash [sha1.Size]byte, err error) {
+	encoded, ok := strings.CutPrefix(xt, "urn:btih:")
+	if !ok {
+		return hash, fmt.Errorf("magnet link has no BitTorrent info hash")
+	}
+
+	var decoded []byte
+	switch len(encoded) {
+	case 2 * sha1.Size:
+		decoded, err = hex.DecodeString(encoded)
+	case 32:
+		decoded, err = base32.StdEncoding.DecodeString(strings.ToUpper(encoded))
+	default:
+		err = fmt.Errorf("invalid length %d", len(encoded))
+	}
+	if err != nil {
+		return hash, fmt.Errorf("invalid info hash '%s': %w", encoded, err)
+	}
+
+	copy(hash[:], decoded)
+
+	return hash, nil
+}
+
+// Parse decodes a .torrent file.
+func Parse(data []byte) (*Metainfo, error) {
+	d := &decoder{data: data}
+
+	v, err := d.value()
+	if err != nil {
+		return nil, fmt.Errorf("invalid torrent file: %w", err)
+	}
+
+	root, ok := v.(map[string]any)
+	if !ok || d.info == nil {
+		return nil, errors.New("invalid torrent file: no info dictionary")
+	}
+	info := root["info"].(map[string]any)
+
+	m := &Metainfo{InfoHash: sha1.Sum(d.info), Info: d.info}
+
+	m.Name, _ = info["name"].(string)
+	if m.Name == "" || !validPathElement(m.Name) {
+		return nil, fmt.Errorf("invalid torrent name '%s'", m.Name)
+	}
+
+	m.PieceLength, _ = info["piece length"].(int64)
+	if m.PieceLength <= 0 {
+		return nil, fmt.Errorf("invalid piece length %d", m.PieceLength)
+	}
+
+	pieces, _ := info["pieces"].(string)
+	if len(pieces)%sha1.Size != 0 {
+		return nil, errors.New("invalid pieces hashes")
+	}
+	m.Pieces = make([][sha1.Size]byte, len(pieces)/sha1.Size)
+	for i := range m.Pieces {
+		copy(m.Pieces[i][:], pieces[i*sha1.Size:])
+	}
+
+	if length, ok := info["length"].(int64); ok {
+		m.Files = []File{{Path: []string{m.Name}, Length: length}}
+	} else {
+		if m.Files, err = parseFiles(info["files"]); err != nil {
+			return nil, err
+		}
+		m.MultiFile = true
+	}
+
+	var total int64
+	for i := range m.Files {
+		if m.Files[i].Length < 0 {
+			return nil, fmt.Errorf("invalid length of file %s", path.Join(m.Files[i].Path...))
+		}
+		m.Files[i].Offset = total
+		total += m.Files[i].Length
+	}
+
+	if int64(len(m.Pieces)) != (total+m.PieceLength-1)/m.PieceLength {
+		return nil, fmt.Errorf("%d pieces hashes for %d bytes", len(m.Pieces), total)
+	}
+
+	switch seeds := root["url-list"].(type) {
+	case string:
+		m.WebSeeds = []string{seeds}
+	case []any:
+		for _, seed := range seeds {
+			if s, ok := seed.(string); ok {
+				m.WebSeeds = append(m.WebSeeds, s)
+			}
+		}
+	}
+
+	if announce, ok := root["announce"].(string); ok {
+		m.Trackers = []string{announce}
+	}
+	if tiers, ok := root["announce-list"].([]any); ok {
+		m.Trackers = nil
+		for _, tier := range tiers {
+			trackers, _ := tier.([]any)
+			for _, tracker := range trackers {
+				if s, ok := tracker.(string); ok {
+					m.Trackers = append(m.Trackers, s)
+				}
+			}
+		}
+	}
+
+	return m, nil
+}
+
+func parseFiles(v any) ([]File, error) {
+	list, ok := v.([]any)
+	if !ok || len(list) == 0 {
+		return nil, errors.New("torrent has neither length nor files")
+	}
+
+	files := make([]File, len(list))
+	for i, item := range list {
+		entry, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("invalid file entry %d", i)
+		}
+
+		files[i].Length, ok = entry["length"].(int64)
+		if !ok {
+			return nil, fmt.Errorf("file entry %d has no length", i)
+		}
+
+		elements, _ := entry["path"].([]any)
+		for _, element := range elements {
+			s, ok := element.(string)
+			if !ok || !validPathElement(s) {
+				return nil, fmt.Errorf("invalid path of file entry %d", i)
+			}
+			files[i].Path = append(files[i].Path, s)
+		}
+		if len(files[i].Path) == 0 {
+			return nil, fmt.Errorf("file entry %d has no path", i)
+		}
+	}
+
+	return files, nil
+}
+
+// validPathElement rejects path elements escaping the download directory.
+func validPathElement(s string) bool {
+	return s != "" && s != "." && s != ".." && !strings.ContainsAny(s, `/\`)
+}
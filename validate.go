@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/manifest"
+)
+
+// setupLayouts describe the setup files the importers expect, printed when a
+// directory doesn't hold the ones of the chosen ceremony.
+var setupLayouts = map[ProtocolName]string{
+	AztecProtocol: `transcripts of the Aztec Ignition ceremony, e.g. transcript00.dat to transcript19.dat, of any name:
+	[28-byte big endian header: transcript number, number of transcripts, number of G1 points of the
+	 ceremony, G1 and G2 points of the transcript, ...] [G1 points] [G2 points] [64-byte BLAKE2b checksum]`,
+	AleoProtocol: `setup files of snarkVM, of any name:
+	the G2 setup file, e.g. beta-h.usrs, of 192 bytes: [τG2]
+	the G1 setup files, e.g. powers-of-beta-15.usrs and shifted-powers-of-beta-16.usrs:
+	[8-byte number of points] [powers of τ in G1, 96 bytes each]`,
+	CeloProtocol: `chunk files of the Plumo ceremony or the one of -profile, named [round].[chunk].[contribution].[contributor],
+	for the 256 chunks: [64-byte hash] [τ powers in G1] [τ powers in G2, α and β powers in G1, chunks 0-127 only] [βG2],
+	the challenge files with uncompressed points, the response files with compressed points and the public key`,
+}
+
+// validateSetup checks that the setup files are the ones of the ceremony from
+// their names, sizes and headers, before the conversion starts: some of them
+// must hold points. Only local files are checked, the downloaded and the
+// streamed ones aren't available yet. Under the lenient validation nothing is
+// checked, the importer skips the files it can't read.
+func validateSetup(protocol ProtocolName, curve CurveName, files []input.File, validation config.Validation) error {
+	describe, ok := supportedManifests[protocol][curve]
+	if !ok || validation == config.ValidationLenient {
+		return nil
+	}
+	for _, file := range files {
+		if file.Path == "" {
+			return nil
+		}
+	}
+	if len(files) == 0 {
+		return errors.New("the setup directory is empty")
+	}
+
+	points, err := setupPoints(files, describe)
+	if err != nil {
+		return fmt.Errorf("the files of the directory aren't %s setup files: %w", protocol, err)
+	}
+	if points == 0 {
+		return fmt.Errorf("none of the %d files of the directory holds points of the %s setup", len(files), protocol)
+	}
+
+	return nil
+}
+
+// printSetupLayout prints the setup files the importer of the ceremony
+// expects, and the other ceremonies the files are setup files of.
+func printSetupLayout(protocol ProtocolName, files []input.File) {
+	fmt.Printf("The %s setup is read from the %s\n", protocol, setupLayouts[protocol])
+
+	var others []string
+	for other, curves := range supportedManifests {
+		for curve, describe := range curves {
+			if points, err := setupPoints(files, describe); other != protocol && err == nil && points > 0 {
+				others = append(others, fmt.Sprintf("%s %s", other, curve))
+			}
+		}
+	}
+	sort.Strings(others)
+
+	for _, other := range others {
+		fmt.Printf("The files look like the setup files of %s\n", other)
+	}
+}
+
+// setupPoints returns the number of points of the setup files the describer
+// recognizes.
+func setupPoints(files []input.File, describe manifest.Describer) (int, error) {
+	descs, err := manifest.Detect(files, describe)
+	if err != nil {
+		return 0, err
+	}
+
+	points := 0
+	for _, desc := range descs {
+		if desc.Kind != manifest.KindIgnored {
+			points += desc.Points
+		}
+	}
+
+	return points, nil
+}
@@ -0,0 +1,70 @@
+package verify
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377"
+	blsKzg "github.com/consensys/gnark-crypto/ecc/bls12-377/kzg"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381"
+	bls381Kzg "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761"
+	bwKzg "github.com/consensys/gnark-crypto/ecc/bw6-761/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+)
+
+// Adjacent checks with a pairing that the first G1 point of next is the first
+// G1 point of prev multiplied by τ, i.e. that they are consecutive powers of
+// τ, with the verifying key of prev: e(next, G2) = e(prev, τ·G2).
+func Adjacent(prev, next kzg.SRS) error {
+	var (
+		ok  bool
+		err error
+	)
+	switch p := prev.(type) {
+	case *bnKzg.SRS:
+		n, isSame := next.(*bnKzg.SRS)
+		if !isSame || len(p.Pk.G1) == 0 || len(n.Pk.G1) == 0 {
+			return errors.New("expected two bn254 SRS with G1 points")
+		}
+		var neg bn254.G1Affine
+		neg.Neg(&p.Pk.G1[0])
+		ok, err = bn254.PairingCheck([]bn254.G1Affine{n.Pk.G1[0], neg}, []bn254.G2Affine{p.Vk.G2[0], p.Vk.G2[1]})
+	case *blsKzg.SRS:
+		n, isSame := next.(*blsKzg.SRS)
+		if !isSame || len(p.Pk.G1) == 0 || len(n.Pk.G1) == 0 {
+			return errors.New("expected two bls12-377 SRS with G1 points")
+		}
+		var neg bls12377.G1Affine
+		neg.Neg(&p.Pk.G1[0])
+		ok, err = bls12377.PairingCheck([]bls12377.G1Affine{n.Pk.G1[0], neg}, []bls12377.G2Affine{p.Vk.G2[0], p.Vk.G2[1]})
+	case *bls381Kzg.SRS:
+		n, isSame := next.(*bls381Kzg.SRS)
+		if !isSame || len(p.Pk.G1) == 0 || len(n.Pk.G1) == 0 {
+			return errors.New("expected two bls12-381 SRS with G1 points")
+		}
+		var neg bls12381.G1Affine
+		neg.Neg(&p.Pk.G1[0])
+		ok, err = bls12381.PairingCheck([]bls12381.G1Affine{n.Pk.G1[0], neg}, []bls12381.G2Affine{p.Vk.G2[0], p.Vk.G2[1]})
+	case *bwKzg.SRS:
+		n, isSame := next.(*bwKzg.SRS)
+		if !isSame || len(p.Pk.G1) == 0 || len(n.Pk.G1) == 0 {
+			return errors.New("expected two bw6-761 SRS with G1 points")
+		}
+		var neg bw6761.G1Affine
+		neg.Neg(&p.Pk.G1[0])
+		ok, err = bw6761.PairingCheck([]bw6761.G1Affine{n.Pk.G1[0], neg}, []bw6761.G2Affine{p.Vk.G2[0], p.Vk.G2[1]})
+	default:
+		return fmt.Errorf("unsupported SRS type %T", prev)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to compute pairing: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("%w: the G1 points aren't consecutive powers of tau", ErrFailed)
+	}
+
+	return nil
+}
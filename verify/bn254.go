@@ -0,0 +1,135 @@
+package verify
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+)
+
+// Bn254Checker verifies bn254 G1 points while they are being parsed and
+// accumulates them into S = Σ ρ^i·G1[i] for a random ρ, which lets Finish
+// check that the points are consecutive powers of tau with a single pairing.
+type Bn254Checker struct {
+	rho fr.Element
+
+	mu  sync.Mutex
+	sum bn254.G1Jac
+	n   int
+}
+
+// NewBn254Checker creates a checker with a fresh random ρ.
+func NewBn254Checker() (*Bn254Checker, error) {
+	c := new(Bn254Checker)
+	if _, err := c.rho.SetRandom(); err != nil {
+		return nil, fmt.Errorf("failed to sample random challenge: %w", err)
+	}
+
+	return c, nil
+}
+
+// Batch checks that the points, starting at index from of the SRS, are on
+// the curve and in the prime order subgroup, and accumulates them. Batches may
+// be passed in any order and from several goroutines.
+func (c *Bn254Checker) Batch(from int, points []bn254.G1Affine) error {
+	for i := range points {
+		if !points[i].IsInSubGroup() {
+			return &PointError{Index: from + i}
+		}
+	}
+
+	scalars := make([]fr.Element, len(points))
+	if len(scalars) > 0 {
+		scalars[0].Exp(c.rho, big.NewInt(int64(from)))
+		for i := 1; i < len(scalars); i++ {
+			scalars[i].Mul(&scalars[i-1], &c.rho)
+		}
+	}
+
+	var partial bn254.G1Jac
+	if _, err := partial.MultiExp(points, scalars, ecc.MultiExpConfig{NbTasks: 1}); err != nil {
+		return fmt.Errorf("failed to accumulate G1 points: %w", err)
+	}
+
+	c.mu.Lock()
+	c.sum.AddAssign(&partial)
+	c.n += len(points)
+	c.mu.Unlock()
+
+	return nil
+}
+
+// Finish checks the verifying key and that every G1[i+1] is tau times G1[i],
+// tau being the discrete logarithm of G2[1]. All the points of the SRS must
+// have been passed to Batch.
+func (c *Bn254Checker) Finish(srs *bnKzg.SRS) error {
+	return c.FinishFrom(srs, 0)
+}
+
+// FinishFrom is Finish for the G1 points of the SRS from index from, the only
+// ones passed to Batch, e.g. the points appended to an SRS already verified
+// with the last one of it.
+func (c *Bn254Checker) FinishFrom(srs *bnKzg.SRS, from int) error {
+	n := len(srs.Pk.G1)
+	if c.n != n-from {
+		return fmt.Errorf("checked %d G1 points, but the SRS has %d from %d", c.n, n-from, from)
+	}
+	if n-from < 2 {
+		return errors.New("SRS has less than 2 G1 points")
+	}
+
+	_, _, gen1Aff, gen2Aff := bn254.Generators()
+	if (from == 0 && !srs.Pk.G1[0].Equal(&gen1Aff)) || !srs.Vk.G1.Equal(&gen1Aff) {
+		return errors.New("first G1 point is not the generator")
+	}
+	if !srs.Vk.G2[0].Equal(&gen2Aff) {
+		return errors.New("first G2 point is not the generator")
+	}
+	if !srs.Vk.G2[1].IsInSubGroup() || srs.Vk.G2[1].IsInfinity() {
+		return errors.New("tau*G2 is not a valid G2 point")
+	}
+
+	// With A = Σ ρ^i·G1[i+1] = (S - ρ^from·G1[from])/ρ and
+	// B = Σ ρ^i·G1[i] = S - ρ^(n-1)·G1[n-1] the powers are consistent iff
+	// e(A, G2) = e(B, tau*G2), i.e. e(S - ρ^from·G1[from], G2) = e(ρ·B, tau*G2).
+	var first, last bn254.G1Jac
+	first.FromAffine(&srs.Pk.G1[from])
+	last.FromAffine(&srs.Pk.G1[n-1])
+
+	var rhoPow big.Int
+	var rhoPowN fr.Element
+	rhoPowN.Exp(c.rho, big.NewInt(int64(n-1))).BigInt(&rhoPow)
+	last.ScalarMultiplication(&last, &rhoPow)
+	if from > 0 {
+		rhoPowN.Exp(c.rho, big.NewInt(int64(from))).BigInt(&rhoPow)
+		first.ScalarMultiplication(&first, &rhoPow)
+	}
+
+	var a, b bn254.G1Jac
+	a.Set(&c.sum).SubAssign(&first)
+	b.Set(&c.sum).SubAssign(&last)
+
+	var rho big.Int
+	c.rho.BigInt(&rho)
+	b.ScalarMultiplication(&b, &rho)
+	b.Neg(&b)
+
+	var aAff, bAff bn254.G1Affine
+	aAff.FromJacobian(&a)
+	bAff.FromJacobian(&b)
+
+	ok, err := bn254.PairingCheck([]bn254.G1Affine{aAff, bAff}, []bn254.G2Affine{srs.Vk.G2[0], srs.Vk.G2[1]})
+	if err != nil {
+		return fmt.Errorf("failed to compute pairing: %w", err)
+	}
+	if !ok {
+		return errors.New("G1 points are not consecutive powers of tau*G2")
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package verify
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	blsKzg "github.com/consensys/gnark-crypto/ecc/bls12-377/kzg"
+	bls381Kzg "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	bwKzg "github.com/consensys/gnark-crypto/ecc/bw6-761/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+)
+
+// ConstraintSystem is the part of a gnark constraint system
+// (constraint.ConstraintSystem) the SRS of its PLONK setup depends on, so the
+// compatibility is checked without a dependency on gnark.
+type ConstraintSystem interface {
+	CurveID() ecc.ID
+	GetNbConstraints() int
+	GetNbPublicVariables() int
+}
+
+// RequiredPoints returns the number of G1 points of the SRS the PLONK setup of
+// gnark needs for a circuit: the size of its FFT domain, the smallest power of
+// 2 holding the constraints and a placeholder constraint per public variable,
+// plus 3 for the openings of the blinded polynomials. The SRS in Lagrange
+// basis has the size of the domain.
+func RequiredPoints(nbConstraints, nbPublicVariables int) int {
+	return int(ecc.NextPowerOfTwo(uint64(nbConstraints+nbPublicVariables))) + 3
+}
+
+// IncompatibleError reports an SRS which can't be used for the PLONK setup of
+// a circuit.
+type IncompatibleError struct {
+	Curve, CircuitCurve ecc.ID
+	// Points is the number of G1 points of the SRS.
+	Points int
+	// Required is the number of G1 points the circuit needs, see RequiredPoints.
+	Required int
+}
+
+func (e *IncompatibleError) Error() string {
+	if e.Curve != e.CircuitCurve {
+		return fmt.Sprintf("the SRS is on %s, the circuit on %s", e.Curve, e.CircuitCurve)
+	}
+
+	return fmt.Sprintf("the SRS has %d G1 points, the circuit needs %d (a domain of %d, plus 3)", e.Points, e.Required, e.Required-3)
+}
+
+// Compatibility checks that an SRS of the curve with nbPoints G1 points can be
+// used for the PLONK setup of the circuit, returning an *IncompatibleError
+// otherwise.
+func Compatibility(curve ecc.ID, nbPoints int, ccs ConstraintSystem) error {
+	required := RequiredPoints(ccs.GetNbConstraints(), ccs.GetNbPublicVariables())
+	if curve != ccs.CurveID() || nbPoints < required {
+		return &IncompatibleError{Curve: curve, CircuitCurve: ccs.CurveID(), Points: nbPoints, Required: required}
+	}
+
+	return nil
+}
+
+// VerifyCompatibility checks that the SRS is on the curve of the circuit and
+// large enough for its PLONK setup, so a prover fails before the setup with
+// the exact size required instead of deep inside gnark.
+func VerifyCompatibility(srs kzg.SRS, ccs ConstraintSystem) error {
+	switch s := srs.(type) {
+	case *bnKzg.SRS:
+		return Compatibility(ecc.BN254, len(s.Pk.G1), ccs)
+	case *blsKzg.SRS:
+		return Compatibility(ecc.BLS12_377, len(s.Pk.G1), ccs)
+	case *bls381Kzg.SRS:
+		return Compatibility(ecc.BLS12_381, len(s.Pk.G1), ccs)
+	case *bwKzg.SRS:
+		return Compatibility(ecc.BW6_761, len(s.Pk.G1), ccs)
+	default:
+		return fmt.Errorf("unsupported SRS type %T", srs)
+	}
+}
+
+// Circuit is a ConstraintSystem of given sizes, for checking an SRS against a
+// circuit that isn't compiled at hand.
+type Circuit struct {
+	Curve             ecc.ID
+	NbConstraints     int
+	NbPublicVariables int
+}
+
+func (c Circuit) CurveID() ecc.ID           { return c.Curve }
+func (c Circuit) GetNbConstraints() int     { return c.NbConstraints }
+func (c Circuit) GetNbPublicVariables() int { return c.NbPublicVariables }
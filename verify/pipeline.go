@@ -0,0 +1,40 @@
+package verify
+
+import "linea/aztec-srs-to-gnark/parallel"
+
+// Pipeline runs the batch checks of a checker on a pool of goroutines while
+// the importer keeps parsing, so the points are verified in the same pass.
+// A nil pipeline checks nothing, which lets the importers call it unconditionally.
+type Pipeline[P any] struct {
+	group *parallel.Group
+	check func(from int, points []P) error
+}
+
+// NewPipeline creates a pipeline running check on up to workers goroutines.
+func NewPipeline[P any](check func(from int, points []P) error, workers int) *Pipeline[P] {
+	return &Pipeline[P]{
+		group: parallel.NewGroup(workers),
+		check: check,
+	}
+}
+
+// Check schedules the check of the points starting at index from of the SRS.
+// The points must not be modified afterwards.
+func (p *Pipeline[P]) Check(from int, points []P) {
+	if p == nil {
+		return
+	}
+
+	p.group.Go(func() error {
+		return p.check(from, points)
+	})
+}
+
+// Wait blocks until all the scheduled checks finish and returns the first failure.
+func (p *Pipeline[P]) Wait() error {
+	if p == nil {
+		return nil
+	}
+
+	return p.group.Wait()
+}
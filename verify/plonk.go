@@ -0,0 +1,137 @@
+package verify
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	blsKzg "github.com/consensys/gnark-crypto/ecc/bls12-377/kzg"
+	bls381Kzg "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	bwKzg "github.com/consensys/gnark-crypto/ecc/bw6-761/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/logger"
+
+	"linea/aztec-srs-to-gnark/journal"
+	"linea/aztec-srs-to-gnark/lagrange"
+)
+
+// PlonkTimings are the durations of the steps of PlonkRoundTrip.
+type PlonkTimings struct {
+	Setup  time.Duration
+	Prove  time.Duration
+	Verify time.Duration
+}
+
+// plonkCircuit is the sample circuit of PlonkRoundTrip, proving the knowledge
+// of x such that x³ + x + 5 = y for the public y.
+type plonkCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *plonkCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Add(api.Mul(c.X, c.X, c.X), c.X, 5), c.Y)
+	return nil
+}
+
+// compilePlonkCircuit compiles the sample circuit for PLONK on the curve.
+func compilePlonkCircuit(curve ecc.ID) (constraint.ConstraintSystem, error) {
+	// gnark logs the compilation, the round trip reports its own steps
+	logger.Disable()
+
+	return frontend.Compile(curve.ScalarField(), scs.NewBuilder, &plonkCircuit{})
+}
+
+// PlonkPoints returns the number of G1 points PlonkRoundTrip needs on the
+// curve: the size of the domain of its circuit plus 3, see RequiredPoints.
+func PlonkPoints(curve ecc.ID) (int, error) {
+	ccs, err := compilePlonkCircuit(curve)
+	if err != nil {
+		return 0, err
+	}
+
+	return RequiredPoints(ccs.GetNbConstraints(), ccs.GetNbPublicVariables()), nil
+}
+
+// PlonkRoundTrip compiles a sample circuit with gnark and runs its PLONK setup,
+// prover and verifier with the first points of the SRS. The proof of
+// x³ + x + 5 = 35 must be accepted, and rejected for another public value. The
+// SRS must have at least PlonkPoints points.
+func PlonkRoundTrip(srs kzg.SRS) (PlonkTimings, error) {
+	var (
+		t     PlonkTimings
+		curve ecc.ID
+		n     int
+	)
+	switch s := srs.(type) {
+	case *bnKzg.SRS:
+		curve, n = ecc.BN254, len(s.Pk.G1)
+	case *blsKzg.SRS:
+		curve, n = ecc.BLS12_377, len(s.Pk.G1)
+	case *bls381Kzg.SRS:
+		curve, n = ecc.BLS12_381, len(s.Pk.G1)
+	case *bwKzg.SRS:
+		curve, n = ecc.BW6_761, len(s.Pk.G1)
+	default:
+		return t, fmt.Errorf("unsupported SRS type %T", srs)
+	}
+
+	ccs, err := compilePlonkCircuit(curve)
+	if err != nil {
+		return t, fmt.Errorf("failed to compile the circuit: %w", err)
+	}
+	required := RequiredPoints(ccs.GetNbConstraints(), ccs.GetNbPublicVariables())
+	if n < required {
+		return t, fmt.Errorf("the SRS has %d points, the PLONK circuit needs %d", n, required)
+	}
+
+	start := time.Now()
+	if srs, err = journal.Slice(srs, 0, required); err != nil {
+		return t, err
+	}
+	srsLagrange, err := lagrange.ToLagrange(srs, required-3)
+	if err != nil {
+		return t, err
+	}
+	pk, vk, err := plonk.Setup(ccs, srs, srsLagrange)
+	if err != nil {
+		return t, fmt.Errorf("failed to run the setup: %w", err)
+	}
+	t.Setup = time.Since(start)
+
+	witness, err := frontend.NewWitness(&plonkCircuit{X: 3, Y: 35}, curve.ScalarField())
+	if err != nil {
+		return t, err
+	}
+	start = time.Now()
+	proof, err := plonk.Prove(ccs, pk, witness)
+	if err != nil {
+		return t, fmt.Errorf("failed to prove: %w", err)
+	}
+	t.Prove = time.Since(start)
+
+	public, err := witness.Public()
+	if err != nil {
+		return t, err
+	}
+	start = time.Now()
+	if err = plonk.Verify(proof, vk, public); err != nil {
+		return t, fmt.Errorf("%w: the proof is rejected: %w", ErrFailed, err)
+	}
+	t.Verify = time.Since(start)
+
+	wrong, err := frontend.NewWitness(&plonkCircuit{Y: 36}, curve.ScalarField(), frontend.PublicOnly())
+	if err != nil {
+		return t, err
+	}
+	if plonk.Verify(proof, vk, wrong) == nil {
+		return t, fmt.Errorf("%w: the proof is accepted for a wrong public input", ErrFailed)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,69 @@
+package verify
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	blsKzg "github.com/consensys/gnark-crypto/ecc/bls12-377/kzg"
+	bls381Kzg "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	bwKzg "github.com/consensys/gnark-crypto/ecc/bw6-761/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+)
+
+func TestPlonkRoundTrip(t *testing.T) {
+	tau := big.NewInt(42)
+	for _, tc := range []struct {
+		curve  ecc.ID
+		newSRS func(uint64, *big.Int) (kzg.SRS, error)
+	}{
+		{ecc.BN254, func(n uint64, tau *big.Int) (kzg.SRS, error) { return bnKzg.NewSRS(n, tau) }},
+		{ecc.BLS12_377, func(n uint64, tau *big.Int) (kzg.SRS, error) { return blsKzg.NewSRS(n, tau) }},
+		{ecc.BLS12_381, func(n uint64, tau *big.Int) (kzg.SRS, error) { return bls381Kzg.NewSRS(n, tau) }},
+		{ecc.BW6_761, func(n uint64, tau *big.Int) (kzg.SRS, error) { return bwKzg.NewSRS(n, tau) }},
+	} {
+		t.Run(tc.curve.String(), func(t *testing.T) {
+			points, err := PlonkPoints(tc.curve)
+			if err != nil {
+				t.Fatal(err)
+			}
+			srs, err := tc.newSRS(uint64(points), tau)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err = PlonkRoundTrip(srs); err != nil {
+				t.Fatal(err)
+			}
+
+			small, err := tc.newSRS(uint64(points-1), tau)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err = PlonkRoundTrip(small); err == nil {
+				t.Fatalf("round trip with %d points succeeded", points-1)
+			}
+		})
+	}
+}
+
+func TestPlonkRejectsWrongSRS(t *testing.T) {
+	points, err := PlonkPoints(ecc.BN254)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srs, err := bnKzg.NewSRS(uint64(points), big.NewInt(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A G1 point of another tau breaks the openings.
+	other, err := bnKzg.NewSRS(uint64(points), big.NewInt(43))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srs.Pk.G1[points-1] = other.Pk.G1[points-1]
+
+	if _, err = PlonkRoundTrip(srs); err == nil {
+		t.Fatal("round trip with a corrupted SRS succeeded")
+	}
+}
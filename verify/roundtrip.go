@@ -0,0 +1,280 @@
+package verify
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	blsFr "github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	blsKzg "github.com/consensys/gnark-crypto/ecc/bls12-377/kzg"
+	bls381Fr "github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	bls381Kzg "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	bnFr "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	bwFr "github.com/consensys/gnark-crypto/ecc/bw6-761/fr"
+	bwKzg "github.com/consensys/gnark-crypto/ecc/bw6-761/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+)
+
+// RoundTripTimings are the durations of the steps of RoundTrip.
+type RoundTripTimings struct {
+	Commit      time.Duration
+	Open        time.Duration
+	Verify      time.Duration
+	BatchOpen   time.Duration
+	BatchVerify time.Duration
+}
+
+// RoundTrip uses the SRS the way PLONK provers and verifiers do: it commits to
+// two random polynomials of the degree, opens the first one at a random point
+// and both at once at another one, and verifies the openings with the
+// verifying key, including its precomputed lines. A wrong opening is also
+// checked to be rejected. The SRS must have more points than the degree.
+func RoundTrip(srs kzg.SRS, degree int) (RoundTripTimings, error) {
+	var (
+		t   RoundTripTimings
+		err error
+	)
+	switch s := srs.(type) {
+	case *bnKzg.SRS:
+		if err = checkDegree(len(s.Pk.G1), degree); err != nil {
+			return t, err
+		}
+		p, q := make([]bnFr.Element, degree+1), make([]bnFr.Element, degree+1)
+		var points [2]bnFr.Element
+		if err = randomize(p, q, points[:]); err != nil {
+			return t, err
+		}
+		point, batchPoint := points[0], points[1]
+		var one bnFr.Element
+		one.SetOne()
+
+		var commitments [2]bnKzg.Digest
+		start := time.Now()
+		if commitments[0], err = bnKzg.Commit(p, s.Pk); err == nil {
+			commitments[1], err = bnKzg.Commit(q, s.Pk)
+		}
+		if err != nil {
+			return t, fmt.Errorf("failed to commit: %w", err)
+		}
+		t.Commit = time.Since(start)
+
+		start = time.Now()
+		proof, err := bnKzg.Open(p, point, s.Pk)
+		if err != nil {
+			return t, fmt.Errorf("failed to open: %w", err)
+		}
+		t.Open = time.Since(start)
+
+		start = time.Now()
+		if err = bnKzg.Verify(&commitments[0], &proof, point, s.Vk); err != nil {
+			return t, fmt.Errorf("%w: the opening is rejected: %w", ErrFailed, err)
+		}
+		t.Verify = time.Since(start)
+
+		proof.ClaimedValue.Add(&proof.ClaimedValue, &one)
+		if bnKzg.Verify(&commitments[0], &proof, point, s.Vk) == nil {
+			return t, fmt.Errorf("%w: a wrong opening is accepted", ErrFailed)
+		}
+
+		start = time.Now()
+		batchProof, err := bnKzg.BatchOpenSinglePoint([][]bnFr.Element{p, q}, commitments[:], batchPoint, sha256.New(), s.Pk)
+		if err != nil {
+			return t, fmt.Errorf("failed to open in batch: %w", err)
+		}
+		t.BatchOpen = time.Since(start)
+
+		start = time.Now()
+		if err = bnKzg.BatchVerifySinglePoint(commitments[:], &batchProof, batchPoint, sha256.New(), s.Vk); err != nil {
+			return t, fmt.Errorf("%w: the batch opening is rejected: %w", ErrFailed, err)
+		}
+		t.BatchVerify = time.Since(start)
+	case *blsKzg.SRS:
+		if err = checkDegree(len(s.Pk.G1), degree); err != nil {
+			return t, err
+		}
+		p, q := make([]blsFr.Element, degree+1), make([]blsFr.Element, degree+1)
+		var points [2]blsFr.Element
+		if err = randomize(p, q, points[:]); err != nil {
+			return t, err
+		}
+		point, batchPoint := points[0], points[1]
+		var one blsFr.Element
+		one.SetOne()
+
+		var commitments [2]blsKzg.Digest
+		start := time.Now()
+		if commitments[0], err = blsKzg.Commit(p, s.Pk); err == nil {
+			commitments[1], err = blsKzg.Commit(q, s.Pk)
+		}
+		if err != nil {
+			return t, fmt.Errorf("failed to commit: %w", err)
+		}
+		t.Commit = time.Since(start)
+
+		start = time.Now()
+		proof, err := blsKzg.Open(p, point, s.Pk)
+		if err != nil {
+			return t, fmt.Errorf("failed to open: %w", err)
+		}
+		t.Open = time.Since(start)
+
+		start = time.Now()
+		if err = blsKzg.Verify(&commitments[0], &proof, point, s.Vk); err != nil {
+			return t, fmt.Errorf("%w: the opening is rejected: %w", ErrFailed, err)
+		}
+		t.Verify = time.Since(start)
+
+		proof.ClaimedValue.Add(&proof.ClaimedValue, &one)
+		if blsKzg.Verify(&commitments[0], &proof, point, s.Vk) == nil {
+			return t, fmt.Errorf("%w: a wrong opening is accepted", ErrFailed)
+		}
+
+		start = time.Now()
+		batchProof, err := blsKzg.BatchOpenSinglePoint([][]blsFr.Element{p, q}, commitments[:], batchPoint, sha256.New(), s.Pk)
+		if err != nil {
+			return t, fmt.Errorf("failed to open in batch: %w", err)
+		}
+		t.BatchOpen = time.Since(start)
+
+		start = time.Now()
+		if err = blsKzg.BatchVerifySinglePoint(commitments[:], &batchProof, batchPoint, sha256.New(), s.Vk); err != nil {
+			return t, fmt.Errorf("%w: the batch opening is rejected: %w", ErrFailed, err)
+		}
+		t.BatchVerify = time.Since(start)
+	case *bls381Kzg.SRS:
+		if err = checkDegree(len(s.Pk.G1), degree); err != nil {
+			return t, err
+		}
+		p, q := make([]bls381Fr.Element, degree+1), make([]bls381Fr.Element, degree+1)
+		var points [2]bls381Fr.Element
+		if err = randomize(p, q, points[:]); err != nil {
+			return t, err
+		}
+		point, batchPoint := points[0], points[1]
+		var one bls381Fr.Element
+		one.SetOne()
+
+		var commitments [2]bls381Kzg.Digest
+		start := time.Now()
+		if commitments[0], err = bls381Kzg.Commit(p, s.Pk); err == nil {
+			commitments[1], err = bls381Kzg.Commit(q, s.Pk)
+		}
+		if err != nil {
+			return t, fmt.Errorf("failed to commit: %w", err)
+		}
+		t.Commit = time.Since(start)
+
+		start = time.Now()
+		proof, err := bls381Kzg.Open(p, point, s.Pk)
+		if err != nil {
+			return t, fmt.Errorf("failed to open: %w", err)
+		}
+		t.Open = time.Since(start)
+
+		start = time.Now()
+		if err = bls381Kzg.Verify(&commitments[0], &proof, point, s.Vk); err != nil {
+			return t, fmt.Errorf("%w: the opening is rejected: %w", ErrFailed, err)
+		}
+		t.Verify = time.Since(start)
+
+		proof.ClaimedValue.Add(&proof.ClaimedValue, &one)
+		if bls381Kzg.Verify(&commitments[0], &proof, point, s.Vk) == nil {
+			return t, fmt.Errorf("%w: a wrong opening is accepted", ErrFailed)
+		}
+
+		start = time.Now()
+		batchProof, err := bls381Kzg.BatchOpenSinglePoint([][]bls381Fr.Element{p, q}, commitments[:], batchPoint, sha256.New(), s.Pk)
+		if err != nil {
+			return t, fmt.Errorf("failed to open in batch: %w", err)
+		}
+		t.BatchOpen = time.Since(start)
+
+		start = time.Now()
+		if err = bls381Kzg.BatchVerifySinglePoint(commitments[:], &batchProof, batchPoint, sha256.New(), s.Vk); err != nil {
+			return t, fmt.Errorf("%w: the batch opening is rejected: %w", ErrFailed, err)
+		}
+		t.BatchVerify = time.Since(start)
+	case *bwKzg.SRS:
+		if err = checkDegree(len(s.Pk.G1), degree); err != nil {
+			return t, err
+		}
+		p, q := make([]bwFr.Element, degree+1), make([]bwFr.Element, degree+1)
+		var points [2]bwFr.Element
+		if err = randomize(p, q, points[:]); err != nil {
+			return t, err
+		}
+		point, batchPoint := points[0], points[1]
+		var one bwFr.Element
+		one.SetOne()
+
+		var commitments [2]bwKzg.Digest
+		start := time.Now()
+		if commitments[0], err = bwKzg.Commit(p, s.Pk); err == nil {
+			commitments[1], err = bwKzg.Commit(q, s.Pk)
+		}
+		if err != nil {
+			return t, fmt.Errorf("failed to commit: %w", err)
+		}
+		t.Commit = time.Since(start)
+
+		start = time.Now()
+		proof, err := bwKzg.Open(p, point, s.Pk)
+		if err != nil {
+			return t, fmt.Errorf("failed to open: %w", err)
+		}
+		t.Open = time.Since(start)
+
+		start = time.Now()
+		if err = bwKzg.Verify(&commitments[0], &proof, point, s.Vk); err != nil {
+			return t, fmt.Errorf("%w: the opening is rejected: %w", ErrFailed, err)
+		}
+		t.Verify = time.Since(start)
+
+		proof.ClaimedValue.Add(&proof.ClaimedValue, &one)
+		if bwKzg.Verify(&commitments[0], &proof, point, s.Vk) == nil {
+			return t, fmt.Errorf("%w: a wrong opening is accepted", ErrFailed)
+		}
+
+		start = time.Now()
+		batchProof, err := bwKzg.BatchOpenSinglePoint([][]bwFr.Element{p, q}, commitments[:], batchPoint, sha256.New(), s.Pk)
+		if err != nil {
+			return t, fmt.Errorf("failed to open in batch: %w", err)
+		}
+		t.BatchOpen = time.Since(start)
+
+		start = time.Now()
+		if err = bwKzg.BatchVerifySinglePoint(commitments[:], &batchProof, batchPoint, sha256.New(), s.Vk); err != nil {
+			return t, fmt.Errorf("%w: the batch opening is rejected: %w", ErrFailed, err)
+		}
+		t.BatchVerify = time.Since(start)
+	default:
+		return t, fmt.Errorf("unsupported SRS type %T", srs)
+	}
+
+	return t, nil
+}
+
+// randomize sets the elements of the slices to random values.
+func randomize[E any, P interface {
+	*E
+	SetRandom() (*E, error)
+}](slices ...[]E) error {
+	for _, slice := range slices {
+		for i := range slice {
+			if _, err := P(&slice[i]).SetRandom(); err != nil {
+				return fmt.Errorf("failed to sample random element: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func checkDegree(n, degree int) error {
+	if degree < 0 || degree >= n {
+		return fmt.Errorf("the SRS has %d points, polynomials of degree %d can't be committed to", n, degree)
+	}
+
+	return nil
+}
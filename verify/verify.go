@@ -0,0 +1,143 @@
+package verify
+
+import (
+	"errors"
+	"fmt"
+
+	blsKzg "github.com/consensys/gnark-crypto/ecc/bls12-377/kzg"
+	bls381Kzg "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	bwKzg "github.com/consensys/gnark-crypto/ecc/bw6-761/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/parallel"
+)
+
+// ErrFailed wraps the errors of an SRS failing the verification.
+var ErrFailed = errors.New("SRS verification failed")
+
+// PointError reports a G1 point which is not on the curve or not in the
+// prime order subgroup.
+type PointError struct {
+	// Index is the index of the point in the SRS.
+	Index int
+}
+
+func (e *PointError) Error() string {
+	return fmt.Sprintf("G1 point %d is not on the curve or not in the subgroup", e.Index)
+}
+
+// Recover applies the validation policy of opts to err, a failure of the
+// verification of srs. Under the lenient validation, while it is a
+// *PointError, the SRS ends before the point, recorded as skipped, and what is
+// left is verified again. Otherwise err is returned.
+func Recover(srs kzg.SRS, opts config.Options, err error) error {
+	for {
+		var pointErr *PointError
+		// The generator and τ·G1 are needed for an SRS
+		if !errors.As(err, &pointErr) || pointErr.Index < 2 {
+			return err
+		}
+
+		// The points from the one failed are dropped
+		var n int
+		switch s := srs.(type) {
+		case *bnKzg.SRS:
+			n = len(s.Pk.G1)
+		case *blsKzg.SRS:
+			n = len(s.Pk.G1)
+		case *bls381Kzg.SRS:
+			n = len(s.Pk.G1)
+		case *bwKzg.SRS:
+			n = len(s.Pk.G1)
+		default:
+			return fmt.Errorf("unsupported SRS type %T", srs)
+		}
+		if err = opts.Reject(config.Skip{Offset: &pointErr.Index, Points: n - pointErr.Index}, err); err != nil {
+			return err
+		}
+
+		switch s := srs.(type) {
+		case *bnKzg.SRS:
+			s.Pk.G1 = s.Pk.G1[:pointErr.Index]
+		case *blsKzg.SRS:
+			s.Pk.G1 = s.Pk.G1[:pointErr.Index]
+		case *bls381Kzg.SRS:
+			s.Pk.G1 = s.Pk.G1[:pointErr.Index]
+		case *bwKzg.SRS:
+			s.Pk.G1 = s.Pk.G1[:pointErr.Index]
+		}
+		opts.Warn("the SRS ends at G1 point %d", pointErr.Index-1)
+
+		if err = SRS(srs, opts); err == nil {
+			return nil
+		}
+	}
+}
+
+// SRS verifies an already constructed SRS in a separate pass over its points,
+// split between opts.Workers goroutines. Importers fuse the same checks into
+// parsing instead, see the checkers.
+func SRS(srs kzg.SRS, opts config.Options) error {
+	return SRSFrom(srs, 0, opts)
+}
+
+// SRSFrom verifies the G1 points of the SRS from index from like SRS, e.g. the
+// points appended to an SRS already verified with the last one of it.
+func SRSFrom(srs kzg.SRS, from int, opts config.Options) error {
+	switch s := srs.(type) {
+	case *bnKzg.SRS:
+		c, err := NewBn254Checker()
+		if err != nil {
+			return err
+		}
+		if err = batches(from, len(s.Pk.G1), opts, func(from, to int) error { return c.Batch(from, s.Pk.G1[from:to]) }); err != nil {
+			return err
+		}
+		return c.FinishFrom(s, from)
+	case *blsKzg.SRS:
+		c, err := NewBls12377Checker()
+		if err != nil {
+			return err
+		}
+		if err = batches(from, len(s.Pk.G1), opts, func(from, to int) error { return c.Batch(from, s.Pk.G1[from:to]) }); err != nil {
+			return err
+		}
+		return c.FinishFrom(s, from)
+	case *bls381Kzg.SRS:
+		c, err := NewBls12381Checker()
+		if err != nil {
+			return err
+		}
+		if err = batches(from, len(s.Pk.G1), opts, func(from, to int) error { return c.Batch(from, s.Pk.G1[from:to]) }); err != nil {
+			return err
+		}
+		return c.FinishFrom(s, from)
+	case *bwKzg.SRS:
+		c, err := NewBw6761Checker()
+		if err != nil {
+			return err
+		}
+		if err = batches(from, len(s.Pk.G1), opts, func(from, to int) error { return c.Batch(from, s.Pk.G1[from:to]) }); err != nil {
+			return err
+		}
+		return c.FinishFrom(s, from)
+	default:
+		return fmt.Errorf("unsupported SRS type %T", srs)
+	}
+}
+
+// batches splits [start, n) into batches of opts.BatchSize processed in parallel.
+func batches(start, n int, opts config.Options, fn func(from, to int) error) error {
+	batchSize := opts.BatchSize
+	if batchSize < 1 {
+		batchSize = config.DefaultBatchSize
+	}
+
+	nBatches := (n - start + batchSize - 1) / batchSize
+
+	return parallel.Run(nBatches, opts.Workers, func(i int) error {
+		return fn(start+i*batchSize, min(start+(i+1)*batchSize, n))
+	})
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/mpc"
+	"linea/aztec-srs-to-gnark/srsio"
+)
+
+// verifyContribution checks that an SRS file is a valid contribution on top of
+// another one, given the proof of the contribution.
+func verifyContribution(args []string) error {
+	var opts config.Options
+
+	flags := flag.NewFlagSet("verify-contribution", flag.ExitOnError)
+	proofPath := flags.String("proof", "", "contribution proof file (default: <next SRS file>.proof.json)")
+	flags.IntVar(&opts.Workers, "workers", 0, "number of CPU workers (0 - auto)")
+	flags.IntVar(&opts.BatchSize, "batch-size", 0, "number of points processed by a worker at once (0 - auto)")
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s verify-contribution [flags] <previous SRS file> <next SRS file>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() < 2 {
+		flags.Usage()
+		return errUsage
+	}
+	if *proofPath == "" {
+		*proofPath = flags.Arg(1) + ".proof.json"
+	}
+
+	proof, err := mpc.ReadProof(*proofPath)
+	if err != nil {
+		return err
+	}
+
+	prevReader, err := srsio.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer prevReader.Close()
+
+	nextReader, err := srsio.Open(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+	defer nextReader.Close()
+
+	if prevReader.Curve != nextReader.Curve || prevReader.NbPoints != nextReader.NbPoints {
+		return fmt.Errorf("the previous SRS has %d %s points, the next one %d %s points",
+			prevReader.NbPoints, curveNames[prevReader.Curve], nextReader.NbPoints, curveNames[nextReader.Curve])
+	}
+
+	opts.IOParallelism = 1
+	opts = config.Tune(opts, "")
+
+	// Only τ·G1 and τ·G2 of the previous SRS are needed.
+	prev, err := prevReader.Range(0, min(2, prevReader.NbPoints))
+	if err != nil {
+		return err
+	}
+
+	next, err := nextReader.Range(0, nextReader.NbPoints)
+	if err != nil {
+		return err
+	}
+
+	if err = mpc.VerifyContribution(prev, next, proof, opts); err != nil {
+		return fmt.Errorf("%s isn't a valid contribution on top of %s: %w", flags.Arg(1), flags.Arg(0), err)
+	}
+
+	fmt.Printf("%s is a valid contribution on top of %s\n", flags.Arg(1), flags.Arg(0))
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/srsio"
+)
+
+// versionMigrate rewrites an SRS file written by an older gnark-crypto version,
+// whose verifying key has no precomputed lines, in the current layout.
+func versionMigrate(args []string) error {
+	var opts config.Options
+
+	flags := flag.NewFlagSet("version-migrate", flag.ExitOnError)
+	out := flags.String("o", "", "output SRS file (required)")
+	format := flags.String("format", "", fmt.Sprintf("output format, one of %v (default: the format of the input)", srsio.Formats))
+	flags.IntVar(&opts.Workers, "workers", 0, "number of CPU workers (0 - auto)")
+	flags.IntVar(&opts.BatchSize, "batch-size", 0, "number of points processed by a worker at once (0 - auto)")
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s version-migrate [flags] <input SRS file>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	args = parseInterspersed(flags, args)
+
+	if len(args) < 1 || *out == "" {
+		flags.Usage()
+		return errUsage
+	}
+
+	r, err := srsio.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	outputFormat := r.Format
+	if *format != "" {
+		if outputFormat, err = srsio.ParseFormat(*format); err != nil {
+			return err
+		}
+	}
+
+	if !r.Legacy {
+		fmt.Printf("%s is already in the current layout (%s, %s, %d points), nothing to migrate\n", args[0], r.Format, curveNames[r.Curve], r.NbPoints)
+		return nil
+	}
+
+	fmt.Printf("Migrating %s (%s, %s, %d points) written by an older gnark-crypto version: its verifying key is completed with the precomputed lines\n",
+		args[0], r.Format, curveNames[r.Curve], r.NbPoints)
+
+	opts.IOParallelism = 1
+	opts = config.Tune(opts, "")
+
+	if err = writeRange(r, *out, 0, r.NbPoints, outputFormat, opts); err != nil {
+		return err
+	}
+
+	return nil
+}
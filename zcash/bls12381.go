@@ -0,0 +1,454 @@
+// Package zcash reads the challenge and response files of powersoftau, the
+// phase 1 of the Sapling MPC of Zcash on bls12-381, and of the ceremonies run
+// with its software, and writes the responses contributing to the challenges.
+package zcash
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381"
+	bls381Kzg "github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/audit"
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/digest"
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/offheap"
+	"linea/aztec-srs-to-gnark/parallel"
+	"linea/aztec-srs-to-gnark/phase1"
+	"linea/aztec-srs-to-gnark/verify"
+)
+
+const (
+	// HashAlgorithm is the algorithm of the hash starting every file, of the
+	// previous response in a challenge and of the challenge in a response.
+	HashAlgorithm = digest.BLAKE2b512
+	// HashSize is the size of the hash starting every file.
+	HashSize = 64
+	// PublicKeySize is the size of the public key ending a response file: τ,
+	// α and β in G1 twice and in G2 once, uncompressed.
+	PublicKeySize = 6*bls12381.SizeOfG1AffineUncompressed + 3*bls12381.SizeOfG2AffineUncompressed
+	// SaplingPowers is the number of powers of τ in G2 of the Sapling
+	// ceremony, which has twice as many but one in G1.
+	SaplingPowers = 1 << 21
+	// g1ReadBatch is the number of G1 points read from a file at once.
+	g1ReadBatch = 1 << 12
+)
+
+// layout is the layout of a challenge or a response file of the powersoftau
+// software, whose N is the number of powers of τ in G2:
+//
+//	challenge: [hash of the previous response] [points, uncompressed]
+//	response:  [hash of the challenge] [points, compressed] [public key]
+//
+// The points are the 2N-1 powers of τ in G1, the N ones in G2, the N α and β
+// powers in G1 and βG2, encoded as in ZCash.
+type layout struct {
+	// response is set for the response files
+	response bool
+	// powers is the number of powers of τ in G2
+	powers int
+}
+
+func (l layout) String() string {
+	if l.response {
+		return "response"
+	}
+	return "challenge"
+}
+
+// g1PointSize is the size of a G1 point of the file.
+func (l layout) g1PointSize() int64 {
+	if l.response {
+		return bls12381.SizeOfG1AffineCompressed
+	}
+	return bls12381.SizeOfG1AffineUncompressed
+}
+
+// g2PointSize is the size of a G2 point of the file.
+func (l layout) g2PointSize() int64 {
+	if l.response {
+		return bls12381.SizeOfG2AffineCompressed
+	}
+	return bls12381.SizeOfG2AffineUncompressed
+}
+
+// g1PowersN returns the number of powers of τ in G1 of the file.
+func (l layout) g1PowersN() int {
+	return 2*l.powers - 1
+}
+
+// size returns the size of the file.
+func (l layout) size() int64 {
+	n := int64(l.powers)
+	size := HashSize + (2*n-1+2*n)*l.g1PointSize() + (n+1)*l.g2PointSize()
+	if l.response {
+		size += PublicKeySize
+	}
+
+	return size
+}
+
+// layoutOf tells a challenge file from a response file by its size, which
+// must be the one of either layout of at least 2 powers of τ in G2. A file
+// whose size is unknown, e.g. compressed, is taken for a challenge file of the
+// Sapling ceremony.
+func layoutOf(size int64) (layout, error) {
+	if size == input.UnknownSize {
+		return layout{powers: SaplingPowers}, nil
+	}
+
+	for _, l := range []layout{{response: false}, {response: true}} {
+		// The size grows linearly with the number of powers
+		base := l.size()
+		l.powers = 1
+		perPower := l.size() - base
+		if size > base && (size-base)%perPower == 0 && (size-base)/perPower >= 2 {
+			l.powers = int((size - base) / perPower)
+			return l, nil
+		}
+	}
+
+	return layout{}, fmt.Errorf("size of %d bytes is neither the one of a challenge nor of a response file", size)
+}
+
+// findFile returns the file the SRS is read from and its layout: the only
+// file, or the only one of the size of a challenge or a response file. The
+// other files are returned with the reason they aren't read.
+func findFile(files []input.File) (input.File, layout, map[string]error, error) {
+	others := make(map[string]error)
+	var (
+		found   []input.File
+		layouts []layout
+	)
+	for _, file := range files {
+		l, err := layoutOf(file.Size)
+		if err != nil {
+			others[file.Name] = err
+			continue
+		}
+		found, layouts = append(found, file), append(layouts, l)
+	}
+
+	switch {
+	case len(files) == 1 && len(found) == 0:
+		return input.File{}, layout{}, nil, fmt.Errorf("%s: %w", files[0].Name, others[files[0].Name])
+	case len(found) == 0:
+		return input.File{}, layout{}, nil, errors.New("no challenge or response file found")
+	case len(found) > 1:
+		return input.File{}, layout{}, nil, fmt.Errorf("several challenge or response files found: %s and %s", found[0].Name, found[1].Name)
+	}
+
+	return found[0], layouts[0], others, nil
+}
+
+// decodePoint decodes the point encoded as in ZCash, compressed in a
+// response file, into p without the subgroup checks.
+func decodePoint(data []byte, p any) error {
+	dec := bls12381.NewDecoder(bytes.NewReader(data), bls12381.NoSubgroupChecks())
+	if err := dec.Decode(p); err != nil {
+		return err
+	}
+	if dec.BytesRead() != int64(len(data)) {
+		return fmt.Errorf("encoded in %d bytes, not %d", dec.BytesRead(), len(data))
+	}
+
+	return nil
+}
+
+// decodeG1Points decodes the G1 points of data on workers goroutines. It
+// returns the index of the first point that can't be decoded or isn't on the
+// curve, -1 when there is none.
+func decodeG1Points(data []byte, points []bls12381.G1Affine, pointSize, workers int) int {
+	perWorker := (len(points) + workers - 1) / workers
+	malformedFrom := make([]int, workers)
+	_ = parallel.Run(workers, workers, func(w int) error {
+		malformedFrom[w] = -1
+		for i := w * perWorker; i < min((w+1)*perWorker, len(points)); i++ {
+			p := &points[i]
+			if decodePoint(data[i*pointSize:(i+1)*pointSize], p) != nil || p.IsInfinity() || !p.IsOnCurve() {
+				malformedFrom[w] = i
+				return nil
+			}
+		}
+		return nil
+	})
+
+	for _, from := range malformedFrom {
+		if from >= 0 {
+			return from
+		}
+	}
+
+	return -1
+}
+
+// readG1Points reads the G1 points of the layout in batches, decoded on
+// workers goroutines, and passes every batch to the verification checks. It
+// returns the number of points read before an error.
+func readG1Points(r io.Reader, l layout, points []bls12381.G1Affine, checks *verify.Pipeline[bls12381.G1Affine], workers int, progress *config.Progress) (int, error) {
+	pointSize := int(l.g1PointSize())
+	buf := make([]byte, g1ReadBatch*pointSize)
+
+	for from := 0; from < len(points); from += g1ReadBatch {
+		batch := points[from:min(from+g1ReadBatch, len(points))]
+		data := buf[:len(batch)*pointSize]
+
+		if _, err := io.ReadFull(r, data); err != nil {
+			return from, fmt.Errorf("failed to read G1 points %d-%d: %w", from, from+len(batch)-1, err)
+		}
+		if i := decodeG1Points(data, batch, pointSize, workers); i >= 0 {
+			return from + i, fmt.Errorf("malformed G1 point %d", from+i)
+		}
+
+		checks.Check(from, batch)
+		progress.Add(len(batch))
+	}
+
+	return len(points), nil
+}
+
+// readG2Points reads the G2 points of the layout, checking that they are on
+// the curve.
+func readG2Points(r io.Reader, l layout, points []bls12381.G2Affine) error {
+	data := make([]byte, l.g2PointSize())
+	for i := range points {
+		if _, err := io.ReadFull(r, data); err != nil {
+			return fmt.Errorf("failed to read G2 point %d: %w", i, err)
+		}
+		p := &points[i]
+		if err := decodePoint(data, p); err != nil {
+			return fmt.Errorf("invalid G2 point %d: %w", i, err)
+		}
+		if !p.IsOnCurve() {
+			return fmt.Errorf("G2 point %d is not on the curve", i)
+		}
+	}
+
+	return nil
+}
+
+// readG2Powers reads the first powers of τ in G2 into tauG2, at least the
+// generator and τG2, which are set in the verifying key.
+func readG2Powers(r io.Reader, l layout, tauG2 []bls12381.G2Affine, srs *bls381Kzg.SRS) error {
+	if err := readG2Points(r, l, tauG2); err != nil {
+		return fmt.Errorf("failed to read the powers of τ in G2: %w", err)
+	}
+	copy(srs.Vk.G2[:], tauG2)
+	if _, _, _, g2Gen := bls12381.Generators(); !srs.Vk.G2[0].Equal(&g2Gen) {
+		return errors.New("the first G2 power isn't the generator")
+	}
+	srs.Vk.Lines[0] = bls12381.PrecomputeLines(srs.Vk.G2[0])
+	srs.Vk.Lines[1] = bls12381.PrecomputeLines(srs.Vk.G2[1])
+
+	fmt.Printf("> a^1*G2: %s %s\n", srs.Vk.G2[1].X.String(), srs.Vk.G2[1].Y.String())
+
+	return nil
+}
+
+// readPhase1 reads the Groth16 phase 1 points following the powers of τ in G1
+// of the file: the powers of τ in G2, setting the verifying key, the α and β
+// powers in G1 and βG2. They are only checked to be on the curve.
+func readPhase1(r io.Reader, l layout, srs *bls381Kzg.SRS, opts config.Options) (phase1.Points[bls12381.G1Affine, bls12381.G2Affine], error) {
+	p := phase1.Points[bls12381.G1Affine, bls12381.G2Affine]{TauG1: srs.Pk.G1}
+	br := bufio.NewReaderSize(r, g1ReadBatch*int(l.g1PointSize()))
+
+	var err error
+	if p.TauG2, err = offheap.Make[bls12381.G2Affine](l.powers, opts); err != nil {
+		return p, err
+	}
+	if err = readG2Powers(br, l, p.TauG2, srs); err != nil {
+		return p, err
+	}
+	for _, section := range []struct {
+		name   string
+		points *[]bls12381.G1Affine
+	}{{"α", &p.AlphaTauG1}, {"β", &p.BetaTauG1}} {
+		if *section.points, err = offheap.Make[bls12381.G1Affine](l.powers, opts); err != nil {
+			return p, err
+		}
+		if _, err = readG1Points(br, l, *section.points, nil, opts.Workers, nil); err != nil {
+			return p, fmt.Errorf("failed to read the %s powers: %w", section.name, err)
+		}
+	}
+	var betaG2 [1]bls12381.G2Affine
+	if err = readG2Points(br, l, betaG2[:]); err != nil {
+		return p, fmt.Errorf("failed to read βG2: %w", err)
+	}
+	p.BetaG2 = betaG2[0]
+
+	return p, nil
+}
+
+// TranslateBls12381SRS reads a challenge or a response file of powersoftau,
+// told apart by their size, and constructs a KZG SRS from its powers of τ:
+// all the G1 ones, or with opts.Degree the first 2^opts.Degree ones, only
+// them being read. The compressed points of a response file are decompressed
+// on opts.Workers goroutines, without the subgroup checks, which opts.Verify
+// runs with the check of the powers. The other files and the G1 points that
+// can't be read are rejected with opts.Reject: under the lenient validation
+// the SRS ends before the first point that can't be read. With opts.Phase1
+// the whole file is read and a *phase1.SRS is returned, with the mpcsetup
+// Phase1 of gnark of its Groth16 phase 1.
+func TranslateBls12381SRS(files []input.File, opts config.Options) (kzg.SRS, int, error) {
+	file, l, others, err := findFile(files)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, other := range files {
+		if reason, ok := others[other.Name]; ok {
+			opts.Ignore(other.Name, reason.Error())
+			opts.Audit.Record(audit.Event{Kind: audit.KindSetupFile, File: other.Name, Decision: "ignored: not a challenge or response file"})
+		}
+	}
+
+	n := l.g1PowersN()
+	if opts.Phase1 && opts.Degree > 0 {
+		return nil, 0, errors.New("the Groth16 phase 1 needs all the powers of τ in G1, they can't be selected by degree")
+	}
+	if opts.Degree > 0 {
+		if 1<<opts.Degree > n {
+			return nil, 0, fmt.Errorf("%s file %s holds %d points, less than 2^%d", l, file.Name, n, opts.Degree)
+		}
+		n = 1 << opts.Degree
+	}
+
+	fmt.Printf("Processing %s file %s\n", l, file.Name)
+	fmt.Printf("> %d G1 and %d G2 powers\n", l.g1PowersN(), l.powers)
+	f, err := file.Open()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open %s: %w", file.Name, err)
+	}
+	defer f.Close()
+
+	// The hash at the beginning of the file is only recorded
+	hash := make([]byte, HashSize)
+	if _, err = io.ReadFull(f, hash); err != nil {
+		return nil, 0, fmt.Errorf("failed to read the hash of %s: %w", file.Name, err)
+	}
+
+	srs := new(bls381Kzg.SRS)
+	if srs.Pk.G1, err = offheap.Make[bls12381.G1Affine](n, opts); err != nil {
+		return nil, 0, err
+	}
+	opts.Progress.SetTotal(n)
+
+	var (
+		checker *verify.Bls12381Checker
+		checks  *verify.Pipeline[bls12381.G1Affine]
+	)
+	if opts.Verify {
+		if checker, err = verify.NewBls12381Checker(); err != nil {
+			return nil, 0, err
+		}
+		checks = verify.NewPipeline(checker.Batch, opts.Workers)
+	}
+
+	// The G1 points after the ones read are skipped to reach the G2 ones
+	tauG1 := &io.LimitedReader{R: f, N: int64(l.g1PowersN()) * l.g1PointSize()}
+	read, err := readG1Points(tauG1, l, srs.Pk.G1, checks, opts.Workers, opts.Progress)
+	failedFrom := -1
+	if err != nil {
+		// The points before the first one that can't be read are kept
+		if read < 2 || opts.Phase1 {
+			checks.Wait()
+			return nil, 0, fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+		if err = opts.Reject(config.Skip{File: file.Name, Offset: audit.Index(read), Points: n - read}, err); err != nil {
+			checks.Wait()
+			return nil, 0, err
+		}
+		opts.Warn("G1 points from %d can't be read: the SRS ends at G1 point %d", read, read-1)
+		failedFrom = read
+	}
+	var points phase1.Points[bls12381.G1Affine, bls12381.G2Affine]
+	if err = input.Skip(f, tauG1.N); err == nil {
+		if opts.Phase1 {
+			points, err = readPhase1(f, l, srs, opts)
+		} else {
+			err = readG2Powers(f, l, srs.Vk.G2[:], srs)
+		}
+	}
+	if err != nil {
+		checks.Wait()
+		return nil, 0, fmt.Errorf("failed to read %s: %w", file.Name, err)
+	}
+
+	if failedFrom >= 0 {
+		srs.Pk.G1 = srs.Pk.G1[:failedFrom]
+	}
+	opts.Audit.Record(audit.Event{Kind: audit.KindSetupFile, File: file.Name, Points: len(srs.Pk.G1), Hash: hex.EncodeToString(hash), HashAlgorithm: string(HashAlgorithm), Decision: "used"})
+	srs.Vk.G1 = srs.Pk.G1[0]
+
+	if _, _, g1Gen, _ := bls12381.Generators(); !srs.Vk.G1.Equal(&g1Gen) {
+		checks.Wait()
+		return nil, 0, fmt.Errorf("the first G1 power of %s isn't the generator", file.Name)
+	}
+
+	fmt.Printf("> a^1*G1: %s %s\n", srs.Pk.G1[1].X.String(), srs.Pk.G1[1].Y.String())
+
+	if checker != nil {
+		err = checks.Wait()
+		switch {
+		case failedFrom >= 0:
+			// The points were checked up to the failed one, so the fused checks
+			// don't apply
+			err = verify.SRS(srs, opts)
+		case err == nil:
+			err = checker.Finish(srs)
+		}
+		// Under the lenient validation the SRS ends before a point failing
+		if err = verify.Recover(srs, opts, err); err != nil {
+			return nil, 0, fmt.Errorf("%w: %w", verify.ErrFailed, err)
+		}
+		fmt.Println("SRS verified: all G1 points are in the subgroup and are consecutive powers of tau")
+	}
+
+	if opts.Phase1 {
+		// Under the lenient validation the verification may end the SRS
+		points.TauG1 = srs.Pk.G1
+		p, err := phase1.Bls12381(points)
+		if err != nil {
+			return nil, 0, err
+		}
+		fmt.Printf("Read the Groth16 phase 1 of %d powers of τ in G2\n", l.powers)
+		return &phase1.SRS{SRS: srs, Phase1: p}, len(srs.Pk.G1), nil
+	}
+
+	return srs, len(srs.Pk.G1), nil
+}
+
+// ExtractTauG2 returns an SRS with only the verifying key, read from the
+// first powers of τ in G2 of the challenge or response file. The G1 points
+// are skipped over without being parsed.
+func ExtractTauG2(files []input.File) (kzg.SRS, error) {
+	file, l, _, err := findFile(files)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", file.Name, err)
+	}
+	defer f.Close()
+
+	// [hash] [tau_g1 points] [tau_g2 points], the first of which is the generator
+	if err = input.Skip(f, HashSize+int64(l.g1PowersN())*l.g1PointSize()); err != nil {
+		return nil, fmt.Errorf("failed to skip the G1 points of %s: %w", file.Name, err)
+	}
+
+	srs := new(bls381Kzg.SRS)
+	_, _, srs.Vk.G1, _ = bls12381.Generators()
+	if err = readG2Powers(f, l, srs.Vk.G2[:], srs); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+	}
+
+	return srs, nil
+}
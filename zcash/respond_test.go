@@ -0,0 +1,140 @@
+package zcash_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381"
+	bls381Mpc "github.com/consensys/gnark/backend/groth16/bls12-381/mpcsetup"
+
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/phase1"
+	"linea/aztec-srs-to-gnark/powersoftau"
+	"linea/aztec-srs-to-gnark/testsetup"
+	"linea/aztec-srs-to-gnark/verify"
+	"linea/aztec-srs-to-gnark/zcash"
+)
+
+// readPhase1 converts the challenge or response file of the directory with
+// its Groth16 phase 1.
+func readPhase1(t *testing.T, dir string) *bls381Mpc.Phase1 {
+	t.Helper()
+	files, err := input.Dir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := config.Tune(config.Options{IOParallelism: 1, Phase1: true}, "")
+	srs, _, err := zcash.TranslateBls12381SRS(files, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ext := srs.(*phase1.SRS)
+	if err = verify.SRS(ext.SRS, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	return ext.Phase1.(*bls381Mpc.Phase1)
+}
+
+// sameRatio tells whether b1 / a1 in G1 is b2 / a2 in G2.
+func sameRatio(t *testing.T, a1, b1 bls12381.G1Affine, a2, b2 bls12381.G2Affine) bool {
+	t.Helper()
+	var negB1 bls12381.G1Affine
+	negB1.Neg(&b1)
+	ok, err := bls12381.PairingCheck([]bls12381.G1Affine{a1, negB1}, []bls12381.G2Affine{b2, a2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ok
+}
+
+// TestRespond contributes to a challenge and checks the response as the
+// powersoftau coordinator does: the response starts with the hash of the
+// challenge, its public key proves the knowledge of the secrets, which
+// multiply the points of the challenge, and its points are powers of τ.
+func TestRespond(t *testing.T) {
+	challengeDir, responseDir := t.TempDir(), t.TempDir()
+	if _, err := testsetup.Zcash(challengeDir, 4, false, testsetup.DefaultSecrets()); err != nil {
+		t.Fatal(err)
+	}
+	challengePath := filepath.Join(challengeDir, "challenge")
+	challenge, err := os.Open(challengePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer challenge.Close()
+
+	var response bytes.Buffer
+	opts := config.Tune(config.Options{IOParallelism: 1}, "")
+	challengeHash, err := zcash.Respond(challenge, &response, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(responseDir, "response"), response.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	sum, _, err := zcash.HashAlgorithm.File(challengePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := response.Bytes()[:zcash.HashSize]; !bytes.Equal(got, challengeHash) || sum != hex.EncodeToString(challengeHash) {
+		t.Fatalf("the response starts with %x, not with the hash %s of the challenge", got, sum)
+	}
+
+	before, after := readPhase1(t, challengeDir), readPhase1(t, responseDir)
+
+	// τ, α and β in G1 twice, then in G2
+	key := bytes.NewReader(response.Bytes()[response.Len()-zcash.PublicKeySize:])
+	dec := bls12381.NewDecoder(key)
+	var g1 [6]bls12381.G1Affine
+	var g2 [3]bls12381.G2Affine
+	for i := range g1 {
+		if err = dec.Decode(&g1[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := range g2 {
+		if err = dec.Decode(&g2[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var g2S [3]bls12381.G2Affine
+	for i, personalization := range []byte{powersoftau.TauPersonalization, powersoftau.AlphaPersonalization, powersoftau.BetaPersonalization} {
+		rawS, rawSX := g1[2*i].RawBytes(), g1[2*i+1].RawBytes()
+		g2S[i] = zcash.HashToG2(powersoftau.ProofHash(personalization, challengeHash, rawS[:], rawSX[:]))
+		if !g2S[i].IsInSubGroup() {
+			t.Fatalf("the point hashed to G2 of the secret %d isn't in G2", i)
+		}
+		if !sameRatio(t, g1[2*i], g1[2*i+1], g2S[i], g2[i]) {
+			t.Fatalf("the public key doesn't prove the knowledge of the secret %d", i)
+		}
+	}
+
+	params0, params1 := before.Parameters, after.Parameters
+	for _, c := range []struct {
+		name          string
+		before, after bls12381.G1Affine
+		secret        int
+	}{
+		{"τG1", params0.G1.Tau[1], params1.G1.Tau[1], 0},
+		{"α", params0.G1.AlphaTau[0], params1.G1.AlphaTau[0], 1},
+		{"β", params0.G1.BetaTau[0], params1.G1.BetaTau[0], 2},
+	} {
+		if !sameRatio(t, c.before, c.after, g2S[c.secret], g2[c.secret]) {
+			t.Fatalf("%s isn't multiplied by the secret of the public key", c.name)
+		}
+	}
+	if !sameRatio(t, g1[4], g1[5], params0.G2.Beta, params1.G2.Beta) {
+		t.Fatal("βG2 isn't multiplied by the secret β of the public key")
+	}
+	if !sameRatio(t, g1[0], g1[1], params0.G2.Tau[1], params1.G2.Tau[1]) {
+		t.Fatal("τG2 isn't multiplied by the secret τ of the public key")
+	}
+}
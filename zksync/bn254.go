@@ -0,0 +1,320 @@
+// Package zksync reads the universal setup of Matter Labs, the CRS of zkSync
+// on bn254 published as setup_2^<n>.key files in the format of bellman_ce.
+package zksync
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	bnKzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+
+	"linea/aztec-srs-to-gnark/audit"
+	"linea/aztec-srs-to-gnark/config"
+	"linea/aztec-srs-to-gnark/input"
+	"linea/aztec-srs-to-gnark/offheap"
+	"linea/aztec-srs-to-gnark/verify"
+)
+
+const (
+	// Ext is the extension of the key files.
+	Ext = ".key"
+	// G1PointSize is the size of an uncompressed G1 point: x and y, big endian.
+	G1PointSize = 2 * fp.Bytes
+	// G2PointSize is the size of an uncompressed G2 point: x.c1, x.c0, y.c1
+	// and y.c0, big endian.
+	G2PointSize = 4 * fp.Bytes
+	// countSize is the size of the big endian number of points starting the
+	// G1 and the G2 sections.
+	countSize = 8
+	// maxPoints bounds the number of points a file can announce.
+	maxPoints = 1 << 40
+	// g1ReadBatch is the number of G1 points read at once.
+	g1ReadBatch = 1 << 12
+)
+
+// fileSize returns the size of a key file of g1N G1 and g2N G2 points, the
+// CRS in monomial form of bellman_ce:
+//
+//	[G1 count: u64] [G1 powers of τ, uncompressed] [G2 count: u64] [G2 powers of τ, uncompressed]
+//
+// The G2 powers are the generator and τG2.
+func fileSize(g1N, g2N uint64) int64 {
+	return 2*countSize + int64(g1N)*G1PointSize + int64(g2N)*G2PointSize
+}
+
+// findKey returns the key file among the files, the only one, e.g. read from
+// the standard input, or the only one of extension Ext, and the other files.
+func findKey(files []input.File) (input.File, []input.File, error) {
+	if len(files) == 1 {
+		return files[0], nil, nil
+	}
+
+	var found, others []input.File
+	for _, file := range files {
+		if strings.EqualFold(filepath.Ext(file.Name), Ext) {
+			found = append(found, file)
+		} else {
+			others = append(others, file)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return input.File{}, nil, fmt.Errorf("no %s file found", Ext)
+	case 1:
+		return found[0], others, nil
+	}
+
+	return input.File{}, nil, fmt.Errorf("several %s files found: %s and %s", Ext, found[0].Name, found[1].Name)
+}
+
+// readCount reads the number of points starting a section.
+func readCount(r io.Reader, section string) (uint64, error) {
+	var n uint64
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return 0, fmt.Errorf("failed to read the number of %s points: %w", section, err)
+	}
+	if n > maxPoints {
+		return 0, fmt.Errorf("invalid number of %s points %d", section, n)
+	}
+
+	return n, nil
+}
+
+// decodeCoordinates decodes big endian coordinates, which must be smaller than
+// the modulus: the flags of the point at infinity or of a compressed point,
+// in the most significant bits, aren't.
+func decodeCoordinates(data []byte, coordinates ...*fp.Element) error {
+	for i, c := range coordinates {
+		var err error
+		if *c, err = fp.BigEndian.Element((*[fp.Bytes]byte)(data[i*fp.Bytes:])); err != nil {
+			return fmt.Errorf("coordinate %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// readG1Points reads the G1 points in batches, checks that they are on the
+// curve and passes every batch to the verification checks. It returns the
+// number of points read before an error.
+func readG1Points(r io.Reader, points []bn254.G1Affine, checks *verify.Pipeline[bn254.G1Affine], progress *config.Progress) (int, error) {
+	buf := make([]byte, g1ReadBatch*G1PointSize)
+
+	for from := 0; from < len(points); from += g1ReadBatch {
+		batch := points[from:min(from+g1ReadBatch, len(points))]
+		data := buf[:len(batch)*G1PointSize]
+
+		if _, err := io.ReadFull(r, data); err != nil {
+			return from, fmt.Errorf("failed to read G1 points %d-%d: %w", from, from+len(batch)-1, err)
+		}
+		for i := range batch {
+			p := &batch[i]
+			if err := decodeCoordinates(data[i*G1PointSize:], &p.X, &p.Y); err != nil {
+				return from + i, fmt.Errorf("invalid G1 point %d: %w", from+i, err)
+			}
+			if !p.IsOnCurve() {
+				return from + i, fmt.Errorf("G1 point %d is not on the curve", from+i)
+			}
+		}
+
+		checks.Check(from, batch)
+		progress.Add(len(batch))
+	}
+
+	return len(points), nil
+}
+
+// readG2Powers reads the G2 section, the generator and τG2, into the
+// verifying key.
+func readG2Powers(r io.Reader, srs *bnKzg.SRS) error {
+	n, err := readCount(r, "G2")
+	if err != nil {
+		return err
+	}
+	if n != 2 {
+		return fmt.Errorf("%d G2 points, not the generator and τG2", n)
+	}
+
+	var data [2 * G2PointSize]byte
+	if _, err = io.ReadFull(r, data[:]); err != nil {
+		return fmt.Errorf("failed to read τG2: %w", err)
+	}
+	for i := range srs.Vk.G2 {
+		p := &srs.Vk.G2[i]
+		if err = decodeCoordinates(data[i*G2PointSize:], &p.X.A1, &p.X.A0, &p.Y.A1, &p.Y.A0); err != nil {
+			return fmt.Errorf("invalid G2 power %d: %w", i, err)
+		}
+		if !p.IsOnCurve() {
+			return fmt.Errorf("G2 power %d is not on the curve", i)
+		}
+	}
+	if _, _, _, g2Gen := bn254.Generators(); !srs.Vk.G2[0].Equal(&g2Gen) {
+		return errors.New("the first G2 power isn't the generator")
+	}
+	srs.Vk.Lines[0] = bn254.PrecomputeLines(srs.Vk.G2[0])
+	srs.Vk.Lines[1] = bn254.PrecomputeLines(srs.Vk.G2[1])
+
+	fmt.Printf("> a^1*G2: %s %s\n", srs.Vk.G2[1].X.String(), srs.Vk.G2[1].Y.String())
+
+	return nil
+}
+
+// TranslateBn254SRS reads a key file of the universal setup and constructs a
+// KZG SRS from its G1 powers of τ: all of them, or with opts.Degree the first
+// 2^opts.Degree ones, the others being seeked over to reach the G2 section.
+// The file is read once in its order, so it can be streamed, e.g. from the
+// standard input. The other files and the G1 points that can't be read are
+// rejected with opts.Reject: under the lenient validation the SRS ends before
+// the first point that can't be read.
+func TranslateBn254SRS(files []input.File, opts config.Options) (kzg.SRS, int, error) {
+	file, others, err := findKey(files)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, other := range others {
+		opts.Ignore(other.Name, "not a "+Ext+" file")
+		opts.Audit.Record(audit.Event{Kind: audit.KindSetupFile, File: other.Name, Decision: "ignored: not a " + Ext + " file"})
+	}
+
+	fmt.Printf("Processing file %s\n", file.Name)
+	f, err := file.Open()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open %s: %w", file.Name, err)
+	}
+	defer f.Close()
+
+	g1N, err := readCount(f, "G1")
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read %s: %w", file.Name, err)
+	}
+	// The G2 section holds the generator and τG2
+	if file.Size != input.UnknownSize && file.Size != fileSize(g1N, 2) {
+		return nil, 0, fmt.Errorf("%s holds %d bytes, not the %d of %d G1 points and 2 G2 points", file.Name, file.Size, fileSize(g1N, 2), g1N)
+	}
+	if g1N < 2 {
+		return nil, 0, fmt.Errorf("%s holds %d G1 points, less than 2", file.Name, g1N)
+	}
+	n := int(g1N)
+	if opts.Degree > 0 {
+		if 1<<opts.Degree > n {
+			return nil, 0, fmt.Errorf("%s holds %d points, less than 2^%d", file.Name, n, opts.Degree)
+		}
+		n = 1 << opts.Degree
+	}
+	fmt.Printf("> %d G1 powers\n", g1N)
+
+	srs := new(bnKzg.SRS)
+	if srs.Pk.G1, err = offheap.Make[bn254.G1Affine](n, opts); err != nil {
+		return nil, 0, err
+	}
+	opts.Progress.SetTotal(n)
+
+	var (
+		checker *verify.Bn254Checker
+		checks  *verify.Pipeline[bn254.G1Affine]
+	)
+	if opts.Verify {
+		if checker, err = verify.NewBn254Checker(); err != nil {
+			return nil, 0, err
+		}
+		checks = verify.NewPipeline(checker.Batch, opts.Workers)
+	}
+
+	// The G1 points are read in batches, without a buffer, so the ones after
+	// the points read are seeked over to reach the G2 section in a local file
+	g1Section := &io.LimitedReader{R: f, N: int64(g1N) * G1PointSize}
+	read, err := readG1Points(g1Section, srs.Pk.G1, checks, opts.Progress)
+	failedFrom := -1
+	if err != nil {
+		// The points before the first one that can't be read are kept
+		if read < 2 {
+			checks.Wait()
+			return nil, 0, fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+		if err = opts.Reject(config.Skip{File: file.Name, Offset: audit.Index(read), Points: n - read}, err); err != nil {
+			checks.Wait()
+			return nil, 0, err
+		}
+		opts.Warn("G1 points from %d can't be read: the SRS ends at G1 point %d", read, read-1)
+		failedFrom = read
+	}
+	if err = input.Skip(f, g1Section.N); err == nil {
+		err = readG2Powers(f, srs)
+	}
+	if err != nil {
+		checks.Wait()
+		return nil, 0, fmt.Errorf("failed to read %s: %w", file.Name, err)
+	}
+
+	if failedFrom >= 0 {
+		srs.Pk.G1 = srs.Pk.G1[:failedFrom]
+	}
+	opts.Audit.Record(audit.Event{Kind: audit.KindSetupFile, File: file.Name, Points: len(srs.Pk.G1), Decision: "used"})
+	srs.Vk.G1 = srs.Pk.G1[0]
+
+	if _, _, g1Gen, _ := bn254.Generators(); !srs.Vk.G1.Equal(&g1Gen) {
+		checks.Wait()
+		return nil, 0, fmt.Errorf("the first G1 power of %s isn't the generator", file.Name)
+	}
+
+	fmt.Printf("> a^1*G1: %s %s\n", srs.Pk.G1[1].X.String(), srs.Pk.G1[1].Y.String())
+
+	if checker != nil {
+		err = checks.Wait()
+		switch {
+		case failedFrom >= 0:
+			// The points were checked up to the failed one, so the fused checks
+			// don't apply
+			err = verify.SRS(srs, opts)
+		case err == nil:
+			err = checker.Finish(srs)
+		}
+		// Under the lenient validation the SRS ends before a point failing
+		if err = verify.Recover(srs, opts, err); err != nil {
+			return nil, 0, fmt.Errorf("%w: %w", verify.ErrFailed, err)
+		}
+		fmt.Println("SRS verified: all G1 points are in the subgroup and are consecutive powers of tau")
+	}
+
+	return srs, len(srs.Pk.G1), nil
+}
+
+// ExtractTauG2 returns an SRS with only the verifying key, read from the G2
+// section of the key file. The G1 points are skipped over without being
+// parsed.
+func ExtractTauG2(files []input.File) (kzg.SRS, error) {
+	file, _, err := findKey(files)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", file.Name, err)
+	}
+	defer f.Close()
+
+	g1N, err := readCount(f, "G1")
+	if err == nil {
+		err = input.Skip(f, int64(g1N)*G1PointSize)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to skip the G1 points of %s: %w", file.Name, err)
+	}
+
+	srs := new(bnKzg.SRS)
+	_, _, srs.Vk.G1, _ = bn254.Generators()
+	if err = readG2Powers(f, srs); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+	}
+
+	return srs, nil
+}